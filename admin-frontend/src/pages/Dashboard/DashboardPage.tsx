@@ -1,7 +1,7 @@
 import React, { useEffect, useState } from 'react';
 import { api } from '@/services/api';
 import { DashboardStats } from '@/types';
-import { formatCurrency, formatNumber, formatPercentage } from '@/utils/format';
+import { formatNumber, formatPercentage } from '@/utils/format';
 import { useNotifications } from '@/context/NotificationContext';
 
 interface StatCardProps {
@@ -96,6 +96,8 @@ export const DashboardPage: React.FC = () => {
         <StatCard
           title="Total Users"
           value={formatNumber(stats.totalUsers)}
+          change={`${formatPercentage(stats.growthRate)} signups vs previous 30 days`}
+          changeType={stats.growthRate >= 0 ? 'positive' : 'negative'}
           icon="👥"
         />
         <StatCard
@@ -114,11 +116,9 @@ export const DashboardPage: React.FC = () => {
           icon="💬"
         />
         <StatCard
-          title="Revenue"
-          value={formatCurrency(stats.revenue)}
-          change={`${formatPercentage(stats.growthRate)} from last month`}
-          changeType={stats.growthRate >= 0 ? 'positive' : 'negative'}
-          icon="💰"
+          title="Total Views"
+          value={formatNumber(stats.totalViews)}
+          icon="👁️"
         />
       </div>
 
@@ -32,7 +32,7 @@ interface DashboardStats {
   activeUsers: number;
   totalPosts: number;
   totalComments: number;
-  revenue: number;
+  totalViews: number;
   growthRate: number;
 }
 
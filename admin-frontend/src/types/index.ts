@@ -52,7 +52,7 @@ export interface DashboardStats {
   activeUsers: number;
   totalPosts: number;
   totalComments: number;
-  revenue: number;
+  totalViews: number;
   growthRate: number;
 }
 
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-next/internal/services"
+	"go-next/pkg/database"
+
+	"github.com/spf13/cobra"
+)
+
+var rollupFrom string
+var rollupTo string
+
+var analyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Manage dashboard analytics",
+}
+
+var analyticsRollupCmd = &cobra.Command{
+	Use:   "rollup",
+	Short: "Recompute the daily analytics rollups for a date range",
+	Long: `Recomputes the pre-aggregated daily rollups behind the dashboard analytics.
+The server refreshes today and yesterday on its own; use this to backfill
+history after a deploy or to repair a range.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := database.Setup(); err != nil {
+			log.Fatalf("Failed to setup database: %v", err)
+		}
+
+		to := time.Now().UTC()
+		from := to.AddDate(0, 0, -30)
+		var err error
+		if rollupFrom != "" {
+			if from, err = time.Parse("2006-01-02", rollupFrom); err != nil {
+				log.Fatalf("Invalid --from date: %v", err)
+			}
+		}
+		if rollupTo != "" {
+			if to, err = time.Parse("2006-01-02", rollupTo); err != nil {
+				log.Fatalf("Invalid --to date: %v", err)
+			}
+		}
+
+		if err := services.AnalyticsSvc.Rollup(context.Background(), from, to); err != nil {
+			log.Fatalf("Failed to roll up analytics: %v", err)
+		}
+
+		fmt.Printf("Rolled up analytics from %s to %s\n", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	},
+}
+
+func init() {
+	analyticsRollupCmd.Flags().StringVar(&rollupFrom, "from", "", "Start date YYYY-MM-DD (default: 30 days ago)")
+	analyticsRollupCmd.Flags().StringVar(&rollupTo, "to", "", "End date YYYY-MM-DD (default: today)")
+	analyticsCmd.AddCommand(analyticsRollupCmd)
+	rootCmd.AddCommand(analyticsCmd)
+}
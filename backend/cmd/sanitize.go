@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go-next/internal/services"
+	"go-next/pkg/database"
+
+	"github.com/spf13/cobra"
+)
+
+var sanitizeModels []string
+var sanitizeDryRun bool
+
+var sanitizeCmd = &cobra.Command{
+	Use:   "sanitize",
+	Short: "Re-sanitize stored HTML with the current policies",
+	Long: `Runs post content, html content blocks and comments through the HTML
+sanitization policies. New writes are sanitized by the services; use this
+for rows stored before sanitization existed or after a policy change.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := database.Setup(); err != nil {
+			log.Fatalf("Failed to setup database: %v", err)
+		}
+
+		for _, model := range sanitizeModels {
+			result, err := services.SanitizeSvc.Resanitize(context.Background(), model, sanitizeDryRun)
+			if err != nil {
+				log.Fatalf("Failed to sanitize %s: %v", model, err)
+			}
+			verb := "Sanitized"
+			if sanitizeDryRun {
+				verb = "Would sanitize"
+			}
+			fmt.Printf("%s %d of %d %s\n", verb, result.Changed, result.Scanned, result.Model)
+		}
+	},
+}
+
+func init() {
+	sanitizeCmd.Flags().StringSliceVar(&sanitizeModels, "model", services.SanitizeModels, "Models to sanitize: posts, contents, comments")
+	sanitizeCmd.Flags().BoolVar(&sanitizeDryRun, "dry-run", false, "Report the rows that would change without updating them")
+	rootCmd.AddCommand(sanitizeCmd)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go-next/internal/services"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/search"
+
+	"github.com/spf13/cobra"
+)
+
+var indexPath string
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Manage the embedded search index",
+}
+
+var searchReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the embedded search index from the database",
+	Long: `Drops the embedded (Bleve) search index and rebuilds it from the posts,
+categories, tags and users in the database. The index is held open by a
+running server, so stop the server before reindexing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := config.GetConfig()
+		if err := database.Setup(); err != nil {
+			log.Fatalf("Failed to setup database: %v", err)
+		}
+
+		path := indexPath
+		if path == "" {
+			path = cfg.Search.IndexPath
+		}
+
+		engine, err := search.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open search index: %v", err)
+		}
+
+		svc := services.NewSearchService(engine, path)
+		defer svc.Close()
+
+		stats, err := svc.Reindex(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to reindex: %v", err)
+		}
+
+		fmt.Printf("Reindexed %d posts, %d categories, %d tags, %d users into %s\n",
+			stats.Posts, stats.Categories, stats.Tags, stats.Users, path)
+	},
+}
+
+func init() {
+	searchReindexCmd.Flags().StringVar(&indexPath, "path", "", "Index location (default: SEARCH_INDEX_PATH)")
+	searchCmd.AddCommand(searchReindexCmd)
+	rootCmd.AddCommand(searchCmd)
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"go-next/internal/services"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var treeModels []string
+
+var treeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Check and repair the nested-set trees of categories and comments",
+}
+
+var treeCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report gaps, overlaps and orphans in the nested-set trees",
+	Long: `Compares record_left, record_right and record_dept of every node with its
+parent_id, and reports unused or reused positions, crossing intervals,
+parents that do not exist and parents that disagree with the intervals.
+Exits with status 1 when a tree has issues.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := database.Setup(); err != nil {
+			log.Fatalf("Failed to setup database: %v", err)
+		}
+
+		healthy := true
+		for _, model := range treeModels {
+			report, err := services.TreeSvc.Check(context.Background(), model)
+			if err != nil {
+				log.Fatalf("Failed to check %s tree: %v", model, err)
+			}
+			if len(report.Issues) == 0 {
+				fmt.Printf("%s: %d nodes, no issues\n", report.Model, report.Nodes)
+				continue
+			}
+			healthy = false
+			fmt.Printf("%s: %d nodes, %d issues\n", report.Model, report.Nodes, len(report.Issues))
+			for _, issue := range report.Issues {
+				if issue.ID == uuid.Nil {
+					fmt.Printf("  %-8s %s\n", issue.Kind, issue.Detail)
+				} else {
+					fmt.Printf("  %-8s %s %s\n", issue.Kind, issue.ID, issue.Detail)
+				}
+			}
+		}
+		if !healthy {
+			fmt.Println("Run `tree rebuild` to lay the trees out again from parent_id")
+			os.Exit(1)
+		}
+	},
+}
+
+var treeRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the nested-set trees from parent_id and record_ordering",
+	Long: `Recomputes record_left, record_right and record_dept of every node from
+parent_id, ordering siblings by record_ordering and creation time. Nodes whose
+parent does not exist, and one node of each parent_id cycle, become roots.
+Comments form one tree per post, each numbered from 1. Tree mutations wait
+while a rebuild runs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := database.Setup(); err != nil {
+			log.Fatalf("Failed to setup database: %v", err)
+		}
+
+		for _, model := range treeModels {
+			result, err := services.TreeSvc.Rebuild(context.Background(), model)
+			if err != nil {
+				log.Fatalf("Failed to rebuild %s tree: %v", model, err)
+			}
+			fmt.Printf("Rebuilt %s tree: updated %d of %d nodes, %d made roots\n",
+				result.Model, result.Updated, result.Nodes, result.Detached)
+		}
+	},
+}
+
+func init() {
+	treeCmd.PersistentFlags().StringSliceVar(&treeModels, "model", services.TreeModels, "Trees to process: category, comment")
+	treeCmd.AddCommand(treeCheckCmd, treeRebuildCmd)
+	rootCmd.AddCommand(treeCmd)
+}
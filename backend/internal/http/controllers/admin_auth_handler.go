@@ -58,6 +58,7 @@ func (h *adminAuthHandler) Register(c *gin.Context) {
 		return
 	}
 	var clientKey, secretKey string
+	var user models.User
 	if err := database.DB.Transaction(func(tx *gorm.DB) error {
 		roleName := req.Role
 		if roleName == "" {
@@ -68,7 +69,7 @@ func (h *adminAuthHandler) Register(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing role"})
 			return err
 		}
-		user := models.User{
+		user = models.User{
 			Username:     req.Username,
 			Email:        req.Email,
 			PasswordHash: string(hash),
@@ -103,6 +104,7 @@ func (h *adminAuthHandler) Register(c *gin.Context) {
 	}); err != nil {
 		return
 	}
+	indexUser(&user)
 	c.JSON(http.StatusCreated, responses.CommonResponse{
 		ResponseCode:    http.StatusCreated,
 		ResponseMessage: "Registration successful",
@@ -8,6 +8,7 @@ import (
 	"go-next/pkg/database"
 	"go-next/pkg/utils"
 
+	"log"
 	"time"
 
 	"net/http"
@@ -72,6 +73,7 @@ func (h *authHandler) Register(c *gin.Context) {
 		return
 	}
 	var clientKey, secretKey string
+	var user models.User
 	if err := database.DB.Transaction(func(tx *gorm.DB) error {
 		roleName := req.Role
 		if roleName == "" {
@@ -82,7 +84,7 @@ func (h *authHandler) Register(c *gin.Context) {
 			c.JSON(400, gin.H{"error": "Invalid or missing role"})
 			return err
 		}
-		user := models.User{
+		user = models.User{
 			Username:     req.Username,
 			Email:        req.Email,
 			PasswordHash: string(hash),
@@ -117,6 +119,7 @@ func (h *authHandler) Register(c *gin.Context) {
 	}); err != nil {
 		return
 	}
+	indexUser(&user)
 	c.JSON(http.StatusCreated, responses.CommonResponse{
 		ResponseCode:    http.StatusCreated,
 		ResponseMessage: "Registration successful",
@@ -138,11 +141,24 @@ func (h *authHandler) Login(c *gin.Context) {
 		c.JSON(401, gin.H{"error": "Invalid credentials"})
 		return
 	}
+	if !user.IsActive {
+		c.JSON(401, gin.H{"error": "Account is inactive"})
+		return
+	}
+	services.TokenCacheSvc.CacheUserActive(user.ID, true)
 	token, err := utils.GenerateJWT(user.ID)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to generate token"})
 		return
 	}
+	user.UpdateLastLogin()
+	if err := database.DB.Model(&user).UpdateColumn("last_login_at", user.LastLoginAt).Error; err != nil {
+		c.JSON(500, gin.H{"error": "Failed to record login"})
+		return
+	}
+	if err := services.AnalyticsSvc.RecordLogin(user.ID, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		log.Printf("Warning: failed to record login of user %s: %v", user.ID, err)
+	}
 	refreshToken, err := utils.GenerateRandomKey(32)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to generate refresh token"})
@@ -185,6 +201,10 @@ func (h *authHandler) RefreshToken(c *gin.Context) {
 		// Cache the token
 		services.TokenCacheSvc.CacheToken(tokenModel)
 	}
+	if active, err := services.TokenCacheSvc.IsUserActive(tokenModel.UserID); err != nil || !active {
+		c.JSON(401, gin.H{"error": "Account is inactive"})
+		return
+	}
 
 	// Issue new access token
 	token, err := utils.GenerateJWT(tokenModel.UserID)
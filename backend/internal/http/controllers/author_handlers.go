@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuthorHandler interface {
+	// Public author endpoints
+	GetAuthor(c *gin.Context)
+
+	// Attribution and profiles
+	SetPostAuthors(c *gin.Context)
+	GetMyAuthorProfile(c *gin.Context)
+	UpdateMyAuthorProfile(c *gin.Context)
+}
+
+type authorHandler struct {
+	AuthorService services.AuthorService
+	BlogService   services.BlogService
+}
+
+func NewAuthorHandler(authorService services.AuthorService, blogService services.BlogService) AuthorHandler {
+	return &authorHandler{AuthorService: authorService, BlogService: blogService}
+}
+
+// GetAuthor godoc
+// @Summary      Get author
+// @Description  An author page: the author's public profile and their published posts, co-authored ones included
+// @Tags         authors
+// @Produce      json
+// @Param        username  path      string true  "Author username"
+// @Param        page      query     int    false "Page number" default(1)
+// @Param        per_page  query     int    false "Items per page" default(10)
+// @Param        lang      query     string false "Locale, overriding Accept-Language"
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      404       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /blog/authors/{username} [get]
+func (h *authorHandler) GetAuthor(c *gin.Context) {
+	author, err := h.AuthorService.GetAuthorByUsername(c.Param("username"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.SendError(c, http.StatusNotFound, "Author not found")
+			return
+		}
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch author")
+		return
+	}
+
+	params := responses.ParsePaginationParams(c)
+	posts, total, err := h.BlogService.WithLocale(negotiateLocales(c)).GetPostsByAuthor(author.ID, params.Page, params.PerPage)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch posts")
+		return
+	}
+
+	responses.SendLaravelPaginationWithExtras(c, "Author retrieved successfully", posts, total, int64(params.Page), int64(params.PerPage), gin.H{
+		"author": author,
+	})
+}
+
+// SetPostAuthors godoc
+// @Summary      Set post authors (Admin only)
+// @Description  Replace the byline of a post with users in order; an empty list attributes the post to its creator
+// @Tags         authors
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      string                      true "Post ID"
+// @Param        authors body      requests.PostAuthorsRequest true "User IDs in byline order"
+// @Success      200     {array}   models.Author
+// @Failure      400     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Router       /blog/posts/{id}/authors [put]
+func (h *authorHandler) SetPostAuthors(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.PostAuthorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	authors, err := h.AuthorService.SetPostAuthors(id, req.Authors)
+	if err != nil {
+		sendAuthorError(c, err, "Failed to update post authors")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post authors updated successfully",
+		"data":    authors,
+	})
+}
+
+// GetMyAuthorProfile godoc
+// @Summary      Get my author profile
+// @Description  The author profile of the current user; empty until it is first saved
+// @Tags         authors
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.AuthorProfile
+// @Failure      401  {object}  map[string]string
+// @Router       /profile/author [get]
+func (h *authorHandler) GetMyAuthorProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.AuthorService.GetProfile(userID)
+	if err != nil {
+		sendAuthorError(c, err, "Failed to fetch author profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Author profile retrieved successfully",
+		"data":    profile,
+	})
+}
+
+// UpdateMyAuthorProfile godoc
+// @Summary      Update my author profile
+// @Description  Set the public bio, avatar and social links of the current user
+// @Tags         authors
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        profile body      requests.AuthorProfileRequest true "Author profile"
+// @Success      200     {object}  models.AuthorProfile
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Router       /profile/author [put]
+func (h *authorHandler) UpdateMyAuthorProfile(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req requests.AuthorProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	profile, err := h.AuthorService.UpdateProfile(&models.AuthorProfile{
+		UserID:      userID,
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		AvatarID:    req.AvatarID,
+		Website:     req.Website,
+		Twitter:     req.Twitter,
+		GitHub:      req.GitHub,
+		LinkedIn:    req.LinkedIn,
+		Mastodon:    req.Mastodon,
+	})
+	if err != nil {
+		sendAuthorError(c, err, "Failed to update author profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Author profile updated successfully",
+		"data":    profile,
+	})
+}
+
+// currentUserID returns the authenticated user, answering 401 when there is none
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uuid.UUID); ok {
+			return id, true
+		}
+	}
+	responses.SendError(c, http.StatusUnauthorized, "Authentication required")
+	return uuid.Nil, false
+}
+
+func sendAuthorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Post or user not found")
+	case errors.Is(err, services.ErrInvalidAuthors), errors.Is(err, services.ErrInvalidAuthorProfile):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
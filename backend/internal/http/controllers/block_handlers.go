@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BlockHandler interface {
+	GetBlocks(c *gin.Context)
+	InsertBlock(c *gin.Context)
+	UpdateBlock(c *gin.Context)
+	DeleteBlock(c *gin.Context)
+	ReorderBlocks(c *gin.Context)
+}
+
+type blockHandler struct {
+	BlockService services.BlockService
+}
+
+func NewBlockHandler(blockService services.BlockService) BlockHandler {
+	return &blockHandler{BlockService: blockService}
+}
+
+// GetBlocks godoc
+// @Summary      List post content blocks
+// @Description  Content blocks of a post in order, for the editor
+// @Tags         blocks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string true "Post ID"
+// @Success      200  {array}   models.Content
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /posts/{id}/blocks [get]
+func (h *blockHandler) GetBlocks(c *gin.Context) {
+	postID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	contents, err := h.BlockService.ListBlocks(postID)
+	if err != nil {
+		sendBlockError(c, err, "Failed to retrieve blocks")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Blocks retrieved successfully",
+		"data":    contents,
+	})
+}
+
+// InsertBlock godoc
+// @Summary      Insert a content block
+// @Description  Validate a block and insert it at a zero-based position; without a position it is appended
+// @Tags         blocks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      string                 true "Post ID"
+// @Param        block  body      requests.BlockRequest  true "Block"
+// @Success      201    {object}  models.Content
+// @Failure      400    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /posts/{id}/blocks [post]
+func (h *blockHandler) InsertBlock(c *gin.Context) {
+	postID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.BlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	content, ok := blockContent(c, req)
+	if !ok {
+		return
+	}
+
+	block := &models.Content{Type: req.Type, Content: content}
+	if err := h.BlockService.InsertBlock(postID, block, req.Position); err != nil {
+		sendBlockError(c, err, "Failed to insert block")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Block inserted successfully",
+		"data":    block,
+	})
+}
+
+// UpdateBlock godoc
+// @Summary      Update a content block
+// @Description  Replace the type and content of a block; its position is unchanged
+// @Tags         blocks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string                 true "Post ID"
+// @Param        block_id  path      string                 true "Block ID"
+// @Param        block     body      requests.BlockRequest  true "Block"
+// @Success      200       {object}  models.Content
+// @Failure      400       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /posts/{id}/blocks/{block_id} [put]
+func (h *blockHandler) UpdateBlock(c *gin.Context) {
+	postID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	blockID, ok := parseUUIDParam(c, "block_id")
+	if !ok {
+		return
+	}
+
+	var req requests.BlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	content, ok := blockContent(c, req)
+	if !ok {
+		return
+	}
+
+	block, err := h.BlockService.UpdateBlock(postID, blockID, req.Type, content)
+	if err != nil {
+		sendBlockError(c, err, "Failed to update block")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Block updated successfully",
+		"data":    block,
+	})
+}
+
+// DeleteBlock godoc
+// @Summary      Delete a content block
+// @Description  Delete a block; the blocks after it move up
+// @Tags         blocks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string true "Post ID"
+// @Param        block_id  path      string true "Block ID"
+// @Success      200       {object}  map[string]string
+// @Failure      400       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /posts/{id}/blocks/{block_id} [delete]
+func (h *blockHandler) DeleteBlock(c *gin.Context) {
+	postID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	blockID, ok := parseUUIDParam(c, "block_id")
+	if !ok {
+		return
+	}
+
+	if err := h.BlockService.DeleteBlock(postID, blockID); err != nil {
+		sendBlockError(c, err, "Failed to delete block")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Block deleted successfully"})
+}
+
+// ReorderBlocks godoc
+// @Summary      Reorder content blocks
+// @Description  Set the order of a post's blocks; the order must list every block ID exactly once
+// @Tags         blocks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      string                      true "Post ID"
+// @Param        order  body      requests.BlockOrderRequest  true "Block IDs in their new order"
+// @Success      200    {array}   models.Content
+// @Failure      400    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /posts/{id}/blocks/order [put]
+func (h *blockHandler) ReorderBlocks(c *gin.Context) {
+	postID, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.BlockOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	contents, err := h.BlockService.ReorderBlocks(postID, req.Order)
+	if err != nil {
+		sendBlockError(c, err, "Failed to reorder blocks")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Blocks reordered successfully",
+		"data":    contents,
+	})
+}
+
+// blockContent reads the content of a block request: a JSON string, or a block object for json blocks
+func blockContent(c *gin.Context, req requests.BlockRequest) (string, bool) {
+	var content string
+	if err := json.Unmarshal(req.Content, &content); err == nil {
+		return content, true
+	}
+	if req.Type == "json" && json.Valid(req.Content) {
+		return string(req.Content), true
+	}
+	responses.SendError(c, http.StatusBadRequest, "Content must be a string")
+	return "", false
+}
+
+func sendBlockError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, services.ErrInvalidBlock), errors.Is(err, services.ErrUnsupportedContentType), errors.Is(err, services.ErrBlockOrderMismatch):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
+
+func parseUUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -19,9 +20,11 @@ type BlogHandler interface {
 	GetFeaturedPosts(c *gin.Context)
 	GetRelatedPosts(c *gin.Context)
 	GetPopularPosts(c *gin.Context)
+	GetTrendingPosts(c *gin.Context)
 	GetPostsByCategory(c *gin.Context)
 	GetPostsByTag(c *gin.Context)
 	SearchPosts(c *gin.Context)
+	SearchSuggestions(c *gin.Context)
 
 	// Blog statistics
 	GetBlogStats(c *gin.Context)
@@ -45,11 +48,12 @@ type BlogHandler interface {
 }
 
 type blogHandler struct {
-	BlogService services.BlogService
+	BlogService   services.BlogService
+	SearchService services.SearchService
 }
 
-func NewBlogHandler(blogService services.BlogService) BlogHandler {
-	return &blogHandler{BlogService: blogService}
+func NewBlogHandler(blogService services.BlogService, searchService services.SearchService) BlogHandler {
+	return &blogHandler{BlogService: blogService, SearchService: searchService}
 }
 
 // GetPublicPosts godoc
@@ -61,19 +65,22 @@ func NewBlogHandler(blogService services.BlogService) BlogHandler {
 // @Param        per_page  query     int    false "Items per page" default(10)
 // @Param        search    query     string false "Search term"
 // @Param        category  query     string false "Category slug filter"
+// @Param        lang      query     string false "Locale, overriding Accept-Language"
 // @Success      200       {object}  responses.LaravelPaginationResponse
 // @Failure      500       {object}  map[string]string
 // @Router       /blog/posts [get]
 func (h *blogHandler) GetPublicPosts(c *gin.Context) {
+	blog := h.localized(c)
 	params := responses.ParsePaginationParams(c)
 	search := c.Query("search")
 	categorySlug := c.Query("category")
 
-	posts, total, err := h.BlogService.GetPublicPosts(params.Page, params.PerPage, search, categorySlug)
+	posts, total, err := blog.GetPublicPosts(params.Page, params.PerPage, search, categorySlug)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch posts")
 		return
 	}
+	attachPostReactions(c, postPointers(posts)...)
 
 	responses.SendLaravelPaginationWithMessage(c, "Posts retrieved successfully", posts, total, int64(params.Page), int64(params.PerPage))
 }
@@ -84,23 +91,29 @@ func (h *blogHandler) GetPublicPosts(c *gin.Context) {
 // @Tags         blog
 // @Produce      json
 // @Param        slug   path      string  true  "Post slug"
+// @Param        lang   query     string  false "Locale, overriding Accept-Language"
 // @Success      200    {object}  models.Post
 // @Failure      404    {object}  map[string]string
 // @Router       /blog/posts/{slug} [get]
 func (h *blogHandler) GetPublicPost(c *gin.Context) {
+	blog := h.localized(c)
 	slug := c.Param("slug")
 
-	post, err := h.BlogService.GetPublicPost(slug)
+	post, err := blog.GetPublicPost(slug)
 	if err != nil {
+		var redirect *services.SlugRedirectError
+		if errors.As(err, &redirect) {
+			sendSlugRedirect(c, redirect, slug)
+			return
+		}
 		responses.SendError(c, http.StatusNotFound, "Post not found")
 		return
 	}
 
-	// Increment view count
-	if postID, err := uuid.Parse(post.ID.String()); err == nil {
-		go h.BlogService.IncrementViewCount(postID)
-	}
+	// Record the view
+	go h.BlogService.IncrementViewCount(post.ID, viewerFromContext(c))
 
+	attachPostReactions(c, post)
 	c.JSON(http.StatusOK, post)
 }
 
@@ -110,17 +123,19 @@ func (h *blogHandler) GetPublicPost(c *gin.Context) {
 // @Tags         blog
 // @Produce      json
 // @Param        limit query int false "Number of posts" default(5)
+// @Param        lang  query string false "Locale, overriding Accept-Language"
 // @Success      200   {array}   models.Post
 // @Failure      500   {object}  map[string]string
 // @Router       /blog/posts/featured [get]
 func (h *blogHandler) GetFeaturedPosts(c *gin.Context) {
+	blog := h.localized(c)
 	limitStr := c.DefaultQuery("limit", "5")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
 		limit = 5
 	}
 
-	posts, err := h.BlogService.GetFeaturedPosts(limit)
+	posts, err := blog.GetFeaturedPosts(limit)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch featured posts")
 		return
@@ -134,20 +149,27 @@ func (h *blogHandler) GetFeaturedPosts(c *gin.Context) {
 
 // GetRelatedPosts godoc
 // @Summary      Get related posts
-// @Description  Get related posts based on category
+// @Description  Get related posts ranked by shared tags, category proximity and text similarity, falling back to the same category
 // @Tags         blog
 // @Produce      json
 // @Param        post_id path      string true  "Post ID"
 // @Param        limit   query     int    false "Number of posts" default(3)
+// @Param        lang    query     string false "Locale, overriding Accept-Language"
 // @Success      200     {array}   models.Post
 // @Failure      500     {object}  map[string]string
 // @Router       /blog/posts/{slug}/related [get]
 func (h *blogHandler) GetRelatedPosts(c *gin.Context) {
+	blog := h.localized(c)
 	slug := c.Param("slug")
 
 	// First get the post to get its ID
-	post, err := h.BlogService.GetPublicPost(slug)
+	post, err := blog.GetPublicPost(slug)
 	if err != nil {
+		var redirect *services.SlugRedirectError
+		if errors.As(err, &redirect) {
+			sendSlugRedirect(c, redirect, slug)
+			return
+		}
 		responses.SendError(c, http.StatusNotFound, "Post not found")
 		return
 	}
@@ -158,7 +180,7 @@ func (h *blogHandler) GetRelatedPosts(c *gin.Context) {
 		limit = 3
 	}
 
-	posts, err := h.BlogService.GetRelatedPosts(post.ID, limit)
+	posts, err := blog.GetRelatedPosts(post.ID, limit)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch related posts")
 		return
@@ -177,10 +199,12 @@ func (h *blogHandler) GetRelatedPosts(c *gin.Context) {
 // @Produce      json
 // @Param        limit query int false "Number of posts" default(10)
 // @Param        days  query int false "Days to look back" default(30)
+// @Param        lang  query string false "Locale, overriding Accept-Language"
 // @Success      200   {array}   models.Post
 // @Failure      500   {object}  map[string]string
 // @Router       /blog/posts/popular [get]
 func (h *blogHandler) GetPopularPosts(c *gin.Context) {
+	blog := h.localized(c)
 	limitStr := c.DefaultQuery("limit", "10")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
@@ -193,7 +217,7 @@ func (h *blogHandler) GetPopularPosts(c *gin.Context) {
 		days = 30
 	}
 
-	posts, err := h.BlogService.GetPopularPosts(limit, days)
+	posts, err := blog.GetPopularPosts(limit, days)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch popular posts")
 		return
@@ -205,6 +229,43 @@ func (h *blogHandler) GetPopularPosts(c *gin.Context) {
 	})
 }
 
+// GetTrendingPosts godoc
+// @Summary      Get trending posts
+// @Description  Get posts ranked by recent views, comments and reactions with exponential time decay
+// @Tags         blog
+// @Produce      json
+// @Param        window query string false "Trending window (24h or 7d)" default(24h)
+// @Param        limit  query int    false "Number of posts" default(10)
+// @Param        lang   query string false "Locale, overriding Accept-Language"
+// @Success      200    {array}   models.Post
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /blog/posts/trending [get]
+func (h *blogHandler) GetTrendingPosts(c *gin.Context) {
+	blog := h.localized(c)
+	window := c.DefaultQuery("window", "24h")
+	if _, ok := services.TrendingWindows[window]; !ok {
+		responses.SendError(c, http.StatusBadRequest, "Invalid window, expected 24h or 7d")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	posts, err := blog.GetTrendingPosts(window, limit)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch trending posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trending posts retrieved successfully",
+		"data":    posts,
+	})
+}
+
 // GetPostsByCategory godoc
 // @Summary      Get posts by category
 // @Description  Get posts filtered by category slug
@@ -213,14 +274,16 @@ func (h *blogHandler) GetPopularPosts(c *gin.Context) {
 // @Param        slug path      string true  "Category slug"
 // @Param        page          query     int    false "Page number" default(1)
 // @Param        per_page      query     int    false "Items per page" default(10)
+// @Param        lang          query     string false "Locale, overriding Accept-Language"
 // @Success      200           {object}  responses.LaravelPaginationResponse
 // @Failure      500           {object}  map[string]string
 // @Router       /blog/categories/{slug}/posts [get]
 func (h *blogHandler) GetPostsByCategory(c *gin.Context) {
+	blog := h.localized(c)
 	categorySlug := c.Param("slug")
 	params := responses.ParsePaginationParams(c)
 
-	posts, total, err := h.BlogService.GetPostsByCategory(categorySlug, params.Page, params.PerPage)
+	posts, total, err := blog.GetPostsByCategory(categorySlug, params.Page, params.PerPage)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch posts")
 		return
@@ -237,14 +300,16 @@ func (h *blogHandler) GetPostsByCategory(c *gin.Context) {
 // @Param        slug path      string true  "Tag slug"
 // @Param        page      query     int    false "Page number" default(1)
 // @Param        per_page  query     int    false "Items per page" default(10)
+// @Param        lang      query     string false "Locale, overriding Accept-Language"
 // @Success      200       {object}  responses.LaravelPaginationResponse
 // @Failure      500       {object}  map[string]string
 // @Router       /blog/tags/{slug}/posts [get]
 func (h *blogHandler) GetPostsByTag(c *gin.Context) {
+	blog := h.localized(c)
 	tagSlug := c.Param("slug")
 	params := responses.ParsePaginationParams(c)
 
-	posts, total, err := h.BlogService.GetPostsByTag(tagSlug, params.Page, params.PerPage)
+	posts, total, err := blog.GetPostsByTag(tagSlug, params.Page, params.PerPage)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch posts")
 		return
@@ -255,16 +320,23 @@ func (h *blogHandler) GetPostsByTag(c *gin.Context) {
 
 // SearchPosts godoc
 // @Summary      Search posts
-// @Description  Search posts by query
+// @Description  Search posts by query. When the embedded engine is enabled, results include facet counts and can be filtered by facet.
 // @Tags         blog
 // @Produce      json
 // @Param        query     query     string true  "Search query"
+// @Param        type      query     string false "Document type (post, category, tag, user)" default(post)
+// @Param        category  query     string false "Category slug facet filter"
+// @Param        tag       query     string false "Tag slug facet filter"
+// @Param        author    query     string false "Author username facet filter"
+// @Param        year      query     string false "Publish year facet filter"
 // @Param        page      query     int    false "Page number" default(1)
 // @Param        per_page  query     int    false "Items per page" default(10)
+// @Param        lang      query     string false "Locale, overriding Accept-Language"
 // @Success      200       {object}  responses.LaravelPaginationResponse
 // @Failure      500       {object}  map[string]string
 // @Router       /blog/search [get]
 func (h *blogHandler) SearchPosts(c *gin.Context) {
+	locales := negotiateLocales(c)
 	query := c.Query("query")
 	if query == "" {
 		responses.SendError(c, http.StatusBadRequest, "Search query is required")
@@ -273,7 +345,30 @@ func (h *blogHandler) SearchPosts(c *gin.Context) {
 
 	params := responses.ParsePaginationParams(c)
 
-	posts, total, err := h.BlogService.SearchPosts(query, params.Page, params.PerPage)
+	if h.SearchService != nil && h.SearchService.Enabled() {
+		result, err := h.SearchService.Search(services.SearchRequest{
+			Query:    query,
+			Type:     c.Query("type"),
+			Category: c.Query("category"),
+			Tag:      c.Query("tag"),
+			Author:   c.Query("author"),
+			Year:     c.Query("year"),
+			Page:     params.Page,
+			PerPage:  params.PerPage,
+			Locales:  locales,
+		})
+		if err != nil {
+			responses.SendError(c, http.StatusInternalServerError, "Failed to search posts")
+			return
+		}
+
+		responses.SendLaravelPaginationWithExtras(c, "Search results retrieved successfully", result.Data, result.Total, int64(params.Page), int64(params.PerPage), gin.H{
+			"facets": result.Facets,
+		})
+		return
+	}
+
+	posts, total, err := h.BlogService.WithLocale(locales).SearchPosts(query, params.Page, params.PerPage)
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to search posts")
 		return
@@ -282,6 +377,41 @@ func (h *blogHandler) SearchPosts(c *gin.Context) {
 	responses.SendLaravelPaginationWithMessage(c, "Search results retrieved successfully", posts, total, int64(params.Page), int64(params.PerPage))
 }
 
+// SearchSuggestions godoc
+// @Summary      Search suggestions
+// @Description  Type-ahead prefix matches across post titles, tags and categories ranked by views, with a "did you mean" correction from the search index, or from published titles, tags and categories without it
+// @Tags         blog
+// @Produce      json
+// @Param        query  query     string true  "Partial search query"
+// @Param        limit  query     int    false "Matches per group" default(5)
+// @Success      200    {object}  services.Suggestions
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /blog/search/suggest [get]
+func (h *blogHandler) SearchSuggestions(c *gin.Context) {
+	query := c.Query("query")
+	if query == "" {
+		responses.SendError(c, http.StatusBadRequest, "Search query is required")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil || limit <= 0 {
+		limit = 5
+	}
+
+	suggestions, err := h.SearchService.Suggest(query, limit)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch search suggestions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Search suggestions retrieved successfully",
+		"data":    suggestions,
+	})
+}
+
 // GetBlogStats godoc
 // @Summary      Get blog statistics
 // @Description  Get overall blog statistics
@@ -350,11 +480,13 @@ func (h *blogHandler) GetMonthlyArchive(c *gin.Context) {
 // @Description  Get active categories for public viewing
 // @Tags         blog
 // @Produce      json
+// @Param        lang  query     string  false  "Locale, overriding Accept-Language"
 // @Success      200  {array}   models.Category
 // @Failure      500  {object}  map[string]string
 // @Router       /blog/categories [get]
 func (h *blogHandler) GetPublicCategories(c *gin.Context) {
-	categories, err := h.BlogService.GetPublicCategories()
+	blog := h.localized(c)
+	categories, err := blog.GetPublicCategories()
 	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch categories")
 		return
@@ -393,14 +525,21 @@ func (h *blogHandler) GetPublicTags(c *gin.Context) {
 // @Tags         blog
 // @Produce      json
 // @Param        slug path      string true "Category slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
 // @Success      200  {object}  models.Category
 // @Failure      404  {object}  map[string]string
 // @Router       /blog/categories/{slug} [get]
 func (h *blogHandler) GetCategoryBySlug(c *gin.Context) {
+	blog := h.localized(c)
 	slug := c.Param("slug")
 
-	category, err := h.BlogService.GetCategoryBySlug(slug)
+	category, err := blog.GetCategoryBySlug(slug)
 	if err != nil {
+		var redirect *services.SlugRedirectError
+		if errors.As(err, &redirect) {
+			sendSlugRedirect(c, redirect, slug)
+			return
+		}
 		responses.SendError(c, http.StatusNotFound, "Category not found")
 		return
 	}
@@ -458,6 +597,10 @@ func (h *blogHandler) CreatePost(c *gin.Context) {
 	}
 
 	if err := h.BlogService.CreatePost(&post); err != nil {
+		if errors.Is(err, services.ErrUnsupportedLocale) {
+			responses.SendError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		responses.SendError(c, http.StatusInternalServerError, "Failed to create post")
 		return
 	}
@@ -497,6 +640,10 @@ func (h *blogHandler) UpdatePost(c *gin.Context) {
 	}
 
 	if err := h.BlogService.UpdatePost(&post); err != nil {
+		if errors.Is(err, services.ErrUnsupportedLocale) {
+			responses.SendError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		responses.SendError(c, http.StatusInternalServerError, "Failed to update post")
 		return
 	}
@@ -589,11 +736,11 @@ func (h *blogHandler) ArchivePost(c *gin.Context) {
 }
 
 // IncrementViewCount godoc
-// @Summary      Increment view count
-// @Description  Increment the view count for a post
+// @Summary      Record a post view
+// @Description  Record a view for a post. Bots are ignored and each visitor is counted once per day.
 // @Tags         blog
 // @Param        id   path  string true "Post ID"
-// @Success      200  {object}  map[string]string
+// @Success      200  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]string
 // @Router       /blog/posts/{id}/view [post]
 func (h *blogHandler) IncrementViewCount(c *gin.Context) {
@@ -604,10 +751,43 @@ func (h *blogHandler) IncrementViewCount(c *gin.Context) {
 		return
 	}
 
-	if err := h.BlogService.IncrementViewCount(postID); err != nil {
+	counted, err := h.BlogService.IncrementViewCount(postID, viewerFromContext(c))
+	if err != nil {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to increment view count")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "View count incremented successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "View recorded successfully", "counted": counted})
+}
+
+// localized scopes the blog service to the locale negotiated for the request
+func (h *blogHandler) localized(c *gin.Context) services.BlogService {
+	return h.BlogService.WithLocale(negotiateLocales(c))
+}
+
+// negotiateLocales picks the locale of a request from ?lang= or Accept-Language
+// and returns it with its fallbacks. Sites with a single locale are not filtered.
+func negotiateLocales(c *gin.Context) []string {
+	locales := services.SiteLocales()
+	tag := locales.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", tag)
+	c.Header("Vary", "Accept-Language")
+	if len(locales.Supported()) < 2 {
+		return nil
+	}
+	return locales.Chain(tag)
+}
+
+// viewerFromContext identifies the visitor of the current request
+func viewerFromContext(c *gin.Context) services.Visitor {
+	visitor := services.Visitor{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uuid.UUID); ok {
+			visitor.UserID = &id
+		}
+	}
+	return visitor
 }
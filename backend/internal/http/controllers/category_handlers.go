@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"go-next/internal/http/requests"
 	"go-next/internal/http/responses"
 	"net/http"
@@ -125,6 +126,7 @@ func (h *categoryHandler) CreateCategory(c *gin.Context) {
 	category := models.Category{
 		Name:        reqParams.Name,
 		Description: reqParams.Description,
+		Locale:      reqParams.Locale,
 	}
 	if reqParams.ParentID != nil {
 		category.ParentID = reqParams.ParentID
@@ -137,6 +139,10 @@ func (h *categoryHandler) CreateCategory(c *gin.Context) {
 			return
 		}
 		if err := h.CategoryService.CreateCategory(&category); err != nil {
+			if errors.Is(err, services.ErrUnsupportedLocale) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
 			return
 		}
@@ -148,6 +154,10 @@ func (h *categoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 	if err := h.CategoryService.CreateCategory(&category); err != nil {
+		if errors.Is(err, services.ErrUnsupportedLocale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
 		return
 	}
@@ -228,6 +238,10 @@ func (h *categoryHandler) CreateCategoryNested(c *gin.Context) {
 		}
 	}
 	if err := h.CategoryService.CreateNested(&category, parentID); err != nil {
+		if errors.Is(err, services.ErrUnsupportedLocale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category (nested)"})
 		return
 	}
@@ -259,6 +273,10 @@ func (h *categoryHandler) MoveCategoryNested(c *gin.Context) {
 		}
 	}
 	if err := h.CategoryService.MoveNested(id, parentID); err != nil {
+		if errors.Is(err, services.ErrInvalidTreeParent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move category (nested)"})
 		return
 	}
@@ -1,16 +1,19 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"go-next/internal/http/requests"
 	"go-next/internal/http/responses"
 	"go-next/internal/models"
 	"go-next/internal/services"
-	"go-next/pkg/database"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type CommentHandler interface {
@@ -37,48 +40,62 @@ func NewCommentHandler(commentService services.CommentService) CommentHandler {
 }
 
 // GetCommentsByPost godoc
-// @Summary      List comments for a post
-// @Description  Get all comments for a specific post with pagination
+// @Summary      Get the discussion of a post
+// @Description  Get the approved comments of a post as a tree. Each comment nests up to depth levels of replies; more_replies and next_cursor are cursors that load the rest of a branch or level.
 // @Tags         comments
 // @Produce      json
-// @Param        post_id   path      string true  "Post ID"
-// @Param        page      query     int    false "Page number"
-// @Param        per_page  query     int    false "Items per page"
-// @Success      200       {object}  responses.LaravelPaginationResponse
-// @Failure      500       {object}  map[string]string
-// @Router       /posts/{post_id}/comments [get]
+// @Param        id       path      string true  "Post ID"
+// @Param        sort     query     string false "oldest, newest or top" default(oldest)
+// @Param        depth    query     int    false "Levels of nested replies" default(3)
+// @Param        limit    query     int    false "Top-level comments per page" default(20)
+// @Param        replies  query     int    false "Replies shown per comment" default(5)
+// @Param        cursor   query     string false "Cursor from next_cursor or more_replies"
+// @Success      200      {object}  models.CommentThread
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /posts/{id}/comments [get]
 func (h *commentHandler) GetCommentsByPost(c *gin.Context) {
-	postID := c.Param("post_id")
-	params := responses.ParsePaginationParams(c)
-
-	// Parse post ID
-	parsedPostID, err := uuid.Parse(postID)
-	if err != nil {
-		responses.SendError(c, http.StatusBadRequest, "Invalid post ID")
+	postID, ok := parseUUIDParam(c, "id")
+	if !ok {
 		return
 	}
 
-	offset := (params.Page - 1) * params.PerPage
-
-	var comments []models.Comment
-	var total int64
-
-	query := database.DB.Model(&models.Comment{}).Where("post_id = ?", parsedPostID).Preload("User")
-
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		responses.SendError(c, http.StatusInternalServerError, "Failed to count comments")
-		return
+	opts := services.CommentThreadOptions{
+		Sort:    c.Query("sort"),
+		Depth:   services.DefaultCommentDepth,
+		Limit:   queryInt(c, "limit"),
+		Replies: queryInt(c, "replies"),
+		Cursor:  c.Query("cursor"),
+	}
+	if depth := c.Query("depth"); depth != "" {
+		parsed, err := strconv.Atoi(depth)
+		if err != nil {
+			responses.SendError(c, http.StatusBadRequest, "Invalid depth")
+			return
+		}
+		opts.Depth = parsed
 	}
 
-	// Get paginated comments
-	if err := query.Offset(offset).Limit(params.PerPage).Order("created_at DESC").Find(&comments).Error; err != nil {
-		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch comments")
+	thread, err := h.CommentService.GetThread(postID, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			responses.SendError(c, http.StatusNotFound, "Post not found")
+		case errors.Is(err, services.ErrInvalidCommentThread):
+			responses.SendError(c, http.StatusBadRequest, err.Error())
+		default:
+			responses.SendError(c, http.StatusInternalServerError, "Failed to fetch comments")
+		}
 		return
 	}
 
-	// Send Laravel-style pagination response
-	responses.SendLaravelPaginationWithMessage(c, "Comments retrieved successfully", comments, total, int64(params.Page), int64(params.PerPage))
+	attachThreadReactions(c, thread)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comments retrieved successfully",
+		"data":    thread,
+	})
 }
 
 // GetComment godoc
@@ -92,26 +109,33 @@ func (h *commentHandler) GetCommentsByPost(c *gin.Context) {
 // @Router       /comments/{id} [get]
 func (h *commentHandler) GetComment(c *gin.Context) {
 	id := c.Param("id")
-	comment, err := h.CommentService.GetCommentByID(id)
+	comment, err := h.CommentService.GetPublicComment(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
 	}
+	attachCommentReactions(c, comment)
 	c.JSON(http.StatusOK, comment)
 }
 
 // CreateComment godoc
 // @Summary      Create comment
-// @Description  Create a new comment
+// @Description  Create a new comment by the signed-in user
 // @Tags         comments
 // @Accept       json
 // @Produce      json
-// @Param        comment  body      models.Comment  true  "Comment to create"
+// @Security     BearerAuth
+// @Param        comment  body      requests.CommentCreateRequest  true  "Comment to create"
 // @Success      201   {object}  models.Comment
 // @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /comments [post]
 func (h *commentHandler) CreateComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 	var input requests.CommentCreateRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
@@ -119,9 +143,10 @@ func (h *commentHandler) CreateComment(c *gin.Context) {
 	}
 	comment := models.Comment{
 		Content: input.Content,
-		UserID:  input.UserID,
+		UserID:  userID,
 		PostID:  input.PostID,
 	}
+	comment.Submission = commentSubmission(c, input.Website, input.RenderedAt)
 	if err := h.CommentService.CreateComment(&comment); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
 		return
@@ -131,32 +156,42 @@ func (h *commentHandler) CreateComment(c *gin.Context) {
 
 // UpdateComment godoc
 // @Summary      Update comment
-// @Description  Update an existing comment
+// @Description  Edit the content of a comment by its author. The edit is moderated again, so an approved comment can return to the queue.
 // @Tags         comments
 // @Accept       json
 // @Produce      json
-// @Param        id       path      int            true  "Comment ID"
-// @Param        comment  body      models.Comment true  "Comment to update"
+// @Security     BearerAuth
+// @Param        id       path      string                         true  "Comment ID"
+// @Param        comment  body      requests.CommentUpdateRequest  true  "Comment to update"
 // @Success      200   {object}  models.Comment
 // @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
 // @Failure      404   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /comments/{id} [put]
 func (h *commentHandler) UpdateComment(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
 	id := c.Param("id")
 	comment, err := h.CommentService.GetCommentByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
 	}
+	if comment.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit a comment"})
+		return
+	}
 	var input requests.CommentUpdateRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
 	comment.Content = input.Content
-	comment.UserID = input.UserID
-	comment.PostID = input.PostID
+	comment.Submission = commentSubmission(c, "", 0)
 	if err := h.CommentService.UpdateComment(comment); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
 		return
@@ -183,29 +218,45 @@ func (h *commentHandler) DeleteComment(c *gin.Context) {
 
 // CreateCommentNested godoc
 // @Summary      Create comment (nested)
-// @Description  Create a new comment as root or as a child (nested set)
+// @Description  Create a new comment by the signed-in user as root or as a child (nested set)
 // @Tags         comments
 // @Accept       json
 // @Produce      json
-// @Param        comment   body      models.Comment  true  "Comment to create"
-// @Param        parent_id query     int             false "Parent comment ID"
+// @Security     BearerAuth
+// @Param        comment   body      requests.CommentNestedCreateRequest  true  "Comment to create"
+// @Param        parent_id query     string                               false "Parent comment ID"
 // @Success      201   {object}  models.Comment
 // @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
 // @Failure      500   {object}  map[string]string
 // @Router       /comments/nested [post]
 func (h *commentHandler) CreateCommentNested(c *gin.Context) {
-	var comment models.Comment
-	if err := c.ShouldBindJSON(&comment); err != nil {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var input requests.CommentNestedCreateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	comment := models.Comment{
+		Content: input.Content,
+		UserID:  userID,
+		PostID:  input.PostID,
+	}
 	var parentID *uuid.UUID
 	if pid := c.Query("parent_id"); pid != "" {
 		if parsed, err := uuid.Parse(pid); err == nil {
 			parentID = &parsed
 		}
 	}
+	comment.Submission = commentSubmission(c, input.Website, input.RenderedAt)
 	if err := h.CommentService.CreateNested(&comment, parentID); err != nil {
+		if errors.Is(err, services.ErrInvalidTreeParent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment (nested)"})
 		return
 	}
@@ -239,6 +290,10 @@ func (h *commentHandler) MoveCommentNested(c *gin.Context) {
 		}
 	}
 	if err := h.CommentService.MoveNested(id, parentID); err != nil {
+		if errors.Is(err, services.ErrInvalidTreeParent) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move comment (nested)"})
 		return
 	}
@@ -284,6 +339,10 @@ func (h *commentHandler) GetSiblingComments(c *gin.Context) {
 		return
 	}
 	siblings, err := h.CommentService.GetSiblingComments(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch siblings"})
 		return
@@ -308,6 +367,10 @@ func (h *commentHandler) GetParentComment(c *gin.Context) {
 		return
 	}
 	parent, err := h.CommentService.GetParentComment(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch parent"})
 		return
@@ -336,6 +399,10 @@ func (h *commentHandler) GetDescendantComments(c *gin.Context) {
 		return
 	}
 	descendants, err := h.CommentService.GetDescendantComments(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch descendants"})
 		return
@@ -360,9 +427,33 @@ func (h *commentHandler) GetChildrenComments(c *gin.Context) {
 		return
 	}
 	children, err := h.CommentService.GetChildrenComments(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch children"})
 		return
 	}
 	c.JSON(http.StatusOK, children)
 }
+
+// commentSubmission records how a comment was sent, for spam screening
+func commentSubmission(c *gin.Context, honeypot string, renderedAt int64) *models.CommentSubmission {
+	submission := &models.CommentSubmission{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Honeypot:  honeypot,
+	}
+	if renderedAt > 0 {
+		t := time.UnixMilli(renderedAt)
+		submission.RenderedAt = &t
+	}
+	return submission
+}
+
+// queryInt reads an optional integer query parameter; anything else reads as 0
+func queryInt(c *gin.Context, name string) int {
+	value, _ := strconv.Atoi(c.Query(name))
+	return value
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CommentModerationHandler interface {
+	GetModerationQueue(c *gin.Context)
+	ModerateComments(c *gin.Context)
+	GetCommentRules(c *gin.Context)
+	SetCommentRule(c *gin.Context)
+	DeleteCommentRule(c *gin.Context)
+}
+
+type commentModerationHandler struct {
+	ModerationService services.CommentModerationService
+}
+
+func NewCommentModerationHandler(moderationService services.CommentModerationService) CommentModerationHandler {
+	return &commentModerationHandler{ModerationService: moderationService}
+}
+
+// GetModerationQueue godoc
+// @Summary      Comment moderation queue (Admin only)
+// @Description  List comments oldest first, pending ones by default
+// @Tags         comments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query     string false "pending, approved, rejected, spam or all" default(pending)
+// @Param        post_id   query     string false "Post ID filter"
+// @Param        user_id   query     string false "Author ID filter"
+// @Param        page      query     int    false "Page number"
+// @Param        per_page  query     int    false "Items per page"
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      400       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /admin/comments [get]
+func (h *commentModerationHandler) GetModerationQueue(c *gin.Context) {
+	params := responses.ParsePaginationParams(c)
+
+	postID, ok := parseOptionalUUIDQuery(c, "post_id")
+	if !ok {
+		return
+	}
+	userID, ok := parseOptionalUUIDQuery(c, "user_id")
+	if !ok {
+		return
+	}
+	filter := services.CommentFilter{Status: c.Query("status"), PostID: postID, UserID: userID}
+
+	comments, total, err := h.ModerationService.GetQueue(filter, params.Page, params.PerPage)
+	if err != nil {
+		sendModerationError(c, err, "Failed to fetch comments")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Comments retrieved successfully", comments, total, int64(params.Page), int64(params.PerPage))
+}
+
+// ModerateComments godoc
+// @Summary      Moderate comments (Admin only)
+// @Description  Approve, reject or mark as spam up to 100 comments at once
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        moderation body      requests.CommentModerationRequest true "Comment IDs and action"
+// @Success      200        {array}   models.Comment
+// @Failure      400        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Router       /admin/comments/moderate [post]
+func (h *commentModerationHandler) ModerateComments(c *gin.Context) {
+	var req requests.CommentModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var moderatorID *uuid.UUID
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uuid.UUID); ok {
+			moderatorID = &id
+		}
+	}
+
+	comments, err := h.ModerationService.Moderate(req.IDs, req.Action, moderatorID)
+	if err != nil {
+		sendModerationError(c, err, "Failed to moderate comments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comments moderated successfully",
+		"data":    comments,
+	})
+}
+
+// GetCommentRules godoc
+// @Summary      List comment rules (Admin only)
+// @Description  The moderation mode of each category with a rule; other categories use trusted
+// @Tags         comments
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   models.CommentRule
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/comment-rules [get]
+func (h *commentModerationHandler) GetCommentRules(c *gin.Context) {
+	rules, err := h.ModerationService.GetRules()
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch comment rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment rules retrieved successfully",
+		"data":    rules,
+	})
+}
+
+// SetCommentRule godoc
+// @Summary      Set comment rule (Admin only)
+// @Description  Set how new comments on the posts of a category are moderated: auto approves all, trusted approves trusted commenters, manual queues all
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        category_id path      string                      true "Category ID"
+// @Param        rule        body      requests.CommentRuleRequest true "Moderation mode"
+// @Success      200         {object}  models.CommentRule
+// @Failure      400         {object}  map[string]string
+// @Failure      404         {object}  map[string]string
+// @Router       /admin/comment-rules/{category_id} [put]
+func (h *commentModerationHandler) SetCommentRule(c *gin.Context) {
+	categoryID, ok := parseUUIDParam(c, "category_id")
+	if !ok {
+		return
+	}
+
+	var req requests.CommentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rule, err := h.ModerationService.SetRule(categoryID, req.Mode)
+	if err != nil {
+		sendModerationError(c, err, "Failed to save comment rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Comment rule saved successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteCommentRule godoc
+// @Summary      Delete comment rule (Admin only)
+// @Description  Return a category to the default trusted mode
+// @Tags         comments
+// @Security     BearerAuth
+// @Param        category_id path  string true "Category ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/comment-rules/{category_id} [delete]
+func (h *commentModerationHandler) DeleteCommentRule(c *gin.Context) {
+	categoryID, ok := parseUUIDParam(c, "category_id")
+	if !ok {
+		return
+	}
+
+	if err := h.ModerationService.DeleteRule(categoryID); err != nil {
+		sendModerationError(c, err, "Failed to delete comment rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parseOptionalUUIDQuery parses an optional UUID query parameter, answering 400 when it is malformed
+func parseOptionalUUIDQuery(c *gin.Context, name string) (*uuid.UUID, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid "+name)
+		return nil, false
+	}
+	return &id, true
+}
+
+func sendModerationError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Comment, category or rule not found")
+	case errors.Is(err, services.ErrInvalidModerationAction),
+		errors.Is(err, services.ErrInvalidCommentFilter),
+		errors.Is(err, services.ErrInvalidCommentRule):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ContentHandler interface {
+	GetRenderedPost(c *gin.Context)
+	RenderContent(c *gin.Context)
+}
+
+type contentHandler struct {
+	RenderService services.RenderService
+}
+
+func NewContentHandler(renderService services.RenderService) ContentHandler {
+	return &contentHandler{RenderService: renderService}
+}
+
+// GetRenderedPost godoc
+// @Summary      Get rendered post content
+// @Description  HTML of a public post's content blocks with table of contents, word count and reading time
+// @Tags         content
+// @Produce      json
+// @Param        slug path      string true "Post slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
+// @Success      200  {object}  services.RenderedPost
+// @Failure      301  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /blog/posts/{slug}/rendered [get]
+func (h *contentHandler) GetRenderedPost(c *gin.Context) {
+	slug := c.Param("slug")
+
+	rendered, err := h.RenderService.RenderPost(c.Request.Context(), slug, negotiateLocales(c))
+	if err != nil {
+		var redirect *services.SlugRedirectError
+		if errors.As(err, &redirect) {
+			sendSlugRedirect(c, redirect, slug)
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.SendError(c, http.StatusNotFound, "Post not found")
+			return
+		}
+		responses.SendError(c, http.StatusInternalServerError, "Failed to render post")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Post rendered successfully",
+		"data":    rendered,
+	})
+}
+
+// RenderContent godoc
+// @Summary      Preview rendered content
+// @Description  Render a content block as it will be published, for editor previews
+// @Tags         content
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        content body      requests.ContentRenderRequest true "Content to render"
+// @Success      200     {object}  markdown.Result
+// @Failure      400     {object}  map[string]string
+// @Failure      500     {object}  map[string]string
+// @Router       /content/render [post]
+func (h *contentHandler) RenderContent(c *gin.Context) {
+	var req requests.ContentRenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.RenderService.Render(c.Request.Context(), req.Type, req.Content)
+	if err != nil {
+		if errors.Is(err, services.ErrUnsupportedContentType) || errors.Is(err, services.ErrInvalidBlock) {
+			responses.SendError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		responses.SendError(c, http.StatusInternalServerError, "Failed to render content")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Content rendered successfully",
+		"data":    result,
+	})
+}
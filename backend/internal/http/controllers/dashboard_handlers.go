@@ -1,21 +1,30 @@
 package controllers
 
 import (
-	"net/http"
+	"errors"
 	"go-next/internal/models"
+	"go-next/internal/services"
 	"go-next/pkg/database"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type DashboardHandler interface {
 	GetDashboardStats(c *gin.Context)
+	GetAnalytics(c *gin.Context)
+	GetPostViewHistory(c *gin.Context)
 }
 
-type dashboardHandler struct{}
+type dashboardHandler struct {
+	ViewService      services.ViewService
+	AnalyticsService services.AnalyticsService
+}
 
-func NewDashboardHandler() DashboardHandler {
-	return &dashboardHandler{}
+func NewDashboardHandler(viewService services.ViewService, analyticsService services.AnalyticsService) DashboardHandler {
+	return &dashboardHandler{ViewService: viewService, AnalyticsService: analyticsService}
 }
 
 // GetDashboardStats godoc
@@ -31,6 +40,7 @@ func (h *dashboardHandler) GetDashboardStats(c *gin.Context) {
 	var totalPosts int64
 	var totalComments int64
 	var activeUsers int64
+	var totalViews int64
 
 	// Get total users
 	if err := database.DB.Model(&models.User{}).Count(&totalUsers).Error; err != nil {
@@ -50,25 +60,129 @@ func (h *dashboardHandler) GetDashboardStats(c *gin.Context) {
 		return
 	}
 
-	// Get active users (users with recent activity - simplified for now)
-	// In a real implementation, you might track user activity timestamps
-	if err := database.DB.Model(&models.User{}).Count(&activeUsers).Error; err != nil {
+	// Active users logged in within the last 30 days
+	now := time.Now()
+	if err := database.DB.Model(&models.User{}).Where("last_login_at >= ?", now.AddDate(0, 0, -30)).Count(&activeUsers).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count active users"})
 		return
 	}
 
-	// Calculate growth rate (simplified - in real app you'd compare with previous period)
-	growthRate := 12.5 // Mock growth rate
+	if err := database.DB.Model(&models.Post{}).Select("COALESCE(SUM(view_count), 0)").Scan(&totalViews).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count views"})
+		return
+	}
 
-	// Calculate revenue (mock data for now)
-	revenue := 45678.90
+	// Growth rate compares signups of the last 30 days with the 30 days before
+	report, err := h.AnalyticsService.GetReport(now.AddDate(0, 0, -29), now, services.AnalyticsIntervalMonth)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load analytics"})
+		return
+	}
+	growthRate := 0.0
+	if change := report.Metrics[models.MetricSignups].Change; change != nil {
+		growthRate = *change
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"totalUsers":    totalUsers,
 		"activeUsers":   activeUsers,
 		"totalPosts":    totalPosts,
 		"totalComments": totalComments,
-		"revenue":       revenue,
+		"totalViews":    totalViews,
 		"growthRate":    growthRate,
 	})
 }
+
+// GetAnalytics godoc
+// @Summary      Get dashboard analytics
+// @Description  Get signups, logins, published posts, comments and views per period, compared with the previous period of the same length
+// @Tags         dashboard
+// @Produce      json
+// @Param        from      query     string false "Start date (YYYY-MM-DD), defaults to 29 days ago"
+// @Param        to        query     string false "End date (YYYY-MM-DD), defaults to today"
+// @Param        interval  query     string false "Grouping: day, week or month" default(day)
+// @Success      200       {object}  services.AnalyticsReport
+// @Failure      400       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /dashboard/analytics [get]
+func (h *dashboardHandler) GetAnalytics(c *gin.Context) {
+	from, to, ok := parseDateRange(c, 29)
+	if !ok {
+		return
+	}
+
+	report, err := h.AnalyticsService.GetReport(from, to, c.DefaultQuery("interval", services.AnalyticsIntervalDay))
+	if errors.Is(err, services.ErrInvalidAnalyticsInterval) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval, expected day, week or month"})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidAnalyticsRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date range"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Analytics retrieved successfully",
+		"data":    report,
+	})
+}
+
+// parseDateRange reads the from/to query dates, defaulting to the last days days
+func parseDateRange(c *gin.Context, days int) (time.Time, time.Time, bool) {
+	var err error
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -days)
+	if value := c.Query("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+			return from, to, false
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+			return from, to, false
+		}
+	}
+	return from, to, true
+}
+
+// GetPostViewHistory godoc
+// @Summary      Get post view history
+// @Description  Get the daily views and unique visitors of a post
+// @Tags         dashboard
+// @Produce      json
+// @Param        id    path      string true  "Post ID"
+// @Param        from  query     string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param        to    query     string false "End date (YYYY-MM-DD), defaults to today"
+// @Success      200   {array}   models.PostViewDaily
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /dashboard/posts/{id}/views [get]
+func (h *dashboardHandler) GetPostViewHistory(c *gin.Context) {
+	postID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	from, to, ok := parseDateRange(c, 30)
+	if !ok {
+		return
+	}
+
+	history, err := h.ViewService.GetViewHistory(postID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch view history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "View history retrieved successfully",
+		"data":    history,
+	})
+}
@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+	"go-next/pkg/feed"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// feedMaxAge is how long clients and proxies may cache a feed
+const feedMaxAge = 10 * time.Minute
+
+type FeedHandler interface {
+	GetRSSFeed(c *gin.Context)
+	GetAtomFeed(c *gin.Context)
+	GetJSONFeed(c *gin.Context)
+	GetCategoryRSSFeed(c *gin.Context)
+	GetCategoryAtomFeed(c *gin.Context)
+	GetCategoryJSONFeed(c *gin.Context)
+	GetTagRSSFeed(c *gin.Context)
+	GetTagAtomFeed(c *gin.Context)
+	GetTagJSONFeed(c *gin.Context)
+	GetSeriesRSSFeed(c *gin.Context)
+	GetSeriesAtomFeed(c *gin.Context)
+	GetSeriesJSONFeed(c *gin.Context)
+	GetAuthorRSSFeed(c *gin.Context)
+	GetAuthorAtomFeed(c *gin.Context)
+	GetAuthorJSONFeed(c *gin.Context)
+}
+
+type feedHandler struct {
+	FeedService services.FeedService
+}
+
+func NewFeedHandler(feedService services.FeedService) FeedHandler {
+	return &feedHandler{FeedService: feedService}
+}
+
+// feedFormat renders a feed in one syndication format
+type feedFormat struct {
+	contentType string
+	render      func(f *feed.Feed) ([]byte, error)
+}
+
+var (
+	rssFormat  = feedFormat{"application/rss+xml; charset=utf-8", (*feed.Feed).RSS}
+	atomFormat = feedFormat{"application/atom+xml; charset=utf-8", (*feed.Feed).Atom}
+	jsonFormat = feedFormat{"application/feed+json; charset=utf-8", (*feed.Feed).JSON}
+)
+
+// GetRSSFeed godoc
+// @Summary      Blog RSS feed
+// @Description  RSS 2.0 feed of the latest public posts
+// @Tags         feeds
+// @Produce      xml
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Router       /feed.xml [get]
+func (h *feedHandler) GetRSSFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeAll, rssFormat)
+}
+
+// GetAtomFeed godoc
+// @Summary      Blog Atom feed
+// @Description  Atom 1.0 feed of the latest public posts
+// @Tags         feeds
+// @Produce      xml
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Router       /atom.xml [get]
+func (h *feedHandler) GetAtomFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeAll, atomFormat)
+}
+
+// GetJSONFeed godoc
+// @Summary      Blog JSON Feed
+// @Description  JSON Feed 1.1 of the latest public posts
+// @Tags         feeds
+// @Produce      json
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Router       /feed.json [get]
+func (h *feedHandler) GetJSONFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeAll, jsonFormat)
+}
+
+// GetCategoryRSSFeed godoc
+// @Summary      Category RSS feed
+// @Description  RSS 2.0 feed of the latest public posts in a category
+// @Tags         feeds
+// @Produce      xml
+// @Param        slug    path  string true  "Category slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/categories/{slug}/feed.xml [get]
+func (h *feedHandler) GetCategoryRSSFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeCategory, rssFormat)
+}
+
+// GetCategoryAtomFeed godoc
+// @Summary      Category Atom feed
+// @Description  Atom 1.0 feed of the latest public posts in a category
+// @Tags         feeds
+// @Produce      xml
+// @Param        slug    path  string true  "Category slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/categories/{slug}/atom.xml [get]
+func (h *feedHandler) GetCategoryAtomFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeCategory, atomFormat)
+}
+
+// GetCategoryJSONFeed godoc
+// @Summary      Category JSON Feed
+// @Description  JSON Feed 1.1 of the latest public posts in a category
+// @Tags         feeds
+// @Produce      json
+// @Param        slug    path  string true  "Category slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/categories/{slug}/feed.json [get]
+func (h *feedHandler) GetCategoryJSONFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeCategory, jsonFormat)
+}
+
+// GetTagRSSFeed godoc
+// @Summary      Tag RSS feed
+// @Description  RSS 2.0 feed of the latest public posts with a tag
+// @Tags         feeds
+// @Produce      xml
+// @Param        slug    path  string true  "Tag slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/tags/{slug}/feed.xml [get]
+func (h *feedHandler) GetTagRSSFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeTag, rssFormat)
+}
+
+// GetTagAtomFeed godoc
+// @Summary      Tag Atom feed
+// @Description  Atom 1.0 feed of the latest public posts with a tag
+// @Tags         feeds
+// @Produce      xml
+// @Param        slug    path  string true  "Tag slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/tags/{slug}/atom.xml [get]
+func (h *feedHandler) GetTagAtomFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeTag, atomFormat)
+}
+
+// GetTagJSONFeed godoc
+// @Summary      Tag JSON Feed
+// @Description  JSON Feed 1.1 of the latest public posts with a tag
+// @Tags         feeds
+// @Produce      json
+// @Param        slug    path  string true  "Tag slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/tags/{slug}/feed.json [get]
+func (h *feedHandler) GetTagJSONFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeTag, jsonFormat)
+}
+
+// GetSeriesRSSFeed godoc
+// @Summary      Series RSS feed
+// @Description  RSS 2.0 feed of the public posts of a series in reading order
+// @Tags         feeds
+// @Produce      xml
+// @Param        slug    path  string true  "Series slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/series/{slug}/feed.xml [get]
+func (h *feedHandler) GetSeriesRSSFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeSeries, rssFormat)
+}
+
+// GetSeriesAtomFeed godoc
+// @Summary      Series Atom feed
+// @Description  Atom 1.0 feed of the public posts of a series in reading order
+// @Tags         feeds
+// @Produce      xml
+// @Param        slug    path  string true  "Series slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/series/{slug}/atom.xml [get]
+func (h *feedHandler) GetSeriesAtomFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeSeries, atomFormat)
+}
+
+// GetSeriesJSONFeed godoc
+// @Summary      Series JSON Feed
+// @Description  JSON Feed 1.1 of the public posts of a series in reading order
+// @Tags         feeds
+// @Produce      json
+// @Param        slug    path  string true  "Series slug"
+// @Param        content query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/series/{slug}/feed.json [get]
+func (h *feedHandler) GetSeriesJSONFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeSeries, jsonFormat)
+}
+
+// GetAuthorRSSFeed godoc
+// @Summary      Author RSS feed
+// @Description  RSS 2.0 feed of the latest public posts credited to an author
+// @Tags         feeds
+// @Produce      xml
+// @Param        username path  string true  "Author username"
+// @Param        content  query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/authors/{username}/feed.xml [get]
+func (h *feedHandler) GetAuthorRSSFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeAuthor, rssFormat)
+}
+
+// GetAuthorAtomFeed godoc
+// @Summary      Author Atom feed
+// @Description  Atom 1.0 feed of the latest public posts credited to an author
+// @Tags         feeds
+// @Produce      xml
+// @Param        username path  string true  "Author username"
+// @Param        content  query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/authors/{username}/atom.xml [get]
+func (h *feedHandler) GetAuthorAtomFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeAuthor, atomFormat)
+}
+
+// GetAuthorJSONFeed godoc
+// @Summary      Author JSON Feed
+// @Description  JSON Feed 1.1 of the latest public posts credited to an author
+// @Tags         feeds
+// @Produce      json
+// @Param        username path  string true  "Author username"
+// @Param        content  query string false "Item body: excerpt or full"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/authors/{username}/feed.json [get]
+func (h *feedHandler) GetAuthorJSONFeed(c *gin.Context) {
+	h.serveFeed(c, services.FeedScopeAuthor, jsonFormat)
+}
+
+func (h *feedHandler) serveFeed(c *gin.Context, scope string, format feedFormat) {
+	content := c.Query("content")
+	if content != "" && content != services.FeedContentExcerpt && content != services.FeedContentFull {
+		responses.SendError(c, http.StatusBadRequest, "Invalid content, expected excerpt or full")
+		return
+	}
+
+	// Author feeds are keyed by username
+	slug := c.Param("slug")
+	if scope == services.FeedScopeAuthor {
+		slug = c.Param("username")
+	}
+
+	req := services.FeedRequest{
+		Scope:   scope,
+		Slug:    slug,
+		Content: content,
+		FeedURL: config.GetConfig().Site.AbsoluteURL(c.Request.URL.Path),
+		Locales: negotiateLocales(c),
+	}
+
+	// Answer conditional requests from the cheap bound before building the feed
+	lastModified, err := h.FeedService.LastModified(req)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build feed")
+		return
+	}
+	etag := responses.ETag(c.Request.URL.Path, content, strings.Join(req.Locales, ","), lastModified.Format(time.RFC3339))
+	if !lastModified.IsZero() && responses.NotModified(c, etag, lastModified) {
+		responses.SendNotModified(c, etag, lastModified, feedMaxAge)
+		return
+	}
+
+	f, err := h.FeedService.BuildFeed(req)
+	var redirect *services.SlugRedirectError
+	if errors.As(err, &redirect) {
+		sendSlugRedirect(c, redirect, slug)
+		return
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.SendError(c, http.StatusNotFound, "Feed not found")
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build feed")
+		return
+	}
+
+	body, err := format.render(f)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to render feed")
+		return
+	}
+
+	if lastModified.IsZero() {
+		responses.SendConditional(c, format.contentType, body, f.Updated, feedMaxAge)
+		return
+	}
+	responses.SendWithValidators(c, format.contentType, body, etag, lastModified, feedMaxAge)
+}
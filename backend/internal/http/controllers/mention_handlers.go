@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MentionHandler interface {
+	GetMentions(c *gin.Context)
+}
+
+type mentionHandler struct {
+	MentionService services.MentionService
+}
+
+func NewMentionHandler(mentionService services.MentionService) MentionHandler {
+	return &mentionHandler{MentionService: mentionService}
+}
+
+// GetMentions godoc
+// @Summary      Get my mentions
+// @Description  Posts and comments that mention the current user, newest first; only published posts and approved comments are listed
+// @Tags         mentions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int false "Page number" default(1)
+// @Param        per_page  query     int false "Items per page" default(10)
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      401       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /mentions [get]
+func (h *mentionHandler) GetMentions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	params := responses.ParsePaginationParams(c)
+	mentions, total, err := h.MentionService.GetUserMentions(userID, params.Page, params.PerPage)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch mentions")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Mentions retrieved successfully", mentions, total, int64(params.Page), int64(params.PerPage))
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+	"go-next/pkg/oembed"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type OEmbedHandler interface {
+	GetOEmbed(c *gin.Context)
+	ResolveEmbed(c *gin.Context)
+}
+
+type oembedHandler struct {
+	OEmbedService services.OEmbedService
+}
+
+func NewOEmbedHandler(oembedService services.OEmbedService) OEmbedHandler {
+	return &oembedHandler{OEmbedService: oembedService}
+}
+
+// GetOEmbed godoc
+// @Summary      oEmbed provider
+// @Description  oEmbed response for a public post URL of this site, so other sites can embed it
+// @Tags         oembed
+// @Produce      json
+// @Produce      xml
+// @Param        url        query  string true  "Post URL"
+// @Param        format     query  string false "json (default) or xml"
+// @Param        maxwidth   query  int    false "Maximum embed width"
+// @Param        maxheight  query  int    false "Maximum embed height"
+// @Success      200        {object}  oembed.Response
+// @Failure      400        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      501        {object}  map[string]string
+// @Router       /oembed [get]
+func (h *oembedHandler) GetOEmbed(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		responses.SendError(c, http.StatusBadRequest, "url is required")
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "xml" {
+		responses.SendError(c, http.StatusNotImplemented, "Unsupported format")
+		return
+	}
+	opts, ok := parseOEmbedOptions(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.OEmbedService.Provide(rawURL, opts)
+	if err != nil {
+		if errors.Is(err, services.ErrOEmbedNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.SendError(c, http.StatusNotFound, "Post not found")
+			return
+		}
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build embed")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(response.CacheAge))
+	if format == "xml" {
+		body, err := response.XML()
+		if err != nil {
+			responses.SendError(c, http.StatusInternalServerError, "Failed to build embed")
+			return
+		}
+		c.Data(http.StatusOK, "text/xml; charset=utf-8", body)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ResolveEmbed godoc
+// @Summary      Resolve an embed
+// @Description  oEmbed response of an external URL through the provider registry, for editor previews
+// @Tags         oembed
+// @Produce      json
+// @Security     BearerAuth
+// @Param        url        query  string true  "URL to embed"
+// @Param        maxwidth   query  int    false "Maximum embed width"
+// @Param        maxheight  query  int    false "Maximum embed height"
+// @Success      200        {object}  oembed.Response
+// @Failure      400        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Failure      502        {object}  map[string]string
+// @Router       /oembed/resolve [get]
+func (h *oembedHandler) ResolveEmbed(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		responses.SendError(c, http.StatusBadRequest, "url is required")
+		return
+	}
+	opts, ok := parseOEmbedOptions(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.OEmbedService.Resolve(c.Request.Context(), rawURL, opts)
+	if err != nil {
+		if errors.Is(err, oembed.ErrNoProvider) {
+			responses.SendError(c, http.StatusNotFound, "No embed provider for url")
+			return
+		}
+		responses.SendError(c, http.StatusBadGateway, "Embed provider request failed")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Embed resolved successfully",
+		"data":    response,
+	})
+}
+
+func parseOEmbedOptions(c *gin.Context) (oembed.Options, bool) {
+	var opts oembed.Options
+	for name, dest := range map[string]*int{"maxwidth": &opts.MaxWidth, "maxheight": &opts.MaxHeight} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			responses.SendError(c, http.StatusBadRequest, "Invalid "+name)
+			return opts, false
+		}
+		*dest = n
+	}
+	return opts, true
+}
@@ -51,7 +51,7 @@ func (h *postHandler) GetPosts(c *gin.Context) {
 	var posts []models.Post
 	var total int64
 
-	query := database.DB.Model(&models.Post{}).Preload("Category").Preload("User")
+	query := database.DB.Model(&models.Post{}).Preload("Category")
 
 	// Apply search filter
 	if search != "" {
@@ -76,6 +76,10 @@ func (h *postHandler) GetPosts(c *gin.Context) {
 		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch posts")
 		return
 	}
+	if err := services.AuthorSvc.AttachAuthors(posts); err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch posts")
+		return
+	}
 
 	// Send Laravel-style pagination response
 	responses.SendLaravelPaginationWithMessage(c, "Posts retrieved successfully", posts, total, int64(params.Page), int64(params.PerPage))
@@ -97,6 +101,7 @@ func (h *postHandler) GetPost(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		return
 	}
+	attachPostReactions(c, post)
 	c.JSON(http.StatusOK, post)
 }
 
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReactionHandler interface {
+	GetReactionTypes(c *gin.Context)
+
+	GetPostReactions(c *gin.Context)
+	ReactToPost(c *gin.Context)
+	UnreactToPost(c *gin.Context)
+
+	GetCommentReactions(c *gin.Context)
+	ReactToComment(c *gin.Context)
+	UnreactToComment(c *gin.Context)
+}
+
+type reactionHandler struct {
+	ReactionService services.ReactionService
+}
+
+func NewReactionHandler(reactionService services.ReactionService) ReactionHandler {
+	return &reactionHandler{ReactionService: reactionService}
+}
+
+// GetReactionTypes godoc
+// @Summary      Get reaction types
+// @Description  The reaction types users can react with: like, then the configured emoji
+// @Tags         reactions
+// @Produce      json
+// @Success      200  {array}  string
+// @Router       /reactions/types [get]
+func (h *reactionHandler) GetReactionTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reaction types retrieved successfully",
+		"data":    h.ReactionService.Types(),
+	})
+}
+
+// GetPostReactions godoc
+// @Summary      Get post reactions
+// @Description  Count the reactions to a post by type; with a token, also the types the current user reacted with
+// @Tags         reactions
+// @Produce      json
+// @Param        id   path      string true "Post ID"
+// @Success      200  {object}  models.ReactionSummary
+// @Failure      400  {object}  map[string]string
+// @Router       /posts/{id}/reactions [get]
+func (h *reactionHandler) GetPostReactions(c *gin.Context) {
+	h.getReactions(c, models.ReactableTypePost)
+}
+
+// ReactToPost godoc
+// @Summary      React to post
+// @Description  Add a reaction to a published post; each user reacts with each type once
+// @Tags         reactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string                   true "Post ID"
+// @Param        reaction  body      requests.ReactionRequest true "Reaction type"
+// @Success      200       {object}  models.ReactionSummary
+// @Failure      400       {object}  map[string]string
+// @Failure      401       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Router       /posts/{id}/reactions [post]
+func (h *reactionHandler) ReactToPost(c *gin.Context) {
+	h.react(c, models.ReactableTypePost)
+}
+
+// UnreactToPost godoc
+// @Summary      Remove post reaction
+// @Description  Remove the current user's reaction of one type from a post
+// @Tags         reactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string true "Post ID"
+// @Param        type  path      string true "Reaction type"
+// @Success      200   {object}  models.ReactionSummary
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Router       /posts/{id}/reactions/{type} [delete]
+func (h *reactionHandler) UnreactToPost(c *gin.Context) {
+	h.unreact(c, models.ReactableTypePost)
+}
+
+// GetCommentReactions godoc
+// @Summary      Get comment reactions
+// @Description  Count the reactions to a comment by type; with a token, also the types the current user reacted with
+// @Tags         reactions
+// @Produce      json
+// @Param        id   path      string true "Comment ID"
+// @Success      200  {object}  models.ReactionSummary
+// @Failure      400  {object}  map[string]string
+// @Router       /comments/{id}/reactions [get]
+func (h *reactionHandler) GetCommentReactions(c *gin.Context) {
+	h.getReactions(c, models.ReactableTypeComment)
+}
+
+// ReactToComment godoc
+// @Summary      React to comment
+// @Description  Add a reaction to an approved comment; each user reacts with each type once
+// @Tags         reactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string                   true "Comment ID"
+// @Param        reaction  body      requests.ReactionRequest true "Reaction type"
+// @Success      200       {object}  models.ReactionSummary
+// @Failure      400       {object}  map[string]string
+// @Failure      401       {object}  map[string]string
+// @Failure      404       {object}  map[string]string
+// @Router       /comments/{id}/reactions [post]
+func (h *reactionHandler) ReactToComment(c *gin.Context) {
+	h.react(c, models.ReactableTypeComment)
+}
+
+// UnreactToComment godoc
+// @Summary      Remove comment reaction
+// @Description  Remove the current user's reaction of one type from a comment
+// @Tags         reactions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string true "Comment ID"
+// @Param        type  path      string true "Reaction type"
+// @Success      200   {object}  models.ReactionSummary
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Router       /comments/{id}/reactions/{type} [delete]
+func (h *reactionHandler) UnreactToComment(c *gin.Context) {
+	h.unreact(c, models.ReactableTypeComment)
+}
+
+func (h *reactionHandler) getReactions(c *gin.Context, reactableType string) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	summaries, err := h.ReactionService.GetSummaries(reactableType, []uuid.UUID{id}, viewerFromContext(c).UserID)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch reactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reactions retrieved successfully",
+		"data":    summaries[id],
+	})
+}
+
+func (h *reactionHandler) react(c *gin.Context, reactableType string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	summary, err := h.ReactionService.React(userID, reactableType, id, req.Type)
+	if err != nil {
+		sendReactionError(c, err, "Failed to add reaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reaction added successfully",
+		"data":    summary,
+	})
+}
+
+func (h *reactionHandler) unreact(c *gin.Context, reactableType string) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := h.ReactionService.Unreact(userID, reactableType, id, c.Param("type"))
+	if err != nil {
+		sendReactionError(c, err, "Failed to remove reaction")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reaction removed successfully",
+		"data":    summary,
+	})
+}
+
+func sendReactionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Post or comment not found")
+	case errors.Is(err, services.ErrInvalidReaction):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
+
+// attachPostReactions fills in the reactions of posts for the current user.
+// Failures leave the reactions out rather than failing the response.
+func attachPostReactions(c *gin.Context, posts ...*models.Post) {
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	summaries, err := services.ReactionSvc.GetSummaries(models.ReactableTypePost, ids, viewerFromContext(c).UserID)
+	if err != nil {
+		log.Printf("Warning: failed to load post reactions: %v", err)
+		return
+	}
+	for _, post := range posts {
+		post.Reactions = summaries[post.ID]
+	}
+}
+
+// postPointers points into a list of posts so their reactions can be filled in place
+func postPointers(posts []models.Post) []*models.Post {
+	pointers := make([]*models.Post, len(posts))
+	for i := range posts {
+		pointers[i] = &posts[i]
+	}
+	return pointers
+}
+
+// attachCommentReactions fills in the reactions of comments for the current user
+func attachCommentReactions(c *gin.Context, comments ...*models.Comment) {
+	ids := make([]uuid.UUID, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	summaries, err := services.ReactionSvc.GetSummaries(models.ReactableTypeComment, ids, viewerFromContext(c).UserID)
+	if err != nil {
+		log.Printf("Warning: failed to load comment reactions: %v", err)
+		return
+	}
+	for _, comment := range comments {
+		comment.Reactions = summaries[comment.ID]
+	}
+}
+
+// attachThreadReactions fills in the reactions of every comment shown in a discussion page
+func attachThreadReactions(c *gin.Context, thread *models.CommentThread) {
+	var nodes []*models.CommentNode
+	var collect func(level []models.CommentNode)
+	collect = func(level []models.CommentNode) {
+		for i := range level {
+			nodes = append(nodes, &level[i])
+			collect(level[i].Replies)
+		}
+	}
+	collect(thread.Comments)
+	if len(nodes) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+	}
+	summaries, err := services.ReactionSvc.GetSummaries(models.ReactableTypeComment, ids, viewerFromContext(c).UserID)
+	if err != nil {
+		log.Printf("Warning: failed to load comment reactions: %v", err)
+		return
+	}
+	for _, node := range nodes {
+		node.Reactions = summaries[node.ID]
+	}
+}
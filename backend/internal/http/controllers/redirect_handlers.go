@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RedirectHandler interface {
+	ResolveRedirect(c *gin.Context)
+	GetRedirects(c *gin.Context)
+	GetRedirect(c *gin.Context)
+	CreateRedirect(c *gin.Context)
+	UpdateRedirect(c *gin.Context)
+	DeleteRedirect(c *gin.Context)
+}
+
+type redirectHandler struct {
+	RedirectService services.RedirectService
+}
+
+func NewRedirectHandler(redirectService services.RedirectService) RedirectHandler {
+	return &redirectHandler{RedirectService: redirectService}
+}
+
+// ResolveRedirect godoc
+// @Summary      Resolve a redirect
+// @Description  Find where a public site path redirects to, from custom rules or post and category slug history
+// @Tags         redirects
+// @Produce      json
+// @Param        path query     string true "Public site path, e.g. /blog/old-slug"
+// @Success      200  {object}  services.RedirectResolution
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /redirects/resolve [get]
+func (h *redirectHandler) ResolveRedirect(c *gin.Context) {
+	path := c.Query("path")
+	if path == "" {
+		responses.SendError(c, http.StatusBadRequest, "Path is required")
+		return
+	}
+
+	resolution, err := h.RedirectService.Resolve(path)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.SendError(c, http.StatusNotFound, "No redirect for this path")
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to resolve redirect")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Redirect resolved successfully",
+		"data":    resolution,
+	})
+}
+
+// GetRedirects godoc
+// @Summary      List redirect rules (Admin only)
+// @Description  List custom redirect rules with pagination
+// @Tags         redirects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int    false "Page number"
+// @Param        per_page  query     int    false "Items per page"
+// @Param        search    query     string false "Search source or target"
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      500       {object}  map[string]string
+// @Router       /admin/redirects [get]
+func (h *redirectHandler) GetRedirects(c *gin.Context) {
+	params := responses.ParsePaginationParams(c)
+
+	redirects, total, err := h.RedirectService.GetRedirects(params.Page, params.PerPage, c.Query("search"))
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch redirects")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Redirects retrieved successfully", redirects, total, int64(params.Page), int64(params.PerPage))
+}
+
+// GetRedirect godoc
+// @Summary      Get redirect rule (Admin only)
+// @Description  Get a custom redirect rule by ID
+// @Tags         redirects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string true "Redirect ID"
+// @Success      200  {object}  models.Redirect
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/redirects/{id} [get]
+func (h *redirectHandler) GetRedirect(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid redirect ID")
+		return
+	}
+
+	redirect, err := h.RedirectService.GetRedirect(id)
+	if err != nil {
+		responses.SendError(c, http.StatusNotFound, "Redirect not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Redirect retrieved successfully",
+		"data":    redirect,
+	})
+}
+
+// CreateRedirect godoc
+// @Summary      Create redirect rule (Admin only)
+// @Description  Redirect a public site path to another path or URL
+// @Tags         redirects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        redirect body      requests.RedirectRequest true "Redirect rule"
+// @Success      201      {object}  models.Redirect
+// @Failure      400      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/redirects [post]
+func (h *redirectHandler) CreateRedirect(c *gin.Context) {
+	var req requests.RedirectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	redirect := models.Redirect{IsActive: true}
+	applyRedirectRequest(&redirect, &req)
+	if userID, exists := c.Get("user_id"); exists {
+		if uid, ok := userID.(uuid.UUID); ok {
+			redirect.CreatedBy = &uid
+		}
+	}
+
+	if err := h.RedirectService.CreateRedirect(&redirect); err != nil {
+		sendRedirectError(c, err, "Failed to create redirect")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Redirect created successfully",
+		"data":    redirect,
+	})
+}
+
+// UpdateRedirect godoc
+// @Summary      Update redirect rule (Admin only)
+// @Description  Update a custom redirect rule
+// @Tags         redirects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                   true "Redirect ID"
+// @Param        redirect body      requests.RedirectRequest true "Redirect rule"
+// @Success      200      {object}  models.Redirect
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/redirects/{id} [put]
+func (h *redirectHandler) UpdateRedirect(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid redirect ID")
+		return
+	}
+
+	var req requests.RedirectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	redirect, err := h.RedirectService.GetRedirect(id)
+	if err != nil {
+		responses.SendError(c, http.StatusNotFound, "Redirect not found")
+		return
+	}
+	applyRedirectRequest(redirect, &req)
+	if userID, exists := c.Get("user_id"); exists {
+		if uid, ok := userID.(uuid.UUID); ok {
+			redirect.UpdatedBy = &uid
+		}
+	}
+
+	if err := h.RedirectService.UpdateRedirect(redirect); err != nil {
+		sendRedirectError(c, err, "Failed to update redirect")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Redirect updated successfully",
+		"data":    redirect,
+	})
+}
+
+// DeleteRedirect godoc
+// @Summary      Delete redirect rule (Admin only)
+// @Description  Delete a custom redirect rule
+// @Tags         redirects
+// @Security     BearerAuth
+// @Param        id   path  string true "Redirect ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/redirects/{id} [delete]
+func (h *redirectHandler) DeleteRedirect(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid redirect ID")
+		return
+	}
+
+	if err := h.RedirectService.DeleteRedirect(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.SendError(c, http.StatusNotFound, "Redirect not found")
+			return
+		}
+		responses.SendError(c, http.StatusInternalServerError, "Failed to delete redirect")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func applyRedirectRequest(redirect *models.Redirect, req *requests.RedirectRequest) {
+	redirect.SourcePath = req.SourcePath
+	redirect.TargetURL = req.TargetURL
+	redirect.StatusCode = req.StatusCode
+	if req.IsActive != nil {
+		redirect.IsActive = *req.IsActive
+	}
+}
+
+func sendRedirectError(c *gin.Context, err error, message string) {
+	if errors.Is(err, services.ErrInvalidRedirect) {
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	responses.SendError(c, http.StatusInternalServerError, message)
+}
+
+// sendSlugRedirect answers a request for a moved slug with a 301 pointing at the
+// same endpoint under the current slug, plus the public page URL in the body
+func sendSlugRedirect(c *gin.Context, redirect *services.SlugRedirectError, oldSlug string) {
+	segments := strings.Split(c.Request.URL.Path, "/")
+	for i, segment := range segments {
+		if segment == oldSlug {
+			segments[i] = redirect.Slug
+			break
+		}
+	}
+	location := strings.Join(segments, "/")
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+
+	site := config.GetConfig().Site
+	url := site.PostURL(redirect.Slug)
+	if redirect.Type == models.MediableTypeCategory {
+		url = site.CategoryURL(redirect.Slug)
+	}
+
+	c.Header("Location", location)
+	c.JSON(redirect.StatusCode, gin.H{
+		"message": "Resource has moved",
+		"data": gin.H{
+			"type":        redirect.Type,
+			"slug":        redirect.Slug,
+			"location":    location,
+			"url":         url,
+			"status_code": redirect.StatusCode,
+		},
+	})
+}
@@ -0,0 +1,250 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReportHandler interface {
+	CreateReport(c *gin.Context)
+
+	// Moderator queue
+	GetReportQueue(c *gin.Context)
+	ResolveReports(c *gin.Context)
+	DismissReports(c *gin.Context)
+	GetModerationLog(c *gin.Context)
+}
+
+type reportHandler struct {
+	ReportService services.ReportService
+}
+
+func NewReportHandler(reportService services.ReportService) ReportHandler {
+	return &reportHandler{ReportService: reportService}
+}
+
+// CreateReport godoc
+// @Summary      Report content
+// @Description  Report an abusive published post or approved comment; each user reports each post or comment once. Content is hidden pending review after enough distinct reports.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        report  body      requests.ReportRequest true "Reported content and reason"
+// @Success      201     {object}  models.Report
+// @Failure      400     {object}  map[string]string
+// @Failure      401     {object}  map[string]string
+// @Failure      404     {object}  map[string]string
+// @Failure      409     {object}  map[string]string
+// @Router       /reports [post]
+func (h *reportHandler) CreateReport(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req requests.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := h.ReportService.Report(userID, req.ReportableType, req.ReportableID, req.Reason, req.Details)
+	if err != nil {
+		sendReportError(c, err, "Failed to report content")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Content reported successfully",
+		"data":    report,
+	})
+}
+
+// GetReportQueue godoc
+// @Summary      Report queue (Admin only)
+// @Description  List reported posts and comments with their reports, most reported first; open reports by default
+// @Tags         reports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status    query     string false "open, resolved or dismissed" default(open)
+// @Param        type      query     string false "post or comment"
+// @Param        page      query     int    false "Page number"
+// @Param        per_page  query     int    false "Items per page"
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      400       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /admin/reports [get]
+func (h *reportHandler) GetReportQueue(c *gin.Context) {
+	params := responses.ParsePaginationParams(c)
+	filter := services.ReportFilter{Status: c.Query("status"), ReportableType: c.Query("type")}
+
+	queue, total, err := h.ReportService.GetQueue(filter, params.Page, params.PerPage)
+	if err != nil {
+		sendReportError(c, err, "Failed to fetch reports")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Reports retrieved successfully", queue, total, int64(params.Page), int64(params.PerPage))
+}
+
+// ResolveReports godoc
+// @Summary      Resolve reports (Admin only)
+// @Description  Confirm the open reports of a post or comment and optionally hide or delete it, and warn or suspend its author. Content hidden by the reports stays hidden.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type        path      string                         true "post or comment"
+// @Param        id          path      string                         true "Post or comment ID"
+// @Param        resolution  body      requests.ResolveReportsRequest true "Actions and note"
+// @Success      200         {array}   models.Report
+// @Failure      400         {object}  map[string]string
+// @Failure      404         {object}  map[string]string
+// @Router       /admin/reports/{type}/{id}/resolve [post]
+func (h *reportHandler) ResolveReports(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.ResolveReportsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	reports, err := h.ReportService.Resolve(c.Param("type"), id, req.Actions, req.Note, moderatorFromContext(c))
+	if err != nil {
+		sendReportError(c, err, "Failed to resolve reports")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reports resolved successfully",
+		"data":    reports,
+	})
+}
+
+// DismissReports godoc
+// @Summary      Dismiss reports (Admin only)
+// @Description  Close the open reports of a post or comment without action, showing the content again if the reports hid it
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type       path      string                         true  "post or comment"
+// @Param        id         path      string                         true  "Post or comment ID"
+// @Param        dismissal  body      requests.DismissReportsRequest false "Note"
+// @Success      200        {array}   models.Report
+// @Failure      400        {object}  map[string]string
+// @Failure      404        {object}  map[string]string
+// @Router       /admin/reports/{type}/{id}/dismiss [post]
+func (h *reportHandler) DismissReports(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.DismissReportsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	reports, err := h.ReportService.Dismiss(c.Param("type"), id, req.Note, moderatorFromContext(c))
+	if err != nil {
+		sendReportError(c, err, "Failed to dismiss reports")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Reports dismissed successfully",
+		"data":    reports,
+	})
+}
+
+// GetModerationLog godoc
+// @Summary      Moderation log (Admin only)
+// @Description  List moderation actions newest first: automatic hides, resolved and dismissed reports, warnings, suspensions and deletions
+// @Tags         reports
+// @Produce      json
+// @Security     BearerAuth
+// @Param        action        query     string false "Action filter"
+// @Param        target_type   query     string false "post, comment or user"
+// @Param        target_id     query     string false "Target ID filter"
+// @Param        moderator_id  query     string false "Moderator ID filter"
+// @Param        user_id       query     string false "ID of the user the actions were about"
+// @Param        page          query     int    false "Page number"
+// @Param        per_page      query     int    false "Items per page"
+// @Success      200           {object}  responses.LaravelPaginationResponse
+// @Failure      400           {object}  map[string]string
+// @Failure      500           {object}  map[string]string
+// @Router       /admin/moderation-log [get]
+func (h *reportHandler) GetModerationLog(c *gin.Context) {
+	params := responses.ParsePaginationParams(c)
+
+	targetID, ok := parseOptionalUUIDQuery(c, "target_id")
+	if !ok {
+		return
+	}
+	moderatorID, ok := parseOptionalUUIDQuery(c, "moderator_id")
+	if !ok {
+		return
+	}
+	userID, ok := parseOptionalUUIDQuery(c, "user_id")
+	if !ok {
+		return
+	}
+	filter := services.ModerationLogFilter{
+		Action:      c.Query("action"),
+		TargetType:  c.Query("target_type"),
+		TargetID:    targetID,
+		ModeratorID: moderatorID,
+		UserID:      userID,
+	}
+
+	entries, total, err := h.ReportService.GetModerationLog(filter, params.Page, params.PerPage)
+	if err != nil {
+		sendReportError(c, err, "Failed to fetch moderation log")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Moderation log retrieved successfully", entries, total, int64(params.Page), int64(params.PerPage))
+}
+
+// moderatorFromContext is the logged in moderator, if any
+func moderatorFromContext(c *gin.Context) *uuid.UUID {
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uuid.UUID); ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+func sendReportError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Content or open reports not found")
+	case errors.Is(err, services.ErrAlreadyReported):
+		responses.SendError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidReport),
+		errors.Is(err, services.ErrInvalidReportAction),
+		errors.Is(err, services.ErrInvalidReportFilter),
+		errors.Is(err, services.ErrInvalidModerationQuery):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SEOHandler interface {
+	GetPostSEO(c *gin.Context)
+	GetCategorySEO(c *gin.Context)
+	UpdatePostSEO(c *gin.Context)
+	UpdateCategorySEO(c *gin.Context)
+}
+
+type seoHandler struct {
+	SEOService services.SEOService
+}
+
+func NewSEOHandler(seoService services.SEOService) SEOHandler {
+	return &seoHandler{SEOService: seoService}
+}
+
+// GetPostSEO godoc
+// @Summary      Get post SEO metadata
+// @Description  Meta title, description, canonical URL, robots, OpenGraph/Twitter tags and JSON-LD of a public post
+// @Tags         seo
+// @Produce      json
+// @Param        slug path      string true "Post slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
+// @Success      200  {object}  services.SEO
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /blog/posts/{slug}/seo [get]
+func (h *seoHandler) GetPostSEO(c *gin.Context) {
+	seo, err := h.SEOService.GetPostSEO(c.Param("slug"), negotiateLocales(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.SendError(c, http.StatusNotFound, "Post not found")
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build SEO metadata")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SEO metadata retrieved successfully",
+		"data":    seo,
+	})
+}
+
+// GetCategorySEO godoc
+// @Summary      Get category SEO metadata
+// @Description  Meta title, description, canonical URL, robots, OpenGraph/Twitter tags and JSON-LD of an active category
+// @Tags         seo
+// @Produce      json
+// @Param        slug path      string true "Category slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
+// @Success      200  {object}  services.SEO
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /blog/categories/{slug}/seo [get]
+func (h *seoHandler) GetCategorySEO(c *gin.Context) {
+	seo, err := h.SEOService.GetCategorySEO(c.Param("slug"), negotiateLocales(c))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.SendError(c, http.StatusNotFound, "Category not found")
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build SEO metadata")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SEO metadata retrieved successfully",
+		"data":    seo,
+	})
+}
+
+// UpdatePostSEO godoc
+// @Summary      Update post SEO metadata (Admin only)
+// @Description  Replace the SEO overrides of a post; empty fields fall back to the post's own values
+// @Tags         seo
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string                  true "Post ID"
+// @Param        seo  body      requests.SEOMetaRequest true "SEO overrides"
+// @Success      200  {object}  models.SEOMeta
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /blog/posts/{id}/seo [put]
+func (h *seoHandler) UpdatePostSEO(c *gin.Context) {
+	h.updateSEO(c, models.MediableTypePost)
+}
+
+// UpdateCategorySEO godoc
+// @Summary      Update category SEO metadata
+// @Description  Replace the SEO overrides of a category; empty fields fall back to the category's own values
+// @Tags         seo
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string                  true "Category ID"
+// @Param        seo  body      requests.SEOMetaRequest true "SEO overrides"
+// @Success      200  {object}  models.SEOMeta
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /categories/{id}/seo [put]
+func (h *seoHandler) UpdateCategorySEO(c *gin.Context) {
+	h.updateSEO(c, models.MediableTypeCategory)
+}
+
+func (h *seoHandler) updateSEO(c *gin.Context, seoableType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req requests.SEOMetaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	meta, err := h.SEOService.UpdateSEO(seoableType, id, &models.SEOMeta{
+		MetaTitle:       req.MetaTitle,
+		MetaDescription: req.MetaDescription,
+		CanonicalURL:    req.CanonicalURL,
+		Robots:          req.Robots,
+		ImageID:         req.ImageID,
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.SendError(c, http.StatusNotFound, "Not found")
+		return
+	}
+	if errors.Is(err, services.ErrInvalidSEOMeta) {
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to update SEO metadata")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "SEO metadata updated successfully",
+		"data":    meta,
+	})
+}
@@ -0,0 +1,417 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SeriesHandler interface {
+	// Public series endpoints
+	GetPublicSeries(c *gin.Context)
+	GetSeriesBySlug(c *gin.Context)
+
+	// Series management (admin only)
+	GetSeries(c *gin.Context)
+	GetSeriesByID(c *gin.Context)
+	CreateSeries(c *gin.Context)
+	UpdateSeries(c *gin.Context)
+	DeleteSeries(c *gin.Context)
+	GetSeriesEntries(c *gin.Context)
+	AddSeriesPost(c *gin.Context)
+	RemoveSeriesPost(c *gin.Context)
+	ReorderSeriesPosts(c *gin.Context)
+}
+
+type seriesHandler struct {
+	SeriesService services.SeriesService
+}
+
+func NewSeriesHandler(seriesService services.SeriesService) SeriesHandler {
+	return &seriesHandler{SeriesService: seriesService}
+}
+
+// GetPublicSeries godoc
+// @Summary      List series
+// @Description  Active series with at least one published post
+// @Tags         series
+// @Produce      json
+// @Param        page      query     int false "Page number" default(1)
+// @Param        per_page  query     int false "Items per page" default(10)
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      500       {object}  map[string]string
+// @Router       /blog/series [get]
+func (h *seriesHandler) GetPublicSeries(c *gin.Context) {
+	params := responses.ParsePaginationParams(c)
+
+	series, total, err := h.SeriesService.GetPublicSeries(params.Page, params.PerPage)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch series")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Series retrieved successfully", series, total, int64(params.Page), int64(params.PerPage))
+}
+
+// GetSeriesBySlug godoc
+// @Summary      Get series
+// @Description  A series landing page: the series and its published posts in reading order
+// @Tags         series
+// @Produce      json
+// @Param        slug path      string true "Series slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/series/{slug} [get]
+func (h *seriesHandler) GetSeriesBySlug(c *gin.Context) {
+	series, err := h.SeriesService.GetSeriesBySlug(c.Param("slug"))
+	if err != nil {
+		responses.SendError(c, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	posts, err := h.SeriesService.GetSeriesPosts(series.ID, negotiateLocales(c))
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch series posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Series retrieved successfully",
+		"data": gin.H{
+			"series": series,
+			"posts":  posts,
+		},
+	})
+}
+
+// GetSeries godoc
+// @Summary      List series (Admin only)
+// @Description  List every series with pagination
+// @Tags         series
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int    false "Page number"
+// @Param        per_page  query     int    false "Items per page"
+// @Param        search    query     string false "Search title or description"
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      500       {object}  map[string]string
+// @Router       /admin/series [get]
+func (h *seriesHandler) GetSeries(c *gin.Context) {
+	params := responses.ParsePaginationParams(c)
+
+	series, total, err := h.SeriesService.GetSeries(params.Page, params.PerPage, c.Query("search"))
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch series")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Series retrieved successfully", series, total, int64(params.Page), int64(params.PerPage))
+}
+
+// GetSeriesByID godoc
+// @Summary      Get series (Admin only)
+// @Description  Get a series by ID
+// @Tags         series
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string true "Series ID"
+// @Success      200  {object}  models.Series
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/series/{id} [get]
+func (h *seriesHandler) GetSeriesByID(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	series, err := h.SeriesService.GetSeriesByID(id)
+	if err != nil {
+		responses.SendError(c, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Series retrieved successfully",
+		"data":    series,
+	})
+}
+
+// CreateSeries godoc
+// @Summary      Create series (Admin only)
+// @Description  Create a series to group posts in order
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        series body      requests.SeriesRequest true "Series"
+// @Success      201    {object}  models.Series
+// @Failure      400    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /admin/series [post]
+func (h *seriesHandler) CreateSeries(c *gin.Context) {
+	var req requests.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	series := models.Series{IsActive: true}
+	applySeriesRequest(&series, &req)
+	if userID, exists := c.Get("user_id"); exists {
+		if uid, ok := userID.(uuid.UUID); ok {
+			series.CreatedBy = &uid
+		}
+	}
+
+	if err := h.SeriesService.CreateSeries(&series); err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to create series")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Series created successfully",
+		"data":    series,
+	})
+}
+
+// UpdateSeries godoc
+// @Summary      Update series (Admin only)
+// @Description  Update the details of a series
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      string                 true "Series ID"
+// @Param        series body      requests.SeriesRequest true "Series"
+// @Success      200    {object}  models.Series
+// @Failure      400    {object}  map[string]string
+// @Failure      404    {object}  map[string]string
+// @Failure      500    {object}  map[string]string
+// @Router       /admin/series/{id} [put]
+func (h *seriesHandler) UpdateSeries(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	series, err := h.SeriesService.GetSeriesByID(id)
+	if err != nil {
+		responses.SendError(c, http.StatusNotFound, "Series not found")
+		return
+	}
+	applySeriesRequest(series, &req)
+	if userID, exists := c.Get("user_id"); exists {
+		if uid, ok := userID.(uuid.UUID); ok {
+			series.UpdatedBy = &uid
+		}
+	}
+
+	if err := h.SeriesService.UpdateSeries(series); err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to update series")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Series updated successfully",
+		"data":    series,
+	})
+}
+
+// DeleteSeries godoc
+// @Summary      Delete series (Admin only)
+// @Description  Delete a series; its posts are kept
+// @Tags         series
+// @Security     BearerAuth
+// @Param        id   path  string true "Series ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/series/{id} [delete]
+func (h *seriesHandler) DeleteSeries(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.SeriesService.DeleteSeries(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.SendError(c, http.StatusNotFound, "Series not found")
+			return
+		}
+		responses.SendError(c, http.StatusInternalServerError, "Failed to delete series")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSeriesEntries godoc
+// @Summary      List series posts (Admin only)
+// @Description  The posts of a series in order, drafts included
+// @Tags         series
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string true "Series ID"
+// @Success      200  {array}   models.SeriesPost
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/series/{id}/posts [get]
+func (h *seriesHandler) GetSeriesEntries(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entries, err := h.SeriesService.ListEntries(id)
+	if err != nil {
+		sendSeriesError(c, err, "Failed to fetch series posts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Series posts retrieved successfully",
+		"data":    entries,
+	})
+}
+
+// AddSeriesPost godoc
+// @Summary      Add post to series (Admin only)
+// @Description  Insert a post at a zero-based position of a series; without a position it is appended
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                     true "Series ID"
+// @Param        entry body      requests.SeriesPostRequest true "Post and position"
+// @Success      201   {object}  models.SeriesPost
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Router       /admin/series/{id}/posts [post]
+func (h *seriesHandler) AddSeriesPost(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.SeriesPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entry, err := h.SeriesService.AddPost(id, req.PostID, req.Position)
+	if err != nil {
+		sendSeriesError(c, err, "Failed to add post to series")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Post added to series successfully",
+		"data":    entry,
+	})
+}
+
+// RemoveSeriesPost godoc
+// @Summary      Remove post from series (Admin only)
+// @Description  Take a post out of a series; the post itself is kept
+// @Tags         series
+// @Security     BearerAuth
+// @Param        id      path  string true "Series ID"
+// @Param        post_id path  string true "Post ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/series/{id}/posts/{post_id} [delete]
+func (h *seriesHandler) RemoveSeriesPost(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	postID, ok := parseUUIDParam(c, "post_id")
+	if !ok {
+		return
+	}
+
+	if err := h.SeriesService.RemovePost(id, postID); err != nil {
+		sendSeriesError(c, err, "Failed to remove post from series")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ReorderSeriesPosts godoc
+// @Summary      Reorder series posts (Admin only)
+// @Description  Set the reading order of a series from a list of every post ID in it
+// @Tags         series
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string                      true "Series ID"
+// @Param        order body      requests.SeriesOrderRequest true "Post IDs in order"
+// @Success      200   {array}   models.SeriesPost
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Router       /admin/series/{id}/posts/order [put]
+func (h *seriesHandler) ReorderSeriesPosts(c *gin.Context) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req requests.SeriesOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	entries, err := h.SeriesService.ReorderPosts(id, req.Order)
+	if err != nil {
+		sendSeriesError(c, err, "Failed to reorder series")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Series reordered successfully",
+		"data":    entries,
+	})
+}
+
+func applySeriesRequest(series *models.Series, req *requests.SeriesRequest) {
+	series.Title = req.Title
+	series.Slug = req.Slug
+	series.Description = req.Description
+	if req.IsActive != nil {
+		series.IsActive = *req.IsActive
+	}
+}
+
+func sendSeriesError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Series or post not found")
+	case errors.Is(err, services.ErrPostInSeries):
+		responses.SendError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrSeriesOrderMismatch):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
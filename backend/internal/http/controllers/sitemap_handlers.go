@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sitemapContentType = "application/xml; charset=utf-8"
+
+	// sitemapMaxAge is how long clients and proxies may cache a sitemap
+	sitemapMaxAge = time.Hour
+)
+
+type SitemapHandler interface {
+	GetSitemapIndex(c *gin.Context)
+	GetSitemap(c *gin.Context)
+}
+
+type sitemapHandler struct {
+	SitemapService services.SitemapService
+}
+
+func NewSitemapHandler(sitemapService services.SitemapService) SitemapHandler {
+	return &sitemapHandler{SitemapService: sitemapService}
+}
+
+// GetSitemapIndex godoc
+// @Summary      Sitemap index
+// @Description  Sitemap index listing the post, category, tag and image sitemaps
+// @Tags         sitemaps
+// @Produce      xml
+// @Success      200
+// @Success      304
+// @Router       /sitemap.xml [get]
+func (h *sitemapHandler) GetSitemapIndex(c *gin.Context) {
+	doc, err := h.SitemapService.GetIndex()
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build sitemap")
+		return
+	}
+	responses.SendConditional(c, sitemapContentType, doc.Body, doc.LastModified, sitemapMaxAge)
+}
+
+// GetSitemap godoc
+// @Summary      Child sitemap
+// @Description  One page of a child sitemap, e.g. posts-1.xml
+// @Tags         sitemaps
+// @Produce      xml
+// @Param        file path string true "Sitemap file name"
+// @Success      200
+// @Success      304
+// @Failure      404  {object}  map[string]string
+// @Router       /sitemaps/{file} [get]
+func (h *sitemapHandler) GetSitemap(c *gin.Context) {
+	name, ok := strings.CutSuffix(c.Param("file"), ".xml")
+	if !ok {
+		responses.SendError(c, http.StatusNotFound, "Sitemap not found")
+		return
+	}
+
+	doc, err := h.SitemapService.GetSitemap(name)
+	if errors.Is(err, services.ErrSitemapNotFound) {
+		responses.SendError(c, http.StatusNotFound, "Sitemap not found")
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to build sitemap")
+		return
+	}
+	responses.SendConditional(c, sitemapContentType, doc.Body, doc.LastModified, sitemapMaxAge)
+}
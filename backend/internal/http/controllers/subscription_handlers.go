@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/email"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SubscriptionHandler interface {
+	Subscribe(c *gin.Context)
+	GetSubscriptions(c *gin.Context)
+	Unsubscribe(c *gin.Context)
+	ConfirmUnsubscribe(c *gin.Context)
+	UnsubscribeByToken(c *gin.Context)
+}
+
+type subscriptionHandler struct {
+	SubscriptionService services.SubscriptionService
+}
+
+func NewSubscriptionHandler(subscriptionService services.SubscriptionService) SubscriptionHandler {
+	return &subscriptionHandler{SubscriptionService: subscriptionService}
+}
+
+// Subscribe godoc
+// @Summary      Subscribe to comments
+// @Description  Follow the new comments on a published post, or only the replies in the thread below comment_id; subscribing twice returns the existing subscription
+// @Tags         subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        subscription body      requests.SubscriptionRequest true "Post, and optionally the thread's comment"
+// @Success      201          {object}  models.Subscription
+// @Failure      400          {object}  map[string]string
+// @Failure      401          {object}  map[string]string
+// @Failure      404          {object}  map[string]string
+// @Router       /subscriptions [post]
+func (h *subscriptionHandler) Subscribe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req requests.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	subscription, err := h.SubscriptionService.Subscribe(userID, req.PostID, req.CommentID)
+	if err != nil {
+		sendSubscriptionError(c, err, "Failed to subscribe")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Subscribed successfully",
+		"data":    subscription,
+	})
+}
+
+// GetSubscriptions godoc
+// @Summary      Get my subscriptions
+// @Description  The posts and threads the current user follows, newest first
+// @Tags         subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int false "Page number" default(1)
+// @Param        per_page  query     int false "Items per page" default(10)
+// @Success      200       {object}  responses.LaravelPaginationResponse
+// @Failure      401       {object}  map[string]string
+// @Failure      500       {object}  map[string]string
+// @Router       /subscriptions [get]
+func (h *subscriptionHandler) GetSubscriptions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	params := responses.ParsePaginationParams(c)
+	subscriptions, total, err := h.SubscriptionService.GetUserSubscriptions(userID, params.Page, params.PerPage)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch subscriptions")
+		return
+	}
+
+	responses.SendLaravelPaginationWithMessage(c, "Subscriptions retrieved successfully", subscriptions, total, int64(params.Page), int64(params.PerPage))
+}
+
+// Unsubscribe godoc
+// @Summary      Unsubscribe
+// @Description  Cancel one of the current user's subscriptions
+// @Tags         subscriptions
+// @Security     BearerAuth
+// @Param        id   path  string true "Subscription ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /subscriptions/{id} [delete]
+func (h *subscriptionHandler) Unsubscribe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.SubscriptionService.Unsubscribe(userID, id); err != nil {
+		sendSubscriptionError(c, err, "Failed to unsubscribe")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ConfirmUnsubscribe godoc
+// @Summary      Confirm unsubscribing
+// @Description  The page an email's unsubscribe link opens. It cancels nothing: it asks for confirmation and posts back to the same link.
+// @Tags         subscriptions
+// @Produce      html
+// @Param        token  query     string true "Unsubscribe token"
+// @Success      200    {string}  string
+// @Failure      400    {object}  map[string]string
+// @Router       /subscriptions/unsubscribe [get]
+func (h *subscriptionHandler) ConfirmUnsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	subscription, err := h.SubscriptionService.GetByUnsubscribeToken(token)
+	if err != nil {
+		sendSubscriptionError(c, err, "Failed to load subscription")
+		return
+	}
+
+	page := email.UnsubscribedPage("")
+	if subscription != nil {
+		page = email.UnsubscribeConfirmPage(subscriptionPostTitle(subscription), "?token="+url.QueryEscape(token))
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// UnsubscribeByToken godoc
+// @Summary      One-click unsubscribe
+// @Description  Cancel the subscription of an email's unsubscribe link, without logging in. Serves the confirmation form and List-Unsubscribe-Post one-click requests (RFC 8058); browsers get an HTML page.
+// @Tags         subscriptions
+// @Accept       x-www-form-urlencoded
+// @Produce      json,html
+// @Param        token  query     string true "Unsubscribe token"
+// @Success      200    {object}  map[string]interface{}
+// @Failure      400    {object}  map[string]string
+// @Router       /subscriptions/unsubscribe [post]
+func (h *subscriptionHandler) UnsubscribeByToken(c *gin.Context) {
+	subscription, err := h.SubscriptionService.UnsubscribeByToken(c.Query("token"))
+	if err != nil {
+		sendSubscriptionError(c, err, "Failed to unsubscribe")
+		return
+	}
+
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		title := ""
+		if subscription != nil {
+			title = subscriptionPostTitle(subscription)
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.UnsubscribedPage(title)))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Unsubscribed successfully",
+		"data":    subscription,
+	})
+}
+
+// subscriptionPostTitle names the post of a subscription on the unsubscribe pages
+func subscriptionPostTitle(subscription *models.Subscription) string {
+	if subscription.Post == nil {
+		return "this post"
+	}
+	return subscription.Post.Title
+}
+
+func sendSubscriptionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Post, comment or subscription not found")
+	case errors.Is(err, services.ErrInvalidSubscription), errors.Is(err, services.ErrInvalidUnsubscribeToken):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/http/responses"
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TranslationHandler interface {
+	GetPostTranslations(c *gin.Context)
+	GetCategoryTranslations(c *gin.Context)
+	LinkPostTranslation(c *gin.Context)
+	UnlinkPostTranslation(c *gin.Context)
+	LinkCategoryTranslation(c *gin.Context)
+	UnlinkCategoryTranslation(c *gin.Context)
+}
+
+type translationHandler struct {
+	BlogService        services.BlogService
+	TranslationService services.TranslationService
+}
+
+func NewTranslationHandler(blogService services.BlogService, translationService services.TranslationService) TranslationHandler {
+	return &translationHandler{BlogService: blogService, TranslationService: translationService}
+}
+
+// GetPostTranslations godoc
+// @Summary      List post translations
+// @Description  The published language versions of a post, the post itself included
+// @Tags         blog
+// @Produce      json
+// @Param        slug path      string true  "Post slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
+// @Success      200  {array}   services.Translation
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/posts/{slug}/translations [get]
+func (h *translationHandler) GetPostTranslations(c *gin.Context) {
+	slug := c.Param("slug")
+
+	post, err := h.BlogService.WithLocale(negotiateLocales(c)).GetPublicPost(slug)
+	if err != nil {
+		var redirect *services.SlugRedirectError
+		if errors.As(err, &redirect) {
+			sendSlugRedirect(c, redirect, slug)
+			return
+		}
+		responses.SendError(c, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	translations, err := h.TranslationService.GetPostTranslations(post)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch translations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Translations retrieved successfully",
+		"data":    translations,
+	})
+}
+
+// GetCategoryTranslations godoc
+// @Summary      List category translations
+// @Description  The active language versions of a category, the category itself included
+// @Tags         blog
+// @Produce      json
+// @Param        slug path      string true  "Category slug"
+// @Param        lang query     string false "Locale, overriding Accept-Language"
+// @Success      200  {array}   services.Translation
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/categories/{slug}/translations [get]
+func (h *translationHandler) GetCategoryTranslations(c *gin.Context) {
+	slug := c.Param("slug")
+
+	category, err := h.BlogService.WithLocale(negotiateLocales(c)).GetCategoryBySlug(slug)
+	if err != nil {
+		var redirect *services.SlugRedirectError
+		if errors.As(err, &redirect) {
+			sendSlugRedirect(c, redirect, slug)
+			return
+		}
+		responses.SendError(c, http.StatusNotFound, "Category not found")
+		return
+	}
+
+	translations, err := h.TranslationService.GetCategoryTranslations(category)
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to fetch translations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Translations retrieved successfully",
+		"data":    translations,
+	})
+}
+
+// LinkPostTranslation godoc
+// @Summary      Link post translation (Admin only)
+// @Description  Make a post a translation of another post; a translation group holds one post per locale
+// @Tags         blog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                          true "Post ID"
+// @Param        translation body      requests.TranslationLinkRequest true "Post it translates"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /blog/posts/{id}/translation [put]
+func (h *translationHandler) LinkPostTranslation(c *gin.Context) {
+	h.link(c, models.MediableTypePost)
+}
+
+// UnlinkPostTranslation godoc
+// @Summary      Unlink post translation (Admin only)
+// @Description  Remove a post from its translation group
+// @Tags         blog
+// @Security     BearerAuth
+// @Param        id   path  string true "Post ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Router       /blog/posts/{id}/translation [delete]
+func (h *translationHandler) UnlinkPostTranslation(c *gin.Context) {
+	h.unlink(c, models.MediableTypePost)
+}
+
+// LinkCategoryTranslation godoc
+// @Summary      Link category translation
+// @Description  Make a category a translation of another category; a translation group holds one category per locale
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                          true "Category ID"
+// @Param        translation body      requests.TranslationLinkRequest true "Category it translates"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Router       /categories/{id}/translation [put]
+func (h *translationHandler) LinkCategoryTranslation(c *gin.Context) {
+	h.link(c, models.MediableTypeCategory)
+}
+
+// UnlinkCategoryTranslation godoc
+// @Summary      Unlink category translation
+// @Description  Remove a category from its translation group
+// @Tags         categories
+// @Security     BearerAuth
+// @Param        id   path  string true "Category ID"
+// @Success      204
+// @Failure      404  {object}  map[string]string
+// @Router       /categories/{id}/translation [delete]
+func (h *translationHandler) UnlinkCategoryTranslation(c *gin.Context) {
+	h.unlink(c, models.MediableTypeCategory)
+}
+
+func (h *translationHandler) link(c *gin.Context, translatableType string) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req requests.TranslationLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.SendError(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err := h.TranslationService.LinkTranslation(translatableType, id, req.TranslationOf)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Translation linked successfully"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		responses.SendError(c, http.StatusNotFound, "Not found")
+	case errors.Is(err, services.ErrTranslationExists):
+		responses.SendError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidTranslation):
+		responses.SendError(c, http.StatusBadRequest, err.Error())
+	default:
+		responses.SendError(c, http.StatusInternalServerError, "Failed to link translation")
+	}
+}
+
+func (h *translationHandler) unlink(c *gin.Context, translatableType string) {
+	id, ok := parseUUIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := h.TranslationService.UnlinkTranslation(translatableType, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.SendError(c, http.StatusNotFound, "Not found")
+		return
+	}
+	if err != nil {
+		responses.SendError(c, http.StatusInternalServerError, "Failed to unlink translation")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 
@@ -204,6 +205,7 @@ func (h *userHandler) UserCreate(c *gin.Context) {
 	if !requests.ValidateRequest(c, &input) {
 		return
 	}
+	var user *models.User
 	if err := database.DB.Transaction(func(tx *gorm.DB) error {
 		roleName := input.Role
 		if roleName == "" {
@@ -214,7 +216,7 @@ func (h *userHandler) UserCreate(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or missing role"})
 			return err
 		}
-		user := &models.User{
+		user = &models.User{
 			Username: input.Username,
 			Email:    input.Email,
 			Roles:    []models.Role{role},
@@ -233,6 +235,7 @@ func (h *userHandler) UserCreate(c *gin.Context) {
 	}); err != nil {
 		return
 	}
+	indexUser(user)
 }
 
 // DeleteUser godoc
@@ -260,6 +263,16 @@ func (h *userHandler) DeleteUser(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
+	if err := services.SearchSvc.DeleteUser(user.ID); err != nil {
+		log.Printf("Warning: failed to remove user %s from search index: %v", user.ID, err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+// indexUser adds a new user to the search index, logging failures
+func indexUser(user *models.User) {
+	if err := services.SearchSvc.IndexUser(user); err != nil {
+		log.Printf("Warning: failed to index user %s: %v", user.ID, err)
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"go-next/internal/models"
 	"go-next/internal/services"
 	"go-next/pkg/database"
@@ -19,48 +20,71 @@ func JWTMiddleware() gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid Authorization header"})
 			return
 		}
-		tokenStr := header[7:]
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok || claims["user_id"] == nil {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			if _, ok := claims["user_id"].(string); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
+		userID, err := parseUserToken(header[7:])
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set("user_id", userID)
+		c.Next()
+	}
+}
 
-			// Try to get JWT key from cache first
-			jwtKeys, err := services.TokenCacheSvc.GetActiveJWTKeys()
-			if err != nil || len(jwtKeys) == 0 {
-				// Cache miss, get from database
-				var jwtKey models.JWTKey
-				if err := database.DB.Where("is_active = ?", true).First(&jwtKey).Error; err != nil {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(jwtKey.Key), nil
+// OptionalJWTMiddleware identifies the user of public endpoints that answer
+// differently when logged in. Requests without a valid token go on anonymously.
+func OptionalJWTMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if strings.HasPrefix(header, "Bearer ") {
+			if userID, err := parseUserToken(header[7:]); err == nil {
+				c.Set("user_id", userID)
 			}
-			return []byte(jwtKeys[0].Key), nil
-		})
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			return
 		}
+		c.Next()
+	}
+}
+
+// parseUserToken verifies a token and returns its user; the error is the message to answer with
+func parseUserToken(tokenStr string) (uuid.UUID, error) {
+	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok || claims["user_id"] == nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			return
+			return nil, jwt.ErrSignatureInvalid
 		}
-		userIDStr, ok := claims["user_id"].(string)
-		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
-			return
+		if _, ok := claims["user_id"].(string); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
-		userID, err := uuid.Parse(userIDStr)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
-			return
+
+		// Try to get JWT key from cache first
+		jwtKeys, err := services.TokenCacheSvc.GetActiveJWTKeys()
+		if err != nil || len(jwtKeys) == 0 {
+			// Cache miss, get from database
+			var jwtKey models.JWTKey
+			if err := database.DB.Where("is_active = ?", true).First(&jwtKey).Error; err != nil {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(jwtKey.Key), nil
 		}
-		c.Set("user_id", userID)
-		c.Next()
+		return []byte(jwtKeys[0].Key), nil
+	})
+	if err != nil || !token.Valid {
+		return uuid.Nil, errors.New("Invalid or expired token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["user_id"] == nil {
+		return uuid.Nil, errors.New("Invalid token claims")
+	}
+	userIDStr, ok := claims["user_id"].(string)
+	if !ok {
+		return uuid.Nil, errors.New("Invalid user ID in token")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, errors.New("Invalid user ID format")
+	}
+	// Access tokens of suspended or deleted users stop working, not only when they expire
+	if active, err := services.TokenCacheSvc.IsUserActive(userID); err != nil || !active {
+		return uuid.Nil, errors.New("Account is inactive")
 	}
+	return userID, nil
 }
@@ -0,0 +1,22 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+)
+
+// AuthorProfileRequest represents the request structure for updating an author profile
+type AuthorProfileRequest struct {
+	DisplayName string     `json:"display_name" binding:"omitempty,max=100" validate:"omitempty,max=100"`
+	Bio         string     `json:"bio" binding:"omitempty,max=5000" validate:"omitempty,max=5000"`
+	AvatarID    *uuid.UUID `json:"avatar_id" binding:"omitempty"`
+	Website     string     `json:"website" binding:"omitempty,httpurl,max=255" validate:"omitempty,httpurl,max=255"`
+	Twitter     string     `json:"twitter" binding:"omitempty,httpurl,max=255" validate:"omitempty,httpurl,max=255"`
+	GitHub      string     `json:"github" binding:"omitempty,httpurl,max=255" validate:"omitempty,httpurl,max=255"`
+	LinkedIn    string     `json:"linkedin" binding:"omitempty,httpurl,max=255" validate:"omitempty,httpurl,max=255"`
+	Mastodon    string     `json:"mastodon" binding:"omitempty,httpurl,max=255" validate:"omitempty,httpurl,max=255"`
+}
+
+// PostAuthorsRequest represents the request structure for setting the authors of a post
+type PostAuthorsRequest struct {
+	Authors []uuid.UUID `json:"authors" binding:"required" validate:"required"`
+}
@@ -0,0 +1,20 @@
+package requests
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// BlockRequest represents the request structure for inserting or updating a content block.
+// Content is a string, or for json blocks either a string or the block object itself.
+type BlockRequest struct {
+	Type     string          `json:"type" binding:"required,oneof=html markdown json text" validate:"required,oneof=html markdown json text"`
+	Content  json.RawMessage `json:"content" binding:"required" validate:"required"`
+	Position *int            `json:"position" binding:"omitempty,min=0" validate:"omitempty,min=0"`
+}
+
+// BlockOrderRequest represents the request structure for reordering the content blocks of a post
+type BlockOrderRequest struct {
+	Order []uuid.UUID `json:"order" binding:"required" validate:"required"`
+}
@@ -7,7 +7,8 @@ import (
 type CategoryCreateRequest struct {
 	Name        string     `json:"name" validate:"required,min=2,max=100"`
 	Description string     `json:"description" validate:"omitempty,max=500"`
-	ParentID    *uuid.UUID `json:"parent_id" validate:"omitempty"` // Optional parent category ID
+	ParentID    *uuid.UUID `json:"parent_id" validate:"omitempty"`     // Optional parent category ID
+	Locale      string     `json:"locale" validate:"omitempty,max=35"` // Defaults to the site language
 }
 
 type CategoryUpdateRequest struct {
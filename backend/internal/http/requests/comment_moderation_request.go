@@ -0,0 +1,16 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+)
+
+// CommentModerationRequest represents the request structure for moderating comments in bulk
+type CommentModerationRequest struct {
+	IDs    []uuid.UUID `json:"ids" binding:"required,min=1,max=100" validate:"required,min=1,max=100"`
+	Action string      `json:"action" binding:"required,oneof=approve reject spam" validate:"required,oneof=approve reject spam"`
+}
+
+// CommentRuleRequest represents the request structure for setting the moderation mode of a category
+type CommentRuleRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=auto trusted manual" validate:"required,oneof=auto trusted manual"`
+}
@@ -4,14 +4,28 @@ import (
 	"github.com/google/uuid"
 )
 
+// CommentCreateRequest is a new comment; its author is the signed-in user
 type CommentCreateRequest struct {
 	Content string    `json:"content" validate:"required,min=1,max=1000"`
-	UserID  uuid.UUID `json:"user_id" validate:"required"`
 	PostID  uuid.UUID `json:"post_id" validate:"required"`
+	// Website is a honeypot: the form hides it from people, so bots are the ones filling it in
+	Website string `json:"website"`
+	// RenderedAt is when the comment form was shown, in Unix milliseconds
+	RenderedAt int64 `json:"rendered_at"`
 }
 
+// CommentUpdateRequest is an edit of a comment by its author
 type CommentUpdateRequest struct {
+	Content string `json:"content" validate:"required,min=1,max=1000"`
+}
+
+// CommentNestedCreateRequest is a new comment placed in the comment tree; its
+// author is the signed-in user
+type CommentNestedCreateRequest struct {
 	Content string    `json:"content" validate:"required,min=1,max=1000"`
-	UserID  uuid.UUID `json:"user_id" validate:"required"`
 	PostID  uuid.UUID `json:"post_id" validate:"required"`
+	// Website is a honeypot: the form hides it from people, so bots are the ones filling it in
+	Website string `json:"website"`
+	// RenderedAt is when the comment form was shown, in Unix milliseconds
+	RenderedAt int64 `json:"rendered_at"`
 }
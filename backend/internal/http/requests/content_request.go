@@ -0,0 +1,7 @@
+package requests
+
+// ContentRenderRequest represents the request structure for previewing rendered content
+type ContentRenderRequest struct {
+	Type    string `json:"type" binding:"required,oneof=html markdown json text" validate:"required,oneof=html markdown json text"`
+	Content string `json:"content" binding:"required" validate:"required"`
+}
@@ -0,0 +1,6 @@
+package requests
+
+// ReactionRequest represents the request structure for reacting to a post or comment
+type ReactionRequest struct {
+	Type string `json:"type" binding:"required,max=32" validate:"required,max=32"`
+}
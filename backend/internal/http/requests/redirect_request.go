@@ -0,0 +1,9 @@
+package requests
+
+// RedirectRequest represents the request structure for creating or updating a redirect rule
+type RedirectRequest struct {
+	SourcePath string `json:"source_path" binding:"required,min=1,max=500" validate:"required,min=1,max=500"`
+	TargetURL  string `json:"target_url" binding:"required,min=1,max=1000" validate:"required,min=1,max=1000"`
+	StatusCode int    `json:"status_code" binding:"omitempty,oneof=301 302 307 308" validate:"omitempty,oneof=301 302 307 308"`
+	IsActive   *bool  `json:"is_active" binding:"omitempty"`
+}
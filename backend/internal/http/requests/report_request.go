@@ -0,0 +1,24 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+)
+
+// ReportRequest represents the request structure for reporting a post or comment
+type ReportRequest struct {
+	ReportableType string    `json:"reportable_type" binding:"required,oneof=post comment" validate:"required,oneof=post comment"`
+	ReportableID   uuid.UUID `json:"reportable_id" binding:"required" validate:"required"`
+	Reason         string    `json:"reason" binding:"required,oneof=spam harassment hate violence sexual misinformation other" validate:"required,oneof=spam harassment hate violence sexual misinformation other"`
+	Details        string    `json:"details" binding:"max=1000" validate:"max=1000"`
+}
+
+// ResolveReportsRequest represents the request structure for resolving the reports of a post or comment
+type ResolveReportsRequest struct {
+	Actions []string `json:"actions" binding:"max=4,dive,oneof=hide delete warn suspend" validate:"max=4,dive,oneof=hide delete warn suspend"`
+	Note    string   `json:"note" binding:"max=1000" validate:"max=1000"`
+}
+
+// DismissReportsRequest represents the request structure for dismissing the reports of a post or comment
+type DismissReportsRequest struct {
+	Note string `json:"note" binding:"max=1000" validate:"max=1000"`
+}
@@ -0,0 +1,12 @@
+package requests
+
+import "github.com/google/uuid"
+
+// SEOMetaRequest represents the request structure for setting the SEO overrides of a post or category
+type SEOMetaRequest struct {
+	MetaTitle       string     `json:"meta_title" binding:"omitempty,max=255" validate:"omitempty,max=255"`
+	MetaDescription string     `json:"meta_description" binding:"omitempty,max=500" validate:"omitempty,max=500"`
+	CanonicalURL    string     `json:"canonical_url" binding:"omitempty,url,max=1000" validate:"omitempty,url,max=1000"`
+	Robots          string     `json:"robots" binding:"omitempty,max=255" validate:"omitempty,max=255"`
+	ImageID         *uuid.UUID `json:"image_id" binding:"omitempty" validate:"omitempty"`
+}
@@ -0,0 +1,24 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+)
+
+// SeriesRequest represents the request structure for creating or updating a series
+type SeriesRequest struct {
+	Title       string `json:"title" binding:"required,min=1,max=255" validate:"required,min=1,max=255"`
+	Slug        string `json:"slug" binding:"required,min=1,max=255" validate:"required,min=1,max=255"`
+	Description string `json:"description" binding:"omitempty" validate:"omitempty"`
+	IsActive    *bool  `json:"is_active" binding:"omitempty"`
+}
+
+// SeriesPostRequest represents the request structure for adding a post to a series
+type SeriesPostRequest struct {
+	PostID   uuid.UUID `json:"post_id" binding:"required" validate:"required"`
+	Position *int      `json:"position" binding:"omitempty,min=0" validate:"omitempty,min=0"`
+}
+
+// SeriesOrderRequest represents the request structure for reordering the posts of a series
+type SeriesOrderRequest struct {
+	Order []uuid.UUID `json:"order" binding:"required" validate:"required"`
+}
@@ -0,0 +1,11 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+)
+
+// SubscriptionRequest represents the request structure for following a post's comments, or one thread
+type SubscriptionRequest struct {
+	PostID    uuid.UUID  `json:"post_id" binding:"required" validate:"required"`
+	CommentID *uuid.UUID `json:"comment_id" binding:"omitempty"`
+}
@@ -0,0 +1,10 @@
+package requests
+
+import (
+	"github.com/google/uuid"
+)
+
+// TranslationLinkRequest represents the request structure for linking a post or category to a translation
+type TranslationLinkRequest struct {
+	TranslationOf uuid.UUID `json:"translation_of" binding:"required" validate:"required"`
+}
@@ -0,0 +1,70 @@
+package responses
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns a strong entity tag derived from the given parts, for
+// responses whose validators are known before their body is built
+func ETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// SendConditional sends a cacheable body with ETag and Last-Modified headers,
+// answering 304 Not Modified when the client's copy is still current
+func SendConditional(c *gin.Context, contentType string, body []byte, lastModified time.Time, maxAge time.Duration) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if NotModified(c, etag, lastModified) {
+		SendNotModified(c, etag, lastModified, maxAge)
+		return
+	}
+	SendWithValidators(c, contentType, body, etag, lastModified, maxAge)
+}
+
+// NotModified reports whether the client's copy of a response with these
+// validators is still current
+func NotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110)
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.UTC().Truncate(time.Second).After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// SendNotModified answers 304 Not Modified with the response's validators
+func SendNotModified(c *gin.Context, etag string, lastModified time.Time, maxAge time.Duration) {
+	setValidators(c, etag, lastModified, maxAge)
+	c.Status(http.StatusNotModified)
+}
+
+// SendWithValidators sends a cacheable body with the given validators
+func SendWithValidators(c *gin.Context, contentType string, body []byte, etag string, lastModified time.Time, maxAge time.Duration) {
+	setValidators(c, etag, lastModified, maxAge)
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func setValidators(c *gin.Context, etag string, lastModified time.Time, maxAge time.Duration) {
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.UTC().Truncate(time.Second).Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+}
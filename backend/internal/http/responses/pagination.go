@@ -124,6 +124,23 @@ func SendLaravelPaginationWithMessage(c *gin.Context, message string, data inter
 	c.JSON(http.StatusOK, fullResponse)
 }
 
+// SendLaravelPaginationWithExtras sends a Laravel-style pagination response with additional top-level fields
+func SendLaravelPaginationWithExtras(c *gin.Context, message string, data interface{}, total int64, currentPage, perPage int64, extras gin.H) {
+	response := CreateLaravelPaginationResponse(c, data, total, currentPage, perPage)
+
+	fullResponse := gin.H{
+		"message": message,
+		"data":    response.Data,
+		"links":   response.Links,
+		"meta":    response.Meta,
+	}
+	for key, value := range extras {
+		fullResponse[key] = value
+	}
+
+	c.JSON(http.StatusOK, fullResponse)
+}
+
 // getBaseURL extracts the base URL from the request
 func getBaseURL(c *gin.Context) string {
 	scheme := "http"
@@ -217,6 +217,8 @@ func getValidationMessage(tag, field, param string) string {
 		return "The " + field + " may only contain letters and numbers."
 	case "url":
 		return "The " + field + " format is invalid."
+	case "httpurl":
+		return "The " + field + " must be an http or https URL."
 	case "uuid":
 		return "The " + field + " must be a valid UUID."
 	case "unique":
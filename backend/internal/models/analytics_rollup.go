@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Analytics metrics aggregated per day
+const (
+	MetricSignups        = "signups"
+	MetricLogins         = "logins"
+	MetricPostsPublished = "posts_published"
+	MetricComments       = "comments"
+	MetricViews          = "views"
+)
+
+// AnalyticsMetrics lists every metric kept in the daily rollups
+var AnalyticsMetrics = []string{MetricSignups, MetricLogins, MetricPostsPublished, MetricComments, MetricViews}
+
+// AnalyticsDailyRollup holds the pre-aggregated value of a metric for a single day
+type AnalyticsDailyRollup struct {
+	Date      time.Time `json:"date" gorm:"type:date;primaryKey"`
+	Metric    string    `json:"metric" gorm:"size:50;primaryKey;index"`
+	Value     int64     `json:"value" gorm:"default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for AnalyticsDailyRollup
+func (AnalyticsDailyRollup) TableName() string {
+	return "analytics_daily_rollups"
+}
+
+// BeforeCreate hook for AnalyticsDailyRollup
+func (r *AnalyticsDailyRollup) BeforeCreate(tx *gorm.DB) error {
+	r.Date = TruncateToDay(r.Date)
+	return nil
+}
+
+// BeforeUpdate hook for AnalyticsDailyRollup
+func (r *AnalyticsDailyRollup) BeforeUpdate(tx *gorm.DB) error {
+	return nil
+}
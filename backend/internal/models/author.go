@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuthorProfile is the public face of a user who writes posts
+type AuthorProfile struct {
+	BaseModel
+	UserID      uuid.UUID  `json:"user_id" gorm:"type:uuid;uniqueIndex;not null"`
+	DisplayName string     `json:"display_name" gorm:"size:100" validate:"omitempty,max=100"`
+	Bio         string     `json:"bio" gorm:"type:text" validate:"omitempty,max=5000"`
+	AvatarID    *uuid.UUID `json:"avatar_id,omitempty" gorm:"type:uuid;index"`
+	Website     string     `json:"website" gorm:"size:255" validate:"omitempty,httpurl,max=255"`
+	Twitter     string     `json:"twitter" gorm:"size:255" validate:"omitempty,httpurl,max=255"`
+	GitHub      string     `json:"github" gorm:"column:github;size:255" validate:"omitempty,httpurl,max=255"`
+	LinkedIn    string     `json:"linkedin" gorm:"column:linkedin;size:255" validate:"omitempty,httpurl,max=255"`
+	Mastodon    string     `json:"mastodon" gorm:"size:255" validate:"omitempty,httpurl,max=255"`
+
+	// Relationships
+	User   *User  `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Avatar *Media `json:"avatar,omitempty" gorm:"foreignKey:AvatarID;constraint:OnDelete:SET NULL"`
+}
+
+// TableName specifies the table name for AuthorProfile
+func (AuthorProfile) TableName() string {
+	return "author_profiles"
+}
+
+// BeforeCreate hook for AuthorProfile
+func (p *AuthorProfile) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// PostAuthor credits a user on a post; Position orders the byline. Posts
+// without credits are attributed to their creator.
+type PostAuthor struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PostID    uuid.UUID `json:"post_id" gorm:"type:uuid;not null;uniqueIndex:idx_post_authors_post_user,priority:1"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_post_authors_post_user,priority:2;index"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Post *Post `json:"-" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for PostAuthor
+func (PostAuthor) TableName() string {
+	return "post_authors"
+}
+
+// BeforeCreate hook for PostAuthor
+func (a *PostAuthor) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// Author is the public byline of a user; it never exposes account details
+type Author struct {
+	ID          uuid.UUID    `json:"id"`
+	Username    string       `json:"username"`
+	DisplayName string       `json:"display_name"`
+	Bio         string       `json:"bio,omitempty"`
+	AvatarURL   string       `json:"avatar_url,omitempty"`
+	URL         string       `json:"url"`
+	Links       []SocialLink `json:"links,omitempty"`
+}
+
+// SocialLink is a link from an author profile to another site
+type SocialLink struct {
+	Network string `json:"network"`
+	URL     string `json:"url"`
+}
+
+// Links lists the social links that are set, in a stable order
+func (p *AuthorProfile) Links() []SocialLink {
+	var links []SocialLink
+	for _, link := range []SocialLink{
+		{"website", p.Website},
+		{"twitter", p.Twitter},
+		{"github", p.GitHub},
+		{"linkedin", p.LinkedIn},
+		{"mastodon", p.Mastodon},
+	} {
+		if link.URL != "" {
+			links = append(links, link)
+		}
+	}
+	return links
+}
@@ -9,15 +9,21 @@ import (
 type Category struct {
 	BaseModelWithOrdering
 	Name        string `json:"name" gorm:"not null;size:100" validate:"required,min=1,max=100"`
-	Slug        string `json:"slug" gorm:"uniqueIndex;not null;size:100" validate:"required,min=1,max=100"`
+	Slug        string `json:"slug" gorm:"uniqueIndex:idx_categories_slug_locale,priority:1;not null;size:100" validate:"required,min=1,max=100"`
 	Description string `json:"description" gorm:"size:500"`
 	IsActive    bool   `json:"is_active" gorm:"default:true;index"`
 	SortOrder   int    `json:"sort_order" gorm:"default:0;index"`
 
+	// Locale is the BCP 47 language of the category; translations of one category share a TranslationGroupID.
+	// The column defaults to the site language, set by the migration.
+	Locale             string     `json:"locale" gorm:"uniqueIndex:idx_categories_slug_locale,priority:2;not null;size:35" validate:"omitempty,max=35"`
+	TranslationGroupID *uuid.UUID `json:"translation_group_id,omitempty" gorm:"type:uuid;index"`
+
 	// Relationships
 	Parent   *Category  `json:"parent,omitempty" gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
 	Children []Category `json:"children,omitempty" gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
 	Posts    []Post     `json:"posts,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL"`
+	SEO      *SEOMeta   `json:"seo,omitempty" gorm:"polymorphic:Seoable;polymorphicValue:category;constraint:OnDelete:CASCADE"`
 }
 
 // TableName specifies the table name for Category
@@ -7,16 +7,44 @@ import (
 	"gorm.io/gorm"
 )
 
+// Comment statuses
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+	CommentStatusSpam     = "spam"
+)
+
 // Comment represents a comment on a post
 type Comment struct {
 	BaseModelWithOrdering
-	Content    string     `json:"content" gorm:"type:text;not null" validate:"required,min=1"`
-	Status     string     `json:"status" gorm:"default:'pending';index" validate:"oneof=pending approved rejected"`
-	IsPublic   bool       `json:"is_public" gorm:"default:true;index"`
+	Content  string `json:"content" gorm:"type:text;not null" validate:"required,min=1"`
+	Status   string `json:"status" gorm:"default:'pending';index" validate:"oneof=pending approved rejected spam"`
+	IsPublic bool   `json:"is_public" gorm:"default:true;index"`
+	// HiddenAt is set while reports keep the comment out of public view; IsPublic is false meanwhile
+	HiddenAt   *time.Time `json:"hidden_at,omitempty" gorm:"index"`
 	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index" validate:"required"`
 	PostID     uuid.UUID  `json:"post_id" gorm:"type:uuid;not null;index" validate:"required"`
 	ApprovedAt *time.Time `json:"approved_at,omitempty" gorm:"index"`
 
+	// ModeratedBy is the moderator of the last decision; automatic approvals leave it empty
+	ModeratedBy *uuid.UUID `json:"moderated_by,omitempty" gorm:"type:uuid;index"`
+	ModeratedAt *time.Time `json:"moderated_at,omitempty"`
+
+	// SpamScore is the screening score from 0 to 1; SpamReport holds each classifier's score and reasons as JSON
+	SpamScore  float64 `json:"spam_score" gorm:"default:0;index"`
+	SpamReport string  `json:"spam_report,omitempty" gorm:"type:text"`
+	// SpamLabel is what the spam filter learned from the moderation of the comment: spam, ham or nothing
+	SpamLabel string `json:"-" gorm:"size:10"`
+
+	// Submission describes how a new or edited comment was sent, for spam screening; it is not stored
+	Submission *CommentSubmission `json:"-" gorm:"-"`
+
+	// ReactionCount is kept in step with the reactions by their reconciliation;
+	// updates omit it. Reactions is filled in for responses.
+	ReactionCount int64            `json:"reaction_count" gorm:"default:0;not null"`
+	Reactions     *ReactionSummary `json:"reactions,omitempty" gorm:"-"`
+
 	// Relationships
 	User     *User     `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 	Post     *Post     `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
@@ -24,6 +52,16 @@ type Comment struct {
 	Children []Comment `json:"children,omitempty" gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
 }
 
+// CommentSubmission is what the server knows about the request that sent a comment
+type CommentSubmission struct {
+	IP        string
+	UserAgent string
+	// Honeypot is the value of the hidden form field that people leave empty
+	Honeypot string
+	// RenderedAt is when the comment form was shown, as reported by the client
+	RenderedAt *time.Time
+}
+
 // TableName specifies the table name for Comment
 func (Comment) TableName() string {
 	return "comments"
@@ -44,17 +82,28 @@ func (c *Comment) BeforeUpdate(tx *gorm.DB) error {
 
 // IsApproved checks if the comment is approved
 func (c *Comment) IsApproved() bool {
-	return c.Status == "approved"
+	return c.Status == CommentStatusApproved
+}
+
+// IsVisible checks if readers can see the comment: approved, public and not
+// hidden by reports
+func (c *Comment) IsVisible() bool {
+	return c.IsApproved() && c.IsPublic && c.HiddenAt == nil
 }
 
 // IsRejected checks if the comment is rejected
 func (c *Comment) IsRejected() bool {
-	return c.Status == "rejected"
+	return c.Status == CommentStatusRejected
 }
 
 // IsPending checks if the comment is pending approval
 func (c *Comment) IsPending() bool {
-	return c.Status == "pending"
+	return c.Status == CommentStatusPending
+}
+
+// IsSpam checks if the comment was marked as spam
+func (c *Comment) IsSpam() bool {
+	return c.Status == CommentStatusSpam
 }
 
 // IsRoot checks if the comment is a root comment
@@ -74,14 +123,20 @@ func (c *Comment) GetDepth() int {
 
 // Approve approves the comment
 func (c *Comment) Approve() {
-	c.Status = "approved"
+	c.Status = CommentStatusApproved
 	now := time.Now()
 	c.ApprovedAt = &now
 }
 
 // Reject rejects the comment
 func (c *Comment) Reject() {
-	c.Status = "rejected"
+	c.Status = CommentStatusRejected
+	c.ApprovedAt = nil
+}
+
+// MarkSpam marks the comment as spam
+func (c *Comment) MarkSpam() {
+	c.Status = CommentStatusSpam
 	c.ApprovedAt = nil
 }
 
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Comment moderation modes of a category
+const (
+	// CommentModeAuto approves every comment
+	CommentModeAuto = "auto"
+	// CommentModeTrusted approves the comments of trusted commenters and queues the rest
+	CommentModeTrusted = "trusted"
+	// CommentModeManual queues every comment for a moderator
+	CommentModeManual = "manual"
+)
+
+// CommentRule sets how new comments on the posts of a category are moderated.
+// Categories without a rule use CommentModeTrusted.
+type CommentRule struct {
+	BaseModel
+	CategoryID uuid.UUID `json:"category_id" gorm:"type:uuid;uniqueIndex;not null"`
+	Mode       string    `json:"mode" gorm:"size:20;not null;default:'trusted'" validate:"required,oneof=auto trusted manual"`
+
+	// Relationships
+	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for CommentRule
+func (CommentRule) TableName() string {
+	return "comment_rules"
+}
+
+// BeforeCreate hook for CommentRule
+func (r *CommentRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
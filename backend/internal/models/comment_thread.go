@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommentNode is an approved comment of a post's discussion with the replies shown under it
+type CommentNode struct {
+	ID        uuid.UUID  `json:"id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Content   string     `json:"content"`
+	Depth     int        `json:"depth"`
+	Author    *Author    `json:"author,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// ReplyCount counts the approved direct replies, TotalReplies every approved reply below
+	ReplyCount   int           `json:"reply_count"`
+	TotalReplies int           `json:"total_replies"`
+	Replies      []CommentNode `json:"replies"`
+	// MoreReplies is the cursor of the replies left out by the reply limit or the depth limit
+	MoreReplies string `json:"more_replies,omitempty"`
+
+	// ReactionCount is the total used to rank the discussion; Reactions is filled in for responses
+	ReactionCount int64            `json:"reaction_count"`
+	Reactions     *ReactionSummary `json:"reactions,omitempty"`
+}
+
+// CommentThread is one page of a post's discussion: the top-level comments,
+// or the replies of one comment when a branch is expanded
+type CommentThread struct {
+	PostID   uuid.UUID     `json:"post_id"`
+	ParentID *uuid.UUID    `json:"parent_id,omitempty"`
+	Sort     string        `json:"sort"`
+	Total    int           `json:"total"`
+	Comments []CommentNode `json:"comments"`
+	// NextCursor loads the next comments of the same level
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -5,6 +5,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Mediable types of models that media can be attached to
+const (
+	MediableTypePost     = "post"
+	MediableTypeCategory = "category"
+)
+
 // Mediable represents a polymorphic relationship between Media and other models
 type Mediable struct {
 	MediaID      uuid.UUID `json:"media_id" gorm:"type:uuid;primaryKey" validate:"required"`
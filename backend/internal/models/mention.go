@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mentionable types of content that can mention users
+const (
+	MentionableTypePost    = "post"
+	MentionableTypeComment = "comment"
+)
+
+// Mention records that a post or comment mentions a user with @username
+type Mention struct {
+	BaseModel
+	UserID          uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_mentions_target_user,priority:3"`
+	MentionableID   uuid.UUID  `json:"mentionable_id" gorm:"type:uuid;not null;uniqueIndex:idx_mentions_target_user,priority:1"`
+	MentionableType string     `json:"mentionable_type" gorm:"size:50;not null;uniqueIndex:idx_mentions_target_user,priority:2"`
+	PostID          uuid.UUID  `json:"post_id" gorm:"type:uuid;not null;index"`
+	MentionedByID   *uuid.UUID `json:"mentioned_by_id,omitempty" gorm:"type:uuid;index"`
+	// NotifiedAt is set once the user is notified: when the post is published or the comment approved
+	NotifiedAt *time.Time `json:"notified_at,omitempty" gorm:"index"`
+
+	// Relationships
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Post *Post `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+
+	// MentionedBy and URL are filled in for responses; they are not columns
+	MentionedBy *Author `json:"mentioned_by,omitempty" gorm:"-"`
+	URL         string  `json:"url,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for Mention
+func (Mention) TableName() string {
+	return "mentions"
+}
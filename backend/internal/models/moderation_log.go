@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Moderation log actions
+const (
+	ModerationActionAutoHide = "auto_hide"
+	ModerationActionResolve  = "resolve"
+	ModerationActionDismiss  = "dismiss"
+	ModerationActionHide     = "hide"
+	ModerationActionWarn     = "warn"
+	ModerationActionSuspend  = "suspend"
+	ModerationActionDelete   = "delete"
+)
+
+// Moderation log target types besides the reportable ones
+const ModerationTargetUser = "user"
+
+// ErrModerationLogImmutable is returned when an entry of the moderation log would be changed
+var ErrModerationLogImmutable = errors.New("moderation log entries cannot be changed")
+
+// ModerationLog records one moderation action. Entries are only ever
+// appended: updating or deleting one fails, in the hooks below and in the
+// database triggers AutoMigrate installs.
+type ModerationLog struct {
+	ID uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	// ModeratorID is empty for automatic actions
+	ModeratorID *uuid.UUID `json:"moderator_id,omitempty" gorm:"type:uuid;index"`
+	Action      string     `json:"action" gorm:"size:30;not null;index"`
+	TargetType  string     `json:"target_type" gorm:"size:50;not null;index:idx_moderation_logs_target,priority:1"`
+	TargetID    uuid.UUID  `json:"target_id" gorm:"type:uuid;not null;index:idx_moderation_logs_target,priority:2"`
+	// SubjectUserID is the user the action is about: the author of the content, or the warned or suspended user
+	SubjectUserID *uuid.UUID `json:"subject_user_id,omitempty" gorm:"type:uuid;index"`
+	Note          string     `json:"note,omitempty" gorm:"type:text"`
+	// Details holds the reports the action closed and other context as JSON
+	Details   string    `json:"details,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for ModerationLog
+func (ModerationLog) TableName() string {
+	return "moderation_logs"
+}
+
+// BeforeUpdate keeps the moderation log append-only
+func (l *ModerationLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrModerationLogImmutable
+}
+
+// BeforeDelete keeps the moderation log append-only
+func (l *ModerationLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrModerationLogImmutable
+}
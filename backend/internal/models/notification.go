@@ -15,6 +15,8 @@ const (
 	NotificationTypeError   NotificationType = "error"
 	NotificationTypeWarning NotificationType = "warning"
 	NotificationTypeInfo    NotificationType = "info"
+	NotificationTypeMention NotificationType = "mention"
+	NotificationTypeComment NotificationType = "comment"
 )
 
 // Notification represents a user notification
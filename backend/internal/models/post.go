@@ -10,21 +10,40 @@ import (
 // Post represents a blog post or article
 type Post struct {
 	BaseModelWithUser
-	Title       string     `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
-	Slug        string     `json:"slug" gorm:"uniqueIndex;not null;size:255" validate:"required,min=1,max=255"`
-	Content     string     `json:"content" gorm:"type:text;not null"`
-	Excerpt     string     `json:"excerpt" gorm:"size:500"`
-	Status      string     `json:"status" gorm:"default:'draft';index" validate:"oneof=draft published archived"`
-	Public      bool       `json:"public" gorm:"default:true;index"`
+	Title   string `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
+	Slug    string `json:"slug" gorm:"uniqueIndex:idx_posts_slug_locale,priority:1;not null;size:255" validate:"required,min=1,max=255"`
+	Content string `json:"content" gorm:"type:text;not null"`
+	Excerpt string `json:"excerpt" gorm:"size:500"`
+	Status  string `json:"status" gorm:"default:'draft';index" validate:"oneof=draft published archived"`
+	Public  bool   `json:"public" gorm:"default:true;index"`
+	// HiddenAt is set while reports keep the post out of public view; Public is false meanwhile
+	HiddenAt    *time.Time `json:"hidden_at,omitempty" gorm:"index"`
 	PublishedAt *time.Time `json:"published_at,omitempty" gorm:"index"`
 	ViewCount   int64      `json:"view_count" gorm:"default:0;index"`
 	CategoryID  *uuid.UUID `json:"category_id,omitempty" gorm:"type:uuid;index"`
 
+	// Locale is the BCP 47 language of the post; translations of one post share a TranslationGroupID.
+	// The column defaults to the site language, set by the migration.
+	Locale             string     `json:"locale" gorm:"uniqueIndex:idx_posts_slug_locale,priority:2;not null;size:35" validate:"omitempty,max=35"`
+	TranslationGroupID *uuid.UUID `json:"translation_group_id,omitempty" gorm:"type:uuid;index"`
+
 	// Relationships
 	Category *Category `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL"`
 	Comments []Comment `json:"comments,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
 	Contents []Content `json:"contents,omitempty" gorm:"polymorphic:Model;polymorphicValue:post;constraint:OnDelete:CASCADE"`
 	Media    []Media   `json:"media,omitempty" gorm:"many2many:mediables;constraint:OnDelete:CASCADE"`
+	SEO      *SEOMeta  `json:"seo,omitempty" gorm:"polymorphic:Seoable;polymorphicValue:post;constraint:OnDelete:CASCADE"`
+
+	// Series is filled in for public responses; it is not a column
+	Series *SeriesNavigation `json:"series,omitempty" gorm:"-"`
+
+	// Authors is the ordered byline, filled in for public responses
+	Authors []Author `json:"authors,omitempty" gorm:"-"`
+
+	// ReactionCount is kept in step with the reactions by their reconciliation;
+	// updates omit it. Reactions is filled in for responses.
+	ReactionCount int64            `json:"reaction_count" gorm:"default:0;not null"`
+	Reactions     *ReactionSummary `json:"reactions,omitempty" gorm:"-"`
 }
 
 // TableName specifies the table name for Post
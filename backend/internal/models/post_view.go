@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostViewDaily holds the flushed view totals of a post for a single day
+type PostViewDaily struct {
+	PostID         uuid.UUID `json:"post_id" gorm:"type:uuid;primaryKey"`
+	Date           time.Time `json:"date" gorm:"type:date;primaryKey;index"`
+	Views          int64     `json:"views" gorm:"default:0"`
+	UniqueVisitors int64     `json:"unique_visitors" gorm:"default:0"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Relationships
+	Post *Post `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for PostViewDaily
+func (PostViewDaily) TableName() string {
+	return "post_view_dailies"
+}
+
+// BeforeCreate hook for PostViewDaily
+func (v *PostViewDaily) BeforeCreate(tx *gorm.DB) error {
+	v.Date = TruncateToDay(v.Date)
+	return nil
+}
+
+// BeforeUpdate hook for PostViewDaily
+func (v *PostViewDaily) BeforeUpdate(tx *gorm.DB) error {
+	return nil
+}
+
+// TruncateToDay returns midnight UTC of the given time's UTC day
+func TruncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Reactable types of content users can react to
+const (
+	ReactableTypePost    = "post"
+	ReactableTypeComment = "comment"
+)
+
+// ReactionLike is always available; the other reaction types are configured emoji
+const ReactionLike = "like"
+
+// Reaction is one user's reaction of one type to a post or comment. A user
+// can react with several types, but with each type once.
+type Reaction struct {
+	BaseModel
+	UserID        uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_reactions_target_user_type,priority:3"`
+	ReactableID   uuid.UUID `json:"reactable_id" gorm:"type:uuid;not null;uniqueIndex:idx_reactions_target_user_type,priority:1"`
+	ReactableType string    `json:"reactable_type" gorm:"size:50;not null;uniqueIndex:idx_reactions_target_user_type,priority:2"`
+	Type          string    `json:"type" gorm:"size:32;not null;uniqueIndex:idx_reactions_target_user_type,priority:4"`
+
+	// Relationships
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for Reaction
+func (Reaction) TableName() string {
+	return "reactions"
+}
+
+// ReactionSummary counts the reactions to a post or comment by type, and
+// lists the types the current user reacted with
+type ReactionSummary struct {
+	Counts  map[string]int64 `json:"counts"`
+	Total   int64            `json:"total"`
+	Reacted []string         `json:"reacted"`
+}
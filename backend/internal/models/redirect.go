@@ -0,0 +1,62 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RedirectStatusCodes are the HTTP statuses a redirect rule may use
+var RedirectStatusCodes = []int{301, 302, 307, 308}
+
+// Redirect is an admin-managed rule sending a public path to another URL
+type Redirect struct {
+	BaseModelWithUser
+	SourcePath string     `json:"source_path" gorm:"size:500;not null;uniqueIndex" validate:"required,min=1,max=500"`
+	TargetURL  string     `json:"target_url" gorm:"size:1000;not null" validate:"required,min=1,max=1000"`
+	StatusCode int        `json:"status_code" gorm:"default:301" validate:"oneof=301 302 307 308"`
+	IsActive   bool       `json:"is_active" gorm:"default:true;index"`
+	HitCount   int64      `json:"hit_count" gorm:"default:0"`
+	LastHitAt  *time.Time `json:"last_hit_at,omitempty"`
+}
+
+// TableName specifies the table name for Redirect
+func (Redirect) TableName() string {
+	return "redirects"
+}
+
+// BeforeCreate hook for Redirect
+func (r *Redirect) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	r.SourcePath = NormalizeRedirectPath(r.SourcePath)
+	if r.StatusCode == 0 {
+		r.StatusCode = 301
+	}
+	return nil
+}
+
+// BeforeUpdate hook for Redirect
+func (r *Redirect) BeforeUpdate(tx *gorm.DB) error {
+	r.SourcePath = NormalizeRedirectPath(r.SourcePath)
+	return nil
+}
+
+// IsPermanent checks if the redirect may be cached by clients
+func (r *Redirect) IsPermanent() bool {
+	return r.StatusCode == 301 || r.StatusCode == 308
+}
+
+// NormalizeRedirectPath reduces a path or URL to its path, with a leading and no trailing slash
+func NormalizeRedirectPath(path string) string {
+	path = strings.TrimSpace(path)
+	if u, err := url.Parse(path); err == nil && u.Path != "" {
+		path = u.Path
+	}
+	path = "/" + strings.Trim(path, "/")
+	return path
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reportable types of content readers can report
+const (
+	ReportableTypePost    = "post"
+	ReportableTypeComment = "comment"
+)
+
+// Report reasons
+const (
+	ReportReasonSpam           = "spam"
+	ReportReasonHarassment     = "harassment"
+	ReportReasonHate           = "hate"
+	ReportReasonViolence       = "violence"
+	ReportReasonSexual         = "sexual"
+	ReportReasonMisinformation = "misinformation"
+	ReportReasonOther          = "other"
+)
+
+// ReportReasons lists every reason a report can give
+var ReportReasons = []string{
+	ReportReasonSpam, ReportReasonHarassment, ReportReasonHate, ReportReasonViolence,
+	ReportReasonSexual, ReportReasonMisinformation, ReportReasonOther,
+}
+
+// Report statuses: open reports wait in the moderation queue until the
+// content is resolved, confirming the reports, or dismissed
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Report flags a post or comment as abusive. A reader reports each piece of content once.
+type Report struct {
+	BaseModel
+	ReporterID     uuid.UUID `json:"reporter_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_reports_target_reporter,priority:3"`
+	ReportableID   uuid.UUID `json:"reportable_id" gorm:"type:uuid;not null;uniqueIndex:idx_reports_target_reporter,priority:1"`
+	ReportableType string    `json:"reportable_type" gorm:"size:50;not null;uniqueIndex:idx_reports_target_reporter,priority:2"`
+	Reason         string    `json:"reason" gorm:"size:30;not null;index"`
+	Details        string    `json:"details,omitempty" gorm:"type:text"`
+	Status         string    `json:"status" gorm:"size:20;not null;default:'open';index"`
+
+	// ResolvedBy is the moderator who closed the report
+	ResolvedBy *uuid.UUID `json:"resolved_by,omitempty" gorm:"type:uuid;index"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	// Relationships
+	Reporter *User `json:"-" gorm:"foreignKey:ReporterID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for Report
+func (Report) TableName() string {
+	return "reports"
+}
+
+// IsOpen reports whether the report still waits for a moderator
+func (r *Report) IsOpen() bool {
+	return r.Status == ReportStatusOpen
+}
+
+// ReportedContent is one post or comment in the moderation queue with its open reports
+type ReportedContent struct {
+	ReportableType string    `json:"reportable_type"`
+	ReportableID   uuid.UUID `json:"reportable_id"`
+	// AuthorID is the user who wrote the content; Excerpt is its title or the start of its text
+	AuthorID *uuid.UUID `json:"author_id,omitempty"`
+	Excerpt  string     `json:"excerpt"`
+	// Hidden is set while the reports keep the content out of public view
+	Hidden        bool             `json:"hidden"`
+	ReportCount   int64            `json:"report_count"`
+	Reasons       map[string]int64 `json:"reasons"`
+	FirstReportAt time.Time        `json:"first_report_at"`
+	LastReportAt  time.Time        `json:"last_report_at"`
+	Reports       []Report         `json:"reports"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Default robots directives for indexable pages
+const RobotsIndexFollow = "index, follow"
+
+// robotsDirectives are the robots meta values accepted without an argument
+var robotsDirectives = map[string]bool{
+	"all": true, "none": true, "index": true, "noindex": true, "follow": true, "nofollow": true,
+	"noarchive": true, "nosnippet": true, "noimageindex": true, "notranslate": true,
+}
+
+// robotsArgDirectives are the robots meta values that take an argument, such as max-snippet:50
+var robotsArgDirectives = map[string]bool{
+	"max-snippet": true, "max-image-preview": true, "max-video-preview": true, "unavailable_after": true,
+}
+
+// SEOMeta holds search engine and social sharing overrides for a post or category
+type SEOMeta struct {
+	SeoableID       uuid.UUID  `json:"seoable_id" gorm:"type:uuid;primaryKey"`
+	SeoableType     string     `json:"seoable_type" gorm:"size:50;primaryKey"`
+	MetaTitle       string     `json:"meta_title" gorm:"size:255" validate:"omitempty,max=255"`
+	MetaDescription string     `json:"meta_description" gorm:"size:500" validate:"omitempty,max=500"`
+	CanonicalURL    string     `json:"canonical_url" gorm:"size:1000" validate:"omitempty,url,max=1000"`
+	Robots          string     `json:"robots" gorm:"size:255" validate:"omitempty,max=255"`
+	ImageID         *uuid.UUID `json:"image_id,omitempty" gorm:"type:uuid;index"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	// Relationships
+	Image *Media `json:"image,omitempty" gorm:"foreignKey:ImageID;constraint:OnDelete:SET NULL"`
+}
+
+// TableName specifies the table name for SEOMeta
+func (SEOMeta) TableName() string {
+	return "seo_metas"
+}
+
+// BeforeCreate hook for SEOMeta
+func (s *SEOMeta) BeforeCreate(tx *gorm.DB) error {
+	s.Robots = NormalizeRobots(s.Robots)
+	return ValidateRobots(s.Robots)
+}
+
+// BeforeUpdate hook for SEOMeta
+func (s *SEOMeta) BeforeUpdate(tx *gorm.DB) error {
+	s.Robots = NormalizeRobots(s.Robots)
+	return ValidateRobots(s.Robots)
+}
+
+// IsEmpty checks if no override is set
+func (s *SEOMeta) IsEmpty() bool {
+	return s.MetaTitle == "" && s.MetaDescription == "" && s.CanonicalURL == "" && s.Robots == "" && s.ImageID == nil
+}
+
+// NormalizeRobots lowercases robots directives and joins them with ", "
+func NormalizeRobots(robots string) string {
+	parts := strings.Split(robots, ",")
+	directives := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			directives = append(directives, part)
+		}
+	}
+	return strings.Join(directives, ", ")
+}
+
+// ValidateRobots checks that every robots directive is known
+func ValidateRobots(robots string) error {
+	if robots == "" {
+		return nil
+	}
+	for _, directive := range strings.Split(robots, ",") {
+		directive = strings.TrimSpace(directive)
+		name, _, hasArg := strings.Cut(directive, ":")
+		if hasArg && robotsArgDirectives[name] {
+			continue
+		}
+		if !hasArg && robotsDirectives[name] {
+			continue
+		}
+		return fmt.Errorf("invalid robots directive %q", directive)
+	}
+	return nil
+}
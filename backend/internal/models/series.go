@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Series is an ordered collection of posts, such as a multi-part tutorial
+type Series struct {
+	BaseModelWithUser
+	Title       string `json:"title" gorm:"not null;size:255" validate:"required,min=1,max=255"`
+	Slug        string `json:"slug" gorm:"uniqueIndex;not null;size:255" validate:"required,min=1,max=255"`
+	Description string `json:"description" gorm:"type:text"`
+	IsActive    bool   `json:"is_active" gorm:"default:true;index"`
+
+	// Relationships
+	Entries []SeriesPost `json:"entries,omitempty" gorm:"foreignKey:SeriesID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for Series
+func (Series) TableName() string {
+	return "series"
+}
+
+// SeriesPost places a post in a series; a post belongs to at most one series
+type SeriesPost struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SeriesID  uuid.UUID `json:"series_id" gorm:"type:uuid;not null;index:idx_series_posts_series_position,priority:1"`
+	PostID    uuid.UUID `json:"post_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Position  int       `json:"position" gorm:"not null;default:0;index:idx_series_posts_series_position,priority:2"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	Post *Post `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for SeriesPost
+func (SeriesPost) TableName() string {
+	return "series_posts"
+}
+
+// BeforeCreate hook for SeriesPost
+func (s *SeriesPost) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SeriesNavigation places a post within its series. Part and Total count
+// published posts only.
+type SeriesNavigation struct {
+	ID       uuid.UUID   `json:"id"`
+	Title    string      `json:"title"`
+	Slug     string      `json:"slug"`
+	Part     int         `json:"part"`
+	Total    int         `json:"total"`
+	Previous *SeriesLink `json:"previous,omitempty"`
+	Next     *SeriesLink `json:"next,omitempty"`
+}
+
+// SeriesLink is a neighbouring post of a series
+type SeriesLink struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Slug  string    `json:"slug"`
+}
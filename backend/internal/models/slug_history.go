@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SlugHistory records a slug a post or category used to have
+type SlugHistory struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	SluggableID   uuid.UUID `json:"sluggable_id" gorm:"type:uuid;not null;index"`
+	SluggableType string    `json:"sluggable_type" gorm:"size:50;not null;uniqueIndex:idx_slug_histories_type_slug"`
+	Slug          string    `json:"slug" gorm:"size:255;not null;uniqueIndex:idx_slug_histories_type_slug"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for SlugHistory
+func (SlugHistory) TableName() string {
+	return "slug_histories"
+}
+
+// BeforeCreate hook for SlugHistory
+func (s *SlugHistory) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
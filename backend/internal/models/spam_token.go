@@ -0,0 +1,14 @@
+package models
+
+// SpamToken counts the spam and ham comments a word appeared in, for the
+// naive-Bayes spam filter
+type SpamToken struct {
+	Token     string `json:"token" gorm:"primaryKey;size:64"`
+	SpamCount int64  `json:"spam_count" gorm:"not null;default:0"`
+	HamCount  int64  `json:"ham_count" gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for SpamToken
+func (SpamToken) TableName() string {
+	return "spam_tokens"
+}
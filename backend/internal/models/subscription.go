@@ -0,0 +1,39 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Reasons a user subscribed
+const (
+	SubscriptionReasonManual    = "manual"
+	SubscriptionReasonAuthor    = "author"
+	SubscriptionReasonCommenter = "commenter"
+)
+
+// Subscription notifies a user of new approved comments on a post, or only of
+// replies in the thread below CommentID when it is set
+type Subscription struct {
+	BaseModel
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_subscriptions_user_post_comment,priority:1"`
+	PostID    uuid.UUID  `json:"post_id" gorm:"type:uuid;not null;uniqueIndex:idx_subscriptions_user_post_comment,priority:2;index"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_subscriptions_user_post_comment,priority:3;index"`
+	Reason    string     `json:"reason" gorm:"size:20;not null;default:'manual'"`
+	// UnsubscribeToken is the random secret of the subscription's email unsubscribe link
+	UnsubscribeToken string `json:"-" gorm:"size:64;not null;uniqueIndex"`
+
+	// Relationships
+	User    *User    `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Post    *Post    `json:"post,omitempty" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"`
+	Comment *Comment `json:"-" gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for Subscription
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// IsThread reports whether the subscription covers one thread rather than the whole post
+func (s *Subscription) IsThread() bool {
+	return s.CommentID != nil
+}
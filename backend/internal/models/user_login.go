@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserLogin records one successful sign-in of a user, so login activity can
+// be counted per day after LastLoginAt has moved on
+type UserLogin struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	IPAddress string    `json:"ip_address" gorm:"size:45"`
+	UserAgent string    `json:"user_agent" gorm:"size:500"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	// Relationships
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName specifies the table name for UserLogin
+func (UserLogin) TableName() string {
+	return "user_logins"
+}
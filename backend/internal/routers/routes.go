@@ -21,18 +21,46 @@ func RegisterRoutes(r *gin.Engine) {
 	roleHandler := controllers.NewRoleHandler(services.RoleSvc)
 	mediaHandler := controllers.NewMediaHandler(mediaSvc)
 	userRoleHandler := controllers.NewUserRoleHandler(services.UserRoleSvc)
-	dashboardHandler := controllers.NewDashboardHandler()
+	dashboardHandler := controllers.NewDashboardHandler(services.ViewSvc, services.AnalyticsSvc)
 
 	// Initialize blog service and handler
 	blogSvc := services.NewBlogService()
-	blogHandler := controllers.NewBlogHandler(blogSvc)
+	blogHandler := controllers.NewBlogHandler(blogSvc, services.SearchSvc)
+	feedHandler := controllers.NewFeedHandler(services.NewFeedService(blogSvc))
+	translationHandler := controllers.NewTranslationHandler(blogSvc, services.TranslationSvc)
+	seriesHandler := controllers.NewSeriesHandler(services.SeriesSvc)
+	authorHandler := controllers.NewAuthorHandler(services.AuthorSvc, blogSvc)
+	moderationHandler := controllers.NewCommentModerationHandler(services.CommentModerationSvc)
+	sitemapHandler := controllers.NewSitemapHandler(services.SitemapSvc)
+	seoHandler := controllers.NewSEOHandler(services.SEOSvc)
+	redirectHandler := controllers.NewRedirectHandler(services.RedirectSvc)
+	contentHandler := controllers.NewContentHandler(services.RenderSvc)
+	blockHandler := controllers.NewBlockHandler(services.BlockSvc)
+	oembedHandler := controllers.NewOEmbedHandler(services.OEmbedSvc)
+	mentionHandler := controllers.NewMentionHandler(services.MentionSvc)
+	subscriptionHandler := controllers.NewSubscriptionHandler(services.SubscriptionSvc)
+	reactionHandler := controllers.NewReactionHandler(services.ReactionSvc)
+	reportHandler := controllers.NewReportHandler(services.ReportSvc)
 
 	// Initialize WebSocket hub and handlers
 	wsHub := services.NewHub()
 	go wsHub.Run()
+	services.GlobalHub = wsHub
 	notificationHandler := controllers.NewNotificationHandler()
 	wsHandler := controllers.NewWebSocketHandler(wsHub)
 
+	// Syndication feeds
+	r.GET("/feed.xml", feedHandler.GetRSSFeed)
+	r.GET("/atom.xml", feedHandler.GetAtomFeed)
+	r.GET("/feed.json", feedHandler.GetJSONFeed)
+
+	// Sitemaps
+	r.GET("/sitemap.xml", sitemapHandler.GetSitemapIndex)
+	r.GET("/sitemaps/:file", sitemapHandler.GetSitemap)
+
+	// oEmbed provider for public posts
+	r.GET("/oembed", oembedHandler.GetOEmbed)
+
 	api := r.Group("/api/v1")
 	api.POST("/register", authHandler.Register)
 	api.POST("/login", authHandler.Login)
@@ -44,22 +72,38 @@ func RegisterRoutes(r *gin.Engine) {
 	posts := api.Group("/posts")
 	{
 		posts.GET("", postHandler.GetPosts)
-		posts.GET(":id", postHandler.GetPost)
+		posts.GET(":id", middleware.OptionalJWTMiddleware(), postHandler.GetPost)
+		posts.GET(":id/comments", middleware.OptionalJWTMiddleware(), commentHandler.GetCommentsByPost)
+		posts.GET(":id/reactions", middleware.OptionalJWTMiddleware(), reactionHandler.GetPostReactions)
+		posts.POST(":id/reactions", middleware.JWTMiddleware(), reactionHandler.ReactToPost)
+		posts.DELETE(":id/reactions/:type", middleware.JWTMiddleware(), reactionHandler.UnreactToPost)
 		posts.POST("", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "POST"), postHandler.CreatePost)
 		posts.PUT(":id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "PUT"), postHandler.UpdatePost)
 		posts.DELETE(":id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "DELETE"), postHandler.DeletePost)
+
+		// Content blocks
+		posts.GET(":id/blocks", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "PUT"), blockHandler.GetBlocks)
+		posts.POST(":id/blocks", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "PUT"), blockHandler.InsertBlock)
+		posts.PUT(":id/blocks/order", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "PUT"), blockHandler.ReorderBlocks)
+		posts.PUT(":id/blocks/:block_id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "PUT"), blockHandler.UpdateBlock)
+		posts.DELETE(":id/blocks/:block_id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/posts", "PUT"), blockHandler.DeleteBlock)
 	}
 
 	// Blog endpoints (public)
 	blog := api.Group("/blog")
 	{
 		// Public blog endpoints
-		blog.GET("/posts", blogHandler.GetPublicPosts)
+		blog.GET("/posts", middleware.OptionalJWTMiddleware(), blogHandler.GetPublicPosts)
 		blog.GET("/posts/featured", blogHandler.GetFeaturedPosts)
 		blog.GET("/posts/popular", blogHandler.GetPopularPosts)
-		blog.GET("/posts/:slug", blogHandler.GetPublicPost)
+		blog.GET("/posts/trending", blogHandler.GetTrendingPosts)
+		blog.GET("/posts/:slug", middleware.OptionalJWTMiddleware(), blogHandler.GetPublicPost)
 		blog.GET("/posts/:slug/related", blogHandler.GetRelatedPosts)
+		blog.GET("/posts/:slug/seo", seoHandler.GetPostSEO)
+		blog.GET("/posts/:slug/rendered", contentHandler.GetRenderedPost)
+		blog.GET("/posts/:slug/translations", translationHandler.GetPostTranslations)
 		blog.GET("/search", blogHandler.SearchPosts)
+		blog.GET("/search/suggest", blogHandler.SearchSuggestions)
 
 		// Blog statistics
 		blog.GET("/stats", blogHandler.GetBlogStats)
@@ -70,9 +114,26 @@ func RegisterRoutes(r *gin.Engine) {
 		blog.GET("/categories", blogHandler.GetPublicCategories)
 		blog.GET("/categories/:slug", blogHandler.GetCategoryBySlug)
 		blog.GET("/categories/:slug/posts", blogHandler.GetPostsByCategory)
+		blog.GET("/categories/:slug/seo", seoHandler.GetCategorySEO)
+		blog.GET("/categories/:slug/translations", translationHandler.GetCategoryTranslations)
+		blog.GET("/categories/:slug/feed.xml", feedHandler.GetCategoryRSSFeed)
+		blog.GET("/categories/:slug/atom.xml", feedHandler.GetCategoryAtomFeed)
+		blog.GET("/categories/:slug/feed.json", feedHandler.GetCategoryJSONFeed)
 		blog.GET("/tags", blogHandler.GetPublicTags)
 		blog.GET("/tags/:slug", blogHandler.GetTagBySlug)
 		blog.GET("/tags/:slug/posts", blogHandler.GetPostsByTag)
+		blog.GET("/tags/:slug/feed.xml", feedHandler.GetTagRSSFeed)
+		blog.GET("/tags/:slug/atom.xml", feedHandler.GetTagAtomFeed)
+		blog.GET("/tags/:slug/feed.json", feedHandler.GetTagJSONFeed)
+		blog.GET("/series", seriesHandler.GetPublicSeries)
+		blog.GET("/series/:slug", seriesHandler.GetSeriesBySlug)
+		blog.GET("/series/:slug/feed.xml", feedHandler.GetSeriesRSSFeed)
+		blog.GET("/series/:slug/atom.xml", feedHandler.GetSeriesAtomFeed)
+		blog.GET("/series/:slug/feed.json", feedHandler.GetSeriesJSONFeed)
+		blog.GET("/authors/:username", authorHandler.GetAuthor)
+		blog.GET("/authors/:username/feed.xml", feedHandler.GetAuthorRSSFeed)
+		blog.GET("/authors/:username/atom.xml", feedHandler.GetAuthorAtomFeed)
+		blog.GET("/authors/:username/feed.json", feedHandler.GetAuthorJSONFeed)
 
 		// View count tracking
 		blog.POST("/posts/:id/view", blogHandler.IncrementViewCount)
@@ -84,8 +145,42 @@ func RegisterRoutes(r *gin.Engine) {
 		blog.POST("/posts/:id/publish", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "POST"), blogHandler.PublishPost)
 		blog.POST("/posts/:id/unpublish", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "POST"), blogHandler.UnpublishPost)
 		blog.POST("/posts/:id/archive", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "POST"), blogHandler.ArchivePost)
+		blog.PUT("/posts/:id/seo", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "PUT"), seoHandler.UpdatePostSEO)
+		blog.PUT("/posts/:id/translation", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "PUT"), translationHandler.LinkPostTranslation)
+		blog.DELETE("/posts/:id/translation", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "PUT"), translationHandler.UnlinkPostTranslation)
+		blog.PUT("/posts/:id/authors", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/blog/posts", "PUT"), authorHandler.SetPostAuthors)
 	}
 
+	// Content rendering previews
+	api.POST("/content/render", middleware.JWTMiddleware(), contentHandler.RenderContent)
+	api.GET("/oembed/resolve", middleware.JWTMiddleware(), oembedHandler.ResolveEmbed)
+
+	// Author profile of the current user
+	api.GET("/profile/author", middleware.JWTMiddleware(), authorHandler.GetMyAuthorProfile)
+	api.PUT("/profile/author", middleware.JWTMiddleware(), authorHandler.UpdateMyAuthorProfile)
+
+	// Mentions of the current user
+	api.GET("/mentions", middleware.JWTMiddleware(), mentionHandler.GetMentions)
+
+	// Reports of abusive posts and comments
+	api.POST("/reports", middleware.JWTMiddleware(), reportHandler.CreateReport)
+
+	// Reaction types offered to users
+	api.GET("/reactions/types", reactionHandler.GetReactionTypes)
+
+	// Comment subscriptions; the unsubscribe links sent by email need no login
+	subscriptions := api.Group("/subscriptions")
+	{
+		subscriptions.GET("", middleware.JWTMiddleware(), subscriptionHandler.GetSubscriptions)
+		subscriptions.POST("", middleware.JWTMiddleware(), subscriptionHandler.Subscribe)
+		subscriptions.DELETE(":id", middleware.JWTMiddleware(), subscriptionHandler.Unsubscribe)
+		subscriptions.GET("/unsubscribe", subscriptionHandler.ConfirmUnsubscribe)
+		subscriptions.POST("/unsubscribe", subscriptionHandler.UnsubscribeByToken)
+	}
+
+	// Redirects (public resolution of old paths)
+	api.GET("/redirects/resolve", redirectHandler.ResolveRedirect)
+
 	// Categories
 	categories := api.Group("/categories")
 	{
@@ -94,6 +189,9 @@ func RegisterRoutes(r *gin.Engine) {
 		categories.GET(":id/children", categoryHandler.GetChildrenCategories)
 		categories.POST("", middleware.JWTMiddleware(), categoryHandler.CreateCategory)
 		categories.PUT(":id", middleware.JWTMiddleware(), categoryHandler.UpdateCategory)
+		categories.PUT(":id/seo", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/categories", "PUT"), seoHandler.UpdateCategorySEO)
+		categories.PUT(":id/translation", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/categories", "PUT"), translationHandler.LinkCategoryTranslation)
+		categories.DELETE(":id/translation", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/categories", "PUT"), translationHandler.UnlinkCategoryTranslation)
 		categories.DELETE(":id", middleware.JWTMiddleware(), categoryHandler.DeleteCategory)
 		categories.POST("/nested", middleware.JWTMiddleware(), categoryHandler.CreateCategoryNested)
 		categories.POST(":id/move", middleware.JWTMiddleware(), categoryHandler.MoveCategoryNested)
@@ -103,7 +201,10 @@ func RegisterRoutes(r *gin.Engine) {
 	// Comments
 	comments := api.Group("/comments")
 	{
-		comments.GET(":id", commentHandler.GetComment)
+		comments.GET(":id", middleware.OptionalJWTMiddleware(), commentHandler.GetComment)
+		comments.GET(":id/reactions", middleware.OptionalJWTMiddleware(), reactionHandler.GetCommentReactions)
+		comments.POST(":id/reactions", middleware.JWTMiddleware(), reactionHandler.ReactToComment)
+		comments.DELETE(":id/reactions/:type", middleware.JWTMiddleware(), reactionHandler.UnreactToComment)
 		comments.GET(":id/siblings", commentHandler.GetSiblingComments)
 		comments.GET(":id/parent", commentHandler.GetParentComment)
 		comments.GET(":id/descendants", commentHandler.GetDescendantComments)
@@ -155,6 +256,8 @@ func RegisterRoutes(r *gin.Engine) {
 	dashboard := api.Group("/dashboard")
 	{
 		dashboard.GET("/stats", middleware.JWTMiddleware(), dashboardHandler.GetDashboardStats)
+		dashboard.GET("/analytics", middleware.JWTMiddleware(), dashboardHandler.GetAnalytics)
+		dashboard.GET("/posts/:id/views", middleware.JWTMiddleware(), dashboardHandler.GetPostViewHistory)
 	}
 
 	// Notifications
@@ -179,6 +282,37 @@ func RegisterRoutes(r *gin.Engine) {
 	admin := api.Group("/admin")
 	{
 		admin.POST("/notifications", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/notifications", "POST"), notificationHandler.CreateNotification)
+
+		// Redirect rules
+		admin.GET("/redirects", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/redirects", "GET"), redirectHandler.GetRedirects)
+		admin.GET("/redirects/:id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/redirects", "GET"), redirectHandler.GetRedirect)
+		admin.POST("/redirects", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/redirects", "POST"), redirectHandler.CreateRedirect)
+		admin.PUT("/redirects/:id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/redirects", "PUT"), redirectHandler.UpdateRedirect)
+		admin.DELETE("/redirects/:id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/redirects", "DELETE"), redirectHandler.DeleteRedirect)
+
+		// Series
+		admin.GET("/series", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "GET"), seriesHandler.GetSeries)
+		admin.GET("/series/:id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "GET"), seriesHandler.GetSeriesByID)
+		admin.POST("/series", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "POST"), seriesHandler.CreateSeries)
+		admin.PUT("/series/:id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "PUT"), seriesHandler.UpdateSeries)
+		admin.DELETE("/series/:id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "DELETE"), seriesHandler.DeleteSeries)
+		admin.GET("/series/:id/posts", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "GET"), seriesHandler.GetSeriesEntries)
+		admin.POST("/series/:id/posts", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "PUT"), seriesHandler.AddSeriesPost)
+		admin.PUT("/series/:id/posts/order", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "PUT"), seriesHandler.ReorderSeriesPosts)
+		admin.DELETE("/series/:id/posts/:post_id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/series", "PUT"), seriesHandler.RemoveSeriesPost)
+
+		// Comment moderation
+		admin.GET("/comments", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/comments", "GET"), moderationHandler.GetModerationQueue)
+		admin.POST("/comments/moderate", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/comments", "PUT"), moderationHandler.ModerateComments)
+		admin.GET("/comment-rules", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/comments", "GET"), moderationHandler.GetCommentRules)
+		admin.PUT("/comment-rules/:category_id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/comments", "PUT"), moderationHandler.SetCommentRule)
+		admin.DELETE("/comment-rules/:category_id", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/comments", "DELETE"), moderationHandler.DeleteCommentRule)
+
+		// Content reports and the moderation log
+		admin.GET("/reports", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/reports", "GET"), reportHandler.GetReportQueue)
+		admin.POST("/reports/:type/:id/resolve", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/reports", "PUT"), reportHandler.ResolveReports)
+		admin.POST("/reports/:type/:id/dismiss", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/reports", "PUT"), reportHandler.DismissReports)
+		admin.GET("/moderation-log", middleware.JWTMiddleware(), middleware.CasbinMiddleware("/api/admin/moderation-log", "GET"), reportHandler.GetModerationLog)
 	}
 
 }
@@ -1,5 +1,33 @@
 package rules
 
-import "github.com/go-playground/validator/v10"
+import (
+	"net/url"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
 
 var Validate = validator.New()
+
+func init() {
+	Register(Validate)
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		Register(engine)
+	}
+}
+
+// Register adds the custom validations to a validator, so binding and
+// validate tags accept the same rules
+func Register(v *validator.Validate) {
+	_ = v.RegisterValidation("httpurl", httpURL)
+}
+
+// httpURL accepts absolute http and https URLs. The url rule also passes
+// javascript: and data: links, which must never be rendered as hrefs.
+func httpURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
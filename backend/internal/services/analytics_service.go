@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/cronjob"
+	"go-next/pkg/database"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Supported analytics intervals
+const (
+	AnalyticsIntervalDay   = "day"
+	AnalyticsIntervalWeek  = "week"
+	AnalyticsIntervalMonth = "month"
+)
+
+const (
+	// analyticsRollupInterval is how often today's and yesterday's rollups, and
+	// the days marked as changed, are refreshed
+	analyticsRollupInterval = 15 * time.Minute
+
+	// analyticsMaxRange caps how many days a single report may span
+	analyticsMaxRange = 731
+
+	// analyticsDirtyDaysKey is the set of earlier days whose rollups must be
+	// recomputed, such as the day a since unapproved comment was approved
+	analyticsDirtyDaysKey = "analytics:dirty_days"
+
+	// analyticsDirtyDaysBatch is how many dirty days one rollup run claims at a time
+	analyticsDirtyDaysBatch = 100
+)
+
+var (
+	ErrInvalidAnalyticsInterval = errors.New("invalid analytics interval")
+	ErrInvalidAnalyticsRange    = errors.New("invalid analytics date range")
+)
+
+type AnalyticsService interface {
+	Rollup(ctx context.Context, from, to time.Time) error
+	RollupDirtyDays(ctx context.Context) error
+	RecordLogin(userID uuid.UUID, ipAddress, userAgent string) error
+	GetReport(from, to time.Time, interval string) (*AnalyticsReport, error)
+	Schedule() error
+}
+
+// AnalyticsPoint is the value of a metric for one period
+type AnalyticsPoint struct {
+	Period string `json:"period"`
+	Value  int64  `json:"value"`
+}
+
+// AnalyticsMetric is a metric over the selected range compared with the previous one
+type AnalyticsMetric struct {
+	Total         int64            `json:"total"`
+	PreviousTotal int64            `json:"previous_total"`
+	Change        *float64         `json:"change"`
+	Series        []AnalyticsPoint `json:"series"`
+}
+
+// AnalyticsReport holds every metric for a date range
+type AnalyticsReport struct {
+	From         string                      `json:"from"`
+	To           string                      `json:"to"`
+	PreviousFrom string                      `json:"previous_from"`
+	PreviousTo   string                      `json:"previous_to"`
+	Interval     string                      `json:"interval"`
+	Metrics      map[string]*AnalyticsMetric `json:"metrics"`
+}
+
+type analyticsService struct{}
+
+func NewAnalyticsService() AnalyticsService {
+	return &analyticsService{}
+}
+
+// Rollup recomputes the daily rollups of every day between from and to
+func (s *analyticsService) Rollup(ctx context.Context, from, to time.Time) error {
+	from, to = models.TruncateToDay(from), models.TruncateToDay(to)
+	if to.Before(from) {
+		return ErrInvalidAnalyticsRange
+	}
+
+	db := database.DB.WithContext(ctx)
+
+	var existing []models.AnalyticsDailyRollup
+	if err := db.Where("date >= ? AND date <= ?", from, to).Find(&existing).Error; err != nil {
+		return err
+	}
+	current := make(map[string]int64, len(existing))
+	for _, row := range existing {
+		current[rollupKey(row.Date, row.Metric)] = row.Value
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		values, err := computeDailyMetrics(db, day)
+		if err != nil {
+			return err
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			for _, metric := range models.AnalyticsMetrics {
+				value := values[metric]
+				previous, ok := current[rollupKey(day, metric)]
+				if !ok {
+					row := models.AnalyticsDailyRollup{Date: day, Metric: metric, Value: value, UpdatedAt: time.Now()}
+					if err := tx.Create(&row).Error; err != nil {
+						return err
+					}
+					continue
+				}
+				if value == previous {
+					continue
+				}
+				if err := tx.Model(&models.AnalyticsDailyRollup{}).
+					Where("date = ? AND metric = ?", day, metric).
+					Updates(map[string]interface{}{"value": value, "updated_at": time.Now()}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// computeDailyMetrics counts every metric for the day starting at day
+func computeDailyMetrics(db *gorm.DB, day time.Time) (map[string]int64, error) {
+	next := day.AddDate(0, 0, 1)
+	values := make(map[string]int64, len(models.AnalyticsMetrics))
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("created_at >= ? AND created_at < ?", day, next).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	values[models.MetricSignups] = count
+
+	if err := db.Model(&models.UserLogin{}).Where("created_at >= ? AND created_at < ?", day, next).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	values[models.MetricLogins] = count
+
+	if err := db.Model(&models.Post{}).Where("status = ? AND published_at >= ? AND published_at < ?", "published", day, next).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	values[models.MetricPostsPublished] = count
+
+	// Comments count on the day they were approved; pending, rejected and spam ones were never published
+	if err := db.Model(&models.Comment{}).Where("status = ? AND approved_at >= ? AND approved_at < ?", models.CommentStatusApproved, day, next).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	values[models.MetricComments] = count
+
+	var views int64
+	if err := db.Model(&models.PostViewDaily{}).Select("COALESCE(SUM(views), 0)").Where("date = ?", day).Scan(&views).Error; err != nil {
+		return nil, err
+	}
+	values[models.MetricViews] = views
+
+	return values, nil
+}
+
+// RollupDirtyDays recomputes the rollups of the days marked as changed.
+// Each day is claimed from the set before it is recomputed, so instances
+// never repeat each other's work; days that fail are marked again.
+func (s *analyticsService) RollupDirtyDays(ctx context.Context) error {
+	if GlobalRedisClient == nil {
+		return nil
+	}
+	for {
+		days, err := GlobalRedisClient.SPopN(ctx, analyticsDirtyDaysKey, analyticsDirtyDaysBatch)
+		if err != nil || len(days) == 0 {
+			return err
+		}
+		for i, value := range days {
+			day, err := time.Parse(viewDateLayout, value)
+			if err != nil {
+				continue
+			}
+			if err := s.Rollup(ctx, day, day); err != nil {
+				for _, remaining := range days[i:] {
+					markAnalyticsDay(ctx, remaining)
+				}
+				return err
+			}
+		}
+	}
+}
+
+// RecordLogin stores a successful sign-in for the logins metric
+func (s *analyticsService) RecordLogin(userID uuid.UUID, ipAddress, userAgent string) error {
+	return database.DB.Create(&models.UserLogin{UserID: userID, IPAddress: ipAddress, UserAgent: userAgent}).Error
+}
+
+// GetReport reads the rollups for a range and the equally long range before it
+func (s *analyticsService) GetReport(from, to time.Time, interval string) (*AnalyticsReport, error) {
+	if interval != AnalyticsIntervalDay && interval != AnalyticsIntervalWeek && interval != AnalyticsIntervalMonth {
+		return nil, ErrInvalidAnalyticsInterval
+	}
+	from, to = models.TruncateToDay(from), models.TruncateToDay(to)
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days < 1 || days > analyticsMaxRange {
+		return nil, ErrInvalidAnalyticsRange
+	}
+
+	previousTo := from.AddDate(0, 0, -1)
+	previousFrom := previousTo.AddDate(0, 0, -(days - 1))
+
+	var rows []models.AnalyticsDailyRollup
+	if err := database.DB.Where("date >= ? AND date <= ?", previousFrom, to).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	report := &AnalyticsReport{
+		From:         from.Format(viewDateLayout),
+		To:           to.Format(viewDateLayout),
+		PreviousFrom: previousFrom.Format(viewDateLayout),
+		PreviousTo:   previousTo.Format(viewDateLayout),
+		Interval:     interval,
+		Metrics:      make(map[string]*AnalyticsMetric, len(models.AnalyticsMetrics)),
+	}
+
+	// Every period in the range is present, even without data
+	periods := make([]string, 0)
+	seen := make(map[string]bool)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		period := analyticsPeriod(day, interval)
+		if !seen[period] {
+			seen[period] = true
+			periods = append(periods, period)
+		}
+	}
+
+	buckets := make(map[string]map[string]int64, len(models.AnalyticsMetrics))
+	for _, metric := range models.AnalyticsMetrics {
+		report.Metrics[metric] = &AnalyticsMetric{}
+		buckets[metric] = make(map[string]int64)
+	}
+
+	for _, row := range rows {
+		metric, ok := report.Metrics[row.Metric]
+		if !ok {
+			continue
+		}
+		day := models.TruncateToDay(row.Date)
+		if day.Before(from) {
+			metric.PreviousTotal += row.Value
+			continue
+		}
+		metric.Total += row.Value
+		buckets[row.Metric][analyticsPeriod(day, interval)] += row.Value
+	}
+
+	for name, metric := range report.Metrics {
+		metric.Series = make([]AnalyticsPoint, 0, len(periods))
+		for _, period := range periods {
+			metric.Series = append(metric.Series, AnalyticsPoint{Period: period, Value: buckets[name][period]})
+		}
+		if metric.PreviousTotal > 0 {
+			change := float64(metric.Total-metric.PreviousTotal) / float64(metric.PreviousTotal) * 100
+			metric.Change = &change
+		}
+	}
+
+	return report, nil
+}
+
+// Schedule registers the job that keeps recent rollups up to date
+func (s *analyticsService) Schedule() error {
+	_, err := cronjob.AddJob(gocron.DurationJob(analyticsRollupInterval), func() {
+		ctx := context.Background()
+		now := time.Now()
+		if err := s.Rollup(ctx, now.AddDate(0, 0, -1), now); err != nil {
+			log.Printf("Warning: failed to roll up analytics: %v", err)
+		}
+		if err := s.RollupDirtyDays(ctx); err != nil {
+			log.Printf("Warning: failed to roll up changed analytics days: %v", err)
+		}
+	}, gocron.WithName("analytics_rollup"))
+	return err
+}
+
+// analyticsPeriod labels the day, ISO week (by its Monday) or month a day falls in
+func analyticsPeriod(day time.Time, interval string) string {
+	switch interval {
+	case AnalyticsIntervalWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset).Format(viewDateLayout)
+	case AnalyticsIntervalMonth:
+		return day.Format("2006-01")
+	default:
+		return day.Format(viewDateLayout)
+	}
+}
+
+// markAnalyticsDay queues the rollup of a day, given as YYYY-MM-DD, to be
+// recomputed by the next scheduled run
+func markAnalyticsDay(ctx context.Context, day string) {
+	if GlobalRedisClient == nil {
+		return
+	}
+	if err := GlobalRedisClient.SAdd(ctx, analyticsDirtyDaysKey, day); err != nil {
+		log.Printf("Warning: failed to mark analytics day %s as changed: %v", day, err)
+	}
+}
+
+// recordCommentApproval marks the day a comment was counted on once it no
+// longer counts there, because it was unapproved, re-approved or deleted.
+// New approvals fall on today, which every scheduled run recomputes.
+func recordCommentApproval(previousApprovedAt, approvedAt *time.Time) {
+	if previousApprovedAt == nil {
+		return
+	}
+	previous := models.TruncateToDay(*previousApprovedAt)
+	if approvedAt != nil && models.TruncateToDay(*approvedAt).Equal(previous) {
+		return
+	}
+	markAnalyticsDay(context.Background(), previous.Format(viewDateLayout))
+}
+
+func rollupKey(day time.Time, metric string) string {
+	return models.TruncateToDay(day).Format(viewDateLayout) + ":" + metric
+}
+
+var AnalyticsSvc AnalyticsService = NewAnalyticsService()
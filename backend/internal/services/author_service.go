@@ -0,0 +1,327 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/rules"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidAuthors       = errors.New("invalid post authors")
+	ErrInvalidAuthorProfile = errors.New("invalid author profile")
+)
+
+type AuthorService interface {
+	// Public author pages
+	GetAuthorByUsername(username string) (*models.Author, error)
+	GetPostAuthors(postID uuid.UUID) ([]models.Author, error)
+	AttachAuthors(posts []models.Post) error
+
+	// Attribution and profile management
+	SetPostAuthors(postID uuid.UUID, userIDs []uuid.UUID) ([]models.Author, error)
+	GetProfile(userID uuid.UUID) (*models.AuthorProfile, error)
+	UpdateProfile(profile *models.AuthorProfile) (*models.AuthorProfile, error)
+}
+
+type authorService struct{}
+
+func NewAuthorService() AuthorService {
+	return &authorService{}
+}
+
+// GetAuthorByUsername retrieves an active user who is credited on at least one public post
+func (s *authorService) GetAuthorByUsername(username string) (*models.Author, error) {
+	var user models.User
+	if err := database.DB.Select("id, username").
+		Where("username = ? AND is_active = ?", username, true).
+		First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	var published int64
+	if err := database.DB.Model(&models.Post{}).
+		Scopes(authoredBy(user.ID)).
+		Where("posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL", "published", true).
+		Limit(1).
+		Count(&published).Error; err != nil {
+		return nil, err
+	}
+	if published == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	authors, err := loadAuthors([]uuid.UUID{user.ID})
+	if err != nil {
+		return nil, err
+	}
+	author := authors[user.ID]
+	return &author, nil
+}
+
+// GetPostAuthors returns the byline of a post
+func (s *authorService) GetPostAuthors(postID uuid.UUID) ([]models.Author, error) {
+	var post models.Post
+	if err := database.DB.Select("id, created_by").First(&post, "id = ?", postID).Error; err != nil {
+		return nil, err
+	}
+	authorsByPost, err := loadPostAuthors([]models.Post{post})
+	if err != nil {
+		return nil, err
+	}
+	return authorsByPost[post.ID], nil
+}
+
+// AttachAuthors fills in the byline of each post
+func (s *authorService) AttachAuthors(posts []models.Post) error {
+	return attachAuthors(posts)
+}
+
+// SetPostAuthors replaces the credits of a post with users in byline order.
+// An empty list drops the credits, attributing the post to its creator again.
+func (s *authorService) SetPostAuthors(postID uuid.UUID, userIDs []uuid.UUID) ([]models.Author, error) {
+	seen := make(map[uuid.UUID]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if seen[userID] {
+			return nil, fmt.Errorf("%w: user %s is listed twice", ErrInvalidAuthors, userID)
+		}
+		seen[userID] = true
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the post so concurrent edits of its byline are serialized
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Post{}, "id = ?", postID).Error; err != nil {
+			return err
+		}
+
+		if len(userIDs) > 0 {
+			var active int64
+			if err := tx.Model(&models.User{}).Where("id IN ? AND is_active = ?", userIDs, true).Count(&active).Error; err != nil {
+				return err
+			}
+			if int(active) != len(userIDs) {
+				return fmt.Errorf("%w: every author must be an active user", ErrInvalidAuthors)
+			}
+		}
+
+		if err := tx.Where("post_id = ?", postID).Delete(&models.PostAuthor{}).Error; err != nil {
+			return err
+		}
+		for i, userID := range userIDs {
+			if err := tx.Create(&models.PostAuthor{PostID: postID, UserID: userID, Position: i}).Error; err != nil {
+				return err
+			}
+		}
+		// The byline is part of the post, so feeds and sitemaps see it change
+		return tx.Model(&models.Post{}).Where("id = ?", postID).UpdateColumn("updated_at", time.Now()).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	syncPostIndex(postID)
+	for _, userID := range userIDs {
+		if _, err := subscribe(userID, postID, nil, models.SubscriptionReasonAuthor); err != nil {
+			log.Printf("Warning: failed to subscribe author %s to post %s: %v", userID, postID, err)
+		}
+	}
+	return s.GetPostAuthors(postID)
+}
+
+// GetProfile returns the author profile of a user; users without one get an empty profile
+func (s *authorService) GetProfile(userID uuid.UUID) (*models.AuthorProfile, error) {
+	if err := database.DB.Select("id").First(&models.User{}, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	var profile models.AuthorProfile
+	err := database.DB.Preload("Avatar").Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.AuthorProfile{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateProfile creates or replaces the author profile of profile.UserID.
+// The avatar must be a public image from the media library.
+func (s *authorService) UpdateProfile(profile *models.AuthorProfile) (*models.AuthorProfile, error) {
+	if err := rules.Validate.Struct(profile); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAuthorProfile, err)
+	}
+	if profile.AvatarID != nil {
+		var avatar models.Media
+		if err := database.DB.Select("id, mime_type, is_public").First(&avatar, "id = ?", *profile.AvatarID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: avatar not found", ErrInvalidAuthorProfile)
+			}
+			return nil, err
+		}
+		if !avatar.IsImage() || !avatar.IsPublic {
+			return nil, fmt.Errorf("%w: avatar must be a public image", ErrInvalidAuthorProfile)
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.User{}, "id = ?", profile.UserID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.AuthorProfile{}).Where("user_id = ?", profile.UserID).
+			Select("display_name", "bio", "avatar_id", "website", "twitter", "github", "linkedin", "mastodon").
+			Updates(profile)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+		return tx.Omit("User", "Avatar").Create(profile).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetProfile(profile.UserID)
+}
+
+// authoredBy limits a post query to the posts credited to a user, and the
+// posts they created that have no credits
+func authoredBy(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(posts.id IN (SELECT post_id FROM post_authors WHERE user_id = ?) OR "+
+			"(posts.created_by = ? AND NOT EXISTS (SELECT 1 FROM post_authors WHERE post_authors.post_id = posts.id)))",
+			userID, userID)
+	}
+}
+
+// attachAuthors fills in the byline of each post
+func attachAuthors(posts []models.Post) error {
+	authorsByPost, err := loadPostAuthors(posts)
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		posts[i].Authors = authorsByPost[posts[i].ID]
+	}
+	return nil
+}
+
+// attachPostAuthors fills in the byline of a post
+func attachPostAuthors(post *models.Post) error {
+	authorsByPost, err := loadPostAuthors([]models.Post{*post})
+	if err != nil {
+		return err
+	}
+	post.Authors = authorsByPost[post.ID]
+	return nil
+}
+
+// loadPostAuthors returns the byline of each post, keyed by post ID: its
+// credited users in order, or its creator when it has no credits
+func loadPostAuthors(posts []models.Post) (map[uuid.UUID][]models.Author, error) {
+	authorsByPost := make(map[uuid.UUID][]models.Author, len(posts))
+	if len(posts) == 0 {
+		return authorsByPost, nil
+	}
+
+	var credits []models.PostAuthor
+	if err := database.DB.Where("post_id IN ?", postIDs(posts)).
+		Order("position ASC, created_at ASC").
+		Find(&credits).Error; err != nil {
+		return nil, err
+	}
+
+	usersByPost := make(map[uuid.UUID][]uuid.UUID, len(posts))
+	for _, credit := range credits {
+		usersByPost[credit.PostID] = append(usersByPost[credit.PostID], credit.UserID)
+	}
+	userIDs := make([]uuid.UUID, 0, len(posts))
+	seen := make(map[uuid.UUID]bool)
+	for _, post := range posts {
+		if _, ok := usersByPost[post.ID]; !ok && post.CreatedBy != nil {
+			usersByPost[post.ID] = []uuid.UUID{*post.CreatedBy}
+		}
+		for _, userID := range usersByPost[post.ID] {
+			if !seen[userID] {
+				seen[userID] = true
+				userIDs = append(userIDs, userID)
+			}
+		}
+	}
+
+	authors, err := loadAuthors(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for postID, users := range usersByPost {
+		for _, userID := range users {
+			if author, ok := authors[userID]; ok {
+				authorsByPost[postID] = append(authorsByPost[postID], author)
+			}
+		}
+	}
+	return authorsByPost, nil
+}
+
+// loadAuthors returns the public bylines of users, keyed by user ID
+func loadAuthors(userIDs []uuid.UUID) (map[uuid.UUID]models.Author, error) {
+	authors := make(map[uuid.UUID]models.Author, len(userIDs))
+	if len(userIDs) == 0 {
+		return authors, nil
+	}
+
+	var users []models.User
+	if err := database.DB.Select("id, username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	var profiles []models.AuthorProfile
+	if err := database.DB.Preload("Avatar").Where("user_id IN ?", userIDs).Find(&profiles).Error; err != nil {
+		return nil, err
+	}
+	profilesByUser := make(map[uuid.UUID]*models.AuthorProfile, len(profiles))
+	for i := range profiles {
+		profilesByUser[profiles[i].UserID] = &profiles[i]
+	}
+
+	site := config.GetConfig().Site
+	for _, user := range users {
+		author := models.Author{
+			ID:          user.ID,
+			Username:    user.Username,
+			DisplayName: user.Username,
+			URL:         site.AuthorURL(user.Username),
+		}
+		if profile, ok := profilesByUser[user.ID]; ok {
+			author.DisplayName = firstNonEmpty(profile.DisplayName, user.Username)
+			author.Bio = profile.Bio
+			author.Links = profile.Links()
+			if profile.Avatar != nil && profile.Avatar.IsPublic {
+				author.AvatarURL = profile.Avatar.URL
+			}
+		}
+		authors[user.ID] = author
+	}
+	return authors, nil
+}
+
+// authorNames returns the display names of a byline
+func authorNames(authors []models.Author) []string {
+	names := make([]string, 0, len(authors))
+	for _, author := range authors {
+		names = append(names, author.DisplayName)
+	}
+	return names
+}
+
+var AuthorSvc AuthorService = NewAuthorService()
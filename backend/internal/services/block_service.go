@@ -0,0 +1,247 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-next/internal/models"
+	"go-next/pkg/blocks"
+	"go-next/pkg/database"
+	"go-next/pkg/sanitize"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidBlock       = blocks.ErrInvalidBlock
+	ErrBlockOrderMismatch = errors.New("block order must list every block of the post exactly once")
+)
+
+type BlockService interface {
+	ListBlocks(postID uuid.UUID) ([]models.Content, error)
+	InsertBlock(postID uuid.UUID, block *models.Content, position *int) error
+	UpdateBlock(postID, blockID uuid.UUID, contentType, content string) (*models.Content, error)
+	DeleteBlock(postID, blockID uuid.UUID) error
+	ReorderBlocks(postID uuid.UUID, order []uuid.UUID) ([]models.Content, error)
+}
+
+type blockService struct{}
+
+func NewBlockService() BlockService {
+	return &blockService{}
+}
+
+// ListBlocks returns the content blocks of a post in order
+func (s *blockService) ListBlocks(postID uuid.UUID) ([]models.Content, error) {
+	if err := database.DB.Select("id").First(&models.Post{}, "id = ?", postID).Error; err != nil {
+		return nil, err
+	}
+	return postBlockOrder.list(database.DB, postID)
+}
+
+// InsertBlock validates a block and inserts it at a zero-based position,
+// shifting the blocks after it. A nil or out of range position appends.
+func (s *blockService) InsertBlock(postID uuid.UUID, block *models.Content, position *int) error {
+	normalized, err := normalizeBlock(block.Type, block.Content)
+	if err != nil {
+		return err
+	}
+	block.Content = normalized
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := postBlockOrder.lock(tx, postID)
+		if err != nil {
+			return err
+		}
+		index, err := postBlockOrder.insertAt(tx, postID, existing, position)
+		if err != nil {
+			return err
+		}
+
+		block.ID = uuid.Nil
+		block.ModelID = postID
+		block.ModelType = models.MediableTypePost
+		block.SortOrder = index
+		if err := tx.Create(block).Error; err != nil {
+			return err
+		}
+		return postBlockOrder.touch(tx, postID)
+	}); err != nil {
+		return err
+	}
+
+	blocksChanged(postID)
+	return nil
+}
+
+// UpdateBlock replaces the type and content of a block
+func (s *blockService) UpdateBlock(postID, blockID uuid.UUID, contentType, content string) (*models.Content, error) {
+	normalized, err := normalizeBlock(contentType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var block models.Content
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := postBlockOrder.lock(tx, postID); err != nil {
+			return err
+		}
+		if err := tx.Where("id = ? AND model_id = ? AND model_type = ?", blockID, postID, models.MediableTypePost).First(&block).Error; err != nil {
+			return err
+		}
+		block.Type = contentType
+		block.Content = normalized
+		if err := tx.Model(&block).Updates(map[string]interface{}{"type": block.Type, "content": block.Content}).Error; err != nil {
+			return err
+		}
+		return postBlockOrder.touch(tx, postID)
+	}); err != nil {
+		return nil, err
+	}
+
+	blocksChanged(postID)
+	return &block, nil
+}
+
+// DeleteBlock removes a block and closes the gap it leaves in the order
+func (s *blockService) DeleteBlock(postID, blockID uuid.UUID) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := postBlockOrder.lock(tx, postID)
+		if err != nil {
+			return err
+		}
+
+		remaining, found := postBlockOrder.without(existing, func(block *models.Content) bool { return block.ID == blockID })
+		if !found {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Delete(&models.Content{}, "id = ?", blockID).Error; err != nil {
+			return err
+		}
+		if err := postBlockOrder.write(tx, remaining); err != nil {
+			return err
+		}
+		return postBlockOrder.touch(tx, postID)
+	}); err != nil {
+		return err
+	}
+
+	blocksChanged(postID)
+	return nil
+}
+
+// ReorderBlocks sets the order of a post's blocks; order must be a permutation of their IDs
+func (s *blockService) ReorderBlocks(postID uuid.UUID, order []uuid.UUID) ([]models.Content, error) {
+	var reordered []models.Content
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := postBlockOrder.lock(tx, postID)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		reordered, ok = postBlockOrder.permute(existing, order, func(block *models.Content) uuid.UUID { return block.ID })
+		if !ok {
+			return ErrBlockOrderMismatch
+		}
+
+		if err := postBlockOrder.write(tx, reordered); err != nil {
+			return err
+		}
+		return postBlockOrder.touch(tx, postID)
+	}); err != nil {
+		return nil, err
+	}
+
+	blocksChanged(postID)
+	return reordered, nil
+}
+
+func blocksChanged(postID uuid.UUID) {
+	syncPostIndex(postID)
+	syncPostMentions(postID)
+	SitemapSvc.MarkDirty()
+}
+
+// normalizeBlock validates content of a models.Content type and returns the
+// form to store: json blocks in canonical form with media resolved, html sanitized
+func normalizeBlock(contentType, content string) (string, error) {
+	switch contentType {
+	case "json":
+		block, err := blocks.Parse([]byte(content))
+		if err != nil {
+			return "", err
+		}
+		if err := resolveBlockMedia(block); err != nil {
+			return "", err
+		}
+		if embed, ok := block.Data.(*blocks.Embed); ok && embed.Provider == "" {
+			embed.Provider = OEmbedSvc.ProviderName(embed.URL)
+		}
+		normalized, err := json.Marshal(block)
+		if err != nil {
+			return "", err
+		}
+		return string(normalized), nil
+	case "html":
+		content = sanitize.Editor(content)
+	case "markdown", "text":
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("%w: content is required", ErrInvalidBlock)
+	}
+	return content, nil
+}
+
+// normalizePostBlocks validates the content blocks of a post before they are stored
+func normalizePostBlocks(post *models.Post) error {
+	for i := range post.Contents {
+		normalized, err := normalizeBlock(post.Contents[i].Type, post.Contents[i].Content)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+		post.Contents[i].Content = normalized
+	}
+	return nil
+}
+
+// resolveBlockMedia fills in the URL and size of images that reference uploaded media
+func resolveBlockMedia(block *blocks.Block) error {
+	var images []*blocks.Image
+	switch data := block.Data.(type) {
+	case *blocks.Image:
+		images = append(images, data)
+	case *blocks.Gallery:
+		for i := range data.Images {
+			images = append(images, &data.Images[i])
+		}
+	}
+
+	for _, image := range images {
+		if image.MediaID == nil || image.URL != "" {
+			continue
+		}
+		var media models.Media
+		if err := database.DB.Select("id, url, width, height").First(&media, "id = ?", *image.MediaID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: media %s not found", ErrInvalidBlock, image.MediaID)
+			}
+			return err
+		}
+		image.URL = media.URL
+		if image.Width == nil {
+			image.Width = media.Width
+		}
+		if image.Height == nil {
+			image.Height = media.Height
+		}
+	}
+	return nil
+}
+
+var BlockSvc BlockService = NewBlockService()
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"log"
 	"time"
 
 	"go-next/internal/models"
@@ -12,14 +13,19 @@ import (
 )
 
 type BlogService interface {
+	// WithLocale scopes the public endpoints to a locale chain, most preferred first
+	WithLocale(locales []string) BlogService
+
 	// Public blog endpoints
 	GetPublicPosts(page, perPage int, search, categorySlug string) ([]models.Post, int64, error)
 	GetPublicPost(slug string) (*models.Post, error)
 	GetFeaturedPosts(limit int) ([]models.Post, error)
 	GetRelatedPosts(postID uuid.UUID, limit int) ([]models.Post, error)
 	GetPopularPosts(limit int, days int) ([]models.Post, error)
+	GetTrendingPosts(window string, limit int) ([]models.Post, error)
 	GetPostsByCategory(categorySlug string, page, perPage int) ([]models.Post, int64, error)
 	GetPostsByTag(tagSlug string, page, perPage int) ([]models.Post, int64, error)
+	GetPostsByAuthor(userID uuid.UUID, page, perPage int) ([]models.Post, int64, error)
 	SearchPosts(query string, page, perPage int) ([]models.Post, int64, error)
 
 	// Blog statistics
@@ -34,7 +40,7 @@ type BlogService interface {
 	PublishPost(id string) error
 	UnpublishPost(id string) error
 	ArchivePost(id string) error
-	IncrementViewCount(postID uuid.UUID) error
+	IncrementViewCount(postID uuid.UUID, visitor Visitor) (bool, error)
 
 	// Category management
 	GetPublicCategories() ([]models.Category, error)
@@ -46,7 +52,8 @@ type BlogService interface {
 }
 
 type blogService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	locales []string
 }
 
 type BlogStats struct {
@@ -76,6 +83,31 @@ func NewBlogService() BlogService {
 	return &blogService{db: database.DB}
 }
 
+// WithLocale returns a copy of the service whose public endpoints show the
+// posts and categories of the first locale, falling back to the later locales
+// for content that has no translation. Without locales every locale is shown.
+func (s *blogService) WithLocale(locales []string) BlogService {
+	return &blogService{db: s.db, locales: locales}
+}
+
+// postLocales limits a posts query to the service's locale chain
+func (s *blogService) postLocales(db *gorm.DB) *gorm.DB {
+	if len(s.locales) == 0 {
+		return db
+	}
+	condition, args := localeFallback("posts", translatedPostVisible, s.locales)
+	return db.Where(condition, args...)
+}
+
+// categoryLocales limits a categories query to the service's locale chain
+func (s *blogService) categoryLocales(db *gorm.DB) *gorm.DB {
+	if len(s.locales) == 0 {
+		return db
+	}
+	condition, args := localeFallback("categories", translatedCategoryVisible, s.locales)
+	return db.Where(condition, args...)
+}
+
 // GetPublicPosts retrieves published posts for public viewing
 func (s *blogService) GetPublicPosts(page, perPage int, search, categorySlug string) ([]models.Post, int64, error) {
 	var posts []models.Post
@@ -84,8 +116,8 @@ func (s *blogService) GetPublicPosts(page, perPage int, search, categorySlug str
 	query := s.db.Model(&models.Post{}).
 		Where("status = ? AND public = ?", "published", true).
 		Where("published_at IS NOT NULL").
+		Scopes(s.postLocales).
 		Preload("Category").
-		Preload("User").
 		Preload("Media")
 
 	// Apply search filter
@@ -113,6 +145,10 @@ func (s *blogService) GetPublicPosts(page, perPage int, search, categorySlug str
 		return nil, 0, err
 	}
 
+	if err := attachAuthors(posts); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
@@ -120,16 +156,27 @@ func (s *blogService) GetPublicPosts(page, perPage int, search, categorySlug str
 func (s *blogService) GetPublicPost(slug string) (*models.Post, error) {
 	var post models.Post
 
+	// Slugs are unique per locale; a slug resolves within the locale chain
 	err := s.db.Where("slug = ? AND status = ? AND public = ? AND published_at IS NOT NULL",
 		slug, "published", true).
+		Scopes(postsInLocales(s.locales)).
 		Preload("Category").
-		Preload("User").
 		Preload("Media").
-		Preload("Comments", "status = ?", "approved").
+		Preload("Comments", publicComments).
 		Preload("Comments.User").
 		First(&post).Error
 
 	if err != nil {
+		return nil, slugRedirect(models.MediableTypePost, slug, err)
+	}
+
+	navigation, err := SeriesSvc.GetNavigation(post.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	post.Series = navigation
+
+	if err := attachPostAuthors(&post); err != nil {
 		return nil, err
 	}
 
@@ -142,17 +189,25 @@ func (s *blogService) GetFeaturedPosts(limit int) ([]models.Post, error) {
 
 	err := s.db.Where("status = ? AND public = ? AND published_at IS NOT NULL",
 		"published", true).
+		Scopes(s.postLocales).
 		Preload("Category").
-		Preload("User").
 		Order("view_count DESC, published_at DESC").
 		Limit(limit).
 		Find(&posts).Error
 
+	if err == nil {
+		err = attachAuthors(posts)
+	}
 	return posts, err
 }
 
-// GetRelatedPosts retrieves related posts based on category
+// GetRelatedPosts retrieves related posts from the precomputed ranking,
+// falling back to the latest posts of the same category
 func (s *blogService) GetRelatedPosts(postID uuid.UUID, limit int) ([]models.Post, error) {
+	if posts, err := s.getRankedRelatedPosts(postID, limit); err == nil && len(posts) > 0 {
+		return posts, nil
+	}
+
 	var post models.Post
 	if err := s.db.Select("category_id").First(&post, postID).Error; err != nil {
 		return nil, err
@@ -161,15 +216,63 @@ func (s *blogService) GetRelatedPosts(postID uuid.UUID, limit int) ([]models.Pos
 	var posts []models.Post
 	err := s.db.Where("id != ? AND category_id = ? AND status = ? AND public = ? AND published_at IS NOT NULL",
 		postID, post.CategoryID, "published", true).
+		Scopes(s.postLocales).
 		Preload("Category").
-		Preload("User").
 		Order("published_at DESC").
 		Limit(limit).
 		Find(&posts).Error
 
+	if err == nil {
+		err = attachAuthors(posts)
+	}
 	return posts, err
 }
 
+// getRankedRelatedPosts loads the cached related-post ranking, keeping its order
+func (s *blogService) getRankedRelatedPosts(postID uuid.UUID, limit int) ([]models.Post, error) {
+	scores, err := RelatedPostSvc.GetRelated(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(scores))
+	for _, score := range scores {
+		ids = append(ids, score.PostID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// Posts may have been unpublished since the ranking was computed
+	var posts []models.Post
+	if err := s.db.Where("id IN ? AND status = ? AND public = ? AND published_at IS NOT NULL",
+		ids, "published", true).
+		Scopes(s.postLocales).
+		Preload("Category").
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+	ordered := make([]models.Post, 0, limit)
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			ordered = append(ordered, post)
+			if len(ordered) == limit {
+				break
+			}
+		}
+	}
+
+	if err := attachAuthors(ordered); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
 // GetPopularPosts retrieves popular posts based on view count
 func (s *blogService) GetPopularPosts(limit int, days int) ([]models.Post, error) {
 	var posts []models.Post
@@ -182,15 +285,63 @@ func (s *blogService) GetPopularPosts(limit int, days int) ([]models.Post, error
 		query = query.Where("published_at >= ?", cutoffDate)
 	}
 
-	err := query.Preload("Category").
-		Preload("User").
+	err := query.Scopes(s.postLocales).
+		Preload("Category").
 		Order("view_count DESC, published_at DESC").
 		Limit(limit).
 		Find(&posts).Error
 
+	if err == nil {
+		err = attachAuthors(posts)
+	}
 	return posts, err
 }
 
+// GetTrendingPosts retrieves posts by their time-decayed activity score,
+// falling back to the most viewed posts published within the window
+func (s *blogService) GetTrendingPosts(window string, limit int) ([]models.Post, error) {
+	ids, err := TrendingSvc.GetTrendingPostIDs(window, limit*2)
+	if errors.Is(err, ErrInvalidTrendingWindow) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Warning: failed to read trending posts: %v", err)
+	}
+
+	if len(ids) > 0 {
+		var posts []models.Post
+		if err := s.db.Where("id IN ? AND status = ? AND public = ? AND published_at IS NOT NULL",
+			ids, "published", true).
+			Scopes(s.postLocales).
+			Preload("Category").
+			Find(&posts).Error; err != nil {
+			return nil, err
+		}
+
+		byID := make(map[uuid.UUID]models.Post, len(posts))
+		for _, post := range posts {
+			byID[post.ID] = post
+		}
+		ordered := make([]models.Post, 0, limit)
+		for _, id := range ids {
+			if post, ok := byID[id]; ok {
+				ordered = append(ordered, post)
+				if len(ordered) == limit {
+					break
+				}
+			}
+		}
+		if len(ordered) > 0 {
+			if err := attachAuthors(ordered); err != nil {
+				return nil, err
+			}
+			return ordered, nil
+		}
+	}
+
+	return s.GetPopularPosts(limit, TrendingWindows[window].Days)
+}
+
 // GetPostsByCategory retrieves posts by category slug
 func (s *blogService) GetPostsByCategory(categorySlug string, page, perPage int) ([]models.Post, int64, error) {
 	var posts []models.Post
@@ -200,8 +351,8 @@ func (s *blogService) GetPostsByCategory(categorySlug string, page, perPage int)
 		Joins("JOIN categories ON posts.category_id = categories.id").
 		Where("categories.slug = ? AND posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL",
 			categorySlug, "published", true).
-		Preload("Category").
-		Preload("User")
+		Scopes(s.postLocales).
+		Preload("Category")
 
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
@@ -216,6 +367,10 @@ func (s *blogService) GetPostsByCategory(categorySlug string, page, perPage int)
 		return nil, 0, err
 	}
 
+	if err := attachAuthors(posts); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
@@ -229,8 +384,38 @@ func (s *blogService) GetPostsByTag(tagSlug string, page, perPage int) ([]models
 		Joins("JOIN tags ON post_tags.tag_id = tags.id").
 		Where("tags.slug = ? AND posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL",
 			tagSlug, "published", true).
-		Preload("Category").
-		Preload("User")
+		Scopes(s.postLocales).
+		Preload("Category")
+
+	// Get total count
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Get paginated posts
+	offset := (page - 1) * perPage
+	if err := query.Offset(offset).Limit(perPage).
+		Order("posts.published_at DESC").
+		Find(&posts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := attachAuthors(posts); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+// GetPostsByAuthor retrieves the posts credited to an author
+func (s *blogService) GetPostsByAuthor(userID uuid.UUID, page, perPage int) ([]models.Post, int64, error) {
+	var posts []models.Post
+	var total int64
+
+	query := s.db.Model(&models.Post{}).
+		Where("posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL", "published", true).
+		Scopes(authoredBy(userID), s.postLocales).
+		Preload("Category")
 
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
@@ -245,6 +430,10 @@ func (s *blogService) GetPostsByTag(tagSlug string, page, perPage int) ([]models
 		return nil, 0, err
 	}
 
+	if err := attachAuthors(posts); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
@@ -257,8 +446,8 @@ func (s *blogService) SearchPosts(query string, page, perPage int) ([]models.Pos
 		Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true).
 		Where("title ILIKE ? OR content ILIKE ? OR excerpt ILIKE ?",
 			"%"+query+"%", "%"+query+"%", "%"+query+"%").
-		Preload("Category").
-		Preload("User")
+		Scopes(s.postLocales).
+		Preload("Category")
 
 	// Get total count
 	if err := searchQuery.Count(&total).Error; err != nil {
@@ -273,6 +462,10 @@ func (s *blogService) SearchPosts(query string, page, perPage int) ([]models.Pos
 		return nil, 0, err
 	}
 
+	if err := attachAuthors(posts); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
@@ -364,21 +557,17 @@ func (s *blogService) GetMonthlyArchive() ([]MonthlyArchive, error) {
 
 // CreatePost creates a new post
 func (s *blogService) CreatePost(post *models.Post) error {
-	return s.db.Create(post).Error
+	return createPost(s.db, post)
 }
 
 // UpdatePost updates an existing post
 func (s *blogService) UpdatePost(post *models.Post) error {
-	return s.db.Save(post).Error
+	return updatePost(s.db, post)
 }
 
 // DeletePost deletes a post
 func (s *blogService) DeletePost(id string) error {
-	postID, err := uuid.Parse(id)
-	if err != nil {
-		return errors.New("invalid post ID")
-	}
-	return s.db.Delete(&models.Post{}, postID).Error
+	return deletePost(s.db, id)
 }
 
 // PublishPost publishes a post
@@ -394,7 +583,13 @@ func (s *blogService) PublishPost(id string) error {
 	}
 
 	post.Publish()
-	return s.db.Save(&post).Error
+	if err := s.saveStatus(&post); err != nil {
+		return err
+	}
+	syncPostIndex(post.ID)
+	syncPostMentions(post.ID)
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
 // UnpublishPost unpublishes a post
@@ -410,7 +605,12 @@ func (s *blogService) UnpublishPost(id string) error {
 	}
 
 	post.Unpublish()
-	return s.db.Save(&post).Error
+	if err := s.saveStatus(&post); err != nil {
+		return err
+	}
+	syncPostIndex(post.ID)
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
 // ArchivePost archives a post
@@ -426,15 +626,24 @@ func (s *blogService) ArchivePost(id string) error {
 	}
 
 	post.Archive()
-	return s.db.Save(&post).Error
+	if err := s.saveStatus(&post); err != nil {
+		return err
+	}
+	syncPostIndex(post.ID)
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
-// IncrementViewCount increments the view count for a post
-func (s *blogService) IncrementViewCount(postID uuid.UUID) error {
-	return s.db.Model(&models.Post{}).
-		Where("id = ?", postID).
-		UpdateColumn("view_count", gorm.Expr("view_count + ?", 1)).
-		Error
+// saveStatus writes only the publication columns of a post, leaving the
+// counters maintained by view flushing and reaction reconciliation as stored
+func (s *blogService) saveStatus(post *models.Post) error {
+	return s.db.Model(post).Select("status", "published_at", "updated_at").Updates(post).Error
+}
+
+// IncrementViewCount records a view of a post; the view count only grows
+// for the first view of a visitor per day and is flushed to the database in batches
+func (s *blogService) IncrementViewCount(postID uuid.UUID, visitor Visitor) (bool, error) {
+	return ViewSvc.RecordView(postID, visitor)
 }
 
 // GetPublicCategories retrieves active categories
@@ -442,6 +651,7 @@ func (s *blogService) GetPublicCategories() ([]models.Category, error) {
 	var categories []models.Category
 
 	err := s.db.Where("is_active = ?", true).
+		Scopes(s.categoryLocales).
 		Preload("Children", "is_active = ?", true).
 		Order("sort_order ASC, name ASC").
 		Find(&categories).Error
@@ -454,11 +664,12 @@ func (s *blogService) GetCategoryBySlug(slug string) (*models.Category, error) {
 	var category models.Category
 
 	err := s.db.Where("slug = ? AND is_active = ?", slug, true).
+		Scopes(categoriesInLocales(s.locales)).
 		Preload("Children", "is_active = ?", true).
 		First(&category).Error
 
 	if err != nil {
-		return nil, err
+		return nil, slugRedirect(models.MediableTypeCategory, slug, err)
 	}
 
 	return &category, nil
@@ -133,16 +133,24 @@ const (
 	CacheKeyVerificationTokens = "verification_tokens:user:%s:type:%s"
 	CacheKeyRefreshToken       = "refresh_token:%s"
 	CacheKeyRefreshTokens      = "refresh_tokens:user:%s"
+	CacheKeyUserActive         = "user_active:%s"
+	CacheKeySearchSuggest      = "search:suggest:%d:%s"
+	CacheKeySearchVocabulary   = "search:vocabulary"
+	CacheKeyRelatedPosts       = "related_posts:%s"
+	CacheKeyRenderedContent    = "content:rendered:%s"
+	CacheKeyOEmbed             = "oembed:v2:%s"
 )
 
 // Cache durations
 const (
-	CacheDurationShort  = 5 * time.Minute
-	CacheDurationMedium = 30 * time.Minute
-	CacheDurationLong   = 2 * time.Hour
-	CacheDurationDay    = 24 * time.Hour
-	CacheDurationToken  = 1 * time.Hour
-	CacheDurationJWTKey = 24 * time.Hour
+	CacheDurationShort      = 5 * time.Minute
+	CacheDurationMedium     = 30 * time.Minute
+	CacheDurationLong       = 2 * time.Hour
+	CacheDurationDay        = 24 * time.Hour
+	CacheDurationWeek       = 7 * 24 * time.Hour
+	CacheDurationToken      = 1 * time.Hour
+	CacheDurationJWTKey     = 24 * time.Hour
+	CacheDurationUserActive = 1 * time.Minute
 )
 
 var CacheSvc CacheService = NewCacheService()
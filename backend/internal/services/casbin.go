@@ -48,10 +48,12 @@ func InitCasbin() error {
 	Enforcer.AddPolicy("admin", "/api/posts", "PUT")
 	Enforcer.AddPolicy("admin", "/api/posts", "DELETE")
 	Enforcer.AddPolicy("admin", "/api/comments", "DELETE")
+	Enforcer.AddPolicy("admin", "/api/categories", "PUT")
 	Enforcer.AddPolicy("editor", "/api/posts", "POST")
 	Enforcer.AddPolicy("editor", "/api/posts", "PUT")
 	Enforcer.AddPolicy("editor", "/api/posts", "DELETE")
 	Enforcer.AddPolicy("editor", "/api/comments", "DELETE")
+	Enforcer.AddPolicy("editor", "/api/categories", "PUT")
 	Enforcer.AddPolicy("moderator", "/api/comments", "DELETE")
 	Enforcer.AddPolicy("user", "/api/posts", "GET")
 	Enforcer.AddPolicy("user", "/api/categories", "GET")
@@ -5,6 +5,7 @@ import (
 	"go-next/internal/models"
 	"go-next/pkg/database"
 	"go-next/pkg/redis"
+	"log"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -58,11 +59,39 @@ func (s *categoryService) GetCategoryByID(id string) (*models.Category, error) {
 }
 
 func (s *categoryService) CreateCategory(category *models.Category) error {
-	return database.DB.Create(category).Error
+	locale, err := normalizeLocale(category.Locale)
+	if err != nil {
+		return err
+	}
+	category.Locale = locale
+	if err := s.CreateNested(category, category.ParentID); err != nil {
+		return err
+	}
+	if err := SearchSvc.IndexCategory(category); err != nil {
+		log.Printf("Warning: failed to index category %s: %v", category.ID, err)
+	}
+	return nil
 }
 
 func (s *categoryService) UpdateCategory(category *models.Category) error {
-	return database.DB.Save(category).Error
+	locale, err := normalizeLocale(category.Locale)
+	if err != nil {
+		return err
+	}
+	category.Locale = locale
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := RedirectSvc.RecordSlugChange(tx, models.MediableTypeCategory, category.ID, category.Slug); err != nil {
+			return err
+		}
+		return tx.Omit(nestedSetColumns...).Save(category).Error
+	}); err != nil {
+		return err
+	}
+	if err := SearchSvc.IndexCategory(category); err != nil {
+		log.Printf("Warning: failed to index category %s: %v", category.ID, err)
+	}
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
 func (s *categoryService) DeleteCategory(id string) error {
@@ -70,167 +99,63 @@ func (s *categoryService) DeleteCategory(id string) error {
 	if err != nil {
 		return err
 	}
-	return database.DB.Delete(&models.Category{}, categoryID).Error
+	if err := database.DB.Delete(&models.Category{}, categoryID).Error; err != nil {
+		return err
+	}
+	if err := SearchSvc.DeleteCategory(categoryID); err != nil {
+		log.Printf("Warning: failed to remove category %s from search index: %v", categoryID, err)
+	}
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
 func (s *categoryService) CreateNested(category *models.Category, parentID *uuid.UUID) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
-		if parentID != nil {
-			var parent models.Category
-			if err := tx.First(&parent, parentID).Error; err != nil {
-				return err
-			}
-			if parent.RecordRight == 0 {
-				return gorm.ErrRecordNotFound
-			}
-
-			// Update parent's right value
-			tx.Model(&models.Category{}).
-				Where("record_right >= ?", parent.RecordRight).
-				Update("record_right", gorm.Expr("record_right + 2"))
-			tx.Model(&models.Category{}).
-				Where("record_left > ?", parent.RecordRight).
-				Update("record_left", gorm.Expr("record_left + 2"))
-
-			category.RecordLeft = parent.RecordRight
-			category.RecordRight = parent.RecordRight + 1
-			category.RecordDept = parent.RecordDept + 1
-			category.ParentID = parentID
-		} else {
-			// Create as root
-			var maxRight int
-			tx.Model(&models.Category{}).Select("COALESCE(MAX(record_right), 0)").Scan(&maxRight)
-			category.RecordLeft = maxRight + 1
-			category.RecordRight = maxRight + 2
-			category.RecordDept = 0
-			category.ParentID = nil
+	locale, err := normalizeLocale(category.Locale)
+	if err != nil {
+		return err
+	}
+	category.Locale = locale
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := categoryNestedSet.lock(tx); err != nil {
+			return err
 		}
-
+		position, err := categoryNestedSet.insert(tx, parentID)
+		if err != nil {
+			return err
+		}
+		category.ParentID = position.ParentID
+		category.RecordLeft = position.RecordLeft
+		category.RecordRight = position.RecordRight
+		category.RecordDept = position.RecordDept
+		category.RecordOrdering = position.RecordOrdering
 		return tx.Create(category).Error
-	})
+	}); err != nil {
+		return err
+	}
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
 func (s *categoryService) MoveNested(id uuid.UUID, newParentID *uuid.UUID) error {
 	return database.DB.Transaction(func(tx *gorm.DB) error {
-		var node models.Category
-		if err := tx.First(&node, id).Error; err != nil {
+		if err := categoryNestedSet.lock(tx); err != nil {
 			return err
 		}
-		if node.RecordLeft == 0 || node.RecordRight == 0 {
-			return gorm.ErrRecordNotFound
-		}
-		left := node.RecordLeft
-		right := node.RecordRight
-		width := right - left + 1
-
-		var newParentRight int
-		var newDepth int = 0
-		if newParentID != nil {
-			var newParent models.Category
-			if err := tx.First(&newParent, newParentID).Error; err != nil {
-				return err
-			}
-			if newParent.RecordRight == 0 {
-				return gorm.ErrRecordNotFound
-			}
-			newParentRight = newParent.RecordRight
-			newDepth = newParent.RecordDept + 1
-		} else {
-			tx.Model(&models.Category{}).Select("COALESCE(MAX(record_right), 0)").Scan(&newParentRight)
-			newParentRight++
-		}
-
-		if newParentRight >= left && newParentRight <= right {
-			return gorm.ErrInvalidData
-		}
-
-		var offset int
-		if newParentRight > right {
-			offset = newParentRight - right - 1
-		} else {
-			offset = newParentRight - left
-		}
-
-		temp := -1
-		tx.Model(&models.Category{}).
-			Where("record_left >= ? AND record_right <= ?", left, right).
-			Update("record_left", gorm.Expr("record_left * ?", temp))
-		tx.Model(&models.Category{}).
-			Where("record_left >= ? AND record_right <= ?", left, right).
-			Update("record_right", gorm.Expr("record_right * ?", temp))
-
-		tx.Model(&models.Category{}).
-			Where("record_left > ?", right).
-			Update("record_left", gorm.Expr("record_left - ?", width))
-		tx.Model(&models.Category{}).
-			Where("record_right > ?", right).
-			Update("record_right", gorm.Expr("record_right - ?", width))
-
-		if newParentRight > right {
-			tx.Model(&models.Category{}).
-				Where("record_left >= ?", newParentRight-width).
-				Update("record_left", gorm.Expr("record_left + ?", width))
-			tx.Model(&models.Category{}).
-				Where("record_right >= ?", newParentRight-width).
-				Update("record_right", gorm.Expr("record_right + ?", width))
-			depthDiff := newDepth - node.RecordDept
-			tx.Model(&models.Category{}).
-				Where("record_left <= ? AND record_right >= ?", temp*left, temp*right).
-				Updates(map[string]interface{}{
-					"record_left":  gorm.Expr("record_left * -1 + ?", offset),
-					"record_right": gorm.Expr("record_right * -1 + ?", offset),
-					"record_dept":  gorm.Expr("record_dept + ?", depthDiff),
-				})
-		} else {
-			tx.Model(&models.Category{}).
-				Where("record_left >= ?", newParentRight).
-				Update("record_left", gorm.Expr("record_left + ?", width))
-			tx.Model(&models.Category{}).
-				Where("record_right >= ?", newParentRight).
-				Update("record_right", gorm.Expr("record_right + ?", width))
-			depthDiff := newDepth - node.RecordDept
-			tx.Model(&models.Category{}).
-				Where("record_left <= ? AND record_right >= ?", temp*left, temp*right).
-				Updates(map[string]interface{}{
-					"record_left":  gorm.Expr("record_left * -1 + ?", offset),
-					"record_right": gorm.Expr("record_right * -1 + ?", offset),
-					"record_dept":  gorm.Expr("record_dept + ?", depthDiff),
-				})
-		}
-
-		tx.Model(&models.Category{}).Where("id = ?", id).Update("parent_id", newParentID)
-
-		return nil
+		return categoryNestedSet.move(tx, id, newParentID)
 	})
 }
 
 func (s *categoryService) DeleteNested(id uuid.UUID) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
-		var category models.Category
-		if err := tx.First(&category, id).Error; err != nil {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := categoryNestedSet.lock(tx); err != nil {
 			return err
 		}
-		if category.RecordLeft == 0 || category.RecordRight == 0 {
-			return gorm.ErrRecordNotFound
-		}
-
-		left := category.RecordLeft
-		right := category.RecordRight
-		width := right - left + 1
-
-		// Delete the category and all its descendants
-		tx.Where("record_left >= ? AND record_right <= ?", left, right).Delete(&models.Category{})
-
-		// Update the remaining nodes
-		tx.Model(&models.Category{}).
-			Where("record_left > ?", right).
-			Update("record_left", gorm.Expr("record_left - ?", width))
-		tx.Model(&models.Category{}).
-			Where("record_right > ?", right).
-			Update("record_right", gorm.Expr("record_right - ?", width))
-
-		return nil
-	})
+		return categoryNestedSet.remove(tx, id)
+	}); err != nil {
+		return err
+	}
+	SitemapSvc.MarkDirty()
+	return nil
 }
 
 func (s *categoryService) GetSiblingCategory(id uuid.UUID) ([]models.Category, error) {
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Moderation actions
+const (
+	CommentActionApprove = "approve"
+	CommentActionReject  = "reject"
+	CommentActionSpam    = "spam"
+)
+
+// CommentStatusAll lists the comments of every status in the moderation queue
+const CommentStatusAll = "all"
+
+var (
+	ErrInvalidModerationAction = errors.New("invalid moderation action")
+	ErrInvalidCommentFilter    = errors.New("invalid comment filter")
+	ErrInvalidCommentRule      = errors.New("invalid comment rule")
+)
+
+// CommentFilter selects the comments of the moderation queue
+type CommentFilter struct {
+	// Status defaults to pending; CommentStatusAll lists every status
+	Status string
+	PostID *uuid.UUID
+	UserID *uuid.UUID
+}
+
+type CommentModerationService interface {
+	// Moderation queue
+	GetQueue(filter CommentFilter, page, perPage int) ([]models.Comment, int64, error)
+	Moderate(ids []uuid.UUID, action string, moderatorID *uuid.UUID) ([]models.Comment, error)
+
+	// Auto-approval
+	GetRules() ([]models.CommentRule, error)
+	SetRule(categoryID uuid.UUID, mode string) (*models.CommentRule, error)
+	DeleteRule(categoryID uuid.UUID) error
+	IsTrusted(userID uuid.UUID) (bool, error)
+}
+
+type commentModerationService struct{}
+
+func NewCommentModerationService() CommentModerationService {
+	return &commentModerationService{}
+}
+
+// GetQueue lists comments for moderation, oldest first
+func (s *commentModerationService) GetQueue(filter CommentFilter, page, perPage int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	var total int64
+
+	query := database.DB.Model(&models.Comment{})
+	switch filter.Status {
+	case "":
+		query = query.Where("status = ?", models.CommentStatusPending)
+	case CommentStatusAll:
+	case models.CommentStatusPending, models.CommentStatusApproved, models.CommentStatusRejected, models.CommentStatusSpam:
+		query = query.Where("status = ?", filter.Status)
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown status %q", ErrInvalidCommentFilter, filter.Status)
+	}
+	if filter.PostID != nil {
+		query = query.Where("post_id = ?", *filter.PostID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Preload("User").
+		Preload("Post", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, title, slug, locale")
+		}).
+		Order("created_at ASC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&comments).Error
+
+	return comments, total, err
+}
+
+// Moderate applies one action to a batch of comments. Every comment must exist.
+func (s *commentModerationService) Moderate(ids []uuid.UUID, action string, moderatorID *uuid.UUID) ([]models.Comment, error) {
+	var apply func(*models.Comment)
+	switch action {
+	case CommentActionApprove:
+		apply = (*models.Comment).Approve
+	case CommentActionReject:
+		apply = (*models.Comment).Reject
+	case CommentActionSpam:
+		apply = (*models.Comment).MarkSpam
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidModerationAction, action)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: no comments given", ErrInvalidModerationAction)
+	}
+
+	var comments []models.Comment
+	previous := make(map[uuid.UUID]string, len(ids))
+	previousApprovedAt := make(map[uuid.UUID]*time.Time, len(ids))
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).
+			Find(&comments).Error; err != nil {
+			return err
+		}
+		if len(comments) != len(uniqueUUIDs(ids)) {
+			return gorm.ErrRecordNotFound
+		}
+
+		now := time.Now()
+		for i := range comments {
+			previous[comments[i].ID] = comments[i].Status
+			previousApprovedAt[comments[i].ID] = comments[i].ApprovedAt
+			apply(&comments[i])
+			comments[i].ModeratedBy = moderatorID
+			comments[i].ModeratedAt = &now
+			if err := tx.Model(&comments[i]).
+				Select("status", "approved_at", "moderated_by", "moderated_at").
+				Updates(&comments[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	postIDs := make([]uuid.UUID, 0, len(comments))
+	for i := range comments {
+		postIDs = append(postIDs, comments[i].PostID)
+		recordApprovedComment(&comments[i], previous[comments[i].ID])
+		recordCommentApproval(previousApprovedAt[comments[i].ID], comments[i].ApprovedAt)
+		syncCommentMentions(&comments[i])
+		notifyCommentSubscribers(&comments[i], previous[comments[i].ID])
+		if err := SpamSvc.Learn(&comments[i]); err != nil {
+			log.Printf("Warning: failed to train spam filter with comment %s: %v", comments[i].ID, err)
+		}
+	}
+	for _, postID := range uniqueUUIDs(postIDs) {
+		invalidateCommentThread(postID)
+	}
+	return comments, nil
+}
+
+// GetRules lists the auto-approval rules of categories
+func (s *commentModerationService) GetRules() ([]models.CommentRule, error) {
+	var rules []models.CommentRule
+	err := database.DB.Preload("Category", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, name, slug, locale")
+	}).Order("created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+// SetRule creates or replaces the moderation mode of a category
+func (s *commentModerationService) SetRule(categoryID uuid.UUID, mode string) (*models.CommentRule, error) {
+	switch mode {
+	case models.CommentModeAuto, models.CommentModeTrusted, models.CommentModeManual:
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidCommentRule, mode)
+	}
+
+	rule := models.CommentRule{CategoryID: categoryID, Mode: mode}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Category{}, "id = ?", categoryID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.CommentRule{}).Where("category_id = ?", categoryID).Update("mode", mode)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return tx.Where("category_id = ?", categoryID).First(&rule).Error
+		}
+		return tx.Omit("Category").Create(&rule).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// DeleteRule returns a category to the default mode
+func (s *commentModerationService) DeleteRule(categoryID uuid.UUID) error {
+	result := database.DB.Where("category_id = ?", categoryID).Delete(&models.CommentRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IsTrusted reports whether a user has enough approved comments, and none
+// marked as spam, to skip the moderation queue
+func (s *commentModerationService) IsTrusted(userID uuid.UUID) (bool, error) {
+	threshold := config.GetConfig().Comments.TrustedAfter
+	if threshold <= 0 {
+		return false, nil
+	}
+
+	var counts struct {
+		Approved int
+		Spam     int
+	}
+	if err := database.DB.Model(&models.Comment{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS approved, COUNT(*) FILTER (WHERE status = ?) AS spam",
+			models.CommentStatusApproved, models.CommentStatusSpam).
+		Where("user_id = ?", userID).
+		Scan(&counts).Error; err != nil {
+		return false, err
+	}
+	return counts.Spam == 0 && counts.Approved >= threshold, nil
+}
+
+// moderateNewComment sets the status of a new comment from the rule of its
+// post's category and the trust of its author, then screens it for spam
+func moderateNewComment(comment *models.Comment) error {
+	mode := models.CommentModeTrusted
+	var rule models.CommentRule
+	err := database.DB.Joins("JOIN posts ON posts.category_id = comment_rules.category_id").
+		Where("posts.id = ?", comment.PostID).
+		First(&rule).Error
+	switch {
+	case err == nil:
+		mode = rule.Mode
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return err
+	}
+
+	approve := mode == models.CommentModeAuto
+	if mode == models.CommentModeTrusted {
+		if approve, err = CommentModerationSvc.IsTrusted(comment.UserID); err != nil {
+			return err
+		}
+	}
+
+	if approve {
+		comment.Approve()
+	} else {
+		comment.Status = models.CommentStatusPending
+		comment.ApprovedAt = nil
+	}
+	return SpamSvc.Screen(comment)
+}
+
+// uniqueUUIDs drops repeated IDs, keeping the first occurrence
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+var CommentModerationSvc CommentModerationService = NewCommentModerationService()
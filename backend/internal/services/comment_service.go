@@ -1,6 +1,8 @@
 package services
 
 import (
+	"fmt"
+
 	"go-next/internal/models"
 	"go-next/pkg/database"
 	"go-next/pkg/redis"
@@ -11,7 +13,9 @@ import (
 
 type CommentService interface {
 	GetCommentsByPost(postID string) ([]models.Comment, error)
+	GetThread(postID uuid.UUID, opts CommentThreadOptions) (*models.CommentThread, error)
 	GetCommentByID(id string) (*models.Comment, error)
+	GetPublicComment(id string) (*models.Comment, error)
 	CreateComment(comment *models.Comment) error
 	UpdateComment(comment *models.Comment) error
 	DeleteComment(id string) error
@@ -59,12 +63,60 @@ func (s *commentService) GetCommentByID(id string) (*models.Comment, error) {
 	return &comment, nil
 }
 
+// GetPublicComment retrieves a comment readers can see
+func (s *commentService) GetPublicComment(id string) (*models.Comment, error) {
+	commentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment models.Comment
+	if err := database.DB.Scopes(publicComments).First(&comment, "id = ?", commentID).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
 func (s *commentService) CreateComment(comment *models.Comment) error {
-	return database.DB.Create(comment).Error
+	sanitizeComment(comment)
+	if err := moderateNewComment(comment); err != nil {
+		return err
+	}
+	if err := createCommentInTree(comment, nil); err != nil {
+		return err
+	}
+	invalidateCommentThread(comment.PostID)
+	recordApprovedComment(comment, "")
+	syncCommentMentions(comment)
+	subscribeCommenter(comment)
+	notifyCommentSubscribers(comment, "")
+	return nil
 }
 
+// UpdateComment saves an edit of a comment. The edit is moderated like a new
+// comment, so approved text cannot be swapped for unreviewed text; rejected
+// and spam comments keep their status.
 func (s *commentService) UpdateComment(comment *models.Comment) error {
-	return database.DB.Save(comment).Error
+	sanitizeComment(comment)
+	var previous models.Comment
+	if err := database.DB.Select("status, post_id, approved_at").First(&previous, comment.ID).Error; err != nil {
+		return err
+	}
+	if !previous.IsRejected() && !previous.IsSpam() {
+		if err := moderateNewComment(comment); err != nil {
+			return err
+		}
+	}
+	if err := database.DB.Omit(append([]string{reactionCountColumn}, nestedSetColumns...)...).Save(comment).Error; err != nil {
+		return err
+	}
+	invalidateCommentThread(previous.PostID)
+	invalidateCommentThread(comment.PostID)
+	recordApprovedComment(comment, previous.Status)
+	recordCommentApproval(previous.ApprovedAt, comment.ApprovedAt)
+	syncCommentMentions(comment)
+	notifyCommentSubscribers(comment, previous.Status)
+	return nil
 }
 
 func (s *commentService) DeleteComment(id string) error {
@@ -72,94 +124,138 @@ func (s *commentService) DeleteComment(id string) error {
 	if err != nil {
 		return err
 	}
-	return database.DB.Delete(&models.Comment{}, commentID).Error
+	var comment models.Comment
+	if err := database.DB.Select("id, post_id, approved_at").First(&comment, commentID).Error; err != nil {
+		return err
+	}
+	if err := database.DB.Delete(&models.Comment{}, commentID).Error; err != nil {
+		return err
+	}
+	invalidateCommentThread(comment.PostID)
+	recordCommentApproval(comment.ApprovedAt, nil)
+	return nil
 }
 
 func (s *commentService) CreateNested(comment *models.Comment, parentID *uuid.UUID) error {
+	sanitizeComment(comment)
+	if err := moderateNewComment(comment); err != nil {
+		return err
+	}
+	if err := createCommentInTree(comment, parentID); err != nil {
+		return err
+	}
+	invalidateCommentThread(comment.PostID)
+	recordApprovedComment(comment, "")
+	syncCommentMentions(comment)
+	subscribeCommenter(comment)
+	notifyCommentSubscribers(comment, "")
+	return nil
+}
+
+// createCommentInTree stores a comment as the last reply to parentID, or as
+// a new thread, under the lock of its post's comment tree
+func createCommentInTree(comment *models.Comment, parentID *uuid.UUID) error {
+	tree := commentNestedSet.within(comment.PostID)
 	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tree.lock(tx); err != nil {
+			return err
+		}
 		if parentID != nil {
 			var parent models.Comment
-			if err := tx.First(&parent, parentID).Error; err != nil {
+			if err := tx.Select("id, post_id").First(&parent, "id = ?", *parentID).Error; err != nil {
 				return err
 			}
-			if parent.RecordRight == 0 {
-				return gorm.ErrRecordNotFound
+			if parent.PostID != comment.PostID {
+				return fmt.Errorf("%w: the parent comment is on another post", ErrInvalidTreeParent)
 			}
-
-			// Update parent's right value
-			tx.Model(&models.Comment{}).
-				Where("record_right >= ?", parent.RecordRight).
-				Update("record_right", gorm.Expr("record_right + 2"))
-			tx.Model(&models.Comment{}).
-				Where("record_left > ?", parent.RecordRight).
-				Update("record_left", gorm.Expr("record_left + 2"))
-
-			comment.RecordLeft = parent.RecordRight
-			comment.RecordRight = parent.RecordRight + 1
-			comment.RecordDept = parent.RecordDept + 1
-			comment.ParentID = parentID
-		} else {
-			// Create as root
-			var maxRight int
-			tx.Model(&models.Comment{}).Select("COALESCE(MAX(record_right), 0)").Scan(&maxRight)
-			comment.RecordLeft = maxRight + 1
-			comment.RecordRight = maxRight + 2
-			comment.RecordDept = 0
-			comment.ParentID = nil
 		}
-
+		position, err := tree.insert(tx, parentID)
+		if err != nil {
+			return err
+		}
+		comment.ParentID = position.ParentID
+		comment.RecordLeft = position.RecordLeft
+		comment.RecordRight = position.RecordRight
+		comment.RecordDept = position.RecordDept
+		comment.RecordOrdering = position.RecordOrdering
 		return tx.Create(comment).Error
 	})
 }
 
+// recordApprovedComment counts a comment towards its post's trending score
+// once, when it becomes approved
+func recordApprovedComment(comment *models.Comment, previousStatus string) {
+	if comment.Status == models.CommentStatusApproved && previousStatus != models.CommentStatusApproved {
+		recordTrending(comment.PostID, TrendingEventComment)
+	}
+}
+
 func (s *commentService) MoveNested(id uuid.UUID, newParentID *uuid.UUID) error {
-	// For brevity, not implemented here. (Can be added if needed.)
+	// A comment never changes post, so its tree is known before taking the lock
+	var comment models.Comment
+	if err := database.DB.Select("id, post_id").First(&comment, "id = ?", id).Error; err != nil {
+		return err
+	}
+	tree := commentNestedSet.within(comment.PostID)
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tree.lock(tx); err != nil {
+			return err
+		}
+		if newParentID != nil {
+			var parent models.Comment
+			if err := tx.Select("id, post_id").First(&parent, "id = ?", *newParentID).Error; err != nil {
+				return err
+			}
+			if parent.PostID != comment.PostID {
+				return fmt.Errorf("%w: the parent comment is on another post", ErrInvalidTreeParent)
+			}
+		}
+		return tree.move(tx, id, newParentID)
+	}); err != nil {
+		return err
+	}
+	invalidateCommentThread(comment.PostID)
 	return nil
 }
 
 func (s *commentService) DeleteNested(id uuid.UUID) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
-		var comment models.Comment
-		if err := tx.First(&comment, id).Error; err != nil {
+	var comment models.Comment
+	if err := database.DB.Select("id, post_id").First(&comment, "id = ?", id).Error; err != nil {
+		return err
+	}
+	tree := commentNestedSet.within(comment.PostID)
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tree.lock(tx); err != nil {
 			return err
 		}
-		if comment.RecordLeft == 0 || comment.RecordRight == 0 {
-			return gorm.ErrRecordNotFound
-		}
-
-		left := comment.RecordLeft
-		right := comment.RecordRight
-		width := right - left + 1
-
-		// Delete the comment and all its descendants
-		tx.Where("record_left >= ? AND record_right <= ?", left, right).Delete(&models.Comment{})
-
-		// Update the remaining nodes
-		tx.Model(&models.Comment{}).
-			Where("record_left > ?", right).
-			Update("record_left", gorm.Expr("record_left - ?", width))
-		tx.Model(&models.Comment{}).
-			Where("record_right > ?", right).
-			Update("record_right", gorm.Expr("record_right - ?", width))
-
-		return nil
-	})
+		return tree.remove(tx, id)
+	}); err != nil {
+		return err
+	}
+	invalidateCommentThread(comment.PostID)
+	return nil
 }
 
 func (s *commentService) GetSiblingComments(id uuid.UUID) ([]models.Comment, error) {
 	var comment models.Comment
-	if err := database.DB.First(&comment, id).Error; err != nil {
+	if err := database.DB.Scopes(publicComments).First(&comment, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 
+	query := database.DB.Scopes(publicComments).Where("post_id = ? AND id != ?", comment.PostID, id)
+	if comment.ParentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *comment.ParentID)
+	}
 	var siblings []models.Comment
-	err := database.DB.Where("parent_id = ? AND id != ?", comment.ParentID, id).Find(&siblings).Error
+	err := query.Find(&siblings).Error
 	return siblings, err
 }
 
 func (s *commentService) GetParentComment(id uuid.UUID) (*models.Comment, error) {
 	var comment models.Comment
-	if err := database.DB.First(&comment, id).Error; err != nil {
+	if err := database.DB.Scopes(publicComments).First(&comment, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 
@@ -168,7 +264,7 @@ func (s *commentService) GetParentComment(id uuid.UUID) (*models.Comment, error)
 	}
 
 	var parent models.Comment
-	err := database.DB.First(&parent, comment.ParentID).Error
+	err := database.DB.Scopes(publicComments).First(&parent, "id = ?", *comment.ParentID).Error
 	if err != nil {
 		return nil, err
 	}
@@ -177,19 +273,32 @@ func (s *commentService) GetParentComment(id uuid.UUID) (*models.Comment, error)
 
 func (s *commentService) GetDescendantComments(id uuid.UUID) ([]models.Comment, error) {
 	var comment models.Comment
-	if err := database.DB.First(&comment, id).Error; err != nil {
+	if err := database.DB.Scopes(publicComments).First(&comment, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 
 	var descendants []models.Comment
-	err := database.DB.Where("record_left > ? AND record_right < ?", comment.RecordLeft, comment.RecordRight).Find(&descendants).Error
+	err := database.DB.Scopes(publicComments).
+		Where("post_id = ? AND record_left > ? AND record_right < ?", comment.PostID, comment.RecordLeft, comment.RecordRight).
+		Find(&descendants).Error
 	return descendants, err
 }
 
 func (s *commentService) GetChildrenComments(id uuid.UUID) ([]models.Comment, error) {
+	var comment models.Comment
+	if err := database.DB.Scopes(publicComments).Select("id").First(&comment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
 	var children []models.Comment
-	err := database.DB.Where("parent_id = ?", id).Find(&children).Error
+	err := database.DB.Scopes(publicComments).Where("parent_id = ?", id).Find(&children).Error
 	return children, err
 }
 
+// publicComments limits a query to the comments readers can see: approved,
+// public and not hidden by reports
+func publicComments(db *gorm.DB) *gorm.DB {
+	return db.Where("comments.status = ? AND comments.is_public = ? AND comments.hidden_at IS NULL", models.CommentStatusApproved, true)
+}
+
 var CommentSvc CommentService = &commentService{}
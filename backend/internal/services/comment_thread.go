@@ -0,0 +1,301 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-next/internal/models"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+// Orders of a discussion; top puts the comments with the most replies and reactions first
+const (
+	CommentSortOldest = "oldest"
+	CommentSortNewest = "newest"
+	CommentSortTop    = "top"
+)
+
+// Limits of a discussion page
+const (
+	DefaultCommentDepth   = 3
+	MaxCommentDepth       = 10
+	DefaultCommentLimit   = 20
+	MaxCommentLimit       = 100
+	DefaultCommentReplies = 5
+	MaxCommentReplies     = 50
+)
+
+var ErrInvalidCommentThread = errors.New("invalid comment thread request")
+
+// CommentThreadOptions selects a page of a discussion
+type CommentThreadOptions struct {
+	Sort string
+	// Depth is how many levels of replies are nested under the listed comments
+	Depth int
+	// Limit is the number of top-level comments, Replies the number of replies shown per comment
+	Limit   int
+	Replies int
+	// Cursor continues a level: the top-level comments, or the replies of one comment
+	Cursor string
+}
+
+// commentForest is the cached discussion of a post: its approved comments in
+// nested-set order, and their authors
+type commentForest struct {
+	Comments []models.Comment
+	Authors  map[uuid.UUID]models.Author
+}
+
+// GetThread returns a page of the approved discussion of a post
+func (s *commentService) GetThread(postID uuid.UUID, opts CommentThreadOptions) (*models.CommentThread, error) {
+	if err := database.DB.Select("id").First(&models.Post{}, "id = ?", postID).Error; err != nil {
+		return nil, err
+	}
+	forest, err := loadCommentForest(postID)
+	if err != nil {
+		return nil, err
+	}
+	return BuildCommentThread(postID, forest.Comments, forest.Authors, opts)
+}
+
+// loadCommentForest reads the approved comments of a post in one query. A reply
+// is only shown when every comment above it is, which the nested-set order lets
+// the thread check in a single pass.
+func loadCommentForest(postID uuid.UUID) (*commentForest, error) {
+	key := fmt.Sprintf(CacheKeyComments, postID)
+	var forest commentForest
+	if err := CacheSvc.Get(key, &forest); err == nil {
+		return &forest, nil
+	}
+
+	if err := database.DB.
+		Select("id, parent_id, content, user_id, post_id, record_left, record_right, record_dept, reaction_count, created_at, updated_at").
+		Scopes(publicComments).
+		Where("post_id = ?", postID).
+		Order("record_left ASC, created_at ASC").
+		Find(&forest.Comments).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(forest.Comments))
+	for _, comment := range forest.Comments {
+		userIDs = append(userIDs, comment.UserID)
+	}
+	authors, err := loadAuthors(uniqueUUIDs(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	forest.Authors = authors
+
+	_ = CacheSvc.Set(key, forest, CacheDurationMedium)
+	return &forest, nil
+}
+
+// invalidateCommentThread drops the cached discussion of a post
+func invalidateCommentThread(postID uuid.UUID) {
+	_ = CacheSvc.Delete(fmt.Sprintf(CacheKeyComments, postID))
+}
+
+type threadEntry struct {
+	comment  *models.Comment
+	depth    int
+	replies  int
+	children []*threadEntry
+}
+
+// BuildCommentThread arranges approved comments, given in nested-set order,
+// into the page of a discussion that opts select. Replies whose parent is not
+// among the comments are left out along with everything below them.
+func BuildCommentThread(postID uuid.UUID, comments []models.Comment, authors map[uuid.UUID]models.Author, opts CommentThreadOptions) (*models.CommentThread, error) {
+	opts, err := normalizeCommentThreadOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	parentID, offset, err := decodeCommentCursor(postID, opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*threadEntry, len(comments))
+	var roots []*threadEntry
+	for i := range comments {
+		entry := &threadEntry{comment: &comments[i]}
+		if entry.comment.ParentID == nil {
+			roots = append(roots, entry)
+		} else if parent, ok := byID[*entry.comment.ParentID]; ok {
+			entry.depth = parent.depth + 1
+			parent.children = append(parent.children, entry)
+		} else {
+			continue
+		}
+		byID[entry.comment.ID] = entry
+	}
+	for _, root := range roots {
+		countThreadReplies(root)
+	}
+
+	thread := &models.CommentThread{PostID: postID, ParentID: parentID, Sort: opts.Sort, Total: len(byID)}
+	level, limit := roots, opts.Limit
+	if parentID != nil {
+		parent, ok := byID[*parentID]
+		if !ok {
+			return nil, fmt.Errorf("%w: comment %s is not part of the discussion", ErrInvalidCommentThread, parentID)
+		}
+		level, limit = parent.children, opts.Replies
+	}
+
+	builder := threadBuilder{postID: postID, authors: authors, opts: opts}
+	thread.Comments, thread.NextCursor = builder.level(parentID, level, offset, limit, opts.Depth)
+	return thread, nil
+}
+
+type threadBuilder struct {
+	postID  uuid.UUID
+	authors map[uuid.UUID]models.Author
+	opts    CommentThreadOptions
+}
+
+// level renders limit entries of one level from offset, nesting depth levels of replies
+func (b threadBuilder) level(parentID *uuid.UUID, entries []*threadEntry, offset, limit, depth int) ([]models.CommentNode, string) {
+	sortThreadEntries(entries, b.opts.Sort)
+	offset = min(offset, len(entries))
+	end := min(offset+limit, len(entries))
+
+	nodes := make([]models.CommentNode, 0, end-offset)
+	for _, entry := range entries[offset:end] {
+		node := b.node(entry)
+		if len(entry.children) > 0 {
+			id := entry.comment.ID
+			if depth > 0 {
+				node.Replies, node.MoreReplies = b.level(&id, entry.children, 0, b.opts.Replies, depth-1)
+			} else {
+				node.MoreReplies = encodeCommentCursor(b.postID, &id, 0)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	next := ""
+	if end < len(entries) {
+		next = encodeCommentCursor(b.postID, parentID, end)
+	}
+	return nodes, next
+}
+
+func (b threadBuilder) node(entry *threadEntry) models.CommentNode {
+	comment := entry.comment
+	node := models.CommentNode{
+		ID:            comment.ID,
+		ParentID:      comment.ParentID,
+		Content:       comment.Content,
+		Depth:         entry.depth,
+		CreatedAt:     comment.CreatedAt,
+		UpdatedAt:     comment.UpdatedAt,
+		ReplyCount:    len(entry.children),
+		TotalReplies:  entry.replies,
+		Replies:       []models.CommentNode{},
+		ReactionCount: comment.ReactionCount,
+	}
+	if author, ok := b.authors[comment.UserID]; ok {
+		node.Author = &author
+	}
+	return node
+}
+
+// score ranks a comment for the top order: each reply and each reaction counts once
+func (e *threadEntry) score() int64 {
+	return int64(e.replies) + e.comment.ReactionCount
+}
+
+func countThreadReplies(entry *threadEntry) int {
+	entry.replies = 0
+	for _, child := range entry.children {
+		entry.replies += 1 + countThreadReplies(child)
+	}
+	return entry.replies
+}
+
+func sortThreadEntries(entries []*threadEntry, order string) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if order == CommentSortTop {
+			if scoreA, scoreB := a.score(), b.score(); scoreA != scoreB {
+				return scoreA > scoreB
+			}
+		}
+		if !a.comment.CreatedAt.Equal(b.comment.CreatedAt) {
+			if order == CommentSortNewest {
+				return a.comment.CreatedAt.After(b.comment.CreatedAt)
+			}
+			return a.comment.CreatedAt.Before(b.comment.CreatedAt)
+		}
+		return a.comment.ID.String() < b.comment.ID.String()
+	})
+}
+
+func normalizeCommentThreadOptions(opts CommentThreadOptions) (CommentThreadOptions, error) {
+	switch opts.Sort {
+	case "":
+		opts.Sort = CommentSortOldest
+	case CommentSortOldest, CommentSortNewest, CommentSortTop:
+	default:
+		return opts, fmt.Errorf("%w: unknown sort %q", ErrInvalidCommentThread, opts.Sort)
+	}
+	if opts.Depth < 0 {
+		return opts, fmt.Errorf("%w: depth must not be negative", ErrInvalidCommentThread)
+	}
+	opts.Depth = min(opts.Depth, MaxCommentDepth)
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultCommentLimit
+	}
+	opts.Limit = min(opts.Limit, MaxCommentLimit)
+	if opts.Replies <= 0 {
+		opts.Replies = DefaultCommentReplies
+	}
+	opts.Replies = min(opts.Replies, MaxCommentReplies)
+	return opts, nil
+}
+
+// encodeCommentCursor points at a level of a post's discussion, from an offset
+func encodeCommentCursor(postID uuid.UUID, parentID *uuid.UUID, offset int) string {
+	parent := ""
+	if parentID != nil {
+		parent = parentID.String()
+	}
+	raw := postID.String() + ":" + parent + ":" + strconv.Itoa(offset)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCommentCursor(postID uuid.UUID, cursor string) (*uuid.UUID, int, error) {
+	if cursor == "" {
+		return nil, 0, nil
+	}
+	invalid := fmt.Errorf("%w: invalid cursor", ErrInvalidCommentThread)
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] != postID.String() {
+		return nil, 0, invalid
+	}
+	offset, err := strconv.Atoi(parts[2])
+	if err != nil || offset < 0 {
+		return nil, 0, invalid
+	}
+	if parts[1] == "" {
+		return nil, offset, nil
+	}
+	parentID, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, 0, invalid
+	}
+	return &parentID, offset, nil
+}
@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/feed"
+
+	"gorm.io/gorm"
+)
+
+// Feed scopes
+const (
+	FeedScopeAll      = "all"
+	FeedScopeCategory = "category"
+	FeedScopeTag      = "tag"
+	FeedScopeSeries   = "series"
+	FeedScopeAuthor   = "author"
+)
+
+// Feed content modes
+const (
+	FeedContentExcerpt = "excerpt"
+	FeedContentFull    = "full"
+)
+
+// feedSummaryLength is the length of summaries derived from post content
+const feedSummaryLength = 280
+
+var ErrInvalidFeedScope = errors.New("invalid feed scope")
+
+type FeedService interface {
+	BuildFeed(req FeedRequest) (*feed.Feed, error)
+	LastModified(req FeedRequest) (time.Time, error)
+}
+
+// FeedRequest selects the posts of a feed and how they are rendered
+type FeedRequest struct {
+	Scope   string
+	Slug    string
+	Content string
+	FeedURL string
+	// Locales is the negotiated locale chain; empty lists posts of every locale
+	Locales []string
+}
+
+type feedService struct {
+	blogService BlogService
+}
+
+func NewFeedService(blogService BlogService) FeedService {
+	return &feedService{blogService: blogService}
+}
+
+// BuildFeed loads the latest public posts of a scope into a feed
+func (s *feedService) BuildFeed(req FeedRequest) (*feed.Feed, error) {
+	site := config.GetConfig().Site
+	limit := site.FeedItems
+	if limit <= 0 {
+		limit = 20
+	}
+	if req.Content == "" {
+		req.Content = site.FeedContent
+	}
+
+	f := &feed.Feed{
+		ID:          req.FeedURL,
+		Title:       site.Name,
+		Description: site.Description,
+		Link:        site.URL,
+		FeedURL:     req.FeedURL,
+		Language:    site.Language,
+	}
+	blog := s.blogService
+	if len(req.Locales) > 0 {
+		f.Language = req.Locales[0]
+		blog = blog.WithLocale(req.Locales)
+	}
+
+	var posts []models.Post
+	var err error
+	switch req.Scope {
+	case FeedScopeAll:
+		posts, _, err = blog.GetPublicPosts(1, limit, "", "")
+	case FeedScopeCategory:
+		var category *models.Category
+		if category, err = blog.GetCategoryBySlug(req.Slug); err != nil {
+			return nil, err
+		}
+		f.Title = site.Name + " - " + category.Name
+		f.Description = category.Description
+		f.Link = site.LocalizedCategoryURL(category.Locale, category.Slug)
+		posts, _, err = blog.GetPostsByCategory(req.Slug, 1, limit)
+	case FeedScopeTag:
+		var tag *models.Tag
+		if tag, err = blog.GetTagBySlug(req.Slug); err != nil {
+			return nil, err
+		}
+		f.Title = site.Name + " - " + tag.Name
+		f.Description = tag.Description
+		f.Link = site.TagURL(tag.Slug)
+		posts, _, err = blog.GetPostsByTag(req.Slug, 1, limit)
+	case FeedScopeSeries:
+		// A series feed lists its parts in reading order
+		var series *models.Series
+		if series, err = SeriesSvc.GetSeriesBySlug(req.Slug); err != nil {
+			return nil, err
+		}
+		f.Title = site.Name + " - " + series.Title
+		f.Description = series.Description
+		f.Link = site.SeriesURL(series.Slug)
+		if posts, err = SeriesSvc.GetSeriesPosts(series.ID, req.Locales); err == nil && len(posts) > limit {
+			posts = posts[len(posts)-limit:]
+		}
+	case FeedScopeAuthor:
+		var author *models.Author
+		if author, err = AuthorSvc.GetAuthorByUsername(req.Slug); err != nil {
+			return nil, err
+		}
+		f.Title = site.Name + " - " + author.DisplayName
+		f.Description = author.Bio
+		f.Link = author.URL
+		posts, _, err = blog.GetPostsByAuthor(author.ID, 1, limit)
+	default:
+		return nil, ErrInvalidFeedScope
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.Description == "" {
+		f.Description = site.Description
+	}
+
+	ids := postIDs(posts)
+	tagsByPost, err := loadPostTags(ids)
+	if err != nil {
+		return nil, err
+	}
+	authorsByPost, err := loadPostAuthors(posts)
+	if err != nil {
+		return nil, err
+	}
+	mediaByPost, err := loadMediaFor(models.MediableTypePost, ids)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := loadPostTranslations(postTranslationGroups(posts))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, post := range posts {
+		item := feed.Item{
+			ID:      "urn:uuid:" + post.ID.String(),
+			Title:   post.Title,
+			Link:    site.LocalizedPostURL(post.Locale, post.Slug),
+			Summary: postSummary(&post),
+			Updated: post.UpdatedAt,
+		}
+		if post.PublishedAt != nil {
+			item.Published = *post.PublishedAt
+		}
+		if req.Content == FeedContentFull {
+			item.Content = post.Content
+		}
+		if names := authorNames(authorsByPost[post.ID]); len(names) > 0 {
+			item.Author, item.Contributors = names[0], names[1:]
+		}
+		if post.Category != nil {
+			item.Categories = append(item.Categories, post.Category.Name)
+		}
+		for _, tag := range tagsByPost[post.ID] {
+			item.Categories = append(item.Categories, tag.Name)
+		}
+		for _, media := range mediaByPost[post.ID] {
+			if media.URL == "" {
+				continue
+			}
+			item.Enclosures = append(item.Enclosures, feed.Enclosure{URL: media.URL, MimeType: media.MimeType, Length: media.Size})
+		}
+		if post.TranslationGroupID != nil {
+			for _, translation := range translations[*post.TranslationGroupID] {
+				if translation.ID != post.ID {
+					item.Alternates = append(item.Alternates, feed.Alternate{Hreflang: translation.Locale, Href: translation.URL})
+				}
+			}
+		}
+
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	if f.Updated.IsZero() {
+		f.Updated = time.Now()
+	}
+	f.Updated = f.Updated.UTC().Truncate(time.Second)
+
+	return f, nil
+}
+
+// LastModified bounds when the feed of a request last changed: the latest
+// change to any post or to the records its scope is titled after. It is
+// cheap enough to answer conditional requests before building the feed, and
+// is zero when there is nothing to go by.
+func (s *feedService) LastModified(req FeedRequest) (time.Time, error) {
+	sources := []interface{}{&models.Post{}}
+	switch req.Scope {
+	case FeedScopeAll:
+	case FeedScopeCategory:
+		sources = append(sources, &models.Category{})
+	case FeedScopeTag:
+		sources = append(sources, &models.Tag{})
+	case FeedScopeSeries:
+		sources = append(sources, &models.Series{})
+	case FeedScopeAuthor:
+		sources = append(sources, &models.User{}, &models.AuthorProfile{})
+	default:
+		return time.Time{}, ErrInvalidFeedScope
+	}
+
+	var lastModified time.Time
+	for _, model := range sources {
+		changed, err := latestChange(model)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if changed.After(lastModified) {
+			lastModified = changed
+		}
+	}
+	return lastModified.UTC().Truncate(time.Second), nil
+}
+
+// latestChange returns when a row of a model's table was last updated or,
+// for soft-deleted models, deleted
+func latestChange(model interface{}) (time.Time, error) {
+	stmt := &gorm.Statement{DB: database.DB}
+	if err := stmt.Parse(model); err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, column := range []string{"updated_at", "deleted_at"} {
+		if stmt.Schema.LookUpField(column) == nil {
+			continue
+		}
+		var times []time.Time
+		if err := database.DB.Unscoped().Model(model).
+			Where(column+" IS NOT NULL").
+			Order(column+" DESC").
+			Limit(1).
+			Pluck(column, &times).Error; err != nil {
+			return time.Time{}, err
+		}
+		if len(times) > 0 && times[0].After(latest) {
+			latest = times[0]
+		}
+	}
+	return latest, nil
+}
+
+// postSummary returns the excerpt of a post, or the start of its text
+func postSummary(post *models.Post) string {
+	if post.Excerpt != "" {
+		return post.Excerpt
+	}
+	return truncateText(stripTags(post.Content), feedSummaryLength)
+}
+
+// truncateText shortens text to at most length runes, cutting at a word boundary
+func truncateText(text string, length int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	cut := string(runes[:length])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
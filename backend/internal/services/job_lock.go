@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const jobLockKey = "lock:job:%s"
+
+// runExclusive runs fn unless another instance holds the named lock, and
+// reports whether it ran. The lock expires after ttl so a crashed holder
+// cannot block the job forever; it is only released by the run that took it.
+// Without Redis there is a single instance and fn always runs.
+func runExclusive(ctx context.Context, name string, ttl time.Duration, fn func() error) (bool, error) {
+	if GlobalRedisClient == nil {
+		return true, fn()
+	}
+
+	key := fmt.Sprintf(jobLockKey, name)
+	token := uuid.NewString()
+	acquired, err := GlobalRedisClient.SetNX(ctx, key, token, ttl)
+	if err != nil || !acquired {
+		return false, err
+	}
+	defer func() {
+		if _, err := GlobalRedisClient.DelIfEqual(context.Background(), key, token); err != nil {
+			log.Printf("Warning: failed to release job lock %s: %v", name, err)
+		}
+	}()
+
+	return true, fn()
+}
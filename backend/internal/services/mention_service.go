@@ -0,0 +1,288 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/mention"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MentionService interface {
+	GetUserMentions(userID uuid.UUID, page, perPage int) ([]models.Mention, int64, error)
+}
+
+type mentionService struct{}
+
+func NewMentionService() MentionService {
+	return &mentionService{}
+}
+
+// GetUserMentions lists where a user has been mentioned, newest first. Only
+// mentions in published posts and comments readers can see are listed.
+func (s *mentionService) GetUserMentions(userID uuid.UUID, page, perPage int) ([]models.Mention, int64, error) {
+	visibleComment := database.DB.Model(&models.Comment{}).Select("1").
+		Scopes(publicComments).
+		Where("comments.id = mentions.mentionable_id")
+	query := database.DB.Model(&models.Mention{}).
+		Where("mentions.user_id = ? AND mentions.notified_at IS NOT NULL", userID).
+		Where(`(mentions.mentionable_type = ? AND EXISTS (
+				SELECT 1 FROM posts WHERE posts.id = mentions.mentionable_id AND posts.status = ? AND posts.deleted_at IS NULL))
+			OR (mentions.mentionable_type = ? AND EXISTS (?))`,
+			models.MentionableTypePost, "published", models.MentionableTypeComment, visibleComment)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var mentions []models.Mention
+	if err := query.Preload("Post", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, title, slug, locale")
+	}).
+		Order("mentions.created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&mentions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var authorIDs []uuid.UUID
+	for _, m := range mentions {
+		if m.MentionedByID != nil {
+			authorIDs = append(authorIDs, *m.MentionedByID)
+		}
+	}
+	authors, err := loadAuthors(uniqueUUIDs(authorIDs))
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range mentions {
+		m := &mentions[i]
+		if m.MentionedByID != nil {
+			if author, ok := authors[*m.MentionedByID]; ok {
+				m.MentionedBy = &author
+			}
+		}
+		if m.Post != nil {
+			m.URL = mentionURL(m.Post, m.MentionableType, m.MentionableID)
+		}
+	}
+	return mentions, total, nil
+}
+
+// mentionTarget is a post or comment whose text may mention users
+type mentionTarget struct {
+	Type     string
+	ID       uuid.UUID
+	Post     *models.Post
+	AuthorID *uuid.UUID
+	Text     string
+	// Visible is whether readers can see the text; mentioned users are notified once it is
+	Visible bool
+}
+
+// syncCommentMentions records the mentions of a comment, notifying the users once it is approved
+func syncCommentMentions(comment *models.Comment) {
+	var post models.Post
+	if err := database.DB.Select("id, title, slug, locale").First(&post, "id = ?", comment.PostID).Error; err != nil {
+		log.Printf("Warning: failed to record mentions of comment %s: %v", comment.ID, err)
+		return
+	}
+	userID := comment.UserID
+	if err := syncMentions(mentionTarget{
+		Type:     models.MentionableTypeComment,
+		ID:       comment.ID,
+		Post:     &post,
+		AuthorID: &userID,
+		Text:     comment.Content,
+		Visible:  comment.IsApproved(),
+	}); err != nil {
+		log.Printf("Warning: failed to record mentions of comment %s: %v", comment.ID, err)
+	}
+}
+
+// syncPostMentions records the mentions of a post and its content blocks,
+// notifying the users once it is published
+func syncPostMentions(postID uuid.UUID) {
+	var post models.Post
+	if err := database.DB.Select("id, title, slug, locale, content, status, published_at, created_by").
+		First(&post, "id = ?", postID).Error; err != nil {
+		log.Printf("Warning: failed to record mentions of post %s: %v", postID, err)
+		return
+	}
+	var blocks []string
+	if err := database.DB.Model(&models.Content{}).
+		Where("model_id = ? AND model_type = ?", postID, "post").
+		Order("sort_order ASC").
+		Pluck("content", &blocks).Error; err != nil {
+		log.Printf("Warning: failed to record mentions of post %s: %v", postID, err)
+		return
+	}
+
+	if err := syncMentions(mentionTarget{
+		Type:     models.MentionableTypePost,
+		ID:       post.ID,
+		Post:     &post,
+		AuthorID: post.CreatedBy,
+		Text:     post.Content + "\n" + strings.Join(blocks, "\n"),
+		Visible:  post.IsPublished(),
+	}); err != nil {
+		log.Printf("Warning: failed to record mentions of post %s: %v", postID, err)
+	}
+}
+
+// syncMentions makes the mention records of a text match the users it
+// mentions now. Authors mentioning themselves are ignored.
+func syncMentions(target mentionTarget) error {
+	mentioned := make(map[uuid.UUID]bool)
+	if usernames := mention.Parse(target.Text); len(usernames) > 0 {
+		var users []models.User
+		if err := database.DB.Select("id").
+			Where("LOWER(username) IN ? AND is_active = ?", usernames, true).
+			Find(&users).Error; err != nil {
+			return err
+		}
+		for _, user := range users {
+			if target.AuthorID == nil || user.ID != *target.AuthorID {
+				mentioned[user.ID] = true
+			}
+		}
+	}
+
+	var existing []models.Mention
+	if err := database.DB.Where("mentionable_type = ? AND mentionable_id = ?", target.Type, target.ID).
+		Find(&existing).Error; err != nil {
+		return err
+	}
+
+	var stale []uuid.UUID
+	kept := make(map[uuid.UUID]bool, len(existing))
+	for _, m := range existing {
+		if mentioned[m.UserID] {
+			kept[m.UserID] = true
+		} else {
+			stale = append(stale, m.ID)
+		}
+	}
+	var added []models.Mention
+	for userID := range mentioned {
+		if !kept[userID] {
+			added = append(added, models.Mention{
+				UserID:          userID,
+				MentionableID:   target.ID,
+				MentionableType: target.Type,
+				PostID:          target.Post.ID,
+				MentionedByID:   target.AuthorID,
+			})
+		}
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(stale) > 0 {
+			if err := tx.Unscoped().Where("id IN ?", stale).Delete(&models.Mention{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&added).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if !target.Visible {
+		return nil
+	}
+	return notifyMentions(target)
+}
+
+// notifyMentions notifies the mentioned users of a text who have not been notified yet
+func notifyMentions(target mentionTarget) error {
+	var pending []models.Mention
+	if err := database.DB.Where("mentionable_type = ? AND mentionable_id = ? AND notified_at IS NULL", target.Type, target.ID).
+		Find(&pending).Error; err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	byline := "Someone"
+	if target.AuthorID != nil {
+		authors, err := loadAuthors([]uuid.UUID{*target.AuthorID})
+		if err != nil {
+			return err
+		}
+		if author, ok := authors[*target.AuthorID]; ok {
+			byline = author.DisplayName
+		}
+	}
+	message := fmt.Sprintf("in %q", target.Post.Title)
+	if target.Type == models.MentionableTypeComment {
+		message = fmt.Sprintf("in a comment on %q", target.Post.Title)
+	}
+	url := mentionURL(target.Post, target.Type, target.ID)
+
+	for _, m := range pending {
+		data, err := json.Marshal(map[string]interface{}{
+			"mention_id":       m.ID,
+			"mentionable_type": m.MentionableType,
+			"mentionable_id":   m.MentionableID,
+			"post_id":          m.PostID,
+			"url":              url,
+		})
+		if err != nil {
+			return err
+		}
+		notification := &models.Notification{
+			UserID:  m.UserID,
+			Type:    string(models.NotificationTypeMention),
+			Title:   byline + " mentioned you",
+			Message: message,
+			Data:    string(data),
+		}
+		// Claim the mention and store its notification together, so concurrent
+		// syncs notify once and a failed insert leaves the mention to retry
+		claimed := false
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Mention{}).
+				Where("id = ? AND notified_at IS NULL", m.ID).
+				UpdateColumn("notified_at", time.Now())
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			claimed = true
+			return storeNotification(tx, notification)
+		}); err != nil {
+			return err
+		}
+		if claimed {
+			pushNotification(notification)
+		}
+	}
+	return nil
+}
+
+// mentionURL links to the post, or to the comment on it
+func mentionURL(post *models.Post, mentionableType string, mentionableID uuid.UUID) string {
+	url := config.GetConfig().Site.LocalizedPostURL(post.Locale, post.Slug)
+	if mentionableType == models.MentionableTypeComment {
+		url += "#comment-" + mentionableID.String()
+	}
+	return url
+}
+
+var MentionSvc MentionService = NewMentionService()
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"go-next/internal/models"
 	"go-next/pkg/database"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -191,4 +192,40 @@ func (s *NotificationService) GetNotificationByID(notificationID string, userID
 	return &notification, nil
 }
 
+// notifyUser stores a notification and pushes it to the user's open WebSocket connections
+func notifyUser(notification *models.Notification) error {
+	if err := storeNotification(database.DB, notification); err != nil {
+		return err
+	}
+	pushNotification(notification)
+	return nil
+}
+
+// storeNotification saves a notification, in a transaction when db is one
+func storeNotification(db *gorm.DB, notification *models.Notification) error {
+	if notification.Priority == "" {
+		notification.Priority = "normal"
+	}
+	return db.Create(notification).Error
+}
+
+// pushNotification sends a stored notification to the user's open WebSocket connections
+func pushNotification(notification *models.Notification) {
+	if GlobalHub == nil {
+		return
+	}
+	GlobalHub.SendToUser(notification.UserID.String(), &NotificationMessage{
+		Type: notification.Type,
+		Data: map[string]interface{}{
+			"id":         notification.ID,
+			"title":      notification.Title,
+			"message":    notification.Message,
+			"data":       notification.Data,
+			"priority":   notification.Priority,
+			"created_at": notification.CreatedAt,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
 var NotificationSvc *NotificationService = NewNotificationService()
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/oembed"
+)
+
+// Embeds of our own posts
+const (
+	oembedDefaultWidth = 600
+	oembedHeight       = 240
+	oembedCacheAge     = 3600
+)
+
+// oembedFailureTTL is how long a failed provider request is remembered before it is retried
+const oembedFailureTTL = CacheDurationShort
+
+var ErrOEmbedNotFound = errors.New("no public post for url")
+
+type OEmbedService interface {
+	Resolve(ctx context.Context, rawURL string, opts oembed.Options) (*oembed.Response, error)
+	ProviderName(rawURL string) string
+	Provide(rawURL string, opts oembed.Options) (*oembed.Response, error)
+}
+
+// cachedEmbed is a cached provider result; a nil Response records a failure
+type cachedEmbed struct {
+	Response *oembed.Response `json:"response,omitempty"`
+}
+
+type oembedService struct {
+	client *oembed.Client
+}
+
+// NewOEmbedService creates the oEmbed consumer and provider; tests pass a
+// client whose registry points at a stub server
+func NewOEmbedService(client *oembed.Client) OEmbedService {
+	return &oembedService{client: client}
+}
+
+// Resolve fetches the embed of an external URL, caching successes for the
+// provider's cache_age and failures briefly
+func (s *oembedService) Resolve(ctx context.Context, rawURL string, opts oembed.Options) (*oembed.Response, error) {
+	if _, ok := s.client.Registry.Match(rawURL); !ok {
+		return nil, fmt.Errorf("%w: %s", oembed.ErrNoProvider, rawURL)
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%d", rawURL, opts.MaxWidth, opts.MaxHeight)))
+	key := fmt.Sprintf(CacheKeyOEmbed, hex.EncodeToString(sum[:]))
+
+	var cached cachedEmbed
+	if err := CacheSvc.Get(key, &cached); err == nil {
+		if cached.Response == nil {
+			return nil, fmt.Errorf("%w: %s (cached)", oembed.ErrProviderFailed, rawURL)
+		}
+		return cached.Response, nil
+	}
+
+	response, err := s.client.Fetch(ctx, rawURL, opts)
+	if err != nil {
+		if errors.Is(err, oembed.ErrProviderFailed) {
+			_ = CacheSvc.Set(key, cachedEmbed{}, oembedFailureTTL)
+		}
+		return nil, err
+	}
+
+	_ = CacheSvc.Set(key, cachedEmbed{Response: response}, oembedCacheDuration(response.CacheAge))
+	return response, nil
+}
+
+// ProviderName returns the name of the provider of a URL, or "" when none matches
+func (s *oembedService) ProviderName(rawURL string) string {
+	if provider, ok := s.client.Registry.Match(rawURL); ok {
+		return provider.Name
+	}
+	return ""
+}
+
+// Provide answers oEmbed requests for the public posts of this site
+func (s *oembedService) Provide(rawURL string, opts oembed.Options) (*oembed.Response, error) {
+	site := config.GetConfig().Site
+	slug, tag, ok := postSlugFromURL(rawURL, site)
+	if !ok {
+		return nil, ErrOEmbedNotFound
+	}
+
+	post, err := publicPostBySlug(slug, tag)
+	if err != nil {
+		var redirect *SlugRedirectError
+		if !errors.As(err, &redirect) {
+			return nil, err
+		}
+		if post, err = publicPostBySlug(redirect.Slug, tag); err != nil {
+			return nil, err
+		}
+	}
+
+	width := oembedDefaultWidth
+	if opts.MaxWidth > 0 && opts.MaxWidth < width {
+		width = opts.MaxWidth
+	}
+	height := oembedHeight
+	if opts.MaxHeight > 0 && opts.MaxHeight < height {
+		height = opts.MaxHeight
+	}
+
+	postURL := site.LocalizedPostURL(post.Locale, post.Slug)
+	excerpt := firstNonEmpty(post.Excerpt, truncateText(stripTags(post.Content), seoDescriptionLength))
+	var b strings.Builder
+	fmt.Fprintf(&b, `<blockquote class="oembed-post" cite="%s">`, html.EscapeString(postURL))
+	fmt.Fprintf(&b, `<p><a href="%s">%s</a></p>`, html.EscapeString(postURL), html.EscapeString(post.Title))
+	if excerpt != "" {
+		fmt.Fprintf(&b, "<p>%s</p>", html.EscapeString(excerpt))
+	}
+	fmt.Fprintf(&b, "<footer>%s</footer></blockquote>", html.EscapeString(site.Name))
+
+	response := &oembed.Response{
+		Type:         oembed.TypeRich,
+		Version:      oembed.Version,
+		Title:        post.Title,
+		ProviderName: site.Name,
+		ProviderURL:  site.URL,
+		CacheAge:     oembedCacheAge,
+		HTML:         b.String(),
+		Width:        width,
+		Height:       height,
+	}
+
+	if err := attachPostAuthors(post); err != nil {
+		return nil, err
+	}
+	if len(post.Authors) > 0 {
+		response.AuthorName = strings.Join(authorNames(post.Authors), ", ")
+		response.AuthorURL = post.Authors[0].URL
+	}
+
+	image, err := resolveSEOImage(&models.SEOMeta{}, models.MediableTypePost, post.ID, site)
+	if err != nil {
+		return nil, err
+	}
+	if image != nil {
+		response.ThumbnailURL = image.URL
+		if image.Width != nil && image.Height != nil {
+			response.ThumbnailWidth = *image.Width
+			response.ThumbnailHeight = *image.Height
+		}
+	}
+
+	return response, nil
+}
+
+// postSlugFromURL extracts the slug of a post URL of this site, and the
+// locale of its prefix when the URL is of a translation
+func postSlugFromURL(rawURL string, site config.SiteConfig) (string, string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || site.URL == "" {
+		return "", "", false
+	}
+	base, err := url.Parse(site.PostURL(""))
+	if err != nil || !strings.EqualFold(u.Host, base.Host) {
+		return "", "", false
+	}
+
+	// Translations live under a locale prefix: /fr/blog/slug
+	path, tag := u.Path, ""
+	if !strings.HasPrefix(path, base.Path) {
+		root := strings.TrimSuffix(base.Path, "blog/")
+		for _, supported := range SiteLocales().Supported() {
+			prefix := site.LocalePrefix(supported)
+			if prefix == "" {
+				continue
+			}
+			localized := root + strings.TrimPrefix(prefix, "/") + "/"
+			if strings.HasPrefix(path, localized+"blog/") {
+				path, tag = root+strings.TrimPrefix(path, localized), supported
+				break
+			}
+		}
+	}
+
+	slug := strings.TrimSuffix(strings.TrimPrefix(path, base.Path), "/")
+	if !strings.HasPrefix(path, base.Path) || slug == "" || strings.Contains(slug, "/") {
+		return "", "", false
+	}
+	return slug, tag, true
+}
+
+// publicPostBySlug finds a public post within the locale chain of the locale
+// of its URL; URLs without a locale prefix are of the site language
+func publicPostBySlug(slug, tag string) (*models.Post, error) {
+	var chain []string
+	if locales := SiteLocales(); len(locales.Supported()) > 1 {
+		if tag == "" {
+			tag = locales.Fallback()
+		}
+		chain = locales.Chain(tag)
+	}
+
+	var post models.Post
+	if err := database.DB.Where("slug = ? AND status = ? AND public = ? AND published_at IS NOT NULL", slug, "published", true).
+		Scopes(postsInLocales(chain)).
+		First(&post).Error; err != nil {
+		return nil, slugRedirect(models.MediableTypePost, slug, err)
+	}
+	return &post, nil
+}
+
+// oembedCacheDuration honours a provider's cache_age within sane bounds
+func oembedCacheDuration(cacheAge int) time.Duration {
+	if cacheAge <= 0 {
+		return CacheDurationDay
+	}
+	age := time.Duration(cacheAge) * time.Second
+	if age < CacheDurationLong {
+		return CacheDurationLong
+	}
+	if age > CacheDurationWeek {
+		return CacheDurationWeek
+	}
+	return age
+}
+
+var OEmbedSvc OEmbedService = NewOEmbedService(oembed.NewClient(oembed.NewRegistry(oembed.DefaultProviders()...), nil))
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"time"
+
+	"go-next/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// orderedList keeps the members of an owner row numbered densely from zero in
+// an order column, as the posts of a series and the blocks of a post are.
+// Every change runs in the caller's transaction after lock has locked the
+// owner row, so concurrent edits of one list wait for each other instead of
+// numbering from stale positions.
+type orderedList[T any] struct {
+	owner   func() interface{}
+	members func(tx *gorm.DB, ownerID uuid.UUID) *gorm.DB
+	column  string
+	id      func(member *T) uuid.UUID
+	order   func(member *T) *int
+}
+
+var (
+	seriesPostOrder = orderedList[models.SeriesPost]{
+		owner: func() interface{} { return &models.Series{} },
+		members: func(tx *gorm.DB, seriesID uuid.UUID) *gorm.DB {
+			return tx.Model(&models.SeriesPost{}).Where("series_id = ?", seriesID)
+		},
+		column: "position",
+		id:     func(entry *models.SeriesPost) uuid.UUID { return entry.ID },
+		order:  func(entry *models.SeriesPost) *int { return &entry.Position },
+	}
+	postBlockOrder = orderedList[models.Content]{
+		owner: func() interface{} { return &models.Post{} },
+		members: func(tx *gorm.DB, postID uuid.UUID) *gorm.DB {
+			return tx.Model(&models.Content{}).Where("model_id = ? AND model_type = ?", postID, models.MediableTypePost)
+		},
+		column: "sort_order",
+		id:     func(block *models.Content) uuid.UUID { return block.ID },
+		order:  func(block *models.Content) *int { return &block.SortOrder },
+	}
+)
+
+// list loads the members of an owner in order
+func (l orderedList[T]) list(db *gorm.DB, ownerID uuid.UUID) ([]T, error) {
+	var members []T
+	err := l.members(db, ownerID).Order(l.column + " ASC, created_at ASC").Find(&members).Error
+	return members, err
+}
+
+// lock locks the owner row until the transaction ends, then loads its members
+func (l orderedList[T]) lock(tx *gorm.DB, ownerID uuid.UUID) ([]T, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(l.owner(), "id = ?", ownerID).Error; err != nil {
+		return nil, err
+	}
+	return l.list(tx, ownerID)
+}
+
+// write renumbers members from zero in slice order, skipping unchanged rows
+func (l orderedList[T]) write(tx *gorm.DB, members []T) error {
+	for i := range members {
+		order := l.order(&members[i])
+		if *order == i {
+			continue
+		}
+		*order = i
+		if err := tx.Model(new(T)).Where("id = ?", l.id(&members[i])).UpdateColumn(l.column, i).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertAt makes room for a new member at a zero-based position of the locked
+// members and returns its order; a nil or out of range position appends.
+// Stored orders can have gaps or ties, so the members are renumbered first.
+func (l orderedList[T]) insertAt(tx *gorm.DB, ownerID uuid.UUID, members []T, position *int) (int, error) {
+	if err := l.write(tx, members); err != nil {
+		return 0, err
+	}
+	index := len(members)
+	if position != nil && *position >= 0 && *position < len(members) {
+		index = *position
+	}
+	err := l.members(tx, ownerID).Where(l.column+" >= ?", index).
+		UpdateColumn(l.column, gorm.Expr(l.column+" + 1")).Error
+	return index, err
+}
+
+// without returns the members other than the first one matching, and whether one did
+func (l orderedList[T]) without(members []T, match func(member *T) bool) ([]T, bool) {
+	remaining := make([]T, 0, len(members))
+	found := false
+	for i := range members {
+		if !found && match(&members[i]) {
+			found = true
+			continue
+		}
+		remaining = append(remaining, members[i])
+	}
+	return remaining, found
+}
+
+// permute arranges members in the order of their keys; order must list every
+// member's key exactly once
+func (l orderedList[T]) permute(members []T, order []uuid.UUID, key func(member *T) uuid.UUID) ([]T, bool) {
+	if len(order) != len(members) {
+		return nil, false
+	}
+	byKey := make(map[uuid.UUID]T, len(members))
+	for i := range members {
+		byKey[key(&members[i])] = members[i]
+	}
+	permuted := make([]T, 0, len(order))
+	for _, k := range order {
+		member, ok := byKey[k]
+		if !ok {
+			return nil, false
+		}
+		delete(byKey, k)
+		permuted = append(permuted, member)
+	}
+	return permuted, true
+}
+
+// touch bumps the owner's updated_at so feeds, caches and the sitemap see the change
+func (l orderedList[T]) touch(tx *gorm.DB, ownerID uuid.UUID) error {
+	return tx.Model(l.owner()).Where("id = ?", ownerID).UpdateColumn("updated_at", time.Now()).Error
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"go-next/internal/models"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+)
+
+// loadPostTags returns the tags of each post, keyed by post ID
+func loadPostTags(postIDs []uuid.UUID) (map[uuid.UUID][]models.Tag, error) {
+	tagsByPost := make(map[uuid.UUID][]models.Tag)
+	if len(postIDs) == 0 {
+		return tagsByPost, nil
+	}
+
+	var rows []struct {
+		PostID uuid.UUID
+		models.Tag
+	}
+	if err := database.DB.Table("post_tags").
+		Select("post_tags.post_id, tags.*").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ? AND tags.deleted_at IS NULL", postIDs).
+		Order("tags.name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		tagsByPost[row.PostID] = append(tagsByPost[row.PostID], row.Tag)
+	}
+	return tagsByPost, nil
+}
+
+// loadMediaFor returns the public media attached to each model, in sort order
+func loadMediaFor(mediableType string, ids []uuid.UUID) (map[uuid.UUID][]models.Media, error) {
+	mediaByModel := make(map[uuid.UUID][]models.Media)
+	if len(ids) == 0 {
+		return mediaByModel, nil
+	}
+
+	var rows []struct {
+		MediableID uuid.UUID
+		models.Media
+	}
+	if err := database.DB.Table("mediables").
+		Select("mediables.mediable_id, media.*").
+		Joins("JOIN media ON media.id = mediables.media_id").
+		Where("mediables.mediable_type = ? AND mediables.mediable_id IN ?", mediableType, ids).
+		Where("media.is_public = ? AND media.deleted_at IS NULL", true).
+		Order("mediables.sort_order ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		mediaByModel[row.MediableID] = append(mediaByModel[row.MediableID], row.Media)
+	}
+	return mediaByModel, nil
+}
+
+// postIDs collects the IDs of the given posts
+func postIDs(posts []models.Post) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	return ids
+}
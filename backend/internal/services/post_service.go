@@ -5,8 +5,6 @@ import (
 	"go-next/internal/models"
 	"go-next/pkg/database"
 	"go-next/pkg/redis"
-
-	"gorm.io/gorm"
 )
 
 type PostService interface {
@@ -32,38 +30,32 @@ func NewPostService(redisService *redis.RedisService) PostService {
 
 func (s *postService) GetAllPosts() ([]models.Post, error) {
 	var posts []models.Post
-	err := database.DB.Preload("User").Preload("Category").Find(&posts).Error
+	err := database.DB.Preload("Category").Find(&posts).Error
+	if err == nil {
+		err = attachAuthors(posts)
+	}
 	return posts, err
 }
 
 func (s *postService) GetPostByID(id string) (*models.Post, error) {
 	var post models.Post
-	err := database.DB.Preload("User").Preload("Category").First(&post, id).Error
+	err := database.DB.Preload("Category").First(&post, id).Error
+	if err == nil {
+		err = attachPostAuthors(&post)
+	}
 	return &post, err
 }
 
 func (s *postService) CreatePost(post *models.Post) error {
-	return database.DB.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(post).Error; err != nil {
-			return err
-		}
-		for i := range post.Contents {
-			post.Contents[i].ModelID = post.ID
-			post.Contents[i].ModelType = "post"
-			if err := tx.Create(&post.Contents[i]).Error; err != nil {
-				return err
-			}
-		}
-		return nil
-	})
+	return createPost(database.DB, post)
 }
 
 func (s *postService) UpdatePost(post *models.Post) error {
-	return database.DB.Save(post).Error
+	return updatePost(database.DB, post)
 }
 
 func (s *postService) DeletePost(id string) error {
-	return database.DB.Delete(&models.Post{}, id).Error
+	return deletePost(database.DB, id)
 }
 
 func (s *postService) GetPublishedPosts(ctx context.Context) ([]*models.Post, error) {
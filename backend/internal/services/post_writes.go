@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+
+	"go-next/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// preparePost normalizes what an editor submitted before it is stored
+func preparePost(post *models.Post) error {
+	locale, err := normalizeLocale(post.Locale)
+	if err != nil {
+		return err
+	}
+	post.Locale = locale
+	sanitizePost(post)
+	return normalizePostBlocks(post)
+}
+
+// createPost stores a new post with its blocks and brings search, mentions,
+// subscriptions and the sitemap up to date
+func createPost(db *gorm.DB, post *models.Post) error {
+	if err := preparePost(post); err != nil {
+		return err
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Contents").Create(post).Error; err != nil {
+			return err
+		}
+		for i := range post.Contents {
+			post.Contents[i].ModelID = post.ID
+			post.Contents[i].ModelType = models.MediableTypePost
+			if err := tx.Create(&post.Contents[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	afterPostSaved(post.ID)
+	subscribePostAuthors(post)
+	return nil
+}
+
+// updatePost saves an edited post, recording a changed slug for redirects.
+// The reaction count maintained by reconciliation is left as stored.
+func updatePost(db *gorm.DB, post *models.Post) error {
+	if err := preparePost(post); err != nil {
+		return err
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := RedirectSvc.RecordSlugChange(tx, models.MediableTypePost, post.ID, post.Slug); err != nil {
+			return err
+		}
+		return tx.Omit(reactionCountColumn).Save(post).Error
+	}); err != nil {
+		return err
+	}
+	afterPostSaved(post.ID)
+	return nil
+}
+
+// deletePost soft-deletes a post and drops it from search and the sitemap
+func deletePost(db *gorm.DB, id string) error {
+	postID, err := uuid.Parse(id)
+	if err != nil {
+		return errors.New("invalid post ID")
+	}
+	if err := db.Delete(&models.Post{}, postID).Error; err != nil {
+		return err
+	}
+	syncPostIndex(postID)
+	SitemapSvc.MarkDirty()
+	return nil
+}
+
+// afterPostSaved refreshes what is derived from a stored post
+func afterPostSaved(postID uuid.UUID) {
+	syncPostIndex(postID)
+	syncPostMentions(postID)
+	SitemapSvc.MarkDirty()
+}
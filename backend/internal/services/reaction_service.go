@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/cronjob"
+	"go-next/pkg/database"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// reactionReconcileInterval is how often changed counters are recomputed from the reactions
+	reactionReconcileInterval = time.Minute
+
+	// reactionCountsTTL lets counters of content nobody reads expire
+	reactionCountsTTL = 24 * time.Hour
+
+	// reactionCountColumn is only written by reaction reconciliation; updates omit it
+	reactionCountColumn = "reaction_count"
+)
+
+// Redis keys of the reaction counters
+const (
+	// reactionKeyCounts holds the counts of one post or comment by reaction type
+	reactionKeyCounts = "reactions:counts:%s:%s"
+	// reactionKeyDirty lists the "type:id" of content whose reactions changed since the last reconciliation
+	reactionKeyDirty = "reactions:dirty"
+	// reactionLoadedField marks counters loaded from the database, so content without reactions is cached too
+	reactionLoadedField = "_loaded"
+)
+
+// defaultReactionEmojis are offered when REACTION_EMOJIS is not set
+var defaultReactionEmojis = []string{"heart", "laugh", "wow", "sad", "party"}
+
+var reactionTypePattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+type ReactionService interface {
+	Types() []string
+	React(userID uuid.UUID, reactableType string, reactableID uuid.UUID, reactionType string) (*models.ReactionSummary, error)
+	Unreact(userID uuid.UUID, reactableType string, reactableID uuid.UUID, reactionType string) (*models.ReactionSummary, error)
+	GetSummaries(reactableType string, ids []uuid.UUID, userID *uuid.UUID) (map[uuid.UUID]*models.ReactionSummary, error)
+	Reconcile(ctx context.Context) (int, error)
+	Schedule() error
+}
+
+type reactionService struct{}
+
+func NewReactionService() ReactionService {
+	return &reactionService{}
+}
+
+// Types lists the reaction types users can react with: like, then the configured emoji
+func (s *reactionService) Types() []string {
+	emojis := config.GetConfig().Reactions.Emojis
+	if len(emojis) == 0 {
+		emojis = defaultReactionEmojis
+	}
+	return NormalizeReactionTypes(emojis)
+}
+
+// NormalizeReactionTypes puts like first and drops duplicate and malformed
+// emoji names; names are 1 to 32 lower-case letters, digits and underscores
+func NormalizeReactionTypes(emojis []string) []string {
+	types := []string{models.ReactionLike}
+	seen := map[string]bool{models.ReactionLike: true}
+	for _, emoji := range emojis {
+		emoji = strings.ToLower(strings.TrimSpace(emoji))
+		if seen[emoji] || !reactionTypePattern.MatchString(emoji) {
+			continue
+		}
+		seen[emoji] = true
+		types = append(types, emoji)
+	}
+	return types
+}
+
+// React adds a reaction to a published post or an approved comment. Reacting
+// twice with the same type keeps the first reaction.
+func (s *reactionService) React(userID uuid.UUID, reactableType string, reactableID uuid.UUID, reactionType string) (*models.ReactionSummary, error) {
+	if err := s.validate(reactableType, reactableID, reactionType); err != nil {
+		return nil, err
+	}
+
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Reaction{
+		UserID:        userID,
+		ReactableID:   reactableID,
+		ReactableType: reactableType,
+		Type:          reactionType,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		countReaction(reactableType, reactableID, reactionType, 1)
+		if reactableType == models.ReactableTypePost {
+			recordTrending(reactableID, TrendingEventReaction)
+		}
+	}
+	return s.summary(reactableType, reactableID, userID)
+}
+
+// Unreact removes a reaction; removing one that does not exist is not an error
+func (s *reactionService) Unreact(userID uuid.UUID, reactableType string, reactableID uuid.UUID, reactionType string) (*models.ReactionSummary, error) {
+	if err := s.validate(reactableType, reactableID, reactionType); err != nil {
+		return nil, err
+	}
+
+	result := database.DB.Unscoped().
+		Where("reactable_type = ? AND reactable_id = ? AND user_id = ? AND type = ?", reactableType, reactableID, userID, reactionType).
+		Delete(&models.Reaction{})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		countReaction(reactableType, reactableID, reactionType, -1)
+	}
+	return s.summary(reactableType, reactableID, userID)
+}
+
+// GetSummaries counts the reactions to posts or comments and, when userID is
+// set, which types that user reacted with. Counts come from the Redis counters,
+// which are loaded from the database on a miss.
+func (s *reactionService) GetSummaries(reactableType string, ids []uuid.UUID, userID *uuid.UUID) (map[uuid.UUID]*models.ReactionSummary, error) {
+	summaries := make(map[uuid.UUID]*models.ReactionSummary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+	ids = uniqueUUIDs(ids)
+	for _, id := range ids {
+		summaries[id] = &models.ReactionSummary{Counts: map[string]int64{}, Reacted: []string{}}
+	}
+
+	missing := ids
+	if GlobalRedisClient != nil {
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = fmt.Sprintf(reactionKeyCounts, reactableType, id)
+		}
+		hashes, err := GlobalRedisClient.HGetAllMany(context.Background(), keys...)
+		if err != nil {
+			hashes = make([]map[string]string, len(ids))
+		}
+		missing = nil
+		for i, id := range ids {
+			values := hashes[i]
+			if values[reactionLoadedField] == "" {
+				missing = append(missing, id)
+				continue
+			}
+			for reactionType, value := range values {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if reactionType == reactionLoadedField || err != nil || n <= 0 {
+					continue
+				}
+				summaries[id].Counts[reactionType] = n
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		counts, err := loadReactionCounts(database.DB, reactableType, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range missing {
+			summaries[id].Counts = counts[id]
+			cacheReactionCounts(context.Background(), reactableType, id, counts[id])
+		}
+	}
+
+	for _, summary := range summaries {
+		for _, n := range summary.Counts {
+			summary.Total += n
+		}
+	}
+
+	if userID != nil {
+		var reactions []models.Reaction
+		if err := database.DB.Select("reactable_id, type").
+			Where("reactable_type = ? AND reactable_id IN ? AND user_id = ?", reactableType, ids, *userID).
+			Order("created_at ASC").
+			Find(&reactions).Error; err != nil {
+			return nil, err
+		}
+		for _, reaction := range reactions {
+			summaries[reaction.ReactableID].Reacted = append(summaries[reaction.ReactableID].Reacted, reaction.Type)
+		}
+	}
+	return summaries, nil
+}
+
+// Reconcile recomputes the counters of content whose reactions changed from
+// the reactions table, and stores the totals in reaction_count. The dirty set
+// is claimed and cleared in one atomic step, so changes made during a run wait
+// for the next one; a failed run puts its claim back to be retried.
+func (s *reactionService) Reconcile(ctx context.Context) (int, error) {
+	if GlobalRedisClient == nil {
+		return 0, nil
+	}
+
+	claimed, err := GlobalRedisClient.HGetAllDel(ctx, reactionKeyDirty)
+	if err != nil {
+		return 0, err
+	}
+	fields := claimed[0]
+	if len(fields) == 0 {
+		return 0, nil
+	}
+	reconciled, err := reconcileReactions(ctx, fields)
+	if err != nil {
+		restoreReactionMarks(fields)
+		return 0, err
+	}
+	return reconciled, nil
+}
+
+// reconcileReactions recomputes the counters of the claimed "type:id" fields
+func reconcileReactions(ctx context.Context, fields map[string]string) (int, error) {
+	targets := make(map[string][]uuid.UUID)
+	for field := range fields {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		id, err := uuid.Parse(parts[1])
+		if err != nil {
+			continue
+		}
+		targets[parts[0]] = append(targets[parts[0]], id)
+	}
+
+	reconciled := 0
+	for reactableType, ids := range targets {
+		counts, err := loadReactionCounts(database.DB.WithContext(ctx), reactableType, ids)
+		if err != nil {
+			return 0, err
+		}
+		if err := storeReactionCounts(ctx, reactableType, counts); err != nil {
+			return 0, err
+		}
+		for _, id := range ids {
+			if err := GlobalRedisClient.Del(ctx, fmt.Sprintf(reactionKeyCounts, reactableType, id)); err != nil {
+				return 0, err
+			}
+			cacheReactionCounts(ctx, reactableType, id, counts[id])
+		}
+		reconciled += len(ids)
+	}
+	return reconciled, nil
+}
+
+// restoreReactionMarks puts claimed marks back into the dirty set after a
+// failed reconciliation; marks that cannot be restored are logged as lost
+func restoreReactionMarks(fields map[string]string) {
+	ctx := context.Background()
+	for field := range fields {
+		if _, err := GlobalRedisClient.HIncrBy(ctx, reactionKeyDirty, field, 1); err != nil {
+			log.Printf("Warning: lost the reconciliation mark of %s: %v", field, err)
+		}
+	}
+}
+
+// Schedule registers the job that reconciles the reaction counters
+func (s *reactionService) Schedule() error {
+	_, err := cronjob.AddJob(gocron.DurationJob(reactionReconcileInterval), func() {
+		if _, err := s.Reconcile(context.Background()); err != nil {
+			log.Printf("Warning: failed to reconcile reaction counters: %v", err)
+		}
+	}, gocron.WithName("reactions_reconcile"))
+	return err
+}
+
+// validate checks the reaction type, and that readers can see the content
+func (s *reactionService) validate(reactableType string, reactableID uuid.UUID, reactionType string) error {
+	known := false
+	for _, t := range s.Types() {
+		known = known || t == reactionType
+	}
+	if !known {
+		return fmt.Errorf("%w: unknown reaction type %q", ErrInvalidReaction, reactionType)
+	}
+
+	switch reactableType {
+	case models.ReactableTypePost:
+		var post models.Post
+		if err := database.DB.Select("id, status, published_at").First(&post, "id = ?", reactableID).Error; err != nil {
+			return err
+		}
+		if !post.IsPublished() {
+			return gorm.ErrRecordNotFound
+		}
+	case models.ReactableTypeComment:
+		var comment models.Comment
+		if err := database.DB.Select("id, status, is_public, hidden_at").First(&comment, "id = ?", reactableID).Error; err != nil {
+			return err
+		}
+		if !comment.IsVisible() {
+			return gorm.ErrRecordNotFound
+		}
+	default:
+		return fmt.Errorf("%w: unknown reactable type %q", ErrInvalidReaction, reactableType)
+	}
+	return nil
+}
+
+func (s *reactionService) summary(reactableType string, reactableID, userID uuid.UUID) (*models.ReactionSummary, error) {
+	summaries, err := s.GetSummaries(reactableType, []uuid.UUID{reactableID}, &userID)
+	if err != nil {
+		return nil, err
+	}
+	return summaries[reactableID], nil
+}
+
+// countReaction applies a reaction change to the counters right away and marks
+// the content for reconciliation. Counters that are not loaded are left alone;
+// the next read loads them. Without Redis, reaction_count is updated directly.
+func countReaction(reactableType string, reactableID uuid.UUID, reactionType string, delta int64) {
+	if GlobalRedisClient == nil {
+		counts, err := loadReactionCounts(database.DB, reactableType, []uuid.UUID{reactableID})
+		if err == nil {
+			err = storeReactionCounts(context.Background(), reactableType, counts)
+		}
+		if err != nil {
+			log.Printf("Warning: failed to update reaction count of %s %s: %v", reactableType, reactableID, err)
+		}
+		return
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf(reactionKeyCounts, reactableType, reactableID)
+	if _, err := GlobalRedisClient.HIncrByIfExists(ctx, key, reactionType, delta); err != nil {
+		log.Printf("Warning: failed to count reaction to %s %s: %v", reactableType, reactableID, err)
+	}
+	if _, err := GlobalRedisClient.HIncrBy(ctx, reactionKeyDirty, reactableType+":"+reactableID.String(), 1); err != nil {
+		log.Printf("Warning: failed to mark reactions to %s %s for reconciliation: %v", reactableType, reactableID, err)
+	}
+}
+
+// loadReactionCounts counts the reactions to each of ids by type
+func loadReactionCounts(db *gorm.DB, reactableType string, ids []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	var rows []struct {
+		ReactableID uuid.UUID
+		Type        string
+		Count       int64
+	}
+	if err := db.Model(&models.Reaction{}).
+		Select("reactable_id, type, COUNT(*) AS count").
+		Where("reactable_type = ? AND reactable_id IN ?", reactableType, ids).
+		Group("reactable_id, type").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uuid.UUID]map[string]int64, len(ids))
+	for _, id := range ids {
+		counts[id] = map[string]int64{}
+	}
+	for _, row := range rows {
+		counts[row.ReactableID][row.Type] = row.Count
+	}
+	return counts, nil
+}
+
+// storeReactionCounts writes the totals to reaction_count. A comment's total
+// ranks its discussion, so the post's cached discussion is dropped.
+func storeReactionCounts(ctx context.Context, reactableType string, counts map[uuid.UUID]map[string]int64) error {
+	var model interface{}
+	switch reactableType {
+	case models.ReactableTypePost:
+		model = &models.Post{}
+	case models.ReactableTypeComment:
+		model = &models.Comment{}
+	default:
+		return fmt.Errorf("%w: unknown reactable type %q", ErrInvalidReaction, reactableType)
+	}
+
+	ids := make([]uuid.UUID, 0, len(counts))
+	for id, byType := range counts {
+		var total int64
+		for _, n := range byType {
+			total += n
+		}
+		if err := database.DB.WithContext(ctx).Model(model).Where("id = ?", id).
+			UpdateColumn(reactionCountColumn, total).Error; err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	if reactableType == models.ReactableTypeComment {
+		var postIDs []uuid.UUID
+		if err := database.DB.WithContext(ctx).Model(&models.Comment{}).Where("id IN ?", ids).
+			Distinct().Pluck("post_id", &postIDs).Error; err != nil {
+			return err
+		}
+		for _, postID := range postIDs {
+			invalidateCommentThread(postID)
+		}
+	}
+	return nil
+}
+
+// cacheReactionCounts stores counters loaded from the database in Redis
+func cacheReactionCounts(ctx context.Context, reactableType string, id uuid.UUID, counts map[string]int64) {
+	if GlobalRedisClient == nil {
+		return
+	}
+	key := fmt.Sprintf(reactionKeyCounts, reactableType, id)
+	values := map[string]interface{}{reactionLoadedField: 1}
+	for reactionType, n := range counts {
+		values[reactionType] = n
+	}
+	if err := GlobalRedisClient.HSet(ctx, key, values); err != nil {
+		log.Printf("Warning: failed to cache reaction counts of %s %s: %v", reactableType, id, err)
+		return
+	}
+	_ = GlobalRedisClient.Expire(ctx, key, reactionCountsTTL)
+}
+
+var ReactionSvc ReactionService = NewReactionService()
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// redirectMaxHops bounds how far a chain of rules is followed when looking for loops
+const redirectMaxHops = 10
+
+var ErrInvalidRedirect = errors.New("invalid redirect")
+
+// SlugRedirectError is returned by slug lookups when the slug used to belong to a record
+// that now lives under another slug
+type SlugRedirectError struct {
+	Type       string
+	Slug       string
+	StatusCode int
+}
+
+func (e *SlugRedirectError) Error() string {
+	return fmt.Sprintf("%s moved to slug %q", e.Type, e.Slug)
+}
+
+// Unwrap lets callers that do not handle redirects treat the slug as not found
+func (e *SlugRedirectError) Unwrap() error {
+	return gorm.ErrRecordNotFound
+}
+
+// RedirectResolution is where a public path should send the visitor
+type RedirectResolution struct {
+	Location   string `json:"location"`
+	StatusCode int    `json:"status_code"`
+	Source     string `json:"source"`
+}
+
+type RedirectService interface {
+	RecordSlugChange(tx *gorm.DB, sluggableType string, id uuid.UUID, newSlug string) error
+	ResolveSlug(sluggableType, slug string) (string, error)
+	Resolve(path string) (*RedirectResolution, error)
+	GetRedirects(page, perPage int, search string) ([]models.Redirect, int64, error)
+	GetRedirect(id uuid.UUID) (*models.Redirect, error)
+	CreateRedirect(redirect *models.Redirect) error
+	UpdateRedirect(redirect *models.Redirect) error
+	DeleteRedirect(id uuid.UUID) error
+}
+
+type redirectService struct{}
+
+func NewRedirectService() RedirectService {
+	return &redirectService{}
+}
+
+// RecordSlugChange keeps the current slug of a record in its history before it is
+// replaced. A slug taken back by its record is removed from the history so it
+// cannot redirect to itself.
+func (s *redirectService) RecordSlugChange(tx *gorm.DB, sluggableType string, id uuid.UUID, newSlug string) error {
+	if id == uuid.Nil || newSlug == "" {
+		return nil
+	}
+
+	var table string
+	switch sluggableType {
+	case models.MediableTypePost:
+		table = models.Post{}.TableName()
+	case models.MediableTypeCategory:
+		table = models.Category{}.TableName()
+	default:
+		return fmt.Errorf("unknown sluggable type %q", sluggableType)
+	}
+
+	var current []string
+	if err := tx.Table(table).Where("id = ?", id).Pluck("slug", &current).Error; err != nil {
+		return err
+	}
+	if len(current) == 0 || current[0] == newSlug {
+		return nil
+	}
+
+	if err := tx.Where("sluggable_type = ? AND slug = ?", sluggableType, newSlug).
+		Delete(&models.SlugHistory{}).Error; err != nil {
+		return err
+	}
+
+	// Another record may have owned the old slug before; the latest owner wins
+	if err := tx.Where("sluggable_type = ? AND slug = ?", sluggableType, current[0]).
+		Delete(&models.SlugHistory{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.SlugHistory{SluggableID: id, SluggableType: sluggableType, Slug: current[0]}).Error
+}
+
+// ResolveSlug returns the current public slug of the record that used to have slug
+func (s *redirectService) ResolveSlug(sluggableType, slug string) (string, error) {
+	var history models.SlugHistory
+	if err := database.DB.Where("sluggable_type = ? AND slug = ?", sluggableType, slug).First(&history).Error; err != nil {
+		return "", err
+	}
+
+	var current []string
+	query := database.DB.Where("id = ?", history.SluggableID)
+	switch sluggableType {
+	case models.MediableTypePost:
+		query = query.Model(&models.Post{}).Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true)
+	case models.MediableTypeCategory:
+		query = query.Model(&models.Category{}).Where("is_active = ?", true)
+	default:
+		return "", gorm.ErrRecordNotFound
+	}
+	if err := query.Pluck("slug", &current).Error; err != nil {
+		return "", err
+	}
+	if len(current) == 0 || current[0] == slug {
+		return "", gorm.ErrRecordNotFound
+	}
+	return current[0], nil
+}
+
+// Resolve finds the redirect for a public site path: an active custom rule first,
+// then the slug history of posts and categories
+func (s *redirectService) Resolve(path string) (*RedirectResolution, error) {
+	path = models.NormalizeRedirectPath(path)
+
+	var rule models.Redirect
+	err := database.DB.Where("source_path = ? AND is_active = ?", path, true).First(&rule).Error
+	if err == nil {
+		now := time.Now()
+		if err := database.DB.Model(&models.Redirect{}).Where("id = ?", rule.ID).
+			UpdateColumns(map[string]interface{}{"hit_count": gorm.Expr("hit_count + 1"), "last_hit_at": now}).Error; err != nil {
+			log.Printf("Warning: failed to count hit of redirect %s: %v", rule.ID, err)
+		}
+		return &RedirectResolution{Location: rule.TargetURL, StatusCode: rule.StatusCode, Source: "rule"}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	site := config.GetConfig().Site
+	if slug, ok := strings.CutPrefix(path, "/blog/category/"); ok && !strings.Contains(slug, "/") {
+		current, err := s.ResolveSlug(models.MediableTypeCategory, slug)
+		if err != nil {
+			return nil, err
+		}
+		return &RedirectResolution{Location: site.CategoryURL(current), StatusCode: 301, Source: "slug_history"}, nil
+	}
+	if slug, ok := strings.CutPrefix(path, "/blog/"); ok && !strings.Contains(slug, "/") {
+		current, err := s.ResolveSlug(models.MediableTypePost, slug)
+		if err != nil {
+			return nil, err
+		}
+		return &RedirectResolution{Location: site.PostURL(current), StatusCode: 301, Source: "slug_history"}, nil
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// GetRedirects lists redirect rules, optionally filtered by source or target
+func (s *redirectService) GetRedirects(page, perPage int, search string) ([]models.Redirect, int64, error) {
+	var redirects []models.Redirect
+	var total int64
+
+	query := database.DB.Model(&models.Redirect{})
+	if search != "" {
+		query = query.Where("source_path ILIKE ? OR target_url ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("source_path ASC").Offset(offset).Limit(perPage).Find(&redirects).Error
+	return redirects, total, err
+}
+
+// GetRedirect retrieves a redirect rule by ID
+func (s *redirectService) GetRedirect(id uuid.UUID) (*models.Redirect, error) {
+	var redirect models.Redirect
+	if err := database.DB.First(&redirect, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &redirect, nil
+}
+
+// CreateRedirect validates and stores a redirect rule
+func (s *redirectService) CreateRedirect(redirect *models.Redirect) error {
+	if err := s.validate(redirect); err != nil {
+		return err
+	}
+	return database.DB.Create(redirect).Error
+}
+
+// UpdateRedirect validates and saves a redirect rule
+func (s *redirectService) UpdateRedirect(redirect *models.Redirect) error {
+	if err := s.validate(redirect); err != nil {
+		return err
+	}
+	return database.DB.Save(redirect).Error
+}
+
+// DeleteRedirect permanently deletes a redirect rule so its source path can be reused
+func (s *redirectService) DeleteRedirect(id uuid.UUID) error {
+	result := database.DB.Unscoped().Delete(&models.Redirect{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// validate normalizes a rule and rejects unknown statuses, duplicates and loops
+func (s *redirectService) validate(redirect *models.Redirect) error {
+	redirect.SourcePath = models.NormalizeRedirectPath(redirect.SourcePath)
+	redirect.TargetURL = strings.TrimSpace(redirect.TargetURL)
+	if redirect.StatusCode == 0 {
+		redirect.StatusCode = 301
+	}
+	if !slices.Contains(models.RedirectStatusCodes, redirect.StatusCode) {
+		return fmt.Errorf("%w: unsupported status code %d", ErrInvalidRedirect, redirect.StatusCode)
+	}
+	if redirect.TargetURL == "" {
+		return fmt.Errorf("%w: target is required", ErrInvalidRedirect)
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.Redirect{}).
+		Where("source_path = ? AND id <> ?", redirect.SourcePath, redirect.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: a rule for %s already exists", ErrInvalidRedirect, redirect.SourcePath)
+	}
+
+	// Follow the chain of rules starting at the target; reaching the source is a loop
+	next := redirect.TargetURL
+	for hop := 0; hop < redirectMaxHops; hop++ {
+		if !isSitePath(next) {
+			return nil
+		}
+		path := models.NormalizeRedirectPath(next)
+		if path == redirect.SourcePath {
+			return fmt.Errorf("%w: %s redirects back to itself", ErrInvalidRedirect, redirect.SourcePath)
+		}
+		var rule models.Redirect
+		err := database.DB.Where("source_path = ? AND is_active = ? AND id <> ?", path, true, redirect.ID).First(&rule).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		next = rule.TargetURL
+	}
+	return fmt.Errorf("%w: redirect chain from %s is too long", ErrInvalidRedirect, redirect.SourcePath)
+}
+
+// isSitePath reports whether a redirect target stays on this site
+func isSitePath(target string) bool {
+	if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") {
+		return true
+	}
+	site := strings.TrimRight(config.GetConfig().Site.URL, "/")
+	return site != "" && (target == site || strings.HasPrefix(target, site+"/"))
+}
+
+// slugRedirect turns a missing slug into a SlugRedirectError when the slug has moved
+func slugRedirect(sluggableType, slug string, err error) error {
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	current, resolveErr := RedirectSvc.ResolveSlug(sluggableType, slug)
+	if resolveErr != nil {
+		return err
+	}
+	return &SlugRedirectError{Type: sluggableType, Slug: current, StatusCode: 301}
+}
+
+var RedirectSvc RedirectService = NewRedirectService()
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/cronjob"
+	"go-next/pkg/database"
+	"go-next/pkg/search"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+)
+
+// Weights of the related-post score components; each component is in [0, 1]
+const (
+	relatedWeightTags     = 0.5
+	relatedWeightCategory = 0.2
+	relatedWeightText     = 0.3
+
+	// relatedPostsPerPost is how many ranked posts are kept for each post
+	relatedPostsPerPost = 12
+
+	// relatedPostsCandidates bounds how many posts are scored against each
+	// post, so a run costs O(N) comparisons rather than one per pair
+	relatedPostsCandidates = 100
+
+	// relatedPostsInterval is how often the rankings are recomputed
+	relatedPostsInterval = time.Hour
+
+	// relatedPostsLockTTL bounds how long a stalled run keeps other instances out
+	relatedPostsLockTTL = 30 * time.Minute
+)
+
+type RelatedPostService interface {
+	GetRelated(postID uuid.UUID) ([]RelatedPostScore, error)
+	Precompute(ctx context.Context) (int, error)
+	Schedule() error
+}
+
+// RelatedPostScore is a precomputed relation between two posts
+type RelatedPostScore struct {
+	PostID     uuid.UUID `json:"post_id"`
+	Score      float64   `json:"score"`
+	SharedTags int       `json:"shared_tags"`
+	Category   float64   `json:"category"`
+	Text       float64   `json:"text"`
+}
+
+type relatedPostService struct{}
+
+func NewRelatedPostService() RelatedPostService {
+	return &relatedPostService{}
+}
+
+// GetRelated returns the cached ranking of a post, best match first
+func (s *relatedPostService) GetRelated(postID uuid.UUID) ([]RelatedPostScore, error) {
+	var scores []RelatedPostScore
+	if err := CacheSvc.Get(fmt.Sprintf(CacheKeyRelatedPosts, postID), &scores); err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// Schedule registers the background job that keeps the rankings warm. One
+// instance at a time computes them; the others read the shared cache.
+func (s *relatedPostService) Schedule() error {
+	run := func() {
+		count := 0
+		ran, err := runExclusive(context.Background(), "related_posts", relatedPostsLockTTL, func() error {
+			var err error
+			count, err = s.Precompute(context.Background())
+			return err
+		})
+		if err != nil {
+			log.Printf("Warning: failed to precompute related posts: %v", err)
+			return
+		}
+		if ran {
+			log.Printf("Precomputed related posts for %d posts", count)
+		}
+	}
+
+	if _, err := cronjob.AddJob(gocron.DurationJob(relatedPostsInterval), run, gocron.WithName("related_posts")); err != nil {
+		return err
+	}
+	go run()
+	return nil
+}
+
+// Precompute scores each published post against its candidates, the posts
+// sharing its tags or category, and caches the best matches per post
+func (s *relatedPostService) Precompute(ctx context.Context) (int, error) {
+	db := database.DB.WithContext(ctx)
+
+	var posts []models.Post
+	if err := db.Select("id, title, excerpt, content, category_id").
+		Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true).
+		Find(&posts).Error; err != nil {
+		return 0, err
+	}
+	if len(posts) < 2 {
+		return 0, nil
+	}
+
+	var tagRows []struct {
+		PostID uuid.UUID
+		TagID  uint64
+	}
+	if err := db.Table("post_tags").Select("post_id, tag_id").Scan(&tagRows).Error; err != nil {
+		return 0, err
+	}
+	published := make(map[uuid.UUID]bool, len(posts))
+	for _, post := range posts {
+		published[post.ID] = true
+	}
+	tagsByPost := make(map[uuid.UUID]map[uint64]struct{})
+	postsByTag := make(map[uint64][]uuid.UUID)
+	for _, row := range tagRows {
+		if !published[row.PostID] {
+			continue
+		}
+		if tagsByPost[row.PostID] == nil {
+			tagsByPost[row.PostID] = make(map[uint64]struct{})
+		}
+		tagsByPost[row.PostID][row.TagID] = struct{}{}
+		postsByTag[row.TagID] = append(postsByTag[row.TagID], row.PostID)
+	}
+
+	var categories []models.Category
+	if err := db.Select("id, record_left, record_right").Find(&categories).Error; err != nil {
+		return 0, err
+	}
+	tree := newCategoryTree(categories)
+	categoryByPost := make(map[uuid.UUID]*uuid.UUID, len(posts))
+	postsByCategory := make(map[uuid.UUID][]uuid.UUID)
+	for _, post := range posts {
+		categoryByPost[post.ID] = post.CategoryID
+		if post.CategoryID != nil {
+			postsByCategory[*post.CategoryID] = append(postsByCategory[*post.CategoryID], post.ID)
+		}
+	}
+
+	// Titles are repeated so they weigh more than body text
+	texts := make(map[string]string, len(posts))
+	for _, post := range posts {
+		texts[post.ID.String()] = post.Title + " " + post.Title + " " + post.Excerpt + " " + stripTags(post.Content)
+	}
+	vectors := search.BuildVectors(texts)
+
+	count := 0
+	for _, post := range posts {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		candidates := relatedCandidates(&post, tagsByPost[post.ID], postsByTag, postsByCategory, tree)
+		scores := make([]RelatedPostScore, 0, len(candidates))
+		for _, otherID := range candidates {
+			shared, tagScore := tagOverlap(tagsByPost[post.ID], tagsByPost[otherID])
+			categoryScore := tree.proximity(post.CategoryID, categoryByPost[otherID])
+			textScore := search.Cosine(vectors[post.ID.String()], vectors[otherID.String()])
+
+			score := relatedWeightTags*tagScore + relatedWeightCategory*categoryScore + relatedWeightText*textScore
+			if score <= 0 {
+				continue
+			}
+			scores = append(scores, RelatedPostScore{
+				PostID:     otherID,
+				Score:      score,
+				SharedTags: shared,
+				Category:   categoryScore,
+				Text:       textScore,
+			})
+		}
+
+		sort.Slice(scores, func(i, j int) bool { return scores[i].Score > scores[j].Score })
+		if len(scores) > relatedPostsPerPost {
+			scores = scores[:relatedPostsPerPost]
+		}
+
+		// Entries outlive a few missed runs so a failing job does not empty the cache
+		if err := CacheSvc.Set(fmt.Sprintf(CacheKeyRelatedPosts, post.ID), scores, CacheDurationDay); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// relatedCandidates picks up to relatedPostsCandidates posts to score against
+// a post: those sharing the most tags first, then those in its category, then
+// those in its sibling categories
+func relatedCandidates(post *models.Post, tags map[uint64]struct{}, postsByTag map[uint64][]uuid.UUID, postsByCategory map[uuid.UUID][]uuid.UUID, tree *categoryTree) []uuid.UUID {
+	shared := make(map[uuid.UUID]int)
+	for tag := range tags {
+		for _, id := range postsByTag[tag] {
+			shared[id]++
+		}
+	}
+	ordered := make([]uuid.UUID, 0, len(shared))
+	for id := range shared {
+		ordered = append(ordered, id)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if shared[ordered[i]] != shared[ordered[j]] {
+			return shared[ordered[i]] > shared[ordered[j]]
+		}
+		return ordered[i].String() < ordered[j].String()
+	})
+	if post.CategoryID != nil {
+		ordered = append(ordered, postsByCategory[*post.CategoryID]...)
+		for _, sibling := range tree.siblings(*post.CategoryID) {
+			ordered = append(ordered, postsByCategory[sibling]...)
+		}
+	}
+
+	candidates := make([]uuid.UUID, 0, relatedPostsCandidates)
+	seen := map[uuid.UUID]bool{post.ID: true}
+	for _, id := range ordered {
+		if len(candidates) == relatedPostsCandidates {
+			break
+		}
+		if !seen[id] {
+			seen[id] = true
+			candidates = append(candidates, id)
+		}
+	}
+	return candidates
+}
+
+// tagOverlap returns the number of shared tags and their Jaccard similarity
+func tagOverlap(a, b map[uint64]struct{}) (int, float64) {
+	if len(a) == 0 || len(b) == 0 {
+		return 0, 0
+	}
+	shared := 0
+	for id := range a {
+		if _, ok := b[id]; ok {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	return shared, float64(shared) / float64(union)
+}
+
+// categoryTree answers proximity queries over the nested-set category tree
+type categoryTree struct {
+	// ancestors lists the ancestors of each placed category from the root
+	// down, the category itself last
+	ancestors map[uuid.UUID][]uuid.UUID
+	children  map[uuid.UUID][]uuid.UUID
+	cache     map[[2]uuid.UUID]float64
+}
+
+// newCategoryTree resolves every category's ancestors in one pass over the
+// categories in nested-set order. Categories outside the tree have none.
+func newCategoryTree(categories []models.Category) *categoryTree {
+	placed := make([]models.Category, 0, len(categories))
+	for _, category := range categories {
+		if category.RecordLeft > 0 {
+			placed = append(placed, category)
+		}
+	}
+	sort.Slice(placed, func(i, j int) bool { return placed[i].RecordLeft < placed[j].RecordLeft })
+
+	tree := &categoryTree{
+		ancestors: make(map[uuid.UUID][]uuid.UUID, len(placed)),
+		children:  make(map[uuid.UUID][]uuid.UUID),
+		cache:     make(map[[2]uuid.UUID]float64),
+	}
+	var open []models.Category
+	for _, category := range placed {
+		for len(open) > 0 && open[len(open)-1].RecordRight < category.RecordLeft {
+			open = open[:len(open)-1]
+		}
+		chain := make([]uuid.UUID, 0, len(open)+1)
+		for _, ancestor := range open {
+			chain = append(chain, ancestor.ID)
+		}
+		tree.ancestors[category.ID] = append(chain, category.ID)
+		if len(open) > 0 {
+			parent := open[len(open)-1].ID
+			tree.children[parent] = append(tree.children[parent], category.ID)
+		}
+		open = append(open, category)
+	}
+	return tree
+}
+
+// siblings returns the other children of a category's parent
+func (t *categoryTree) siblings(id uuid.UUID) []uuid.UUID {
+	chain := t.ancestors[id]
+	if len(chain) < 2 {
+		return nil
+	}
+	var siblings []uuid.UUID
+	for _, child := range t.children[chain[len(chain)-2]] {
+		if child != id {
+			siblings = append(siblings, child)
+		}
+	}
+	return siblings
+}
+
+// proximity is 1 for the same category and decays with the number of edges
+// between two categories through their lowest common ancestor
+func (t *categoryTree) proximity(a, b *uuid.UUID) float64 {
+	if a == nil || b == nil {
+		return 0
+	}
+	if *a == *b {
+		return 1
+	}
+
+	key := [2]uuid.UUID{*a, *b}
+	if score, ok := t.cache[key]; ok {
+		return score
+	}
+	score := t.distanceScore(*a, *b)
+	t.cache[key] = score
+	return score
+}
+
+func (t *categoryTree) distanceScore(a, b uuid.UUID) float64 {
+	chainA, chainB := t.ancestors[a], t.ancestors[b]
+
+	// The chains agree down to the lowest common ancestor
+	common := 0
+	for common < len(chainA) && common < len(chainB) && chainA[common] == chainB[common] {
+		common++
+	}
+	if common == 0 {
+		return 0
+	}
+
+	distance := (len(chainA) - common) + (len(chainB) - common)
+	return 1 / float64(1+distance)
+}
+
+var RelatedPostSvc RelatedPostService = NewRelatedPostService()
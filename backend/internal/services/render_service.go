@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/blocks"
+	"go-next/pkg/database"
+	"go-next/pkg/markdown"
+	"go-next/pkg/oembed"
+	"go-next/pkg/sanitize"
+
+	"gorm.io/gorm"
+)
+
+// rendererVersion is part of every cache key; bump it when rendering output changes
+const rendererVersion = "4"
+
+// renderEmbedTimeout bounds the oEmbed request of an uncached embed while a
+// page renders; an embed that misses it renders as a link until the next try
+const renderEmbedTimeout = 2 * time.Second
+
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+type RenderService interface {
+	Render(ctx context.Context, contentType, source string) (*markdown.Result, error)
+	RenderPost(ctx context.Context, slug string, locales []string) (*RenderedPost, error)
+}
+
+// RenderedPost is the HTML and plain text of a post's content blocks, in order, with the facts derived from them
+type RenderedPost struct {
+	PostID      string              `json:"post_id"`
+	HTML        string              `json:"html"`
+	Text        string              `json:"text"`
+	TOC         []markdown.TOCEntry `json:"toc"`
+	WordCount   int                 `json:"word_count"`
+	ReadingTime int                 `json:"reading_time"`
+}
+
+type renderService struct {
+	markdown *markdown.Renderer
+	oembed   OEmbedService
+}
+
+func NewRenderService() RenderService {
+	return &renderService{markdown: markdown.NewRenderer(), oembed: OEmbedSvc}
+}
+
+// Render converts content of a models.Content type to HTML, caching the result by content hash
+func (s *renderService) Render(ctx context.Context, contentType, source string) (*markdown.Result, error) {
+	sum := sha256.Sum256([]byte(rendererVersion + "\x00" + contentType + "\x00" + source))
+	key := fmt.Sprintf(CacheKeyRenderedContent, hex.EncodeToString(sum[:]))
+
+	var cached markdown.Result
+	if err := CacheSvc.Get(key, &cached); err == nil {
+		return &cached, nil
+	}
+
+	var result *markdown.Result
+	cacheable := true
+	switch contentType {
+	case "markdown":
+		var err error
+		if result, err = s.markdown.Render([]byte(source)); err != nil {
+			return nil, err
+		}
+		result.HTML = sanitize.Editor(result.HTML)
+	case "html":
+		text := html.UnescapeString(stripTags(source))
+		words := markdown.CountWords(text)
+		result = &markdown.Result{HTML: sanitize.Editor(source), Text: text, WordCount: words, ReadingTime: markdown.ReadingTime(words)}
+	case "text":
+		words := markdown.CountWords(source)
+		result = &markdown.Result{HTML: textToHTML(source), Text: strings.TrimSpace(source), WordCount: words, ReadingTime: markdown.ReadingTime(words)}
+	case "json":
+		block, err := blocks.Parse([]byte(source))
+		if err != nil {
+			return nil, err
+		}
+		result, cacheable = s.renderBlock(ctx, block)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	// Without Redis every render is computed; a failed cache write is not an error
+	if cacheable {
+		_ = CacheSvc.Set(key, result, CacheDurationWeek)
+	}
+	return result, nil
+}
+
+// RenderPost renders the content blocks of a public post, looking its slug up
+// within a locale chain. Posts without blocks render their Content field as HTML.
+func (s *renderService) RenderPost(ctx context.Context, slug string, locales []string) (*RenderedPost, error) {
+	var post models.Post
+	if err := database.DB.Where("slug = ? AND status = ? AND public = ? AND published_at IS NOT NULL", slug, "published", true).
+		Scopes(postsInLocales(locales)).
+		Preload("Contents", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC, created_at ASC")
+		}).
+		First(&post).Error; err != nil {
+		return nil, slugRedirect(models.MediableTypePost, slug, err)
+	}
+
+	contents := post.Contents
+	if len(contents) == 0 {
+		contents = []models.Content{{Type: "html", Content: post.Content}}
+	}
+
+	rendered := &RenderedPost{PostID: post.ID.String()}
+	var b strings.Builder
+	var text []string
+	var headings []markdown.Heading
+	for _, block := range contents {
+		result, err := s.Render(ctx, block.Type, block.Content)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(result.HTML)
+		if result.Text != "" {
+			text = append(text, result.Text)
+		}
+		headings = flattenTOC(headings, result.TOC)
+		rendered.WordCount += result.WordCount
+	}
+	rendered.HTML = b.String()
+	rendered.Text = strings.Join(text, "\n\n")
+	// Headings of separate blocks nest under each other, so the TOC is built over all of them
+	rendered.TOC = markdown.BuildTOC(headings)
+	if rendered.TOC == nil {
+		rendered.TOC = []markdown.TOCEntry{}
+	}
+	rendered.ReadingTime = markdown.ReadingTime(rendered.WordCount)
+
+	return rendered, nil
+}
+
+// renderBlock renders a structured block; code is left out of the word count
+// as it is for Markdown. Embeds are resolved through oEmbed within
+// renderEmbedTimeout, falling back to a link; a render whose provider failed
+// or timed out is not cacheable so it is retried.
+func (s *renderService) renderBlock(ctx context.Context, block *blocks.Block) (*markdown.Result, bool) {
+	cacheable := true
+	if embed, ok := block.Data.(*blocks.Embed); ok {
+		embedCtx, cancel := context.WithTimeout(ctx, renderEmbedTimeout)
+		response, err := s.oembed.Resolve(embedCtx, embed.URL, oembed.Options{})
+		cancel()
+		switch {
+		case err == nil:
+			embed.HTML = response.EmbedHTML()
+			if embed.Provider == "" {
+				embed.Provider = response.ProviderName
+			}
+		case errors.Is(err, oembed.ErrProviderFailed):
+			cacheable = false
+		}
+	}
+
+	result := &markdown.Result{HTML: block.HTML(), Text: block.Text()}
+	if block.Type != blocks.TypeCode {
+		result.WordCount = markdown.CountWords(result.Text)
+		result.ReadingTime = markdown.ReadingTime(result.WordCount)
+	}
+	if heading, ok := block.Data.(*blocks.Heading); ok {
+		result.TOC = markdown.BuildTOC([]markdown.Heading{{Level: heading.Level, Text: block.Text(), ID: heading.ID}})
+	}
+	return result, cacheable
+}
+
+// flattenTOC appends the headings of a table of contents in document order
+func flattenTOC(headings []markdown.Heading, toc []markdown.TOCEntry) []markdown.Heading {
+	for _, entry := range toc {
+		headings = append(headings, markdown.Heading{Level: entry.Level, Text: entry.Text, ID: entry.ID})
+		headings = flattenTOC(headings, entry.Children)
+	}
+	return headings
+}
+
+// textToHTML escapes plain text, turning blank-line separated blocks into paragraphs
+func textToHTML(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n")
+	if text == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(html.EscapeString(paragraph), "\n", "<br>\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+var RenderSvc RenderService = NewRenderService()
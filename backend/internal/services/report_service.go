@@ -0,0 +1,684 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actions a moderator can take when resolving the reports of a post or comment
+const (
+	ReportActionHide    = models.ModerationActionHide
+	ReportActionDelete  = models.ModerationActionDelete
+	ReportActionWarn    = models.ModerationActionWarn
+	ReportActionSuspend = models.ModerationActionSuspend
+)
+
+// reportActions lists the resolution actions in the order they are applied
+var reportActions = []string{ReportActionHide, ReportActionDelete, ReportActionWarn, ReportActionSuspend}
+
+// reportExcerptLength is how much of a reported comment the queue shows
+const reportExcerptLength = 200
+
+var (
+	ErrInvalidReport          = errors.New("invalid report")
+	ErrAlreadyReported        = errors.New("content already reported")
+	ErrInvalidReportAction    = errors.New("invalid report action")
+	ErrInvalidReportFilter    = errors.New("invalid report filter")
+	ErrInvalidModerationQuery = errors.New("invalid moderation log filter")
+)
+
+// ReportFilter selects the content of the report queue
+type ReportFilter struct {
+	// Status defaults to open
+	Status         string
+	ReportableType string
+}
+
+// ModerationLogFilter selects moderation log entries; empty fields match everything
+type ModerationLogFilter struct {
+	Action      string
+	TargetType  string
+	TargetID    *uuid.UUID
+	ModeratorID *uuid.UUID
+	// UserID matches the user an action was about
+	UserID *uuid.UUID
+}
+
+type ReportService interface {
+	// Reader reports
+	Report(reporterID uuid.UUID, reportableType string, reportableID uuid.UUID, reason, details string) (*models.Report, error)
+
+	// Moderator queue
+	GetQueue(filter ReportFilter, page, perPage int) ([]models.ReportedContent, int64, error)
+	Resolve(reportableType string, reportableID uuid.UUID, actions []string, note string, moderatorID *uuid.UUID) ([]models.Report, error)
+	Dismiss(reportableType string, reportableID uuid.UUID, note string, moderatorID *uuid.UUID) ([]models.Report, error)
+
+	// Moderation log
+	GetModerationLog(filter ModerationLogFilter, page, perPage int) ([]models.ModerationLog, int64, error)
+}
+
+type reportService struct{}
+
+func NewReportService() ReportService {
+	return &reportService{}
+}
+
+// reportTarget is the reported post or comment as moderation needs it
+type reportTarget struct {
+	Type     string
+	ID       uuid.UUID
+	AuthorID *uuid.UUID
+	// PostID is the post itself, or the post a comment belongs to
+	PostID   uuid.UUID
+	Excerpt  string
+	Visible  bool
+	Public   bool
+	HiddenAt *time.Time
+	Deleted  bool
+}
+
+// Report records a reader's report of a published post or an approved
+// comment, hiding the content once enough readers reported it
+func (s *reportService) Report(reporterID uuid.UUID, reportableType string, reportableID uuid.UUID, reason, details string) (*models.Report, error) {
+	if !IsReportReason(reason) {
+		return nil, fmt.Errorf("%w: unknown reason %q", ErrInvalidReport, reason)
+	}
+	target, err := loadReportTarget(database.DB, reportableType, reportableID)
+	if err != nil {
+		return nil, err
+	}
+	if !target.Visible || target.Deleted {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if target.AuthorID != nil && *target.AuthorID == reporterID {
+		return nil, fmt.Errorf("%w: you cannot report your own content", ErrInvalidReport)
+	}
+
+	report := &models.Report{
+		ReporterID:     reporterID,
+		ReportableID:   reportableID,
+		ReportableType: reportableType,
+		Reason:         reason,
+		Details:        details,
+		Status:         models.ReportStatusOpen,
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAlreadyReported
+	}
+
+	if err := autoHideReported(target); err != nil {
+		log.Printf("Warning: failed to hide reported %s %s: %v", reportableType, reportableID, err)
+	}
+	return report, nil
+}
+
+// IsReportReason reports whether reason is one of models.ReportReasons
+func IsReportReason(reason string) bool {
+	for _, r := range models.ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeReportActions checks resolution actions and puts them in the order
+// they are applied, dropping duplicates
+func NormalizeReportActions(actions []string) ([]string, error) {
+	given := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		known := false
+		for _, a := range reportActions {
+			known = known || a == action
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidReportAction, action)
+		}
+		given[action] = true
+	}
+	normalized := []string{}
+	for _, action := range reportActions {
+		if given[action] {
+			normalized = append(normalized, action)
+		}
+	}
+	return normalized, nil
+}
+
+// GetQueue lists reported content with its reports, most reported first
+func (s *reportService) GetQueue(filter ReportFilter, page, perPage int) ([]models.ReportedContent, int64, error) {
+	status := filter.Status
+	switch status {
+	case "":
+		status = models.ReportStatusOpen
+	case models.ReportStatusOpen, models.ReportStatusResolved, models.ReportStatusDismissed:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown status %q", ErrInvalidReportFilter, filter.Status)
+	}
+
+	query := database.DB.Model(&models.Report{}).Where("status = ?", status)
+	switch filter.ReportableType {
+	case "":
+	case models.ReportableTypePost, models.ReportableTypeComment:
+		query = query.Where("reportable_type = ?", filter.ReportableType)
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown type %q", ErrInvalidReportFilter, filter.ReportableType)
+	}
+	groups := query.Select("reportable_type, reportable_id, COUNT(*) AS report_count, MIN(created_at) AS first_report_at, MAX(created_at) AS last_report_at").
+		Group("reportable_type, reportable_id")
+
+	var total int64
+	if err := database.DB.Table("(?) AS reported", groups).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []struct {
+		ReportableType string
+		ReportableID   uuid.UUID
+		ReportCount    int64
+		FirstReportAt  time.Time
+		LastReportAt   time.Time
+	}
+	offset := (page - 1) * perPage
+	if err := groups.Order("report_count DESC, first_report_at ASC").
+		Offset(offset).
+		Limit(perPage).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	queue := make([]models.ReportedContent, len(rows))
+	for i, row := range rows {
+		queue[i] = models.ReportedContent{
+			ReportableType: row.ReportableType,
+			ReportableID:   row.ReportableID,
+			ReportCount:    row.ReportCount,
+			FirstReportAt:  row.FirstReportAt,
+			LastReportAt:   row.LastReportAt,
+		}
+	}
+
+	idsByType := make(map[string][]uuid.UUID)
+	for _, item := range queue {
+		idsByType[item.ReportableType] = append(idsByType[item.ReportableType], item.ReportableID)
+	}
+	var reports []models.Report
+	targets := make(map[string]*reportTarget)
+	for reportableType, ids := range idsByType {
+		var batch []models.Report
+		if err := database.DB.Where("status = ? AND reportable_type = ? AND reportable_id IN ?", status, reportableType, ids).
+			Order("created_at ASC").
+			Find(&batch).Error; err != nil {
+			return nil, 0, err
+		}
+		reports = append(reports, batch...)
+
+		for _, id := range ids {
+			target, err := loadReportTarget(database.DB, reportableType, id)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+			targets[reportableType+":"+id.String()] = target
+		}
+	}
+
+	GroupReports(queue, reports)
+	for i := range queue {
+		target := targets[queue[i].ReportableType+":"+queue[i].ReportableID.String()]
+		if target == nil {
+			continue
+		}
+		queue[i].AuthorID = target.AuthorID
+		queue[i].Excerpt = target.Excerpt
+		queue[i].Hidden = target.HiddenAt != nil || !target.Public || target.Deleted
+	}
+	return queue, total, nil
+}
+
+// GroupReports fills in the reports and reason counts of each queue entry
+func GroupReports(queue []models.ReportedContent, reports []models.Report) {
+	index := make(map[string]int, len(queue))
+	for i := range queue {
+		queue[i].Reports = []models.Report{}
+		queue[i].Reasons = map[string]int64{}
+		index[queue[i].ReportableType+":"+queue[i].ReportableID.String()] = i
+	}
+	for _, report := range reports {
+		i, ok := index[report.ReportableType+":"+report.ReportableID.String()]
+		if !ok {
+			continue
+		}
+		queue[i].Reports = append(queue[i].Reports, report)
+		queue[i].Reasons[report.Reason]++
+	}
+}
+
+// Resolve confirms the open reports of a post or comment and applies the
+// moderator's actions: hide or delete the content, warn or suspend its
+// author. Content hidden by the reports stays hidden. Every action is logged.
+func (s *reportService) Resolve(reportableType string, reportableID uuid.UUID, actions []string, note string, moderatorID *uuid.UUID) ([]models.Report, error) {
+	actions, err := NormalizeReportActions(actions)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []models.Report
+	var target *reportTarget
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		reports, err = lockOpenReports(tx, reportableType, reportableID)
+		if err != nil {
+			return err
+		}
+		target, err = loadReportTarget(tx.Unscoped(), reportableType, reportableID)
+		if err != nil {
+			return err
+		}
+
+		for _, action := range actions {
+			entry := &models.ModerationLog{
+				ModeratorID:   moderatorID,
+				Action:        action,
+				TargetType:    target.Type,
+				TargetID:      target.ID,
+				SubjectUserID: target.AuthorID,
+				Note:          note,
+			}
+			switch action {
+			case ReportActionHide:
+				if err := setContentHidden(tx, target, false, nil); err != nil {
+					return err
+				}
+			case ReportActionDelete:
+				if err := deleteReportedContent(tx, target); err != nil {
+					return err
+				}
+			case ReportActionWarn, ReportActionSuspend:
+				if target.AuthorID == nil {
+					return fmt.Errorf("%w: the %s has no author to %s", ErrInvalidReportAction, target.Type, action)
+				}
+				entry.TargetType = models.ModerationTargetUser
+				entry.TargetID = *target.AuthorID
+				entry.Details = moderationDetails(map[string]interface{}{
+					"reportable_type": target.Type,
+					"reportable_id":   target.ID,
+				})
+				if action == ReportActionSuspend {
+					if err := suspendUser(tx, *target.AuthorID); err != nil {
+						return err
+					}
+				}
+			}
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+		}
+
+		// Resolving confirms the hide of content the reports hid
+		if target.HiddenAt != nil {
+			if err := setContentHidden(tx, target, false, nil); err != nil {
+				return err
+			}
+		}
+		return closeReports(tx, reports, models.ReportStatusResolved, models.ModerationActionResolve, target, note, moderatorID, map[string]interface{}{
+			"actions": actions,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, action := range actions {
+		switch action {
+		case ReportActionHide:
+			afterVisibilityChange(target)
+		case ReportActionDelete:
+			afterReportedContentDeleted(target)
+		case ReportActionWarn:
+			warnAuthor(target, note)
+		case ReportActionSuspend:
+			if err := TokenCacheSvc.InvalidateUserRefreshTokens(*target.AuthorID); err != nil {
+				log.Printf("Warning: failed to invalidate cached tokens of suspended user %s: %v", *target.AuthorID, err)
+			}
+			if err := TokenCacheSvc.InvalidateUserActive(*target.AuthorID); err != nil {
+				log.Printf("Warning: failed to invalidate cached status of suspended user %s: %v", *target.AuthorID, err)
+			}
+		}
+	}
+	return reports, nil
+}
+
+// Dismiss closes the open reports of a post or comment without action and
+// shows the content again if the reports hid it
+func (s *reportService) Dismiss(reportableType string, reportableID uuid.UUID, note string, moderatorID *uuid.UUID) ([]models.Report, error) {
+	var reports []models.Report
+	var target *reportTarget
+	restored := false
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reports, err = lockOpenReports(tx, reportableType, reportableID)
+		if err != nil {
+			return err
+		}
+		target, err = loadReportTarget(tx.Unscoped(), reportableType, reportableID)
+		if err != nil {
+			return err
+		}
+
+		if target.HiddenAt != nil && !target.Deleted {
+			if err := setContentHidden(tx, target, true, nil); err != nil {
+				return err
+			}
+			restored = true
+		}
+		return closeReports(tx, reports, models.ReportStatusDismissed, models.ModerationActionDismiss, target, note, moderatorID, map[string]interface{}{
+			"restored": restored,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	if restored {
+		afterVisibilityChange(target)
+	}
+	return reports, nil
+}
+
+// GetModerationLog lists moderation actions, newest first
+func (s *reportService) GetModerationLog(filter ModerationLogFilter, page, perPage int) ([]models.ModerationLog, int64, error) {
+	var entries []models.ModerationLog
+	var total int64
+
+	query := database.DB.Model(&models.ModerationLog{})
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	switch filter.TargetType {
+	case "":
+	case models.ReportableTypePost, models.ReportableTypeComment, models.ModerationTargetUser:
+		query = query.Where("target_type = ?", filter.TargetType)
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown target type %q", ErrInvalidModerationQuery, filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query = query.Where("target_id = ?", *filter.TargetID)
+	}
+	if filter.ModeratorID != nil {
+		query = query.Where("moderator_id = ?", *filter.ModeratorID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("subject_user_id = ?", *filter.UserID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&entries).Error
+
+	return entries, total, err
+}
+
+// loadReportTarget loads the reported post or comment
+func loadReportTarget(db *gorm.DB, reportableType string, id uuid.UUID) (*reportTarget, error) {
+	target := &reportTarget{Type: reportableType, ID: id}
+	switch reportableType {
+	case models.ReportableTypePost:
+		var post models.Post
+		if err := db.Select("id, title, status, public, published_at, hidden_at, created_by, deleted_at").First(&post, "id = ?", id).Error; err != nil {
+			return nil, err
+		}
+		target.AuthorID = post.CreatedBy
+		target.PostID = post.ID
+		target.Excerpt = post.Title
+		target.Visible = post.IsPublished()
+		target.Public = post.Public
+		target.HiddenAt = post.HiddenAt
+		target.Deleted = post.DeletedAt.Valid
+	case models.ReportableTypeComment:
+		var comment models.Comment
+		if err := db.Select("id, content, status, is_public, hidden_at, user_id, post_id, deleted_at").First(&comment, "id = ?", id).Error; err != nil {
+			return nil, err
+		}
+		authorID := comment.UserID
+		target.AuthorID = &authorID
+		target.PostID = comment.PostID
+		target.Excerpt = truncateText(stripTags(comment.Content), reportExcerptLength)
+		target.Visible = comment.IsApproved()
+		target.Public = comment.IsPublic
+		target.HiddenAt = comment.HiddenAt
+		target.Deleted = comment.DeletedAt.Valid
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidReport, reportableType)
+	}
+	return target, nil
+}
+
+// lockOpenReports locks the open reports of a post or comment; there must be one
+func lockOpenReports(tx *gorm.DB, reportableType string, reportableID uuid.UUID) ([]models.Report, error) {
+	var reports []models.Report
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("reportable_type = ? AND reportable_id = ? AND status = ?", reportableType, reportableID, models.ReportStatusOpen).
+		Order("created_at ASC").
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return reports, nil
+}
+
+// closeReports marks reports as resolved or dismissed and logs the decision
+func closeReports(tx *gorm.DB, reports []models.Report, status, action string, target *reportTarget, note string, moderatorID *uuid.UUID, details map[string]interface{}) error {
+	now := time.Now()
+	ids := make([]uuid.UUID, len(reports))
+	for i := range reports {
+		ids[i] = reports[i].ID
+		reports[i].Status = status
+		reports[i].ResolvedBy = moderatorID
+		reports[i].ResolvedAt = &now
+	}
+	if err := tx.Model(&models.Report{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":      status,
+		"resolved_by": moderatorID,
+		"resolved_at": now,
+	}).Error; err != nil {
+		return err
+	}
+
+	details["reports"] = ids
+	return tx.Create(&models.ModerationLog{
+		ModeratorID:   moderatorID,
+		Action:        action,
+		TargetType:    target.Type,
+		TargetID:      target.ID,
+		SubjectUserID: target.AuthorID,
+		Note:          note,
+		Details:       moderationDetails(details),
+	}).Error
+}
+
+// autoHideReported hides content once it has as many distinct open reports
+// as configured. Content its author or a moderator already hid is left alone.
+func autoHideReported(target *reportTarget) error {
+	hideAfter := config.GetConfig().Moderation.ReportHideAfter
+	if hideAfter <= 0 || target.HiddenAt != nil || !target.Public {
+		return nil
+	}
+
+	var reporters int64
+	if err := database.DB.Model(&models.Report{}).
+		Where("reportable_type = ? AND reportable_id = ? AND status = ?", target.Type, target.ID, models.ReportStatusOpen).
+		Distinct("reporter_id").
+		Count(&reporters).Error; err != nil {
+		return err
+	}
+	if reporters < int64(hideAfter) {
+		return nil
+	}
+
+	now := time.Now()
+	hidden := false
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setContentHidden(tx, target, false, &now); err != nil {
+			return err
+		}
+		// Another report may have hidden the content first
+		if target.HiddenAt == nil {
+			return nil
+		}
+		hidden = true
+		return tx.Create(&models.ModerationLog{
+			Action:        models.ModerationActionAutoHide,
+			TargetType:    target.Type,
+			TargetID:      target.ID,
+			SubjectUserID: target.AuthorID,
+			Details: moderationDetails(map[string]interface{}{
+				"reports":    reporters,
+				"hide_after": hideAfter,
+			}),
+		}).Error
+	}); err != nil {
+		return err
+	}
+	if hidden {
+		afterVisibilityChange(target)
+	}
+	return nil
+}
+
+// setContentHidden changes whether a post or comment is public. With hiddenAt
+// set it hides public content pending review; otherwise it clears hidden_at,
+// so content the reports hid is shown again or stays hidden for good.
+func setContentHidden(tx *gorm.DB, target *reportTarget, public bool, hiddenAt *time.Time) error {
+	publicColumn := "public"
+	var model interface{} = &models.Post{}
+	if target.Type == models.ReportableTypeComment {
+		publicColumn = "is_public"
+		model = &models.Comment{}
+	}
+
+	query := tx.Model(model).Where("id = ?", target.ID)
+	if hiddenAt != nil {
+		query = query.Where("hidden_at IS NULL AND "+publicColumn+" = ?", true)
+	}
+	result := query.UpdateColumns(map[string]interface{}{
+		publicColumn: public,
+		"hidden_at":  hiddenAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		target.Public = public
+		target.HiddenAt = hiddenAt
+	}
+	return nil
+}
+
+// deleteReportedContent soft-deletes a post or comment; replies to a deleted comment stay
+func deleteReportedContent(tx *gorm.DB, target *reportTarget) error {
+	if target.Deleted {
+		return nil
+	}
+	var model interface{} = &models.Post{}
+	if target.Type == models.ReportableTypeComment {
+		model = &models.Comment{}
+	}
+	if err := tx.Delete(model, "id = ?", target.ID).Error; err != nil {
+		return err
+	}
+	target.Deleted = true
+	return nil
+}
+
+// suspendUser deactivates a user and revokes their refresh tokens
+func suspendUser(tx *gorm.DB, userID uuid.UUID) error {
+	var user models.User
+	if err := tx.Select("id, is_active").First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	user.Deactivate()
+	if err := tx.Model(&user).Update("is_active", user.IsActive).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Update("is_active", false).Error
+}
+
+// warnAuthor notifies the author of reported content that a moderator warned them
+func warnAuthor(target *reportTarget, note string) {
+	message := fmt.Sprintf("A moderator reviewed reports about your %s and issued a warning.", target.Type)
+	if note != "" {
+		message += " " + note
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"reportable_type": target.Type,
+		"reportable_id":   target.ID,
+		"post_id":         target.PostID,
+	})
+	if err := notifyUser(&models.Notification{
+		UserID:   *target.AuthorID,
+		Type:     string(models.NotificationTypeWarning),
+		Title:    "Moderator warning",
+		Message:  message,
+		Data:     string(data),
+		Priority: "high",
+	}); err != nil {
+		log.Printf("Warning: failed to notify user %s of a moderator warning: %v", *target.AuthorID, err)
+	}
+}
+
+// afterVisibilityChange refreshes what readers see of content that was hidden or shown
+func afterVisibilityChange(target *reportTarget) {
+	if target.Type == models.ReportableTypePost {
+		syncPostIndex(target.ID)
+		SitemapSvc.MarkDirty()
+		return
+	}
+	invalidateCommentThread(target.PostID)
+}
+
+// afterReportedContentDeleted drops deleted content from search, the sitemap and the comment cache
+func afterReportedContentDeleted(target *reportTarget) {
+	if target.Type == models.ReportableTypePost {
+		if err := SearchSvc.DeletePost(target.ID); err != nil {
+			log.Printf("Warning: failed to remove post %s from search index: %v", target.ID, err)
+		}
+		SitemapSvc.MarkDirty()
+		return
+	}
+	invalidateCommentThread(target.PostID)
+}
+
+// moderationDetails encodes the context of a moderation log entry
+func moderationDetails(details map[string]interface{}) string {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+var ReportSvc ReportService = NewReportService()
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"go-next/internal/models"
+	"go-next/pkg/database"
+	"go-next/pkg/sanitize"
+
+	"gorm.io/gorm"
+)
+
+// Models that can be re-sanitized
+const (
+	SanitizeModelPosts    = "posts"
+	SanitizeModelContents = "contents"
+	SanitizeModelComments = "comments"
+)
+
+// SanitizeModels lists every model re-sanitized by default
+var SanitizeModels = []string{SanitizeModelPosts, SanitizeModelContents, SanitizeModelComments}
+
+// sanitizeBatchSize is how many rows are re-sanitized per query
+const sanitizeBatchSize = 500
+
+type SanitizeService interface {
+	Resanitize(ctx context.Context, model string, dryRun bool) (*SanitizeResult, error)
+}
+
+// SanitizeResult counts the rows scanned and changed by a re-sanitize run
+type SanitizeResult struct {
+	Model   string `json:"model"`
+	Scanned int    `json:"scanned"`
+	Changed int    `json:"changed"`
+}
+
+type sanitizeService struct{}
+
+func NewSanitizeService() SanitizeService {
+	return &sanitizeService{}
+}
+
+// Resanitize applies the current policies to stored rows, for content written
+// before sanitization existed or after a policy was tightened. Rows are
+// updated without touching updated_at or running hooks.
+func (s *sanitizeService) Resanitize(ctx context.Context, model string, dryRun bool) (*SanitizeResult, error) {
+	result := &SanitizeResult{Model: model}
+	db := database.DB.WithContext(ctx)
+
+	var err error
+	switch model {
+	case SanitizeModelPosts:
+		var batch []models.Post
+		err = db.Select("id, content").FindInBatches(&batch, sanitizeBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, post := range batch {
+				if err := resanitizeColumn(db, &models.Post{}, post.ID, "content", post.Content, sanitize.Editor(post.Content), dryRun, result); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	case SanitizeModelContents:
+		var batch []models.Content
+		err = db.Select("id, content").Where("type = ?", "html").FindInBatches(&batch, sanitizeBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, content := range batch {
+				if err := resanitizeColumn(db, &models.Content{}, content.ID, "content", content.Content, sanitize.Editor(content.Content), dryRun, result); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	case SanitizeModelComments:
+		var batch []models.Comment
+		err = db.Select("id, content").FindInBatches(&batch, sanitizeBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, comment := range batch {
+				if err := resanitizeColumn(db, &models.Comment{}, comment.ID, "content", comment.Content, sanitize.Comment(comment.Content), dryRun, result); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	default:
+		return nil, fmt.Errorf("unknown model %q", model)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func resanitizeColumn(db *gorm.DB, model interface{}, id interface{}, column, current, clean string, dryRun bool, result *SanitizeResult) error {
+	result.Scanned++
+	if clean == current {
+		return nil
+	}
+	result.Changed++
+	if dryRun {
+		return nil
+	}
+	return db.Model(model).Where("id = ?", id).UpdateColumn(column, clean).Error
+}
+
+// sanitizePost cleans the HTML of a post before it is stored; content blocks
+// are sanitized by normalizePostBlocks
+func sanitizePost(post *models.Post) {
+	post.Content = sanitize.Editor(post.Content)
+}
+
+// sanitizeComment cleans the HTML of a comment before it is stored
+func sanitizeComment(comment *models.Comment) {
+	comment.Content = sanitize.Comment(comment.Content)
+}
+
+var SanitizeSvc SanitizeService = NewSanitizeService()
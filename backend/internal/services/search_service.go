@@ -0,0 +1,683 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/search"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrSearchDisabled is returned when the embedded search engine is not configured
+var ErrSearchDisabled = errors.New("embedded search engine is disabled")
+
+type SearchService interface {
+	Enabled() bool
+	Search(req SearchRequest) (*SearchResult, error)
+	Suggest(query string, limit int) (*Suggestions, error)
+	IndexPost(postID uuid.UUID) error
+	DeletePost(postID uuid.UUID) error
+	IndexCategory(category *models.Category) error
+	DeleteCategory(categoryID uuid.UUID) error
+	IndexTag(tag *models.Tag) error
+	DeleteTag(tagID uint64) error
+	IndexUser(user *models.User) error
+	DeleteUser(userID uuid.UUID) error
+	Reindex(ctx context.Context) (*ReindexStats, error)
+	Engine() *search.Engine
+	Close() error
+}
+
+// SearchRequest holds the query and facet filters of a search
+type SearchRequest struct {
+	Query    string
+	Type     string
+	Category string
+	Tag      string
+	Author   string
+	Year     string
+	Page     int
+	PerPage  int
+	// Locales limits posts to a locale chain as the blog listings do; empty searches every locale
+	Locales []string
+}
+
+// SearchResult holds hydrated records along with facet counts
+type SearchResult struct {
+	Data   interface{}                   `json:"data"`
+	Hits   []search.Hit                  `json:"hits"`
+	Total  int64                         `json:"total"`
+	Facets map[string][]search.FacetTerm `json:"facets"`
+}
+
+// Suggestion is a single type-ahead match
+type Suggestion struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	ViewCount int64  `json:"view_count"`
+}
+
+// Suggestions groups type-ahead matches by record type
+type Suggestions struct {
+	Query      string       `json:"query"`
+	Posts      []Suggestion `json:"posts"`
+	Tags       []Suggestion `json:"tags"`
+	Categories []Suggestion `json:"categories"`
+	DidYouMean string       `json:"did_you_mean,omitempty"`
+}
+
+// ReindexStats reports how many documents of each type were indexed
+type ReindexStats struct {
+	Posts      int `json:"posts"`
+	Categories int `json:"categories"`
+	Tags       int `json:"tags"`
+	Users      int `json:"users"`
+}
+
+type searchService struct {
+	engine    *search.Engine
+	indexPath string
+	mu        sync.RWMutex
+}
+
+// NewSearchService wraps an opened engine; a nil engine yields a disabled service
+func NewSearchService(engine *search.Engine, indexPath string) SearchService {
+	return &searchService{engine: engine, indexPath: indexPath}
+}
+
+// InitSearch opens the embedded index when SEARCH_ENGINE is set to bleve
+func InitSearch(cfg config.SearchConfig) error {
+	if !strings.EqualFold(cfg.Engine, "bleve") {
+		SearchSvc = NewSearchService(nil, cfg.IndexPath)
+		return nil
+	}
+
+	engine, err := search.Open(cfg.IndexPath)
+	if err != nil {
+		return err
+	}
+	SearchSvc = NewSearchService(engine, cfg.IndexPath)
+	return nil
+}
+
+func (s *searchService) Enabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.engine != nil
+}
+
+func (s *searchService) Engine() *search.Engine {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.engine
+}
+
+func (s *searchService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.engine == nil {
+		return nil
+	}
+	err := s.engine.Close()
+	s.engine = nil
+	return err
+}
+
+func (s *searchService) Search(req SearchRequest) (*SearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil, ErrSearchDisabled
+	}
+
+	if req.Type == "" {
+		req.Type = search.TypePost
+	}
+
+	q := search.Query{
+		Text: req.Query,
+		Type: req.Type,
+		Filters: map[string]string{
+			search.FacetCategory: req.Category,
+			search.FacetTag:      req.Tag,
+			search.FacetAuthor:   req.Author,
+			search.FacetYear:     req.Year,
+		},
+		Page:    req.Page,
+		PerPage: req.PerPage,
+	}
+	if req.Type == search.TypePost {
+		q.Locales = req.Locales
+	}
+	res, err := s.engine.Search(q)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := hydrateSearchHits(req.Type, res.Hits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SearchResult{
+		Data:   data,
+		Hits:   res.Hits,
+		Total:  int64(res.Total),
+		Facets: res.Facets,
+	}, nil
+}
+
+// Suggest returns prefix matches across post titles, tags and categories,
+// most viewed first, along with a spelling correction
+func (s *searchService) Suggest(query string, limit int) (*Suggestions, error) {
+	query = strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if limit < 1 || limit > 20 {
+		limit = 5
+	}
+
+	cacheKey := fmt.Sprintf(CacheKeySearchSuggest, limit, query)
+	var cached Suggestions
+	if err := CacheSvc.Get(cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	suggestions := &Suggestions{
+		Query:      query,
+		Posts:      []Suggestion{},
+		Tags:       []Suggestion{},
+		Categories: []Suggestion{},
+	}
+	if query == "" {
+		return suggestions, nil
+	}
+
+	// Match the start of the title or of any word within it
+	pattern := strings.NewReplacer("%", "", "_", "").Replace(query)
+	prefix, wordPrefix := pattern+"%", "% "+pattern+"%"
+
+	if err := database.DB.Model(&models.Post{}).
+		Select("id, title, slug, view_count").
+		Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true).
+		Where("title ILIKE ? OR title ILIKE ?", prefix, wordPrefix).
+		Order("view_count DESC, published_at DESC").
+		Limit(limit).
+		Scan(&suggestions.Posts).Error; err != nil {
+		return nil, err
+	}
+
+	// Tags and categories are ranked by the views of their published posts
+	if err := database.DB.Table("tags").
+		Select("tags.id, tags.name AS title, tags.slug, COALESCE(SUM(posts.view_count), 0) AS view_count").
+		Joins("LEFT JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("LEFT JOIN posts ON posts.id = post_tags.post_id AND posts.status = ? AND posts.deleted_at IS NULL", "published").
+		Where("tags.is_active = ? AND tags.deleted_at IS NULL", true).
+		Where("tags.name ILIKE ? OR tags.name ILIKE ?", prefix, wordPrefix).
+		Group("tags.id, tags.name, tags.slug").
+		Order("view_count DESC, tags.name ASC").
+		Limit(limit).
+		Scan(&suggestions.Tags).Error; err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Table("categories").
+		Select("categories.id, categories.name AS title, categories.slug, COALESCE(SUM(posts.view_count), 0) AS view_count").
+		Joins("LEFT JOIN posts ON posts.category_id = categories.id AND posts.status = ? AND posts.deleted_at IS NULL", "published").
+		Where("categories.is_active = ? AND categories.deleted_at IS NULL", true).
+		Where("categories.name ILIKE ? OR categories.name ILIKE ?", prefix, wordPrefix).
+		Group("categories.id, categories.name, categories.slug").
+		Order("view_count DESC, categories.name ASC").
+		Limit(limit).
+		Scan(&suggestions.Categories).Error; err != nil {
+		return nil, err
+	}
+
+	correction, err := s.correct(query)
+	if err != nil {
+		log.Printf("Warning: failed to build search correction: %v", err)
+	} else if correction != query {
+		suggestions.DidYouMean = correction
+	}
+
+	if err := CacheSvc.Set(cacheKey, suggestions, CacheDurationShort); err != nil && GlobalRedisClient != nil {
+		log.Printf("Warning: failed to cache search suggestions: %v", err)
+	}
+
+	return suggestions, nil
+}
+
+// correct spells a query the way the site does: from the index vocabulary
+// with SEARCH_ENGINE=bleve, otherwise from the words of published post
+// titles, tags and categories
+func (s *searchService) correct(query string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine != nil {
+		return s.engine.Correct(query)
+	}
+
+	vocabulary, err := databaseVocabulary()
+	if err != nil {
+		return "", err
+	}
+	return search.CorrectFrom(query, vocabulary), nil
+}
+
+// databaseVocabulary counts the words of published post titles, tags and
+// categories; it is cached because every correction reads all of it
+func databaseVocabulary() (map[string]uint64, error) {
+	var vocabulary map[string]uint64
+	if err := CacheSvc.Get(CacheKeySearchVocabulary, &vocabulary); err == nil && vocabulary != nil {
+		return vocabulary, nil
+	}
+
+	var texts, names []string
+	if err := database.DB.Model(&models.Post{}).
+		Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true).
+		Pluck("title", &texts).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Table("tags").Where("is_active = ? AND deleted_at IS NULL", true).Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	texts = append(texts, names...)
+	names = nil
+	if err := database.DB.Model(&models.Category{}).Where("is_active = ?", true).Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	texts = append(texts, names...)
+
+	vocabulary = make(map[string]uint64)
+	for _, text := range texts {
+		search.AddVocabulary(vocabulary, text)
+	}
+	if err := CacheSvc.Set(CacheKeySearchVocabulary, vocabulary, CacheDurationMedium); err != nil && GlobalRedisClient != nil {
+		log.Printf("Warning: failed to cache search vocabulary: %v", err)
+	}
+	return vocabulary, nil
+}
+
+func (s *searchService) IndexPost(postID uuid.UUID) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+
+	var post models.Post
+	if err := database.DB.Unscoped().Preload("Category").First(&post, postID).Error; err != nil {
+		return err
+	}
+
+	// Only published public posts are searchable
+	if post.DeletedAt.Valid || !post.IsPublished() || !post.Public {
+		return s.engine.Delete(search.TypePost, postID.String())
+	}
+
+	docs, err := buildPostDocuments([]models.Post{post})
+	if err != nil {
+		return err
+	}
+	return s.engine.Index(docs[0])
+}
+
+func (s *searchService) DeletePost(postID uuid.UUID) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	return s.engine.Delete(search.TypePost, postID.String())
+}
+
+func (s *searchService) IndexCategory(category *models.Category) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	if !category.IsActive {
+		return s.engine.Delete(search.TypeCategory, category.ID.String())
+	}
+	return s.engine.Index(categoryDocument(category))
+}
+
+func (s *searchService) DeleteCategory(categoryID uuid.UUID) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	return s.engine.Delete(search.TypeCategory, categoryID.String())
+}
+
+func (s *searchService) IndexTag(tag *models.Tag) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	if !tag.IsActive {
+		return s.engine.Delete(search.TypeTag, strconv.FormatUint(tag.ID, 10))
+	}
+	return s.engine.Index(tagDocument(tag))
+}
+
+func (s *searchService) DeleteTag(tagID uint64) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	return s.engine.Delete(search.TypeTag, strconv.FormatUint(tagID, 10))
+}
+
+func (s *searchService) IndexUser(user *models.User) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	if !user.IsActive {
+		return s.engine.Delete(search.TypeUser, user.ID.String())
+	}
+	return s.engine.Index(userDocument(user))
+}
+
+func (s *searchService) DeleteUser(userID uuid.UUID) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.engine == nil {
+		return nil
+	}
+	return s.engine.Delete(search.TypeUser, userID.String())
+}
+
+// Reindex drops the index and rebuilds it from the database
+func (s *searchService) Reindex(ctx context.Context) (*ReindexStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.engine == nil {
+		return nil, ErrSearchDisabled
+	}
+
+	if err := s.engine.Close(); err != nil {
+		return nil, err
+	}
+	engine, err := search.Recreate(s.indexPath)
+	if err != nil {
+		s.engine = nil
+		return nil, err
+	}
+	s.engine = engine
+
+	stats := &ReindexStats{}
+	db := database.DB.WithContext(ctx)
+
+	const batchSize = 500
+
+	var posts []models.Post
+	if err := db.Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true).
+		Preload("Category").
+		FindInBatches(&posts, batchSize, func(_ *gorm.DB, _ int) error {
+			docs, err := buildPostDocuments(posts)
+			if err != nil {
+				return err
+			}
+			stats.Posts += len(docs)
+			return s.engine.IndexBatch(docs)
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	var categories []models.Category
+	if err := db.Where("is_active = ?", true).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	docs := make([]search.Document, 0, len(categories))
+	for i := range categories {
+		docs = append(docs, categoryDocument(&categories[i]))
+	}
+	if err := s.engine.IndexBatch(docs); err != nil {
+		return nil, err
+	}
+	stats.Categories = len(docs)
+
+	var tags []models.Tag
+	if err := db.Where("is_active = ?", true).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	docs = make([]search.Document, 0, len(tags))
+	for i := range tags {
+		docs = append(docs, tagDocument(&tags[i]))
+	}
+	if err := s.engine.IndexBatch(docs); err != nil {
+		return nil, err
+	}
+	stats.Tags = len(docs)
+
+	var users []models.User
+	if err := db.Where("is_active = ?", true).
+		FindInBatches(&users, batchSize, func(_ *gorm.DB, _ int) error {
+			docs := make([]search.Document, 0, len(users))
+			for i := range users {
+				docs = append(docs, userDocument(&users[i]))
+			}
+			stats.Users += len(docs)
+			return s.engine.IndexBatch(docs)
+		}).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// syncPostIndex refreshes posts and their translations in the embedded index,
+// since a post's document records which locales its translation group is
+// published in. Failures are logged.
+func syncPostIndex(postIDs ...uuid.UUID) {
+	if SearchSvc == nil || !SearchSvc.Enabled() || len(postIDs) == 0 {
+		return
+	}
+	var translations []uuid.UUID
+	if err := database.DB.Model(&models.Post{}).
+		Where("translation_group_id IN (?)", database.DB.Unscoped().Model(&models.Post{}).
+			Select("translation_group_id").
+			Where("id IN ? AND translation_group_id IS NOT NULL", postIDs)).
+		Pluck("id", &translations).Error; err != nil {
+		log.Printf("Warning: failed to load the translations of posts %v: %v", postIDs, err)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(postIDs)+len(translations))
+	for _, id := range append(postIDs, translations...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := SearchSvc.IndexPost(id); err != nil {
+			log.Printf("Warning: failed to index post %s: %v", id, err)
+		}
+	}
+}
+
+// syncUserIndex refreshes a user in the embedded index, logging failures
+func syncUserIndex(user *models.User) {
+	if SearchSvc == nil || !SearchSvc.Enabled() {
+		return
+	}
+	if err := SearchSvc.IndexUser(user); err != nil {
+		log.Printf("Warning: failed to index user %s: %v", user.ID, err)
+	}
+}
+
+// buildPostDocuments converts posts into index documents, resolving tags and authors in bulk
+func buildPostDocuments(posts []models.Post) ([]search.Document, error) {
+	if len(posts) == 0 {
+		return nil, nil
+	}
+
+	tagsByPost, err := loadPostTags(postIDs(posts))
+	if err != nil {
+		return nil, err
+	}
+	authorsByPost, err := loadPostAuthors(posts)
+	if err != nil {
+		return nil, err
+	}
+	var groupIDs []uuid.UUID
+	for _, post := range posts {
+		if post.TranslationGroupID != nil {
+			groupIDs = append(groupIDs, *post.TranslationGroupID)
+		}
+	}
+	translations, err := loadPostTranslations(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]search.Document, 0, len(posts))
+	for _, post := range posts {
+		doc := search.Document{
+			ID:        post.ID.String(),
+			Type:      search.TypePost,
+			Title:     post.Title,
+			Slug:      post.Slug,
+			Excerpt:   post.Excerpt,
+			Body:      stripTags(post.Content),
+			ViewCount: post.ViewCount,
+			Locale:    post.Locale,
+		}
+		if post.TranslationGroupID != nil {
+			for _, translation := range translations[*post.TranslationGroupID] {
+				doc.Translations = append(doc.Translations, translation.Locale)
+			}
+		}
+		for _, tag := range tagsByPost[post.ID] {
+			doc.Tags = append(doc.Tags, tag.Slug)
+		}
+		if post.Category != nil {
+			doc.Category = post.Category.Slug
+		}
+		// The author facet filters by the lead author's username
+		if authors := authorsByPost[post.ID]; len(authors) > 0 {
+			doc.Author = authors[0].Username
+		}
+		if post.PublishedAt != nil {
+			doc.PublishedAt = *post.PublishedAt
+			doc.Year = strconv.Itoa(post.PublishedAt.Year())
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+func categoryDocument(category *models.Category) search.Document {
+	return search.Document{
+		ID:    category.ID.String(),
+		Type:  search.TypeCategory,
+		Title: category.Name,
+		Slug:  category.Slug,
+		Body:  category.Description,
+	}
+}
+
+func tagDocument(tag *models.Tag) search.Document {
+	return search.Document{
+		ID:    strconv.FormatUint(tag.ID, 10),
+		Type:  search.TypeTag,
+		Title: tag.Name,
+		Slug:  tag.Slug,
+		Body:  tag.Description,
+	}
+}
+
+func userDocument(user *models.User) search.Document {
+	return search.Document{
+		ID:    user.ID.String(),
+		Type:  search.TypeUser,
+		Title: user.Username,
+		Slug:  user.Username,
+	}
+}
+
+// hydrateSearchHits loads the records behind the hits, preserving score order
+func hydrateSearchHits(docType string, hits []search.Hit) (interface{}, error) {
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+
+	switch docType {
+	case search.TypePost:
+		var posts []models.Post
+		if len(ids) > 0 {
+			if err := database.DB.Where("id IN ?", ids).Preload("Category").Find(&posts).Error; err != nil {
+				return nil, err
+			}
+		}
+		return inHitOrder(ids, posts, func(post *models.Post) string { return post.ID.String() }), nil
+	case search.TypeCategory:
+		var categories []models.Category
+		if len(ids) > 0 {
+			if err := database.DB.Where("id IN ?", ids).Find(&categories).Error; err != nil {
+				return nil, err
+			}
+		}
+		return inHitOrder(ids, categories, func(category *models.Category) string { return category.ID.String() }), nil
+	case search.TypeTag:
+		var tags []models.Tag
+		if len(ids) > 0 {
+			if err := database.DB.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+				return nil, err
+			}
+		}
+		return inHitOrder(ids, tags, func(tag *models.Tag) string { return strconv.FormatUint(tag.ID, 10) }), nil
+	case search.TypeUser:
+		var users []models.User
+		if len(ids) > 0 {
+			if err := database.DB.Select("id, username, created_at").Where("id IN ?", ids).Find(&users).Error; err != nil {
+				return nil, err
+			}
+		}
+		return inHitOrder(ids, users, func(user *models.User) string { return user.ID.String() }), nil
+	default:
+		return hits, nil
+	}
+}
+
+// inHitOrder arranges records in the order of the hit IDs, dropping hits
+// whose record no longer exists
+func inHitOrder[T any](ids []string, records []T, id func(record *T) string) []T {
+	byID := make(map[string]T, len(records))
+	for i := range records {
+		byID[id(&records[i])] = records[i]
+	}
+	ordered := make([]T, 0, len(records))
+	for _, hitID := range ids {
+		if record, ok := byID[hitID]; ok {
+			ordered = append(ordered, record)
+		}
+	}
+	return ordered
+}
+
+var tagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripTags removes markup so only readable text is indexed
+func stripTags(content string) string {
+	return strings.Join(strings.Fields(tagPattern.ReplaceAllString(content, " ")), " ")
+}
+
+var SearchSvc SearchService = NewSearchService(nil, "")
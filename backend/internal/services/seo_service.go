@@ -0,0 +1,461 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/locale"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// seoDescriptionLength is the longest description search engines usually show
+	seoDescriptionLength = 160
+
+	// seoMaxCategoryDepth bounds the parent walk of categories without nested-set values
+	seoMaxCategoryDepth = 20
+)
+
+var ErrInvalidSEOMeta = errors.New("invalid SEO metadata")
+
+type SEOService interface {
+	GetPostSEO(slug string, locales []string) (*SEO, error)
+	GetCategorySEO(slug string, locales []string) (*SEO, error)
+	UpdateSEO(seoableType string, seoableID uuid.UUID, meta *models.SEOMeta) (*models.SEOMeta, error)
+}
+
+// SEO is the resolved head metadata of a page, with every fallback applied
+type SEO struct {
+	Title        string                   `json:"title"`
+	Description  string                   `json:"description"`
+	CanonicalURL string                   `json:"canonical_url"`
+	Locale       string                   `json:"locale"`
+	Alternates   []Alternate              `json:"alternates,omitempty"`
+	Robots       string                   `json:"robots"`
+	Image        *SEOImage                `json:"image,omitempty"`
+	OpenGraph    []SEOTag                 `json:"open_graph"`
+	Twitter      []SEOTag                 `json:"twitter"`
+	JSONLD       []map[string]interface{} `json:"json_ld"`
+}
+
+// SEOImage is the image shown when a page is shared
+type SEOImage struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+	Width    *int   `json:"width,omitempty"`
+	Height   *int   `json:"height,omitempty"`
+	Alt      string `json:"alt,omitempty"`
+}
+
+// SEOTag is a <meta property|name="..." content="..."> pair; OpenGraph allows repeated properties
+type SEOTag struct {
+	Property string `json:"property"`
+	Content  string `json:"content"`
+}
+
+// breadcrumb is one step of a BreadcrumbList
+type breadcrumb struct {
+	Name string
+	URL  string
+}
+
+type seoService struct{}
+
+func NewSEOService() SEOService {
+	return &seoService{}
+}
+
+// GetPostSEO resolves the metadata of a public post, looking its slug up within a locale chain
+func (s *seoService) GetPostSEO(slug string, locales []string) (*SEO, error) {
+	var post models.Post
+	if err := database.DB.Where("slug = ? AND status = ? AND public = ? AND published_at IS NOT NULL", slug, "published", true).
+		Scopes(postsInLocales(locales)).
+		Preload("Category").
+		Preload("SEO.Image").
+		First(&post).Error; err != nil {
+		return nil, err
+	}
+
+	site := config.GetConfig().Site
+	meta := post.SEO
+	if meta == nil {
+		meta = &models.SEOMeta{}
+	}
+
+	seo := &SEO{
+		Title:        firstNonEmpty(meta.MetaTitle, pageTitle(post.Title, site.Name)),
+		Description:  firstNonEmpty(meta.MetaDescription, truncateText(post.Excerpt, seoDescriptionLength), truncateText(stripTags(post.Content), seoDescriptionLength), site.Description),
+		CanonicalURL: firstNonEmpty(meta.CanonicalURL, site.LocalizedPostURL(post.Locale, post.Slug)),
+		Locale:       post.Locale,
+		Robots:       firstNonEmpty(meta.Robots, models.RobotsIndexFollow),
+	}
+
+	translations, err := TranslationSvc.GetPostTranslations(&post)
+	if err != nil {
+		return nil, err
+	}
+	seo.Alternates = hreflangAlternates(translations, site.Language)
+
+	image, err := resolveSEOImage(meta, models.MediableTypePost, post.ID, site)
+	if err != nil {
+		return nil, err
+	}
+	seo.Image = image
+
+	tagsByPost, err := loadPostTags([]uuid.UUID{post.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := attachPostAuthors(&post); err != nil {
+		return nil, err
+	}
+
+	crumbs := []breadcrumb{{Name: site.Name, URL: site.URL}}
+	if post.Category != nil {
+		categories, err := categoryTrail(post.Category)
+		if err != nil {
+			return nil, err
+		}
+		for _, category := range categories {
+			crumbs = append(crumbs, breadcrumb{Name: category.Name, URL: site.LocalizedCategoryURL(category.Locale, category.Slug)})
+		}
+	}
+	crumbs = append(crumbs, breadcrumb{Name: post.Title, URL: seo.CanonicalURL})
+
+	seo.OpenGraph = openGraphTags(seo, "article", site)
+	if post.PublishedAt != nil {
+		seo.OpenGraph = append(seo.OpenGraph, SEOTag{"article:published_time", post.PublishedAt.UTC().Format(time.RFC3339)})
+	}
+	seo.OpenGraph = append(seo.OpenGraph, SEOTag{"article:modified_time", post.UpdatedAt.UTC().Format(time.RFC3339)})
+	if post.Category != nil {
+		seo.OpenGraph = append(seo.OpenGraph, SEOTag{"article:section", post.Category.Name})
+	}
+	keywords := make([]string, 0, len(tagsByPost[post.ID]))
+	for _, tag := range tagsByPost[post.ID] {
+		seo.OpenGraph = append(seo.OpenGraph, SEOTag{"article:tag", tag.Name})
+		keywords = append(keywords, tag.Name)
+	}
+	seo.Twitter = twitterTags(seo, site)
+
+	posting := map[string]interface{}{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         truncateText(post.Title, 110),
+		"description":      seo.Description,
+		"url":              seo.CanonicalURL,
+		"mainEntityOfPage": map[string]interface{}{"@type": "WebPage", "@id": seo.CanonicalURL},
+		"dateModified":     post.UpdatedAt.UTC().Format(time.RFC3339),
+		"inLanguage":       post.Locale,
+		"publisher":        map[string]interface{}{"@type": "Organization", "name": site.Name, "url": site.URL},
+	}
+	if post.PublishedAt != nil {
+		posting["datePublished"] = post.PublishedAt.UTC().Format(time.RFC3339)
+	}
+	if seo.Image != nil {
+		posting["image"] = seo.Image.URL
+	}
+	if len(post.Authors) > 0 {
+		authors := make([]map[string]interface{}, 0, len(post.Authors))
+		for _, author := range post.Authors {
+			person := map[string]interface{}{"@type": "Person", "name": author.DisplayName, "url": author.URL}
+			if len(author.Links) > 0 {
+				sameAs := make([]string, 0, len(author.Links))
+				for _, link := range author.Links {
+					sameAs = append(sameAs, link.URL)
+				}
+				person["sameAs"] = sameAs
+			}
+			authors = append(authors, person)
+		}
+		posting["author"] = authors
+	}
+	if post.Category != nil {
+		posting["articleSection"] = post.Category.Name
+	}
+	if len(keywords) > 0 {
+		posting["keywords"] = strings.Join(keywords, ", ")
+	}
+	if words := len(strings.Fields(stripTags(post.Content))); words > 0 {
+		posting["wordCount"] = words
+	}
+	seo.JSONLD = []map[string]interface{}{posting, breadcrumbList(crumbs)}
+
+	return seo, nil
+}
+
+// GetCategorySEO resolves the metadata of an active category, looking its slug up within a locale chain
+func (s *seoService) GetCategorySEO(slug string, locales []string) (*SEO, error) {
+	var category models.Category
+	if err := database.DB.Where("slug = ? AND is_active = ?", slug, true).
+		Scopes(categoriesInLocales(locales)).
+		Preload("SEO.Image").
+		First(&category).Error; err != nil {
+		return nil, err
+	}
+
+	site := config.GetConfig().Site
+	meta := category.SEO
+	if meta == nil {
+		meta = &models.SEOMeta{}
+	}
+
+	seo := &SEO{
+		Title:        firstNonEmpty(meta.MetaTitle, pageTitle(category.Name, site.Name)),
+		Description:  firstNonEmpty(meta.MetaDescription, truncateText(category.Description, seoDescriptionLength), site.Description),
+		CanonicalURL: firstNonEmpty(meta.CanonicalURL, site.LocalizedCategoryURL(category.Locale, category.Slug)),
+		Locale:       category.Locale,
+		Robots:       firstNonEmpty(meta.Robots, models.RobotsIndexFollow),
+	}
+
+	translations, err := TranslationSvc.GetCategoryTranslations(&category)
+	if err != nil {
+		return nil, err
+	}
+	seo.Alternates = hreflangAlternates(translations, site.Language)
+
+	image, err := resolveSEOImage(meta, models.MediableTypeCategory, category.ID, site)
+	if err != nil {
+		return nil, err
+	}
+	seo.Image = image
+
+	categories, err := categoryTrail(&category)
+	if err != nil {
+		return nil, err
+	}
+	crumbs := []breadcrumb{{Name: site.Name, URL: site.URL}}
+	for _, c := range categories {
+		crumbs = append(crumbs, breadcrumb{Name: c.Name, URL: site.LocalizedCategoryURL(c.Locale, c.Slug)})
+	}
+	// The page itself is canonical, which may differ from its category URL
+	crumbs[len(crumbs)-1].URL = seo.CanonicalURL
+
+	seo.OpenGraph = openGraphTags(seo, "website", site)
+	seo.Twitter = twitterTags(seo, site)
+	seo.JSONLD = []map[string]interface{}{breadcrumbList(crumbs)}
+
+	return seo, nil
+}
+
+// UpdateSEO replaces the overrides of a post or category; empty overrides remove the record
+func (s *seoService) UpdateSEO(seoableType string, seoableID uuid.UUID, meta *models.SEOMeta) (*models.SEOMeta, error) {
+	var target interface{}
+	switch seoableType {
+	case models.MediableTypePost:
+		target = &models.Post{}
+	case models.MediableTypeCategory:
+		target = &models.Category{}
+	default:
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSEOMeta, seoableType)
+	}
+	if err := database.DB.Select("id").First(target, "id = ?", seoableID).Error; err != nil {
+		return nil, err
+	}
+
+	meta.SeoableID = seoableID
+	meta.SeoableType = seoableType
+	meta.Robots = models.NormalizeRobots(meta.Robots)
+	if err := models.ValidateRobots(meta.Robots); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSEOMeta, err)
+	}
+	if meta.ImageID != nil {
+		var media models.Media
+		if err := database.DB.First(&media, "id = ?", *meta.ImageID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: image not found", ErrInvalidSEOMeta)
+			}
+			return nil, err
+		}
+		if !media.IsImage() {
+			return nil, fmt.Errorf("%w: media is not an image", ErrInvalidSEOMeta)
+		}
+		meta.Image = &media
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		where := tx.Where("seoable_id = ? AND seoable_type = ?", seoableID, seoableType)
+		if meta.IsEmpty() {
+			return where.Delete(&models.SEOMeta{}).Error
+		}
+
+		result := where.Model(&models.SEOMeta{}).Select("meta_title", "meta_description", "canonical_url", "robots", "image_id", "updated_at").
+			Updates(&models.SEOMeta{
+				MetaTitle:       meta.MetaTitle,
+				MetaDescription: meta.MetaDescription,
+				CanonicalURL:    meta.CanonicalURL,
+				Robots:          meta.Robots,
+				ImageID:         meta.ImageID,
+				UpdatedAt:       time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+		return tx.Omit("Image").Create(meta).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// resolveImage picks the override image, then the first attached image, then the site image
+func resolveSEOImage(meta *models.SEOMeta, mediableType string, id uuid.UUID, site config.SiteConfig) (*SEOImage, error) {
+	if meta.Image != nil && meta.Image.URL != "" {
+		return seoImage(meta.Image), nil
+	}
+
+	mediaByModel, err := loadMediaFor(mediableType, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	for _, media := range mediaByModel[id] {
+		if media.URL != "" && media.IsImage() {
+			return seoImage(&media), nil
+		}
+	}
+
+	if site.Image != "" {
+		return &SEOImage{URL: site.Image, Alt: site.Name}, nil
+	}
+	return nil, nil
+}
+
+func seoImage(media *models.Media) *SEOImage {
+	return &SEOImage{
+		URL:      media.URL,
+		MimeType: media.MimeType,
+		Width:    media.Width,
+		Height:   media.Height,
+		Alt:      media.OriginalName,
+	}
+}
+
+// categoryTrail returns a category's ancestors from the root down, ending with the category
+func categoryTrail(category *models.Category) ([]models.Category, error) {
+	var trail []models.Category
+	if category.RecordRight > category.RecordLeft {
+		if err := database.DB.Select("id, name, slug, locale").
+			Where("record_left < ? AND record_right > ?", category.RecordLeft, category.RecordRight).
+			Order("record_left ASC").
+			Find(&trail).Error; err != nil {
+			return nil, err
+		}
+		return append(trail, *category), nil
+	}
+
+	// Categories created without the nested-set helpers only have a parent link
+	trail = []models.Category{*category}
+	parentID := category.ParentID
+	for depth := 0; parentID != nil && depth < seoMaxCategoryDepth; depth++ {
+		var parent models.Category
+		if err := database.DB.Select("id, name, slug, locale, parent_id").First(&parent, "id = ?", *parentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+		trail = append([]models.Category{parent}, trail...)
+		parentID = parent.ParentID
+	}
+	return trail, nil
+}
+
+func openGraphTags(seo *SEO, ogType string, site config.SiteConfig) []SEOTag {
+	tags := []SEOTag{
+		{"og:type", ogType},
+		{"og:title", seo.Title},
+		{"og:description", seo.Description},
+		{"og:url", seo.CanonicalURL},
+		{"og:site_name", site.Name},
+	}
+	if language := firstNonEmpty(seo.Locale, site.Language); language != "" {
+		tags = append(tags, SEOTag{"og:locale", strings.ReplaceAll(language, "-", "_")})
+	}
+	for _, alternate := range seo.Alternates {
+		if alternate.Hreflang != seo.Locale && alternate.Hreflang != locale.XDefault {
+			tags = append(tags, SEOTag{"og:locale:alternate", strings.ReplaceAll(alternate.Hreflang, "-", "_")})
+		}
+	}
+	if seo.Image != nil {
+		tags = append(tags, SEOTag{"og:image", seo.Image.URL})
+		if seo.Image.MimeType != "" {
+			tags = append(tags, SEOTag{"og:image:type", seo.Image.MimeType})
+		}
+		if seo.Image.Width != nil && seo.Image.Height != nil {
+			tags = append(tags,
+				SEOTag{"og:image:width", strconv.Itoa(*seo.Image.Width)},
+				SEOTag{"og:image:height", strconv.Itoa(*seo.Image.Height)})
+		}
+		if seo.Image.Alt != "" {
+			tags = append(tags, SEOTag{"og:image:alt", seo.Image.Alt})
+		}
+	}
+	return tags
+}
+
+func twitterTags(seo *SEO, site config.SiteConfig) []SEOTag {
+	card := "summary"
+	if seo.Image != nil {
+		card = "summary_large_image"
+	}
+	tags := []SEOTag{
+		{"twitter:card", card},
+		{"twitter:title", seo.Title},
+		{"twitter:description", seo.Description},
+	}
+	if seo.Image != nil {
+		tags = append(tags, SEOTag{"twitter:image", seo.Image.URL})
+	}
+	if site.TwitterHandle != "" {
+		tags = append(tags, SEOTag{"twitter:site", "@" + strings.TrimPrefix(site.TwitterHandle, "@")})
+	}
+	return tags
+}
+
+func breadcrumbList(crumbs []breadcrumb) map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(crumbs))
+	for i, crumb := range crumbs {
+		items = append(items, map[string]interface{}{
+			"@type":    "ListItem",
+			"position": i + 1,
+			"name":     crumb.Name,
+			"item":     crumb.URL,
+		})
+	}
+	return map[string]interface{}{
+		"@context":        "https://schema.org",
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	}
+}
+
+// pageTitle appends the site name to a page title
+func pageTitle(title, siteName string) string {
+	if siteName == "" || title == siteName {
+		return title
+	}
+	return title + " | " + siteName
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+var SEOSvc SEOService = NewSEOService()
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"errors"
+
+	"go-next/internal/models"
+	"go-next/pkg/database"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSeriesOrderMismatch = errors.New("series order must list every post of the series exactly once")
+	ErrPostInSeries        = errors.New("post already belongs to a series")
+)
+
+type SeriesService interface {
+	// Public series endpoints
+	GetPublicSeries(page, perPage int) ([]models.Series, int64, error)
+	GetSeriesBySlug(slug string) (*models.Series, error)
+	GetSeriesPosts(seriesID uuid.UUID, locales []string) ([]models.Post, error)
+	GetNavigation(postID uuid.UUID) (*models.SeriesNavigation, error)
+
+	// Series management
+	GetSeries(page, perPage int, search string) ([]models.Series, int64, error)
+	GetSeriesByID(id uuid.UUID) (*models.Series, error)
+	CreateSeries(series *models.Series) error
+	UpdateSeries(series *models.Series) error
+	DeleteSeries(id uuid.UUID) error
+	ListEntries(seriesID uuid.UUID) ([]models.SeriesPost, error)
+	AddPost(seriesID, postID uuid.UUID, position *int) (*models.SeriesPost, error)
+	RemovePost(seriesID, postID uuid.UUID) error
+	ReorderPosts(seriesID uuid.UUID, order []uuid.UUID) ([]models.SeriesPost, error)
+}
+
+type seriesService struct{}
+
+func NewSeriesService() SeriesService {
+	return &seriesService{}
+}
+
+// GetPublicSeries lists active series that have at least one public post
+func (s *seriesService) GetPublicSeries(page, perPage int) ([]models.Series, int64, error) {
+	var series []models.Series
+	var total int64
+
+	query := database.DB.Model(&models.Series{}).
+		Where("is_active = ?", true).
+		Where("id IN (?)", publicSeriesIDs())
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("updated_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&series).Error
+
+	return series, total, err
+}
+
+// GetSeriesBySlug retrieves an active series
+func (s *seriesService) GetSeriesBySlug(slug string) (*models.Series, error) {
+	var series models.Series
+	if err := database.DB.Where("slug = ? AND is_active = ?", slug, true).First(&series).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// GetSeriesPosts returns the public posts of a series in series order, limited to a locale chain
+func (s *seriesService) GetSeriesPosts(seriesID uuid.UUID, locales []string) ([]models.Post, error) {
+	var posts []models.Post
+	err := database.DB.
+		Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+		Where("series_posts.series_id = ? AND posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL",
+			seriesID, "published", true).
+		Order("series_posts.position ASC, posts.published_at ASC").
+		Scopes(postsInLocales(locales)).
+		Preload("Category").
+		Find(&posts).Error
+	if err == nil {
+		err = attachAuthors(posts)
+	}
+	return posts, err
+}
+
+// GetNavigation places a public post within its series, linking the
+// published posts before and after it. Posts outside an active series
+// return gorm.ErrRecordNotFound.
+func (s *seriesService) GetNavigation(postID uuid.UUID) (*models.SeriesNavigation, error) {
+	var entry models.SeriesPost
+	if err := database.DB.Where("post_id = ?", postID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	series, err := s.GetSeriesByID(entry.SeriesID)
+	if err != nil {
+		return nil, err
+	}
+	if !series.IsActive {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var parts []models.SeriesLink
+	if err := database.DB.Table("posts").
+		Select("posts.id, posts.title, posts.slug").
+		Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+		Where("series_posts.series_id = ? AND posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL AND posts.deleted_at IS NULL",
+			series.ID, "published", true).
+		Order("series_posts.position ASC, posts.published_at ASC").
+		Scan(&parts).Error; err != nil {
+		return nil, err
+	}
+
+	navigation := &models.SeriesNavigation{ID: series.ID, Title: series.Title, Slug: series.Slug, Total: len(parts)}
+	for i, part := range parts {
+		if part.ID != postID {
+			continue
+		}
+		navigation.Part = i + 1
+		if i > 0 {
+			navigation.Previous = &parts[i-1]
+		}
+		if i < len(parts)-1 {
+			navigation.Next = &parts[i+1]
+		}
+		return navigation, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// GetSeries lists every series for management
+func (s *seriesService) GetSeries(page, perPage int, search string) ([]models.Series, int64, error) {
+	var series []models.Series
+	var total int64
+
+	query := database.DB.Model(&models.Series{})
+	if search != "" {
+		query = query.Where("title ILIKE ? OR description ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * perPage
+	err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(perPage).
+		Find(&series).Error
+
+	return series, total, err
+}
+
+// GetSeriesByID retrieves a series
+func (s *seriesService) GetSeriesByID(id uuid.UUID) (*models.Series, error) {
+	var series models.Series
+	if err := database.DB.First(&series, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (s *seriesService) CreateSeries(series *models.Series) error {
+	if err := database.DB.Create(series).Error; err != nil {
+		return err
+	}
+	SitemapSvc.MarkDirty()
+	return nil
+}
+
+func (s *seriesService) UpdateSeries(series *models.Series) error {
+	if err := database.DB.Save(series).Error; err != nil {
+		return err
+	}
+	SitemapSvc.MarkDirty()
+	return nil
+}
+
+// DeleteSeries removes a series; its posts are kept
+func (s *seriesService) DeleteSeries(id uuid.UUID) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", id).Delete(&models.SeriesPost{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Series{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	SitemapSvc.MarkDirty()
+	return nil
+}
+
+// ListEntries returns the posts of a series in order, drafts included
+func (s *seriesService) ListEntries(seriesID uuid.UUID) ([]models.SeriesPost, error) {
+	if _, err := s.GetSeriesByID(seriesID); err != nil {
+		return nil, err
+	}
+	var entries []models.SeriesPost
+	err := database.DB.Where("series_id = ?", seriesID).
+		Preload("Post", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id, title, slug, status, public, published_at")
+		}).
+		Order("position ASC, created_at ASC").
+		Find(&entries).Error
+	return entries, err
+}
+
+// AddPost inserts a post into a series at a zero-based position, shifting
+// the posts after it. A nil or out of range position appends.
+func (s *seriesService) AddPost(seriesID, postID uuid.UUID, position *int) (*models.SeriesPost, error) {
+	entry := &models.SeriesPost{SeriesID: seriesID, PostID: postID}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := seriesPostOrder.lock(tx, seriesID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Select("id").First(&models.Post{}, "id = ?", postID).Error; err != nil {
+			return err
+		}
+		var taken int64
+		if err := tx.Model(&models.SeriesPost{}).Where("post_id = ?", postID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return ErrPostInSeries
+		}
+
+		index, err := seriesPostOrder.insertAt(tx, seriesID, existing, position)
+		if err != nil {
+			return err
+		}
+
+		entry.Position = index
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+		return seriesPostOrder.touch(tx, seriesID)
+	}); err != nil {
+		return nil, err
+	}
+
+	SitemapSvc.MarkDirty()
+	return entry, nil
+}
+
+// RemovePost takes a post out of a series and closes the gap it leaves
+func (s *seriesService) RemovePost(seriesID, postID uuid.UUID) error {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := seriesPostOrder.lock(tx, seriesID)
+		if err != nil {
+			return err
+		}
+
+		remaining, found := seriesPostOrder.without(existing, func(entry *models.SeriesPost) bool { return entry.PostID == postID })
+		if !found {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("series_id = ? AND post_id = ?", seriesID, postID).Delete(&models.SeriesPost{}).Error; err != nil {
+			return err
+		}
+		if err := seriesPostOrder.write(tx, remaining); err != nil {
+			return err
+		}
+		return seriesPostOrder.touch(tx, seriesID)
+	}); err != nil {
+		return err
+	}
+
+	SitemapSvc.MarkDirty()
+	return nil
+}
+
+// ReorderPosts sets the order of a series; order must be a permutation of its post IDs
+func (s *seriesService) ReorderPosts(seriesID uuid.UUID, order []uuid.UUID) ([]models.SeriesPost, error) {
+	var reordered []models.SeriesPost
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		existing, err := seriesPostOrder.lock(tx, seriesID)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		reordered, ok = seriesPostOrder.permute(existing, order, func(entry *models.SeriesPost) uuid.UUID { return entry.PostID })
+		if !ok {
+			return ErrSeriesOrderMismatch
+		}
+
+		if err := seriesPostOrder.write(tx, reordered); err != nil {
+			return err
+		}
+		return seriesPostOrder.touch(tx, seriesID)
+	}); err != nil {
+		return nil, err
+	}
+
+	SitemapSvc.MarkDirty()
+	return reordered, nil
+}
+
+// publicSeriesIDs selects the IDs of series with at least one public post
+func publicSeriesIDs() *gorm.DB {
+	return database.DB.Table("series_posts").
+		Select("series_posts.series_id").
+		Joins("JOIN posts ON posts.id = series_posts.post_id").
+		Where("posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL AND posts.deleted_at IS NULL", "published", true)
+}
+
+var SeriesSvc SeriesService = NewSeriesService()
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/cronjob"
+	"go-next/pkg/database"
+	"go-next/pkg/sitemap"
+
+	"github.com/go-co-op/gocron/v2"
+	"golang.org/x/sync/singleflight"
+)
+
+// Child sitemap kinds, used as the prefix of their file names
+const (
+	SitemapPosts      = "posts"
+	SitemapCategories = "categories"
+	SitemapTags       = "tags"
+	SitemapSeries     = "series"
+	SitemapImages     = "images"
+)
+
+const (
+	// sitemapCheckInterval is how often the job looks for publish events to apply
+	sitemapCheckInterval = 5 * time.Minute
+
+	// sitemapMaxAge forces a regeneration to pick up changes made outside the blog service
+	sitemapMaxAge = 24 * time.Hour
+
+	// sitemapMediaBatch bounds the post IDs per media query
+	sitemapMediaBatch = 1000
+
+	// sitemapGenerationKey counts the changes to sitemap content, so every
+	// instance knows whether the sitemaps it built are still current
+	sitemapGenerationKey = "sitemap:generation"
+)
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
+
+type SitemapService interface {
+	Generate(ctx context.Context) error
+	GetIndex() (*SitemapDocument, error)
+	GetSitemap(name string) (*SitemapDocument, error)
+	MarkDirty()
+	Refresh(ctx context.Context) (bool, error)
+	Schedule() error
+}
+
+// SitemapDocument is a rendered sitemap file
+type SitemapDocument struct {
+	Body         []byte
+	LastModified time.Time
+}
+
+type sitemapService struct {
+	mu          sync.RWMutex
+	documents   map[string]*SitemapDocument
+	names       []string
+	generatedAt time.Time
+	// generation is the shared generation the documents were built from
+	generation string
+	// dirty marks changes that could not be recorded in Redis
+	dirty      atomic.Bool
+	generating singleflight.Group
+}
+
+func NewSitemapService() SitemapService {
+	return &sitemapService{}
+}
+
+// Generate rebuilds every child sitemap from the database. Concurrent calls
+// share one run.
+func (s *sitemapService) Generate(ctx context.Context) error {
+	_, err, _ := s.generating.Do("generate", func() (interface{}, error) {
+		return nil, s.generate(ctx)
+	})
+	return err
+}
+
+func (s *sitemapService) generate(ctx context.Context) error {
+	// Read and clear first so a publish during generation triggers another run
+	generation, err := s.sharedGeneration(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to read the sitemap generation: %v", err)
+	}
+	s.dirty.Store(false)
+
+	site := config.GetConfig().Site
+	sets := make(map[string][]sitemap.URL)
+
+	if sets[SitemapPosts], err = s.postURLs(ctx, site, false); err != nil {
+		return err
+	}
+	if sets[SitemapCategories], err = s.categoryURLs(ctx, site); err != nil {
+		return err
+	}
+	if sets[SitemapTags], err = s.tagURLs(ctx, site); err != nil {
+		return err
+	}
+	if sets[SitemapSeries], err = s.seriesURLs(ctx, site); err != nil {
+		return err
+	}
+	if site.SitemapImages {
+		if sets[SitemapImages], err = s.postURLs(ctx, site, true); err != nil {
+			return err
+		}
+	}
+
+	documents := make(map[string]*SitemapDocument)
+	names := make([]string, 0)
+	for _, kind := range []string{SitemapPosts, SitemapCategories, SitemapTags, SitemapSeries, SitemapImages} {
+		urls, ok := sets[kind]
+		if !ok {
+			continue
+		}
+		for page := 1; page <= sitemap.Pages(len(urls)); page++ {
+			pageURLs := sitemap.Page(urls, page)
+			body, err := sitemap.URLSet(pageURLs)
+			if err != nil {
+				return err
+			}
+			name := kind + "-" + strconv.Itoa(page)
+			documents[name] = &SitemapDocument{Body: body, LastModified: latestLastMod(pageURLs)}
+			names = append(names, name)
+		}
+	}
+
+	s.mu.Lock()
+	s.documents = documents
+	s.names = names
+	s.generatedAt = time.Now()
+	s.generation = generation
+	s.mu.Unlock()
+
+	return nil
+}
+
+// GetIndex renders the sitemap index, pointing at child sitemaps on the site URL
+func (s *sitemapService) GetIndex() (*SitemapDocument, error) {
+	if err := s.ensureGenerated(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	site := config.GetConfig().Site
+	entries := make([]sitemap.Entry, 0, len(s.names))
+	var lastModified time.Time
+	for _, name := range s.names {
+		doc := s.documents[name]
+		entries = append(entries, sitemap.Entry{Loc: site.SitemapURL(name), LastMod: doc.LastModified})
+		if doc.LastModified.After(lastModified) {
+			lastModified = doc.LastModified
+		}
+	}
+
+	body, err := sitemap.Index(entries)
+	if err != nil {
+		return nil, err
+	}
+	if lastModified.IsZero() {
+		lastModified = s.generatedAt
+	}
+	return &SitemapDocument{Body: body, LastModified: lastModified}, nil
+}
+
+// GetSitemap returns a child sitemap by name, such as "posts-1"
+func (s *sitemapService) GetSitemap(name string) (*SitemapDocument, error) {
+	if err := s.ensureGenerated(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	doc, ok := s.documents[name]
+	if !ok {
+		return nil, ErrSitemapNotFound
+	}
+	if doc.LastModified.IsZero() {
+		return &SitemapDocument{Body: doc.Body, LastModified: s.generatedAt}, nil
+	}
+	return doc, nil
+}
+
+// MarkDirty schedules a regeneration on the next run of the sitemap job of
+// every instance, by moving the shared generation on in Redis
+func (s *sitemapService) MarkDirty() {
+	if GlobalRedisClient == nil {
+		s.dirty.Store(true)
+		return
+	}
+	if _, err := GlobalRedisClient.Incr(context.Background(), sitemapGenerationKey); err != nil {
+		log.Printf("Warning: failed to mark sitemaps as changed: %v", err)
+		s.dirty.Store(true)
+	}
+}
+
+// Refresh regenerates the sitemaps if their content changed on any
+// instance since they were built, or they are older than sitemapMaxAge,
+// reporting whether it did
+func (s *sitemapService) Refresh(ctx context.Context) (bool, error) {
+	s.mu.RLock()
+	stale := time.Since(s.generatedAt) > sitemapMaxAge
+	built := s.generation
+	s.mu.RUnlock()
+	generation, err := s.sharedGeneration(ctx)
+	if err != nil {
+		log.Printf("Warning: failed to read the sitemap generation: %v", err)
+	}
+	if !s.dirty.Load() && !stale && generation == built {
+		return false, nil
+	}
+	if err := s.Generate(ctx); err != nil {
+		s.dirty.Store(true)
+		return false, err
+	}
+	return true, nil
+}
+
+// Schedule registers the job that regenerates sitemaps after publish events
+func (s *sitemapService) Schedule() error {
+	_, err := cronjob.AddJob(gocron.DurationJob(sitemapCheckInterval), func() {
+		if _, err := s.Refresh(context.Background()); err != nil {
+			log.Printf("Warning: failed to generate sitemaps: %v", err)
+		}
+	}, gocron.WithName("sitemap_generate"))
+	return err
+}
+
+// sharedGeneration returns the generation of sitemap content recorded in
+// Redis, or an empty string without Redis
+func (s *sitemapService) sharedGeneration(ctx context.Context) (string, error) {
+	if GlobalRedisClient == nil {
+		return "", nil
+	}
+	if _, err := GlobalRedisClient.SetNX(ctx, sitemapGenerationKey, 0, 0); err != nil {
+		return "", err
+	}
+	return GlobalRedisClient.Get(ctx, sitemapGenerationKey)
+}
+
+// ensureGenerated builds the sitemaps on first use; concurrent first
+// requests wait for the same build
+func (s *sitemapService) ensureGenerated() error {
+	s.mu.RLock()
+	generated := s.documents != nil
+	s.mu.RUnlock()
+	if generated {
+		return nil
+	}
+	return s.Generate(context.Background())
+}
+
+// postURLs lists public posts; with images set it lists only posts with image media
+func (s *sitemapService) postURLs(ctx context.Context, site config.SiteConfig, images bool) ([]sitemap.URL, error) {
+	var posts []models.Post
+	if err := database.DB.WithContext(ctx).
+		Select("id, slug, locale, translation_group_id, updated_at").
+		Where("status = ? AND public = ? AND published_at IS NOT NULL", "published", true).
+		Order("published_at DESC").
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	translations, err := loadPostTranslations(postTranslationGroups(posts))
+	if err != nil {
+		return nil, err
+	}
+	postURL := func(post *models.Post) sitemap.URL {
+		u := sitemap.URL{Loc: site.LocalizedPostURL(post.Locale, post.Slug), LastMod: post.UpdatedAt}
+		if post.TranslationGroupID != nil {
+			u.Alternates = sitemapAlternates(translations[*post.TranslationGroupID], site.Language)
+		}
+		return u
+	}
+
+	if !images {
+		urls := make([]sitemap.URL, 0, len(posts))
+		for i := range posts {
+			urls = append(urls, postURL(&posts[i]))
+		}
+		return urls, nil
+	}
+
+	urls := make([]sitemap.URL, 0)
+	for start := 0; start < len(posts); start += sitemapMediaBatch {
+		end := start + sitemapMediaBatch
+		if end > len(posts) {
+			end = len(posts)
+		}
+		batch := posts[start:end]
+		mediaByPost, err := loadMediaFor(models.MediableTypePost, postIDs(batch))
+		if err != nil {
+			return nil, err
+		}
+		for i, post := range batch {
+			u := postURL(&batch[i])
+			for _, media := range mediaByPost[post.ID] {
+				if media.URL != "" && media.IsImage() {
+					u.Images = append(u.Images, sitemap.Image{Loc: media.URL})
+				}
+			}
+			if len(u.Images) > 0 {
+				urls = append(urls, u)
+			}
+		}
+	}
+	return urls, nil
+}
+
+func (s *sitemapService) categoryURLs(ctx context.Context, site config.SiteConfig) ([]sitemap.URL, error) {
+	var categories []models.Category
+	if err := database.DB.WithContext(ctx).
+		Select("id, slug, locale, translation_group_id, updated_at").
+		Where("is_active = ?", true).
+		Order("record_left ASC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	translations, err := loadCategoryTranslations(categoryTranslationGroups(categories))
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemap.URL, 0, len(categories))
+	for _, category := range categories {
+		u := sitemap.URL{Loc: site.LocalizedCategoryURL(category.Locale, category.Slug), LastMod: category.UpdatedAt}
+		if category.TranslationGroupID != nil {
+			u.Alternates = sitemapAlternates(translations[*category.TranslationGroupID], site.Language)
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// tagURLs lists active tags that have at least one public post
+func (s *sitemapService) tagURLs(ctx context.Context, site config.SiteConfig) ([]sitemap.URL, error) {
+	var tags []models.Tag
+	if err := database.DB.WithContext(ctx).
+		Select("id, slug, updated_at").
+		Where("is_active = ? AND deleted_at IS NULL", true).
+		Where("id IN (?)", database.DB.Table("post_tags").
+			Select("post_tags.tag_id").
+			Joins("JOIN posts ON posts.id = post_tags.post_id").
+			Where("posts.status = ? AND posts.public = ? AND posts.published_at IS NOT NULL AND posts.deleted_at IS NULL", "published", true)).
+		Order("name ASC").
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemap.URL, 0, len(tags))
+	for _, tag := range tags {
+		urls = append(urls, sitemap.URL{Loc: site.TagURL(tag.Slug), LastMod: tag.UpdatedAt})
+	}
+	return urls, nil
+}
+
+// seriesURLs lists active series that have at least one public post
+func (s *sitemapService) seriesURLs(ctx context.Context, site config.SiteConfig) ([]sitemap.URL, error) {
+	var series []models.Series
+	if err := database.DB.WithContext(ctx).
+		Select("id, slug, updated_at").
+		Where("is_active = ?", true).
+		Where("id IN (?)", publicSeriesIDs()).
+		Order("title ASC").
+		Find(&series).Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemap.URL, 0, len(series))
+	for _, item := range series {
+		urls = append(urls, sitemap.URL{Loc: site.SeriesURL(item.Slug), LastMod: item.UpdatedAt})
+	}
+	return urls, nil
+}
+
+// sitemapAlternates converts the hreflang alternates of a page for a sitemap
+func sitemapAlternates(translations []Translation, defaultLocale string) []sitemap.Alternate {
+	alternates := hreflangAlternates(translations, defaultLocale)
+	if len(alternates) == 0 {
+		return nil
+	}
+	result := make([]sitemap.Alternate, 0, len(alternates))
+	for _, alternate := range alternates {
+		result = append(result, sitemap.Alternate{Hreflang: alternate.Hreflang, Href: alternate.URL})
+	}
+	return result
+}
+
+func latestLastMod(urls []sitemap.URL) time.Time {
+	var latest time.Time
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
+
+var SitemapSvc SitemapService = NewSitemapService()
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/spam"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// spamScreenTimeout bounds the screening of a comment, external services included
+const spamScreenTimeout = 5 * time.Second
+
+// spamDocumentsToken is the row of the token table that counts documents;
+// the tokenizer never yields a token with a space
+const spamDocumentsToken = " documents"
+
+// What the spam filter learned from a moderated comment
+const (
+	spamLabelSpam = "spam"
+	spamLabelHam  = "ham"
+)
+
+type SpamService interface {
+	Screen(comment *models.Comment) error
+	Learn(comment *models.Comment) error
+	Classifiers() []spam.Classifier
+}
+
+type spamService struct {
+	once        sync.Once
+	classifiers []spam.Classifier
+}
+
+// NewSpamService creates the comment screener. Without classifiers it uses the
+// heuristics, the naive-Bayes filter and, when configured, the external service.
+func NewSpamService(classifiers ...spam.Classifier) SpamService {
+	return &spamService{classifiers: classifiers}
+}
+
+// Classifiers returns the classifiers comments are screened with, in order
+func (s *spamService) Classifiers() []spam.Classifier {
+	s.once.Do(func() {
+		if len(s.classifiers) == 0 {
+			s.classifiers = defaultSpamClassifiers(config.GetConfig().Comments.Spam)
+		}
+	})
+	return s.classifiers
+}
+
+// Screen scores a new comment and stores the scores on it. Likely spam is
+// marked as spam, and doubtful comments wait for a moderator even when they
+// would have been approved.
+func (s *spamService) Screen(comment *models.Comment) error {
+	cfg := config.GetConfig().Comments.Spam
+	if !cfg.Enabled {
+		return nil
+	}
+
+	submission, err := spamSubmission(comment)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spamScreenTimeout)
+	defer cancel()
+	result := spam.Screen(ctx, submission, s.Classifiers()...)
+	for _, failure := range result.Errors {
+		log.Printf("Warning: spam classifier failed: %s", failure)
+	}
+
+	report, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	comment.SpamScore = result.Score
+	comment.SpamReport = string(report)
+
+	switch {
+	case result.Score >= cfg.SpamThreshold:
+		comment.MarkSpam()
+	case result.Score >= cfg.ReviewThreshold && comment.IsApproved():
+		comment.Status = models.CommentStatusPending
+		comment.ApprovedAt = nil
+	}
+	return nil
+}
+
+// Learn trains the filters with the moderation of a comment: approved comments
+// are ham, rejected and spam ones are spam. A decision that is overturned is
+// unlearned first.
+func (s *spamService) Learn(comment *models.Comment) error {
+	label := ""
+	switch comment.Status {
+	case models.CommentStatusApproved:
+		label = spamLabelHam
+	case models.CommentStatusRejected, models.CommentStatusSpam:
+		label = spamLabelSpam
+	}
+	if label == "" || label == comment.SpamLabel {
+		return nil
+	}
+
+	ctx := context.Background()
+	submission := spam.Submission{Content: comment.Content}
+	for _, classifier := range s.Classifiers() {
+		trainer, ok := classifier.(spam.Trainer)
+		if !ok {
+			continue
+		}
+		if comment.SpamLabel != "" {
+			if err := trainer.Untrain(ctx, submission, comment.SpamLabel == spamLabelSpam); err != nil {
+				return err
+			}
+		}
+		if err := trainer.Train(ctx, submission, label == spamLabelSpam); err != nil {
+			return err
+		}
+	}
+
+	comment.SpamLabel = label
+	return database.DB.Model(&models.Comment{}).Where("id = ?", comment.ID).UpdateColumn("spam_label", label).Error
+}
+
+func defaultSpamClassifiers(cfg config.SpamConfig) []spam.Classifier {
+	classifiers := []spam.Classifier{
+		&spam.Heuristics{
+			MaxLinks:       cfg.MaxLinks,
+			BlockedWords:   cfg.BlockedWords,
+			BlockedDomains: cfg.BlockedDomains,
+			MinFillTime:    time.Duration(cfg.MinFillSeconds) * time.Second,
+			MinInterval:    time.Duration(cfg.MinIntervalSeconds) * time.Second,
+		},
+		spam.NewBayes(spamTokenStore{}),
+	}
+	if cfg.ExternalURL != "" {
+		classifiers = append(classifiers, spam.NewExternal(cfg.ExternalURL, cfg.ExternalKey, nil))
+	}
+	return classifiers
+}
+
+// spamSubmission describes a new or edited comment for the classifiers
+func spamSubmission(comment *models.Comment) (spam.Submission, error) {
+	submission := spam.Submission{
+		Content:  comment.Content,
+		AuthorID: comment.UserID.String(),
+	}
+
+	var author models.User
+	if err := database.DB.Select("id, username, email").First(&author, "id = ?", comment.UserID).Error; err == nil {
+		submission.Author = author.Username
+		submission.Email = author.Email
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return submission, err
+	}
+
+	var post models.Post
+	if err := database.DB.Select("id, slug, locale").First(&post, "id = ?", comment.PostID).Error; err == nil {
+		submission.Permalink = config.GetConfig().Site.LocalizedPostURL(post.Locale, post.Slug)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return submission, err
+	}
+
+	var previous models.Comment
+	// An edited comment is not its own previous comment
+	if err := database.DB.Select("created_at").
+		Where("user_id = ? AND id <> ?", comment.UserID, comment.ID).
+		Order("created_at DESC").
+		First(&previous).Error; err == nil {
+		submission.SincePrevious = time.Since(previous.CreatedAt)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return submission, err
+	}
+
+	if comment.Submission != nil {
+		submission.IP = comment.Submission.IP
+		submission.UserAgent = comment.Submission.UserAgent
+		submission.Honeypot = comment.Submission.Honeypot
+		if comment.Submission.RenderedAt != nil {
+			if fill := time.Since(*comment.Submission.RenderedAt); fill > 0 {
+				submission.FillTime = fill
+			}
+		}
+	}
+	return submission, nil
+}
+
+// spamTokenStore keeps the naive-Bayes token counts in the spam_tokens table
+type spamTokenStore struct{}
+
+func (spamTokenStore) Counts(ctx context.Context, tokens []string) (map[string]spam.TokenCounts, spam.TokenCounts, error) {
+	var rows []models.SpamToken
+	if err := database.DB.WithContext(ctx).
+		Where("token IN ?", append([]string{spamDocumentsToken}, tokens...)).
+		Find(&rows).Error; err != nil {
+		return nil, spam.TokenCounts{}, err
+	}
+
+	counts := make(map[string]spam.TokenCounts, len(rows))
+	var docs spam.TokenCounts
+	for _, row := range rows {
+		c := spam.TokenCounts{Spam: row.SpamCount, Ham: row.HamCount}
+		if row.Token == spamDocumentsToken {
+			docs = c
+			continue
+		}
+		counts[row.Token] = c
+	}
+	return counts, docs, nil
+}
+
+func (spamTokenStore) Add(ctx context.Context, tokens []string, isSpam bool, delta int64) error {
+	column := "ham_count"
+	if isSpam {
+		column = "spam_count"
+	}
+
+	rows := make([]models.SpamToken, 0, len(tokens)+1)
+	for _, token := range append([]string{spamDocumentsToken}, tokens...) {
+		row := models.SpamToken{Token: token}
+		if delta > 0 {
+			if isSpam {
+				row.SpamCount = delta
+			} else {
+				row.HamCount = delta
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			column: gorm.Expr("GREATEST(spam_tokens."+column+" + ?, 0)", delta),
+		}),
+	}).Create(&rows).Error
+}
+
+var SpamSvc SpamService = NewSpamService()
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/email"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidSubscription     = errors.New("invalid subscription")
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+)
+
+const (
+	// commentNotificationExcerptLength caps the comment text quoted in notifications
+	commentNotificationExcerptLength = 200
+	// commentEmailWorkers is the number of comment notification emails sent at once
+	commentEmailWorkers = 4
+	// commentEmailQueueSize is the number of comment notification emails waiting to be sent
+	commentEmailQueueSize = 256
+)
+
+// GlobalEmailer sends the emails of the running server; email notifications
+// are skipped while it is nil
+var GlobalEmailer *email.EmailService
+
+type SubscriptionService interface {
+	Subscribe(userID, postID uuid.UUID, commentID *uuid.UUID) (*models.Subscription, error)
+	Unsubscribe(userID, id uuid.UUID) error
+	GetByUnsubscribeToken(token string) (*models.Subscription, error)
+	UnsubscribeByToken(token string) (*models.Subscription, error)
+	GetUserSubscriptions(userID uuid.UUID, page, perPage int) ([]models.Subscription, int64, error)
+}
+
+type subscriptionService struct{}
+
+func NewSubscriptionService() SubscriptionService {
+	return &subscriptionService{}
+}
+
+// Subscribe follows the comments of a published post, or the replies in one
+// thread when commentID is set. Subscribing twice returns the existing subscription.
+func (s *subscriptionService) Subscribe(userID, postID uuid.UUID, commentID *uuid.UUID) (*models.Subscription, error) {
+	var post models.Post
+	if err := database.DB.Select("id, status, published_at").First(&post, "id = ?", postID).Error; err != nil {
+		return nil, err
+	}
+	if !post.IsPublished() {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if commentID != nil {
+		var comment models.Comment
+		if err := database.DB.Select("id, post_id, status, is_public, hidden_at").First(&comment, "id = ?", *commentID).Error; err != nil {
+			return nil, err
+		}
+		if comment.PostID != postID {
+			return nil, fmt.Errorf("%w: the comment is on another post", ErrInvalidSubscription)
+		}
+		if !comment.IsVisible() {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+	return subscribe(userID, postID, commentID, models.SubscriptionReasonManual)
+}
+
+// Unsubscribe cancels one of the user's subscriptions
+func (s *subscriptionService) Unsubscribe(userID, id uuid.UUID) error {
+	result := database.DB.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&models.Subscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetByUnsubscribeToken finds the subscription of an email's unsubscribe
+// link, returning nil when it is already cancelled
+func (s *subscriptionService) GetByUnsubscribeToken(token string) (*models.Subscription, error) {
+	if token == "" {
+		return nil, ErrInvalidUnsubscribeToken
+	}
+	var subscription models.Subscription
+	if err := database.DB.Preload("Post", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, title, slug, locale")
+	}).First(&subscription, "unsubscribe_token = ?", token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// UnsubscribeByToken cancels the subscription of an email's unsubscribe link,
+// without a login. Using a link twice is not an error.
+func (s *subscriptionService) UnsubscribeByToken(token string) (*models.Subscription, error) {
+	subscription, err := s.GetByUnsubscribeToken(token)
+	if err != nil || subscription == nil {
+		return nil, err
+	}
+	if err := database.DB.Unscoped().Delete(&models.Subscription{}, "id = ?", subscription.ID).Error; err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// GetUserSubscriptions lists the user's subscriptions, newest first
+func (s *subscriptionService) GetUserSubscriptions(userID uuid.UUID, page, perPage int) ([]models.Subscription, int64, error) {
+	query := database.DB.Model(&models.Subscription{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var subscriptions []models.Subscription
+	err := query.Preload("Post", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id, title, slug, locale")
+	}).
+		Order("created_at DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&subscriptions).Error
+	return subscriptions, total, err
+}
+
+// UnsubscribeURL is the one-click link that cancels a subscription
+func UnsubscribeURL(subscription *models.Subscription) string {
+	return config.GetConfig().Site.APIURL + "/subscriptions/unsubscribe?token=" + url.QueryEscape(subscription.UnsubscribeToken)
+}
+
+// newUnsubscribeToken returns a random token for an unsubscribe link
+func newUnsubscribeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// subscribe finds or creates a subscription. A concurrent subscribe that
+// inserts the same subscription first is returned instead.
+func subscribe(userID, postID uuid.UUID, commentID *uuid.UUID, reason string) (*models.Subscription, error) {
+	find := func() (*models.Subscription, error) {
+		query := database.DB.Where("user_id = ? AND post_id = ?", userID, postID)
+		if commentID == nil {
+			query = query.Where("comment_id IS NULL")
+		} else {
+			query = query.Where("comment_id = ?", *commentID)
+		}
+		var subscription models.Subscription
+		if err := query.First(&subscription).Error; err != nil {
+			return nil, err
+		}
+		return &subscription, nil
+	}
+
+	subscription, err := find()
+	if err == nil {
+		return subscription, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	token, err := newUnsubscribeToken()
+	if err != nil {
+		return nil, err
+	}
+	subscription = &models.Subscription{UserID: userID, PostID: postID, CommentID: commentID, Reason: reason, UnsubscribeToken: token}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(subscription)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return find()
+	}
+	return subscription, nil
+}
+
+// subscribePostAuthors subscribes the creator and co-authors of a post to its comments
+func subscribePostAuthors(post *models.Post) {
+	var authorIDs []uuid.UUID
+	if post.CreatedBy != nil {
+		authorIDs = append(authorIDs, *post.CreatedBy)
+	}
+	var coAuthors []uuid.UUID
+	if err := database.DB.Model(&models.PostAuthor{}).Where("post_id = ?", post.ID).Pluck("user_id", &coAuthors).Error; err != nil {
+		log.Printf("Warning: failed to subscribe authors of post %s: %v", post.ID, err)
+		return
+	}
+	for _, userID := range uniqueUUIDs(append(authorIDs, coAuthors...)) {
+		if _, err := subscribe(userID, post.ID, nil, models.SubscriptionReasonAuthor); err != nil {
+			log.Printf("Warning: failed to subscribe author %s to post %s: %v", userID, post.ID, err)
+		}
+	}
+}
+
+// subscribeCommenter subscribes the author of a comment to the replies it gets
+func subscribeCommenter(comment *models.Comment) {
+	commentID := comment.ID
+	if _, err := subscribe(comment.UserID, comment.PostID, &commentID, models.SubscriptionReasonCommenter); err != nil {
+		log.Printf("Warning: failed to subscribe commenter to comment %s: %v", comment.ID, err)
+	}
+}
+
+// notifyCommentSubscribers tells the subscribers of a post, and of the
+// threads a comment replies in, about the comment once, when it becomes approved
+func notifyCommentSubscribers(comment *models.Comment, previousStatus string) {
+	if comment.Status != models.CommentStatusApproved || previousStatus == models.CommentStatusApproved {
+		return
+	}
+	if err := fanOutComment(comment); err != nil {
+		log.Printf("Warning: failed to notify subscribers of comment %s: %v", comment.ID, err)
+	}
+}
+
+// fanOutComment notifies every subscriber once over in-app, WebSocket and
+// email. The commenter and the users the comment mentions are skipped; the
+// latter already have a mention notification.
+func fanOutComment(comment *models.Comment) error {
+	var subscriptions []models.Subscription
+	if err := database.DB.
+		Where("post_id = ? AND user_id <> ?", comment.PostID, comment.UserID).
+		Where(`comment_id IS NULL OR comment_id IN (
+			SELECT ancestor.id FROM comments ancestor JOIN comments reply ON reply.id = ?
+			WHERE ancestor.post_id = reply.post_id AND ancestor.record_left < reply.record_left AND ancestor.record_right > reply.record_right)`, comment.ID).
+		Where(`user_id NOT IN (
+			SELECT user_id FROM mentions WHERE mentionable_type = ? AND mentionable_id = ? AND deleted_at IS NULL)`, models.MentionableTypeComment, comment.ID).
+		// Thread subscriptions first, so a reply is described as one
+		Order("comment_id IS NULL ASC, created_at ASC").
+		Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	recipients := make(map[uuid.UUID]models.Subscription, len(subscriptions))
+	var userIDs []uuid.UUID
+	for _, subscription := range subscriptions {
+		if _, ok := recipients[subscription.UserID]; !ok {
+			recipients[subscription.UserID] = subscription
+			userIDs = append(userIDs, subscription.UserID)
+		}
+	}
+	var users []models.User
+	if err := database.DB.Select("id, username, email").
+		Where("id IN ? AND is_active = ?", userIDs, true).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	var post models.Post
+	if err := database.DB.Select("id, title, slug, locale").First(&post, "id = ?", comment.PostID).Error; err != nil {
+		return err
+	}
+	byline := "Someone"
+	authors, err := loadAuthors([]uuid.UUID{comment.UserID})
+	if err != nil {
+		return err
+	}
+	if author, ok := authors[comment.UserID]; ok {
+		byline = author.DisplayName
+	}
+	url := mentionURL(&post, models.MentionableTypeComment, comment.ID)
+	excerpt := truncateText(stripTags(comment.Content), commentNotificationExcerptLength)
+
+	for _, user := range users {
+		subscription := recipients[user.ID]
+		title := fmt.Sprintf("%s commented on %q", byline, post.Title)
+		if subscription.IsThread() {
+			title = fmt.Sprintf("%s replied in a thread you follow on %q", byline, post.Title)
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"subscription_id": subscription.ID,
+			"post_id":         post.ID,
+			"comment_id":      comment.ID,
+			"url":             url,
+		})
+		if err != nil {
+			return err
+		}
+		if err := notifyUser(&models.Notification{
+			UserID:  user.ID,
+			Type:    string(models.NotificationTypeComment),
+			Title:   title,
+			Message: excerpt,
+			Data:    string(data),
+		}); err != nil {
+			return err
+		}
+
+		if GlobalEmailer == nil || user.Email == "" {
+			continue
+		}
+		queueCommentEmail(commentEmail{
+			user:           user,
+			title:          title,
+			excerpt:        excerpt,
+			commentURL:     url,
+			unsubscribeURL: UnsubscribeURL(&subscription),
+		})
+	}
+	return nil
+}
+
+// commentEmail is a comment notification waiting to be mailed
+type commentEmail struct {
+	user           models.User
+	title          string
+	excerpt        string
+	commentURL     string
+	unsubscribeURL string
+}
+
+// commentEmails feeds the fixed set of workers that mail comment notifications,
+// so a comment with many subscribers does not start a sender per recipient
+var (
+	commentEmails           = make(chan commentEmail, commentEmailQueueSize)
+	startCommentEmailSender sync.Once
+)
+
+// queueCommentEmail hands a comment notification to the email workers,
+// waiting while the queue is full
+func queueCommentEmail(message commentEmail) {
+	startCommentEmailSender.Do(func() {
+		for i := 0; i < commentEmailWorkers; i++ {
+			go func() {
+				for message := range commentEmails {
+					sendCommentEmail(message)
+				}
+			}()
+		}
+	})
+	commentEmails <- message
+}
+
+// sendCommentEmail mails a comment notification with a one-click
+// List-Unsubscribe header (RFC 8058)
+func sendCommentEmail(message commentEmail) {
+	body := email.CommentNotificationTemplate(message.user.Username, message.title, message.excerpt, message.commentURL, message.unsubscribeURL)
+	if err := GlobalEmailer.SendEmailWithHeaders(message.user.Email, message.title, body, map[string]string{
+		"List-Unsubscribe":      "<" + message.unsubscribeURL + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}); err != nil {
+		log.Printf("Warning: failed to email comment notification to user %s: %v", message.user.ID, err)
+	}
+}
+
+var SubscriptionSvc SubscriptionService = NewSubscriptionService()
@@ -98,8 +98,14 @@ func (s *tagService) CreateTag(ctx context.Context, tag *models.Tag) error {
 		fmt.Printf("Warning: failed to cache tag %d: %v\n", tag.ID, err)
 	}
 
+	// Keep the search index in sync
+	if err := SearchSvc.IndexTag(tag); err != nil {
+		fmt.Printf("Warning: failed to index tag %d: %v\n", tag.ID, err)
+	}
+
 	// Invalidate related caches
 	s.invalidateRelatedCaches(ctx)
+	SitemapSvc.MarkDirty()
 
 	return nil
 }
@@ -186,8 +192,14 @@ func (s *tagService) UpdateTag(ctx context.Context, tag *models.Tag) error {
 		fmt.Printf("Warning: failed to cache tag %d: %v\n", tag.ID, err)
 	}
 
+	// Keep the search index in sync
+	if err := SearchSvc.IndexTag(tag); err != nil {
+		fmt.Printf("Warning: failed to index tag %d: %v\n", tag.ID, err)
+	}
+
 	// Invalidate related caches
 	s.invalidateRelatedCaches(ctx)
+	SitemapSvc.MarkDirty()
 
 	return nil
 }
@@ -207,6 +219,12 @@ func (s *tagService) DeleteTag(ctx context.Context, id uint64) error {
 	s.invalidateTagCaches(ctx, id)
 	s.invalidateRelatedCaches(ctx)
 
+	// Keep the search index in sync
+	if err := SearchSvc.DeleteTag(id); err != nil {
+		fmt.Printf("Warning: failed to remove tag %d from search index: %v\n", id, err)
+	}
+	SitemapSvc.MarkDirty()
+
 	return nil
 }
 
@@ -41,6 +41,11 @@ type TokenCacheService interface {
 	CacheRefreshToken(token *models.RefreshToken) error
 	InvalidateRefreshToken(id uuid.UUID) error
 	InvalidateUserRefreshTokens(userID uuid.UUID) error
+
+	// User status caching
+	IsUserActive(userID uuid.UUID) (bool, error)
+	CacheUserActive(userID uuid.UUID, active bool) error
+	InvalidateUserActive(userID uuid.UUID) error
 }
 
 type tokenCacheService struct{}
@@ -301,4 +306,39 @@ func (t *tokenCacheService) InvalidateUserRefreshTokens(userID uuid.UUID) error
 	return CacheSvc.Delete(cacheKey)
 }
 
+// User status caching methods
+
+// IsUserActive reports whether the user may use their access tokens. The
+// status is cached briefly, so requests do not each read it from the database;
+// suspensions invalidate it at once.
+func (t *tokenCacheService) IsUserActive(userID uuid.UUID) (bool, error) {
+	cacheKey := fmt.Sprintf(CacheKeyUserActive, userID.String())
+
+	var active bool
+	if err := CacheSvc.Get(cacheKey, &active); err == nil {
+		return active, nil
+	}
+
+	// Cache miss, get from database; deleted users are inactive
+	var users []models.User
+	if err := database.DB.Select("id, is_active").Where("id = ?", userID).Limit(1).Find(&users).Error; err != nil {
+		return false, err
+	}
+	active = len(users) > 0 && users[0].IsActive
+
+	// Cache the result
+	t.CacheUserActive(userID, active)
+	return active, nil
+}
+
+func (t *tokenCacheService) CacheUserActive(userID uuid.UUID, active bool) error {
+	cacheKey := fmt.Sprintf(CacheKeyUserActive, userID.String())
+	return CacheSvc.Set(cacheKey, active, CacheDurationUserActive)
+}
+
+func (t *tokenCacheService) InvalidateUserActive(userID uuid.UUID) error {
+	cacheKey := fmt.Sprintf(CacheKeyUserActive, userID.String())
+	return CacheSvc.Delete(cacheKey)
+}
+
 var TokenCacheSvc TokenCacheService = NewTokenCacheService()
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-next/internal/models"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+	"go-next/pkg/locale"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Visibility of the translations that take precedence over a fallback, for the alias t
+const (
+	translatedPostVisible     = "t.status = 'published' AND t.public = true AND t.published_at IS NOT NULL"
+	translatedCategoryVisible = "t.is_active = true"
+)
+
+var (
+	ErrUnsupportedLocale  = errors.New("unsupported locale")
+	ErrTranslationExists  = errors.New("translation group already has a translation in this locale")
+	ErrInvalidTranslation = errors.New("invalid translation")
+)
+
+// Translation is one language version of a post or category
+type Translation struct {
+	ID     uuid.UUID `json:"id"`
+	Locale string    `json:"locale"`
+	Title  string    `json:"title"`
+	Slug   string    `json:"slug"`
+	URL    string    `json:"url"`
+}
+
+// Alternate is an hreflang link to a language version of a page
+type Alternate struct {
+	Hreflang string `json:"hreflang"`
+	URL      string `json:"url"`
+}
+
+type TranslationService interface {
+	GetPostTranslations(post *models.Post) ([]Translation, error)
+	GetCategoryTranslations(category *models.Category) ([]Translation, error)
+	LinkTranslation(translatableType string, id, translationOf uuid.UUID) error
+	UnlinkTranslation(translatableType string, id uuid.UUID) error
+}
+
+type translationService struct{}
+
+func NewTranslationService() TranslationService {
+	return &translationService{}
+}
+
+// SiteLocales returns the matcher of the site's content locales
+func SiteLocales() *locale.Matcher {
+	site := config.GetConfig().Site
+	return locale.NewMatcher(site.Locales, site.Language)
+}
+
+// GetPostTranslations lists the public versions of a post, itself included, by locale
+func (s *translationService) GetPostTranslations(post *models.Post) ([]Translation, error) {
+	if post.TranslationGroupID == nil {
+		return []Translation{postTranslation(post, config.GetConfig().Site)}, nil
+	}
+	groups, err := loadPostTranslations([]uuid.UUID{*post.TranslationGroupID})
+	if err != nil {
+		return nil, err
+	}
+	return groups[*post.TranslationGroupID], nil
+}
+
+// GetCategoryTranslations lists the active versions of a category, itself included, by locale
+func (s *translationService) GetCategoryTranslations(category *models.Category) ([]Translation, error) {
+	if category.TranslationGroupID == nil {
+		return []Translation{categoryTranslation(category, config.GetConfig().Site)}, nil
+	}
+	groups, err := loadCategoryTranslations([]uuid.UUID{*category.TranslationGroupID})
+	if err != nil {
+		return nil, err
+	}
+	return groups[*category.TranslationGroupID], nil
+}
+
+// LinkTranslation makes a post or category a translation of another one,
+// joining its translation group. A group holds one row per locale.
+func (s *translationService) LinkTranslation(translatableType string, id, translationOf uuid.UUID) error {
+	table, err := translatableTable(translatableType)
+	if err != nil {
+		return err
+	}
+	if id == translationOf {
+		return fmt.Errorf("%w: a %s cannot be a translation of itself", ErrInvalidTranslation, translatableType)
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var rows []translatableRow
+		if err := tx.Table(table).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id, locale, translation_group_id").
+			Where("id IN ? AND deleted_at IS NULL", []uuid.UUID{id, translationOf}).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+		row, source := rows[0], rows[1]
+		if row.ID != id {
+			row, source = source, row
+		}
+
+		group := source.ID
+		if source.TranslationGroupID != nil {
+			group = *source.TranslationGroupID
+		} else if err := tx.Table(table).Where("id = ?", source.ID).Update("translation_group_id", group).Error; err != nil {
+			return err
+		}
+
+		var taken int64
+		if err := tx.Table(table).
+			Where("translation_group_id = ? AND locale = ? AND id <> ? AND deleted_at IS NULL", group, row.Locale, row.ID).
+			Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return fmt.Errorf("%w: %s", ErrTranslationExists, row.Locale)
+		}
+
+		return tx.Table(table).Where("id = ?", row.ID).Update("translation_group_id", group).Error
+	}); err != nil {
+		return err
+	}
+	if translatableType == models.MediableTypePost {
+		syncPostIndex(id)
+	}
+	return nil
+}
+
+// UnlinkTranslation removes a post or category from its translation group
+func (s *translationService) UnlinkTranslation(translatableType string, id uuid.UUID) error {
+	table, err := translatableTable(translatableType)
+	if err != nil {
+		return err
+	}
+	// The search index of the posts left behind records the group's locales
+	var translations []uuid.UUID
+	if translatableType == models.MediableTypePost {
+		if err := database.DB.Model(&models.Post{}).
+			Where("id <> ? AND translation_group_id = (?)", id, database.DB.Model(&models.Post{}).Select("translation_group_id").Where("id = ?", id)).
+			Pluck("id", &translations).Error; err != nil {
+			return err
+		}
+	}
+	result := database.DB.Table(table).Where("id = ? AND deleted_at IS NULL", id).Update("translation_group_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	if translatableType == models.MediableTypePost {
+		syncPostIndex(append(translations, id)...)
+	}
+	return nil
+}
+
+// translatableRow is the translation state of a post or category
+type translatableRow struct {
+	ID                 uuid.UUID
+	Locale             string
+	TranslationGroupID *uuid.UUID
+}
+
+func translatableTable(translatableType string) (string, error) {
+	switch translatableType {
+	case models.MediableTypePost:
+		return models.Post{}.TableName(), nil
+	case models.MediableTypeCategory:
+		return models.Category{}.TableName(), nil
+	}
+	return "", fmt.Errorf("%w: unknown type %q", ErrInvalidTranslation, translatableType)
+}
+
+// normalizeLocale canonicalizes a locale, defaulting to the site language; only supported locales are accepted
+func normalizeLocale(tag string) (string, error) {
+	locales := SiteLocales()
+	if tag == "" {
+		return locales.Fallback(), nil
+	}
+	normalized, ok := locales.IsSupported(tag)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedLocale, tag)
+	}
+	return normalized, nil
+}
+
+// localeFallback limits a query of table to a locale chain: rows in the first
+// locale, plus rows in a fallback locale whose translation group has no
+// visible row in a locale preferred over it
+func localeFallback(table, visible string, chain []string) (string, []interface{}) {
+	conditions := make([]string, 0, len(chain))
+	args := make([]interface{}, 0, 2*len(chain))
+	for i, tag := range chain {
+		if i == 0 {
+			conditions = append(conditions, table+".locale = ?")
+			args = append(args, tag)
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf(
+			"(%[1]s.locale = ? AND (%[1]s.translation_group_id IS NULL OR NOT EXISTS (SELECT 1 FROM %[1]s t WHERE t.translation_group_id = %[1]s.translation_group_id AND t.locale IN ? AND t.deleted_at IS NULL AND %[2]s)))",
+			table, visible))
+		args = append(args, tag, chain[:i])
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// postsInLocales limits a posts query to a locale chain with localeFallback,
+// preferring earlier locales among rows that share a slug. Without a chain,
+// as on single-language sites, every locale is queried.
+func postsInLocales(chain []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(chain) == 0 {
+			return db
+		}
+		condition, args := localeFallback("posts", translatedPostVisible, chain)
+		return db.Where(condition, args...).Order(localePreference("posts.locale", chain))
+	}
+}
+
+// categoriesInLocales is postsInLocales for categories
+func categoriesInLocales(chain []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(chain) == 0 {
+			return db
+		}
+		condition, args := localeFallback("categories", translatedCategoryVisible, chain)
+		return db.Where(condition, args...).Order(localePreference("categories.locale", chain))
+	}
+}
+
+// localePreference orders rows by their position in a locale chain, other locales last
+func localePreference(column string, chain []string) clause.OrderBy {
+	var sql strings.Builder
+	vars := make([]interface{}, 0, len(chain))
+	sql.WriteString("CASE " + column)
+	for i, tag := range chain {
+		fmt.Fprintf(&sql, " WHEN ? THEN %d", i)
+		vars = append(vars, tag)
+	}
+	fmt.Fprintf(&sql, " ELSE %d END", len(chain))
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql.String(), Vars: vars, WithoutParentheses: true}}
+}
+
+// loadPostTranslations loads the public posts of translation groups, keyed by group
+func loadPostTranslations(groupIDs []uuid.UUID) (map[uuid.UUID][]Translation, error) {
+	result := make(map[uuid.UUID][]Translation, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	var posts []models.Post
+	if err := database.DB.Select("id, title, slug, locale, translation_group_id").
+		Where("translation_group_id IN ? AND status = ? AND public = ? AND published_at IS NOT NULL", groupIDs, "published", true).
+		Order("locale ASC").
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	site := config.GetConfig().Site
+	for i := range posts {
+		group := *posts[i].TranslationGroupID
+		result[group] = append(result[group], postTranslation(&posts[i], site))
+	}
+	return result, nil
+}
+
+// loadCategoryTranslations loads the active categories of translation groups, keyed by group
+func loadCategoryTranslations(groupIDs []uuid.UUID) (map[uuid.UUID][]Translation, error) {
+	result := make(map[uuid.UUID][]Translation, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	var categories []models.Category
+	if err := database.DB.Select("id, name, slug, locale, translation_group_id").
+		Where("translation_group_id IN ? AND is_active = ?", groupIDs, true).
+		Order("locale ASC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	site := config.GetConfig().Site
+	for i := range categories {
+		group := *categories[i].TranslationGroupID
+		result[group] = append(result[group], categoryTranslation(&categories[i], site))
+	}
+	return result, nil
+}
+
+func postTranslation(post *models.Post, site config.SiteConfig) Translation {
+	return Translation{ID: post.ID, Locale: post.Locale, Title: post.Title, Slug: post.Slug, URL: site.LocalizedPostURL(post.Locale, post.Slug)}
+}
+
+func categoryTranslation(category *models.Category, site config.SiteConfig) Translation {
+	return Translation{ID: category.ID, Locale: category.Locale, Title: category.Name, Slug: category.Slug, URL: site.LocalizedCategoryURL(category.Locale, category.Slug)}
+}
+
+// hreflangAlternates lists the language versions of a translated page, with
+// x-default pointing at the version in the default language. Pages without
+// translations have no alternates.
+func hreflangAlternates(translations []Translation, defaultLocale string) []Alternate {
+	if len(translations) < 2 {
+		return nil
+	}
+	alternates := make([]Alternate, 0, len(translations)+1)
+	for _, translation := range translations {
+		alternates = append(alternates, Alternate{Hreflang: translation.Locale, URL: translation.URL})
+	}
+	for _, translation := range translations {
+		if strings.EqualFold(translation.Locale, defaultLocale) {
+			alternates = append(alternates, Alternate{Hreflang: locale.XDefault, URL: translation.URL})
+			break
+		}
+	}
+	return alternates
+}
+
+// postTranslationGroups returns the distinct translation groups of posts
+func postTranslationGroups(posts []models.Post) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	groups := make([]uuid.UUID, 0)
+	for _, post := range posts {
+		if post.TranslationGroupID != nil && !seen[*post.TranslationGroupID] {
+			seen[*post.TranslationGroupID] = true
+			groups = append(groups, *post.TranslationGroupID)
+		}
+	}
+	return groups
+}
+
+// categoryTranslationGroups returns the distinct translation groups of categories
+func categoryTranslationGroups(categories []models.Category) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool)
+	groups := make([]uuid.UUID, 0)
+	for _, category := range categories {
+		if category.TranslationGroupID != nil && !seen[*category.TranslationGroupID] {
+			seen[*category.TranslationGroupID] = true
+			groups = append(groups, *category.TranslationGroupID)
+		}
+	}
+	return groups
+}
+
+var TranslationSvc TranslationService = NewTranslationService()
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/database"
+	"go-next/pkg/nestedset"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Models kept as nested-set trees
+const (
+	TreeModelCategory = "category"
+	TreeModelComment  = "comment"
+)
+
+// TreeModels lists every model kept as a nested-set tree
+var TreeModels = []string{TreeModelCategory, TreeModelComment}
+
+var (
+	ErrUnknownTreeModel  = errors.New("unknown tree model")
+	ErrInvalidTreeParent = errors.New("invalid tree parent")
+)
+
+type TreeService interface {
+	Check(ctx context.Context, model string) (*TreeReport, error)
+	Rebuild(ctx context.Context, model string) (*TreeRebuildResult, error)
+}
+
+// TreeReport lists the inconsistencies of a tree
+type TreeReport struct {
+	Model  string            `json:"model"`
+	Nodes  int               `json:"nodes"`
+	Issues []nestedset.Issue `json:"issues"`
+}
+
+// TreeRebuildResult counts the nodes a rebuild moved, and the orphans and
+// cycle members it turned into roots
+type TreeRebuildResult struct {
+	Model    string `json:"model"`
+	Nodes    int    `json:"nodes"`
+	Updated  int    `json:"updated"`
+	Detached int    `json:"detached"`
+}
+
+type treeService struct{}
+
+func NewTreeService() TreeService {
+	return &treeService{}
+}
+
+// Check compares the intervals, depths and parents of every node of a tree,
+// or of each tree when the model has one per scope
+func (s *treeService) Check(ctx context.Context, model string) (*TreeReport, error) {
+	table, err := treeOf(model)
+	if err != nil {
+		return nil, err
+	}
+	db := database.DB.WithContext(ctx)
+	trees, err := table.trees(db)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TreeReport{Model: model}
+	for _, tree := range trees {
+		nodes, err := tree.nodes(db)
+		if err != nil {
+			return nil, err
+		}
+		report.Nodes += len(nodes)
+		report.Issues = append(report.Issues, nestedset.Check(nodes)...)
+	}
+	return report, nil
+}
+
+// Rebuild recomputes the intervals and depths of a tree from ParentID and
+// RecordOrdering, holding the tree's lock so no mutation interleaves. Models
+// with one tree per scope are rebuilt one tree at a time.
+func (s *treeService) Rebuild(ctx context.Context, model string) (*TreeRebuildResult, error) {
+	table, err := treeOf(model)
+	if err != nil {
+		return nil, err
+	}
+	trees, err := table.trees(database.DB.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TreeRebuildResult{Model: model}
+	for _, tree := range trees {
+		if err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return rebuildTree(tx, tree, result)
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// rebuildTree lays one tree out again and adds its counts to result
+func rebuildTree(tx *gorm.DB, tree nestedTree, result *TreeRebuildResult) error {
+	if err := tree.lock(tx); err != nil {
+		return err
+	}
+	nodes, err := tree.nodes(tx)
+	if err != nil {
+		return err
+	}
+	current := make(map[uuid.UUID]nestedset.Node, len(nodes))
+	for _, node := range nodes {
+		current[node.ID] = node
+	}
+
+	rebuilt, detached := nestedset.Rebuild(nodes)
+	result.Nodes += len(rebuilt)
+	result.Detached += len(detached)
+	for _, node := range rebuilt {
+		before := current[node.ID]
+		if before.Left == node.Left && before.Right == node.Right && before.Depth == node.Depth && sameParent(before.ParentID, node.ParentID) {
+			continue
+		}
+		if err := tree.rows(tx).Where("id = ?", node.ID).UpdateColumns(map[string]interface{}{
+			"record_left":  node.Left,
+			"record_right": node.Right,
+			"record_dept":  node.Depth,
+			"parent_id":    node.ParentID,
+		}).Error; err != nil {
+			return err
+		}
+		result.Updated++
+	}
+	return nil
+}
+
+// nestedSetColumns are only written by tree mutations; plain updates omit them
+// so they cannot save a position that a concurrent mutation has moved
+var nestedSetColumns = []string{"record_left", "record_right", "record_dept", "record_ordering", "parent_id"}
+
+// nestedTree mutates the nested-set columns of one table. Every mutation
+// runs in the caller's transaction and first takes the tree's lock, so
+// concurrent mutations of the same tree wait for each other instead of
+// shifting intervals from stale positions.
+//
+// A table with a scope column holds one tree per value of the column, as
+// comments form one tree per post; within selects the tree to work on.
+type nestedTree struct {
+	table string
+	model func() interface{}
+	scope string
+	key   uuid.UUID
+}
+
+var (
+	categoryNestedSet = nestedTree{table: "categories", model: func() interface{} { return &models.Category{} }}
+	commentNestedSet  = nestedTree{table: "comments", model: func() interface{} { return &models.Comment{} }, scope: "post_id"}
+)
+
+func treeOf(model string) (nestedTree, error) {
+	switch model {
+	case TreeModelCategory:
+		return categoryNestedSet, nil
+	case TreeModelComment:
+		return commentNestedSet, nil
+	}
+	return nestedTree{}, fmt.Errorf("%w: %q", ErrUnknownTreeModel, model)
+}
+
+// nestedPosition is where a node sits in its tree
+type nestedPosition struct {
+	ID             uuid.UUID
+	ParentID       *uuid.UUID
+	RecordLeft     int
+	RecordRight    int
+	RecordDept     int
+	RecordOrdering int
+	CreatedAt      time.Time
+}
+
+// within returns the tree of one value of the scope column
+func (t nestedTree) within(key uuid.UUID) nestedTree {
+	t.key = key
+	return t
+}
+
+// trees lists the trees of the table: one per scope value, or the table itself
+func (t nestedTree) trees(db *gorm.DB) ([]nestedTree, error) {
+	if t.scope == "" {
+		return []nestedTree{t}, nil
+	}
+	var keys []uuid.UUID
+	if err := db.Model(t.model()).Distinct(t.scope).Order(t.scope).Pluck(t.scope, &keys).Error; err != nil {
+		return nil, err
+	}
+	trees := make([]nestedTree, 0, len(keys))
+	for _, key := range keys {
+		trees = append(trees, t.within(key))
+	}
+	return trees, nil
+}
+
+// rows selects the rows of the tree
+func (t nestedTree) rows(db *gorm.DB) *gorm.DB {
+	query := db.Model(t.model())
+	if t.scope != "" {
+		query = query.Where(t.scope+" = ?", t.key)
+	}
+	return query
+}
+
+// lock serializes the mutations of the tree until the transaction ends.
+// Readers are not blocked, nor are the mutations of other trees of the table.
+func (t nestedTree) lock(tx *gorm.DB) error {
+	name := "nested_set:" + t.table
+	if t.scope != "" {
+		name += ":" + t.key.String()
+	}
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", name).Error
+}
+
+// position reads and row-locks the position of a node
+func (t nestedTree) position(tx *gorm.DB, id uuid.UUID) (*nestedPosition, error) {
+	var positions []nestedPosition
+	if err := t.rows(tx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id, parent_id, record_left, record_right, record_dept, record_ordering, created_at").
+		Where("id = ?", id).
+		Limit(1).
+		Scan(&positions).Error; err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &positions[0], nil
+}
+
+func (t nestedTree) nodes(db *gorm.DB) ([]nestedset.Node, error) {
+	var positions []nestedPosition
+	if err := t.rows(db).
+		Select("id, parent_id, record_left, record_right, record_dept, record_ordering, created_at").
+		Order("record_left ASC").
+		Scan(&positions).Error; err != nil {
+		return nil, err
+	}
+	nodes := make([]nestedset.Node, 0, len(positions))
+	for _, p := range positions {
+		nodes = append(nodes, nestedset.Node{
+			ID:        p.ID,
+			ParentID:  p.ParentID,
+			Left:      p.RecordLeft,
+			Right:     p.RecordRight,
+			Depth:     p.RecordDept,
+			Ordering:  p.RecordOrdering,
+			CreatedAt: p.CreatedAt,
+		})
+	}
+	return nodes, nil
+}
+
+// shift moves every bound from a position on by delta
+func (t nestedTree) shift(tx *gorm.DB, from, delta int) error {
+	if err := t.rows(tx).Where("record_left >= ?", from).
+		UpdateColumn("record_left", gorm.Expr("record_left + ?", delta)).Error; err != nil {
+		return err
+	}
+	return t.rows(tx).Where("record_right >= ?", from).
+		UpdateColumn("record_right", gorm.Expr("record_right + ?", delta)).Error
+}
+
+func (t nestedTree) nextOrdering(tx *gorm.DB, parentID *uuid.UUID) (int, error) {
+	query := t.rows(tx).Select("COALESCE(MAX(record_ordering), 0) + 1")
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	var ordering int
+	err := query.Scan(&ordering).Error
+	return ordering, err
+}
+
+func (t nestedTree) maxRight(tx *gorm.DB) (int, error) {
+	var right int
+	err := t.rows(tx).Select("COALESCE(MAX(record_right), 0)").Scan(&right).Error
+	return right, err
+}
+
+// insert makes room for a new last child of parentID, or a new last root,
+// and returns the position the node takes. The tree must be locked.
+func (t nestedTree) insert(tx *gorm.DB, parentID *uuid.UUID) (*nestedPosition, error) {
+	position := &nestedPosition{ParentID: parentID}
+	if parentID != nil {
+		parent, err := t.position(tx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.RecordRight == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+		if err := t.shift(tx, parent.RecordRight, 2); err != nil {
+			return nil, err
+		}
+		position.RecordLeft = parent.RecordRight
+		position.RecordDept = parent.RecordDept + 1
+	} else {
+		right, err := t.maxRight(tx)
+		if err != nil {
+			return nil, err
+		}
+		position.RecordLeft = right + 1
+	}
+	position.RecordRight = position.RecordLeft + 1
+
+	ordering, err := t.nextOrdering(tx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	position.RecordOrdering = ordering
+	return position, nil
+}
+
+// move makes a node with its subtree the last child of newParentID, or the
+// last root. The tree must be locked.
+func (t nestedTree) move(tx *gorm.DB, id uuid.UUID, newParentID *uuid.UUID) error {
+	node, err := t.position(tx, id)
+	if err != nil {
+		return err
+	}
+	if node.RecordLeft == 0 || node.RecordRight == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	left, right := node.RecordLeft, node.RecordRight
+	width := right - left + 1
+
+	depth := 0
+	if newParentID != nil {
+		parent, err := t.position(tx, *newParentID)
+		if err != nil {
+			return err
+		}
+		if parent.RecordRight == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if parent.RecordLeft >= left && parent.RecordRight <= right {
+			return fmt.Errorf("%w: cannot move a node under itself", ErrInvalidTreeParent)
+		}
+		depth = parent.RecordDept + 1
+	}
+	ordering, err := t.nextOrdering(tx, newParentID)
+	if err != nil {
+		return err
+	}
+
+	// Lift the subtree out by negating its bounds, then close the gap it leaves
+	if err := t.rows(tx).Where("record_left >= ? AND record_right <= ?", left, right).
+		UpdateColumns(map[string]interface{}{
+			"record_left":  gorm.Expr("-record_left"),
+			"record_right": gorm.Expr("-record_right"),
+		}).Error; err != nil {
+		return err
+	}
+	if err := t.shift(tx, right+1, -width); err != nil {
+		return err
+	}
+
+	// Open a gap where the subtree goes, now that positions have closed up
+	var target int
+	if newParentID != nil {
+		parent, err := t.position(tx, *newParentID)
+		if err != nil {
+			return err
+		}
+		target = parent.RecordRight
+	} else {
+		maxRight, err := t.maxRight(tx)
+		if err != nil {
+			return err
+		}
+		target = maxRight + 1
+	}
+	if err := t.shift(tx, target, width); err != nil {
+		return err
+	}
+
+	offset := target - left
+	if err := t.rows(tx).Where("record_left <= ? AND record_right >= ?", -left, -right).
+		UpdateColumns(map[string]interface{}{
+			"record_left":  gorm.Expr("-record_left + ?", offset),
+			"record_right": gorm.Expr("-record_right + ?", offset),
+			"record_dept":  gorm.Expr("record_dept + ?", depth-node.RecordDept),
+		}).Error; err != nil {
+		return err
+	}
+	return t.rows(tx).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"parent_id":       newParentID,
+		"record_ordering": ordering,
+	}).Error
+}
+
+// remove deletes a node with its subtree and closes the gap. The tree must be locked.
+func (t nestedTree) remove(tx *gorm.DB, id uuid.UUID) error {
+	node, err := t.position(tx, id)
+	if err != nil {
+		return err
+	}
+	if node.RecordLeft == 0 || node.RecordRight == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	if err := t.rows(tx).Where("record_left >= ? AND record_right <= ?", node.RecordLeft, node.RecordRight).
+		Delete(t.model()).Error; err != nil {
+		return err
+	}
+	return t.shift(tx, node.RecordRight+1, -(node.RecordRight - node.RecordLeft + 1))
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+var TreeSvc TreeService = NewTreeService()
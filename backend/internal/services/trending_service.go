@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"go-next/pkg/cronjob"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+)
+
+// TrendingEvent is an interaction that makes a post trend
+type TrendingEvent string
+
+const (
+	TrendingEventView     TrendingEvent = "view"
+	TrendingEventComment  TrendingEvent = "comment"
+	TrendingEventReaction TrendingEvent = "reaction"
+)
+
+// trendingWeights is how much each interaction adds to a post's score
+var trendingWeights = map[TrendingEvent]float64{
+	TrendingEventView:     1,
+	TrendingEventComment:  5,
+	TrendingEventReaction: 3,
+}
+
+// TrendingWindow describes a trending list and how fast its scores decay
+type TrendingWindow struct {
+	Name     string
+	HalfLife time.Duration
+	Days     int
+}
+
+// TrendingWindows are the supported trending lists keyed by their query value
+var TrendingWindows = map[string]TrendingWindow{
+	"24h": {Name: "24h", HalfLife: 6 * time.Hour, Days: 1},
+	"7d":  {Name: "7d", HalfLife: 42 * time.Hour, Days: 7},
+}
+
+// ErrInvalidTrendingWindow is returned for a window not in TrendingWindows
+var ErrInvalidTrendingWindow = errors.New("invalid trending window")
+
+const (
+	trendingKey      = "trending:%s"
+	trendingEpochKey = "trending:%s:epoch"
+
+	// trendingRescaleInterval is how often scores are rebased onto the current time
+	trendingRescaleInterval = 6 * time.Hour
+
+	// trendingRescaleLockTTL bounds how long a stalled rescale keeps others out
+	trendingRescaleLockTTL = 5 * time.Minute
+
+	// trendingMinScore drops posts whose decayed score is below a fraction of a view
+	trendingMinScore = 0.05
+)
+
+type TrendingService interface {
+	Record(postID uuid.UUID, event TrendingEvent) error
+	GetTrendingPostIDs(window string, limit int) ([]uuid.UUID, error)
+	Rescale(ctx context.Context) error
+	Schedule() error
+}
+
+type trendingService struct{}
+
+func NewTrendingService() TrendingService {
+	return &trendingService{}
+}
+
+// Record adds an interaction to every trending window.
+//
+// Scores use forward decay: instead of shrinking every score as time passes,
+// each new event is weighted by exp(λ·(now − epoch)), so later events count
+// exponentially more and the set's ordering always reflects the decayed score.
+func (s *trendingService) Record(postID uuid.UUID, event TrendingEvent) error {
+	if GlobalRedisClient == nil {
+		return nil
+	}
+	weight, ok := trendingWeights[event]
+	if !ok {
+		return fmt.Errorf("unknown trending event %q", event)
+	}
+
+	ctx := context.Background()
+	now := time.Now().Unix()
+	for _, window := range TrendingWindows {
+		if err := GlobalRedisClient.ZIncrByDecayed(ctx, fmt.Sprintf(trendingKey, window.Name),
+			fmt.Sprintf(trendingEpochKey, window.Name), postID.String(), weight, decayRate(window), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTrendingPostIDs returns the highest scoring posts of a window
+func (s *trendingService) GetTrendingPostIDs(window string, limit int) ([]uuid.UUID, error) {
+	w, ok := TrendingWindows[window]
+	if !ok {
+		return nil, ErrInvalidTrendingWindow
+	}
+	if GlobalRedisClient == nil {
+		return nil, nil
+	}
+
+	members, err := GlobalRedisClient.ZRevRangeWithScores(context.Background(), fmt.Sprintf(trendingKey, w.Name), 0, int64(limit)-1)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		if id, err := uuid.Parse(member.Member); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Rescale rebases every window onto the current time so the forward-decay
+// weights stay small, and drops posts that have cooled off. Instances take
+// turns, since two rescales from the same epoch would decay the scores twice.
+// Each window is rebased in one atomic step, so concurrent events are always
+// weighted against the epoch their scores are stored relative to.
+func (s *trendingService) Rescale(ctx context.Context) error {
+	if GlobalRedisClient == nil {
+		return nil
+	}
+
+	_, err := runExclusive(ctx, "trending_rescale", trendingRescaleLockTTL, func() error {
+		now := time.Now().Unix()
+		for _, window := range TrendingWindows {
+			if err := GlobalRedisClient.ZRebase(ctx, fmt.Sprintf(trendingKey, window.Name),
+				fmt.Sprintf(trendingEpochKey, window.Name), decayRate(window), now, trendingMinScore); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// Schedule registers the job that rescales the trending windows
+func (s *trendingService) Schedule() error {
+	_, err := cronjob.AddJob(gocron.DurationJob(trendingRescaleInterval), func() {
+		if err := s.Rescale(context.Background()); err != nil {
+			log.Printf("Warning: failed to rescale trending posts: %v", err)
+		}
+	}, gocron.WithName("trending_rescale"))
+	return err
+}
+
+// decayRate is λ in exp(λ·t), chosen so a score halves every half-life
+func decayRate(window TrendingWindow) float64 {
+	return math.Ln2 / window.HalfLife.Seconds()
+}
+
+// recordTrending records an interaction, logging failures
+func recordTrending(postID uuid.UUID, event TrendingEvent) {
+	if err := TrendingSvc.Record(postID, event); err != nil {
+		log.Printf("Warning: failed to record trending %s for post %s: %v", event, postID, err)
+	}
+}
+
+var TrendingSvc TrendingService = NewTrendingService()
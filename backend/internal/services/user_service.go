@@ -37,7 +37,11 @@ func (s *userService) UpdateUserProfile(user *models.User, username, email, phon
 	user.Phone = phone
 	user.EmailVerified = emailVerified
 	user.PhoneVerified = phoneVerified
-	return database.DB.Save(user).Error
+	if err := database.DB.Save(user).Error; err != nil {
+		return err
+	}
+	syncUserIndex(user)
+	return nil
 }
 
 func (s *userService) GetActiveUsers(ctx context.Context) ([]*models.User, error) {
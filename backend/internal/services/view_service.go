@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/pkg/cronjob"
+	"go-next/pkg/database"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// viewFlushInterval is how often buffered views are written to the database
+	viewFlushInterval = time.Minute
+
+	// viewUniqueTTL keeps a day's visitor set around until that day has been flushed
+	viewUniqueTTL = 48 * time.Hour
+
+	viewDateLayout = "2006-01-02"
+)
+
+// Redis keys used to buffer views between flushes
+const (
+	viewKeyUnique         = "views:unique:%s:%s"
+	viewKeyPendingViews   = "views:pending:views"
+	viewKeyPendingUniques = "views:pending:uniques"
+)
+
+// botUserAgents are user-agent fragments of crawlers, link previewers and scripts
+var botUserAgents = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit", "embedly",
+	"preview", "monitor", "pingdom", "uptime", "lighthouse", "headless", "phantomjs",
+	"curl", "wget", "python-requests", "python-urllib", "go-http-client", "java/",
+	"okhttp", "httpclient", "axios", "node-fetch", "postman",
+}
+
+type ViewService interface {
+	RecordView(postID uuid.UUID, visitor Visitor) (bool, error)
+	Flush(ctx context.Context) (int, error)
+	GetViewHistory(postID uuid.UUID, from, to time.Time) ([]models.PostViewDaily, error)
+	Schedule() error
+}
+
+// Visitor identifies who viewed a post
+type Visitor struct {
+	IP        string
+	UserAgent string
+	UserID    *uuid.UUID
+}
+
+// Fingerprint returns a stable, anonymised identifier for the visitor
+func (v Visitor) Fingerprint() string {
+	if v.UserID != nil {
+		return "user:" + v.UserID.String()
+	}
+	sum := sha256.Sum256([]byte(v.IP + "|" + v.UserAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// IsBotUserAgent reports whether a user agent looks automated
+func IsBotUserAgent(userAgent string) bool {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+	for _, fragment := range botUserAgents {
+		if strings.Contains(userAgent, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+type viewService struct{}
+
+func NewViewService() ViewService {
+	return &viewService{}
+}
+
+// RecordView buffers a view in Redis and reports whether it came from a new
+// visitor of the day. Bots are ignored. Without Redis the view is written
+// straight to the database.
+func (s *viewService) RecordView(postID uuid.UUID, visitor Visitor) (bool, error) {
+	if IsBotUserAgent(visitor.UserAgent) {
+		return false, nil
+	}
+
+	day := time.Now().UTC().Format(viewDateLayout)
+	field := postID.String() + ":" + day
+
+	if GlobalRedisClient == nil {
+		return true, applyViewCounts(database.DB, map[string]*viewCount{field: {views: 1, uniques: 1}})
+	}
+
+	ctx := context.Background()
+	uniqueKey := fmt.Sprintf(viewKeyUnique, postID, day)
+	added, err := GlobalRedisClient.PFAdd(ctx, uniqueKey, visitor.Fingerprint())
+	if err != nil {
+		return false, err
+	}
+	if err := GlobalRedisClient.Expire(ctx, uniqueKey, viewUniqueTTL); err != nil {
+		return false, err
+	}
+
+	if _, err := GlobalRedisClient.HIncrBy(ctx, viewKeyPendingViews, field, 1); err != nil {
+		return false, err
+	}
+	if added == 0 {
+		return false, nil
+	}
+	if _, err := GlobalRedisClient.HIncrBy(ctx, viewKeyPendingUniques, field, 1); err != nil {
+		return false, err
+	}
+	recordTrending(postID, TrendingEventView)
+
+	return true, nil
+}
+
+// Flush moves the buffered counts into the daily history and the posts'
+// view_count. The pending hashes are claimed, read and deleted in one step,
+// so views recorded during the flush land in fresh hashes and concurrent or
+// retried flushes never count a view twice. When the database write fails the
+// claimed counts are put back for the next run.
+func (s *viewService) Flush(ctx context.Context) (int, error) {
+	if GlobalRedisClient == nil {
+		return 0, nil
+	}
+
+	keys := []string{viewKeyPendingViews, viewKeyPendingUniques}
+	hashes, err := GlobalRedisClient.HGetAllDel(ctx, keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	counts := make(map[string]*viewCount)
+	for i, values := range hashes {
+		for field, value := range values {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			if counts[field] == nil {
+				counts[field] = &viewCount{}
+			}
+			if keys[i] == viewKeyPendingViews {
+				counts[field].views += n
+			} else {
+				counts[field].uniques += n
+			}
+		}
+	}
+
+	if len(counts) == 0 {
+		return 0, nil
+	}
+
+	if err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return applyViewCounts(tx, counts)
+	}); err != nil {
+		restoreViewCounts(counts)
+		return 0, err
+	}
+
+	return len(counts), nil
+}
+
+// restoreViewCounts puts claimed counts back into the pending hashes after a
+// failed flush; counts that cannot be restored are logged as lost
+func restoreViewCounts(counts map[string]*viewCount) {
+	ctx := context.Background()
+	for field, count := range counts {
+		if count.views != 0 {
+			if _, err := GlobalRedisClient.HIncrBy(ctx, viewKeyPendingViews, field, count.views); err != nil {
+				log.Printf("Warning: lost %d buffered views of %s: %v", count.views, field, err)
+			}
+		}
+		if count.uniques != 0 {
+			if _, err := GlobalRedisClient.HIncrBy(ctx, viewKeyPendingUniques, field, count.uniques); err != nil {
+				log.Printf("Warning: lost %d buffered unique views of %s: %v", count.uniques, field, err)
+			}
+		}
+	}
+}
+
+// GetViewHistory returns the daily view totals of a post within a date range
+func (s *viewService) GetViewHistory(postID uuid.UUID, from, to time.Time) ([]models.PostViewDaily, error) {
+	var history []models.PostViewDaily
+	err := database.DB.Where("post_id = ? AND date >= ? AND date <= ?", postID, models.TruncateToDay(from), models.TruncateToDay(to)).
+		Order("date ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// Schedule registers the job that flushes buffered views to the database
+func (s *viewService) Schedule() error {
+	_, err := cronjob.AddJob(gocron.DurationJob(viewFlushInterval), func() {
+		if _, err := s.Flush(context.Background()); err != nil {
+			log.Printf("Warning: failed to flush post views: %v", err)
+		}
+	}, gocron.WithName("post_views_flush"))
+	return err
+}
+
+type viewCount struct {
+	views   int64
+	uniques int64
+}
+
+// applyViewCounts adds per post per day counts to the history and view_count
+func applyViewCounts(tx *gorm.DB, counts map[string]*viewCount) error {
+	postTotals := make(map[uuid.UUID]int64)
+	rows := make([]models.PostViewDaily, 0, len(counts))
+	for field, count := range counts {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		postID, err := uuid.Parse(parts[0])
+		if err != nil {
+			continue
+		}
+		day, err := time.Parse(viewDateLayout, parts[1])
+		if err != nil {
+			continue
+		}
+		rows = append(rows, models.PostViewDaily{PostID: postID, Date: day, Views: count.views, UniqueVisitors: count.uniques})
+		postTotals[postID] += count.uniques
+	}
+
+	// Views can be buffered for posts deleted since; drop those
+	ids := make([]uuid.UUID, 0, len(postTotals))
+	for id := range postTotals {
+		ids = append(ids, id)
+	}
+	var existing []uuid.UUID
+	if err := tx.Model(&models.Post{}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+		return err
+	}
+	known := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		known[id] = true
+	}
+
+	for _, row := range rows {
+		if !known[row.PostID] {
+			continue
+		}
+		result := tx.Model(&models.PostViewDaily{}).
+			Where("post_id = ? AND date = ?", row.PostID, row.Date).
+			Updates(map[string]interface{}{
+				"views":           gorm.Expr("views + ?", row.Views),
+				"unique_visitors": gorm.Expr("unique_visitors + ?", row.UniqueVisitors),
+				"updated_at":      time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			row.UpdatedAt = time.Now()
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for postID, total := range postTotals {
+		if !known[postID] || total == 0 {
+			continue
+		}
+		if err := tx.Model(&models.Post{}).
+			Where("id = ?", postID).
+			UpdateColumn("view_count", gorm.Expr("view_count + ?", total)).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var ViewSvc ViewService = NewViewService()
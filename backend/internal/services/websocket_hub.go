@@ -63,6 +63,9 @@ type Hub struct {
 	mu sync.RWMutex
 }
 
+// GlobalHub is the hub of the running server; services push live notifications through it
+var GlobalHub *Hub
+
 // NotificationMessage represents a notification sent via WebSocket
 type NotificationMessage struct {
 	Type      string                 `json:"type"`
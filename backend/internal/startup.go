@@ -31,6 +31,7 @@ func GetConfig() *config.Configuration {
 func InitEmailer() {
 	cfg := config.GetConfig()
 	Emailer = email.NewEmailService(cfg.SMTP)
+	services.GlobalEmailer = Emailer
 }
 
 func InitRedis() {
@@ -49,10 +50,35 @@ func RunServer(host, port string) {
 		log.Fatalf("Failed to initialize Casbin: %v", err)
 	}
 
+	if err := services.InitSearch(config.GetConfig().Search); err != nil {
+		log.Fatalf("Failed to open search index: %v", err)
+	}
+	defer services.SearchSvc.Close()
+
 	// Initialize Redis and Email services
 	InitRedis()
 	InitEmailer()
 
+	// Schedule background jobs
+	if err := services.RelatedPostSvc.Schedule(); err != nil {
+		log.Printf("Warning: failed to schedule related posts job: %v", err)
+	}
+	if err := services.ViewSvc.Schedule(); err != nil {
+		log.Printf("Warning: failed to schedule view flush job: %v", err)
+	}
+	if err := services.TrendingSvc.Schedule(); err != nil {
+		log.Printf("Warning: failed to schedule trending rescale job: %v", err)
+	}
+	if err := services.AnalyticsSvc.Schedule(); err != nil {
+		log.Printf("Warning: failed to schedule analytics rollup job: %v", err)
+	}
+	if err := services.SitemapSvc.Schedule(); err != nil {
+		log.Printf("Warning: failed to schedule sitemap job: %v", err)
+	}
+	if err := services.ReactionSvc.Schedule(); err != nil {
+		log.Printf("Warning: failed to schedule reaction reconcile job: %v", err)
+	}
+
 	// Set Gin mode based on environment
 	ginMode := os.Getenv("GIN_MODE")
 	if ginMode == "" {
@@ -143,5 +169,10 @@ func RunServer(host, port string) {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Persist views buffered since the last flush
+	if _, err := services.ViewSvc.Flush(ctx); err != nil {
+		log.Printf("Warning: failed to flush post views: %v", err)
+	}
+
 	log.Println("Server exited")
 }
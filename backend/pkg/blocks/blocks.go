@@ -0,0 +1,523 @@
+package blocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go-next/pkg/sanitize"
+
+	"github.com/google/uuid"
+)
+
+// Block types
+const (
+	TypeParagraph = "paragraph"
+	TypeHeading   = "heading"
+	TypeImage     = "image"
+	TypeGallery   = "gallery"
+	TypeQuote     = "quote"
+	TypeCode      = "code"
+	TypeEmbed     = "embed"
+	TypeCallout   = "callout"
+)
+
+// Callout variants
+const (
+	CalloutInfo    = "info"
+	CalloutTip     = "tip"
+	CalloutWarning = "warning"
+	CalloutDanger  = "danger"
+)
+
+// Limits on block data
+const (
+	MaxTextLength    = 20000
+	MaxCodeLength    = 100000
+	MaxGalleryImages = 50
+)
+
+// ErrInvalidBlock is returned for blocks that fail schema validation
+var ErrInvalidBlock = errors.New("invalid block")
+
+var (
+	// fragmentID matches heading anchors
+	fragmentID = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+	// languageName matches code block languages such as go, c++ or objective-c
+	languageName = regexp.MustCompile(`^[a-zA-Z0-9_+#.\-]{1,30}$`)
+
+	// tagPattern matches markup removed from plain text
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Data is the typed payload of a block
+type Data interface {
+	validate() error
+	html() string
+	text() string
+}
+
+// Block is a typed content block stored as a json Content row, e.g.
+//
+//	{"type": "heading", "data": {"text": "Setup", "level": 2}}
+type Block struct {
+	Type string
+	Data Data
+}
+
+// Paragraph is a paragraph of inline formatted text
+type Paragraph struct {
+	Text string `json:"text"`
+}
+
+// Heading is a section heading; ID defaults to an anchor derived from the text
+type Heading struct {
+	Text  string `json:"text"`
+	Level int    `json:"level"`
+	ID    string `json:"id,omitempty"`
+}
+
+// Image is an image referenced by URL or by uploaded media
+type Image struct {
+	URL     string     `json:"url,omitempty"`
+	MediaID *uuid.UUID `json:"media_id,omitempty"`
+	Alt     string     `json:"alt,omitempty"`
+	Caption string     `json:"caption,omitempty"`
+	Width   *int       `json:"width,omitempty"`
+	Height  *int       `json:"height,omitempty"`
+}
+
+// Gallery is a grid of images
+type Gallery struct {
+	Images  []Image `json:"images"`
+	Columns int     `json:"columns,omitempty"`
+}
+
+// Quote is a block quotation with an optional citation
+type Quote struct {
+	Text     string `json:"text"`
+	Citation string `json:"citation,omitempty"`
+}
+
+// Code is a listing of source code
+type Code struct {
+	Code     string `json:"code"`
+	Language string `json:"language,omitempty"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// Embed is external content such as a video or a social media post. HTML is
+// the provider's embed markup as sanitized by the oEmbed client, set by the
+// renderer and never read from input; without it the embed renders as a link.
+type Embed struct {
+	URL      string `json:"url"`
+	Provider string `json:"provider,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	HTML     string `json:"-"`
+}
+
+// Callout is highlighted text such as a tip or a warning
+type Callout struct {
+	Variant string `json:"variant,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Types lists every supported block type
+func Types() []string {
+	return []string{TypeParagraph, TypeHeading, TypeImage, TypeGallery, TypeQuote, TypeCode, TypeEmbed, TypeCallout}
+}
+
+// newData returns an empty payload for a block type
+func newData(blockType string) (Data, bool) {
+	switch blockType {
+	case TypeParagraph:
+		return &Paragraph{}, true
+	case TypeHeading:
+		return &Heading{}, true
+	case TypeImage:
+		return &Image{}, true
+	case TypeGallery:
+		return &Gallery{}, true
+	case TypeQuote:
+		return &Quote{}, true
+	case TypeCode:
+		return &Code{}, true
+	case TypeEmbed:
+		return &Embed{}, true
+	case TypeCallout:
+		return &Callout{}, true
+	}
+	return nil, false
+}
+
+// Parse decodes and validates a block. Inline HTML is sanitized and defaults
+// are filled in, so marshalling the result gives the canonical stored form.
+func Parse(source []byte) (*Block, error) {
+	var raw struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(source, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlock, err)
+	}
+
+	data, ok := newData(raw.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidBlock, raw.Type)
+	}
+	if len(raw.Data) == 0 {
+		return nil, fmt.Errorf("%w: %s block has no data", ErrInvalidBlock, raw.Type)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw.Data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(data); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBlock, raw.Type, err)
+	}
+	if err := data.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidBlock, raw.Type, err)
+	}
+
+	return &Block{Type: raw.Type, Data: data}, nil
+}
+
+// MarshalJSON encodes the block as {"type": ..., "data": ...}
+func (b *Block) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		Data Data   `json:"data"`
+	}{b.Type, b.Data})
+}
+
+// HTML renders the block
+func (b *Block) HTML() string {
+	return b.Data.html()
+}
+
+// Text renders the block as plain text
+func (b *Block) Text() string {
+	return b.Data.text()
+}
+
+func (p *Paragraph) validate() error {
+	p.Text = sanitize.Inline(strings.TrimSpace(p.Text))
+	return validateText("text", p.Text, true)
+}
+
+func (p *Paragraph) html() string {
+	return "<p>" + p.Text + "</p>\n"
+}
+
+func (p *Paragraph) text() string {
+	return plain(p.Text)
+}
+
+func (h *Heading) validate() error {
+	h.Text = sanitize.Inline(strings.TrimSpace(h.Text))
+	if err := validateText("text", h.Text, true); err != nil {
+		return err
+	}
+	if h.Level == 0 {
+		h.Level = 2
+	}
+	if h.Level < 1 || h.Level > 6 {
+		return errors.New("level must be between 1 and 6")
+	}
+	if h.ID == "" {
+		h.ID = AnchorID(plain(h.Text))
+	}
+	if !fragmentID.MatchString(h.ID) {
+		return errors.New("id may only contain letters, digits, '-' and '_'")
+	}
+	return nil
+}
+
+func (h *Heading) html() string {
+	return fmt.Sprintf("<h%d id=\"%s\">%s<a class=\"heading-anchor\" href=\"#%s\">#</a></h%d>\n", h.Level, h.ID, h.Text, h.ID, h.Level)
+}
+
+func (h *Heading) text() string {
+	return plain(h.Text)
+}
+
+func (i *Image) validate() error {
+	if i.URL == "" && i.MediaID == nil {
+		return errors.New("url or media_id is required")
+	}
+	if i.URL != "" && !isSafeURL(i.URL, true) {
+		return errors.New("url must be an http(s) or site-relative URL")
+	}
+	if (i.Width != nil && *i.Width <= 0) || (i.Height != nil && *i.Height <= 0) {
+		return errors.New("width and height must be positive")
+	}
+	i.Alt = strings.TrimSpace(i.Alt)
+	i.Caption = sanitize.Inline(strings.TrimSpace(i.Caption))
+	return validateText("caption", i.Caption, false)
+}
+
+func (i *Image) html() string {
+	if i.URL == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`<figure class="block-image">`)
+	b.WriteString(i.img())
+	if i.Caption != "" {
+		b.WriteString("<figcaption>" + i.Caption + "</figcaption>")
+	}
+	b.WriteString("</figure>\n")
+	return b.String()
+}
+
+func (i *Image) img() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<img src="%s" alt="%s"`, html.EscapeString(i.URL), html.EscapeString(i.Alt))
+	if i.Width != nil {
+		fmt.Fprintf(&b, ` width="%d"`, *i.Width)
+	}
+	if i.Height != nil {
+		fmt.Fprintf(&b, ` height="%d"`, *i.Height)
+	}
+	b.WriteString(` loading="lazy">`)
+	return b.String()
+}
+
+func (i *Image) text() string {
+	return joinText(i.Alt, plain(i.Caption))
+}
+
+func (g *Gallery) validate() error {
+	if len(g.Images) == 0 {
+		return errors.New("images is required")
+	}
+	if len(g.Images) > MaxGalleryImages {
+		return fmt.Errorf("a gallery holds at most %d images", MaxGalleryImages)
+	}
+	for i := range g.Images {
+		if err := g.Images[i].validate(); err != nil {
+			return fmt.Errorf("image %d: %v", i, err)
+		}
+	}
+	if g.Columns == 0 {
+		g.Columns = 3
+	}
+	if g.Columns < 1 || g.Columns > 6 {
+		return errors.New("columns must be between 1 and 6")
+	}
+	return nil
+}
+
+func (g *Gallery) html() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<figure class="block-gallery columns-%d">`, g.Columns)
+	for _, image := range g.Images {
+		if image.URL == "" {
+			continue
+		}
+		b.WriteString("<figure>" + image.img())
+		if image.Caption != "" {
+			b.WriteString("<figcaption>" + image.Caption + "</figcaption>")
+		}
+		b.WriteString("</figure>")
+	}
+	b.WriteString("</figure>\n")
+	return b.String()
+}
+
+func (g *Gallery) text() string {
+	parts := make([]string, 0, len(g.Images))
+	for i := range g.Images {
+		parts = append(parts, g.Images[i].text())
+	}
+	return joinText(parts...)
+}
+
+func (q *Quote) validate() error {
+	q.Text = sanitize.Inline(strings.TrimSpace(q.Text))
+	q.Citation = sanitize.Inline(strings.TrimSpace(q.Citation))
+	if err := validateText("text", q.Text, true); err != nil {
+		return err
+	}
+	return validateText("citation", q.Citation, false)
+}
+
+func (q *Quote) html() string {
+	var b strings.Builder
+	b.WriteString("<blockquote><p>" + q.Text + "</p>")
+	if q.Citation != "" {
+		b.WriteString("<cite>" + q.Citation + "</cite>")
+	}
+	b.WriteString("</blockquote>\n")
+	return b.String()
+}
+
+func (q *Quote) text() string {
+	if q.Citation == "" {
+		return plain(q.Text)
+	}
+	return plain(q.Text) + " — " + plain(q.Citation)
+}
+
+func (c *Code) validate() error {
+	if strings.TrimSpace(c.Code) == "" {
+		return errors.New("code is required")
+	}
+	if len(c.Code) > MaxCodeLength {
+		return fmt.Errorf("code is longer than %d bytes", MaxCodeLength)
+	}
+	if c.Language != "" && !languageName.MatchString(c.Language) {
+		return errors.New("invalid language")
+	}
+	c.Filename = strings.TrimSpace(c.Filename)
+	if len(c.Filename) > 255 {
+		return errors.New("filename is longer than 255 bytes")
+	}
+	return nil
+}
+
+func (c *Code) html() string {
+	var b strings.Builder
+	b.WriteString(`<figure class="block-code">`)
+	if c.Filename != "" {
+		b.WriteString("<figcaption>" + html.EscapeString(c.Filename) + "</figcaption>")
+	}
+	b.WriteString("<pre><code")
+	if c.Language != "" {
+		b.WriteString(` class="language-` + html.EscapeString(c.Language) + `"`)
+	}
+	b.WriteString(">" + html.EscapeString(c.Code) + "</code></pre></figure>\n")
+	return b.String()
+}
+
+func (c *Code) text() string {
+	return c.Code
+}
+
+func (e *Embed) validate() error {
+	if !isSafeURL(e.URL, false) {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	e.Provider = strings.TrimSpace(e.Provider)
+	e.Caption = sanitize.Inline(strings.TrimSpace(e.Caption))
+	return validateText("caption", e.Caption, false)
+}
+
+func (e *Embed) html() string {
+	var b strings.Builder
+	b.WriteString(`<figure class="block-embed"`)
+	if e.Provider != "" {
+		b.WriteString(` data-provider="` + html.EscapeString(e.Provider) + `"`)
+	}
+	b.WriteString(">")
+	if e.HTML != "" {
+		b.WriteString(e.HTML)
+	} else {
+		link := html.EscapeString(e.URL)
+		b.WriteString(`<a href="` + link + `">` + link + "</a>")
+	}
+	if e.Caption != "" {
+		b.WriteString("<figcaption>" + e.Caption + "</figcaption>")
+	}
+	b.WriteString("</figure>\n")
+	return b.String()
+}
+
+func (e *Embed) text() string {
+	return joinText(plain(e.Caption), e.URL)
+}
+
+func (c *Callout) validate() error {
+	if c.Variant == "" {
+		c.Variant = CalloutInfo
+	}
+	switch c.Variant {
+	case CalloutInfo, CalloutTip, CalloutWarning, CalloutDanger:
+	default:
+		return fmt.Errorf("unknown variant %q", c.Variant)
+	}
+	c.Title = sanitize.Inline(strings.TrimSpace(c.Title))
+	c.Text = sanitize.Inline(strings.TrimSpace(c.Text))
+	if err := validateText("title", c.Title, false); err != nil {
+		return err
+	}
+	return validateText("text", c.Text, true)
+}
+
+func (c *Callout) html() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<aside class="block-callout callout-%s" role="note">`, c.Variant)
+	if c.Title != "" {
+		b.WriteString("<p><strong>" + c.Title + "</strong></p>")
+	}
+	b.WriteString("<p>" + c.Text + "</p></aside>\n")
+	return b.String()
+}
+
+func (c *Callout) text() string {
+	return joinText(plain(c.Title), plain(c.Text))
+}
+
+// AnchorID derives a heading anchor from its text, e.g. "Getting Started!" becomes "getting-started"
+func AnchorID(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	id := strings.TrimSuffix(b.String(), "-")
+	if id == "" {
+		return "section"
+	}
+	return id
+}
+
+func validateText(field, value string, required bool) error {
+	if required && plain(value) == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	if len(value) > MaxTextLength {
+		return fmt.Errorf("%s is longer than %d bytes", field, MaxTextLength)
+	}
+	return nil
+}
+
+// isSafeURL accepts absolute http(s) URLs and, when relative is set, site-relative paths
+func isSafeURL(raw string, relative bool) bool {
+	if relative && strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// plain converts sanitized inline HTML to text
+func plain(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(s, ""))), " ")
+}
+
+func joinText(parts ...string) string {
+	kept := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -6,6 +6,7 @@ import (
 	"go-next/pkg/storage"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/joho/godotenv"
@@ -42,14 +43,137 @@ type WhatsAppConfig struct {
 	Session string
 }
 
+type SearchConfig struct {
+	Engine    string
+	IndexPath string
+}
+
+// ReactionConfig lists the emoji users can react with besides like
+type ReactionConfig struct {
+	Emojis []string
+}
+
+// ModerationConfig tunes the handling of reported content
+type ModerationConfig struct {
+	// ReportHideAfter is how many distinct open reports hide a post or comment; 0 never hides
+	ReportHideAfter int
+}
+
+// CommentConfig tunes comment moderation
+type CommentConfig struct {
+	// TrustedAfter is how many approved comments make a commenter trusted; 0 trusts nobody
+	TrustedAfter int
+	Spam         SpamConfig
+}
+
+// SpamConfig tunes the screening of new comments
+type SpamConfig struct {
+	Enabled bool
+	// Comments scoring SpamThreshold or more are marked as spam; from ReviewThreshold they always wait for a moderator
+	SpamThreshold   float64
+	ReviewThreshold float64
+
+	MaxLinks       int
+	BlockedWords   []string
+	BlockedDomains []string
+	// MinFillSeconds and MinIntervalSeconds flag comments sent faster than a person writes them
+	MinFillSeconds     int
+	MinIntervalSeconds int
+
+	// ExternalURL enables a remote spam-checking service
+	ExternalURL string
+	ExternalKey string
+}
+
+// SiteConfig describes the public blog used in feeds and absolute links
+type SiteConfig struct {
+	Name        string
+	URL         string
+	Description string
+	Language    string
+	FeedItems   int
+	FeedContent string
+
+	// Image is the fallback social sharing image; TwitterHandle is the site's @account
+	Image         string
+	TwitterHandle string
+
+	// SitemapImages adds an image sitemap built from media attached to posts
+	SitemapImages bool
+
+	// APIURL is the public base URL of this API, used in links sent by email
+	APIURL string
+
+	// Locales are the content locales; Language is the default and is always supported
+	Locales []string
+}
+
+// LocalePrefix returns the path prefix of a locale's pages: none for the default language, "/pt-br" otherwise
+func (s SiteConfig) LocalePrefix(locale string) string {
+	if locale == "" || strings.EqualFold(locale, s.Language) {
+		return ""
+	}
+	return "/" + strings.ToLower(locale)
+}
+
+// LocalizedPostURL returns the public URL of a post in a locale
+func (s SiteConfig) LocalizedPostURL(locale, slug string) string {
+	return strings.TrimRight(s.URL, "/") + s.LocalePrefix(locale) + "/blog/" + slug
+}
+
+// LocalizedCategoryURL returns the public URL of a category in a locale
+func (s SiteConfig) LocalizedCategoryURL(locale, slug string) string {
+	return strings.TrimRight(s.URL, "/") + s.LocalePrefix(locale) + "/blog/category/" + slug
+}
+
+// PostURL returns the public URL of a post
+func (s SiteConfig) PostURL(slug string) string {
+	return strings.TrimRight(s.URL, "/") + "/blog/" + slug
+}
+
+// CategoryURL returns the public URL of a category
+func (s SiteConfig) CategoryURL(slug string) string {
+	return strings.TrimRight(s.URL, "/") + "/blog/category/" + slug
+}
+
+// SeriesURL returns the public URL of a series
+func (s SiteConfig) SeriesURL(slug string) string {
+	return strings.TrimRight(s.URL, "/") + "/blog/series/" + slug
+}
+
+// AuthorURL returns the public URL of an author's page
+func (s SiteConfig) AuthorURL(username string) string {
+	return strings.TrimRight(s.URL, "/") + "/blog/authors/" + username
+}
+
+// TagURL returns the public URL of a tag
+func (s SiteConfig) TagURL(slug string) string {
+	return strings.TrimRight(s.URL, "/") + "/blog/tag/" + slug
+}
+
+// SitemapURL returns the public URL of a child sitemap, such as "posts-1"
+func (s SiteConfig) SitemapURL(name string) string {
+	return strings.TrimRight(s.URL, "/") + "/sitemaps/" + name + ".xml"
+}
+
+// AbsoluteURL returns the public URL of a path on the site
+func (s SiteConfig) AbsoluteURL(path string) string {
+	return strings.TrimRight(s.URL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
 type Configuration struct {
-	Database  DatabaseConfig
-	Port      string
-	JwtSecret string
-	SMTP      email.SMTPConfig
-	Redis     redis.RedisConfig
-	Storage   storage.StorageConfig
-	WhatsApp  WhatsAppConfig
+	Database   DatabaseConfig
+	Port       string
+	JwtSecret  string
+	SMTP       email.SMTPConfig
+	Redis      redis.RedisConfig
+	Storage    storage.StorageConfig
+	WhatsApp   WhatsAppConfig
+	Search     SearchConfig
+	Site       SiteConfig
+	Comments   CommentConfig
+	Reactions  ReactionConfig
+	Moderation ModerationConfig
 }
 
 var (
@@ -117,6 +241,45 @@ func LoadConfig() {
 			BaseURL: getEnvOrDefault("WHATSAPP_BASE_URL", "http://localhost:3000"),
 			Session: getEnvOrDefault("WHATSAPP_SESSION", "default"),
 		},
+		Search: SearchConfig{
+			Engine:    getEnvWithDefault("SEARCH_ENGINE", "database"),
+			IndexPath: getEnvWithDefault("SEARCH_INDEX_PATH", "./data/search.bleve"),
+		},
+		Site: SiteConfig{
+			Name:        getEnvWithDefault("SITE_NAME", "Go Next Blog"),
+			URL:         getEnvWithDefault("SITE_URL", "http://localhost:3000"),
+			Description: getEnvWithDefault("SITE_DESCRIPTION", "Latest posts"),
+			Language:    getEnvWithDefault("SITE_LANGUAGE", "en"),
+			FeedItems:   getEnvAsInt("FEED_ITEMS", 20),
+			FeedContent: getEnvWithDefault("FEED_CONTENT", "excerpt"),
+
+			Image:         os.Getenv("SITE_IMAGE"),
+			TwitterHandle: os.Getenv("SITE_TWITTER_HANDLE"),
+			SitemapImages: getEnvWithDefault("SITEMAP_IMAGES", "true") == "true",
+			APIURL:        getEnvWithDefault("API_URL", "http://localhost:8080/api/v1"),
+			Locales:       getEnvAsList("SITE_LOCALES"),
+		},
+		Comments: CommentConfig{
+			TrustedAfter: getEnvAsInt("COMMENT_TRUSTED_AFTER", 3),
+			Spam: SpamConfig{
+				Enabled:            getEnvWithDefault("SPAM_ENABLED", "true") == "true",
+				SpamThreshold:      getEnvAsFloat("SPAM_THRESHOLD", 0.9),
+				ReviewThreshold:    getEnvAsFloat("SPAM_REVIEW_THRESHOLD", 0.5),
+				MaxLinks:           getEnvAsInt("SPAM_MAX_LINKS", 2),
+				BlockedWords:       getEnvAsList("SPAM_BLOCKED_WORDS"),
+				BlockedDomains:     getEnvAsList("SPAM_BLOCKED_DOMAINS"),
+				MinFillSeconds:     getEnvAsInt("SPAM_MIN_FILL_SECONDS", 3),
+				MinIntervalSeconds: getEnvAsInt("SPAM_MIN_INTERVAL_SECONDS", 15),
+				ExternalURL:        os.Getenv("SPAM_EXTERNAL_URL"),
+				ExternalKey:        os.Getenv("SPAM_EXTERNAL_KEY"),
+			},
+		},
+		Reactions: ReactionConfig{
+			Emojis: getEnvAsList("REACTION_EMOJIS"),
+		},
+		Moderation: ModerationConfig{
+			ReportHideAfter: getEnvAsInt("REPORT_HIDE_AFTER", 3),
+		},
 	}
 }
 
@@ -127,6 +290,16 @@ func getEnvWithDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvAsList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvAsInt(name string, defaultVal int) int {
 	valStr := os.Getenv(name)
 	if valStr == "" {
@@ -139,6 +312,18 @@ func getEnvAsInt(name string, defaultVal int) int {
 	return val
 }
 
+func getEnvAsFloat(name string, defaultVal float64) float64 {
+	valStr := os.Getenv(name)
+	if valStr == "" {
+		return defaultVal
+	}
+	val, err := strconv.ParseFloat(valStr, 64)
+	if err != nil {
+		return defaultVal
+	}
+	return val
+}
+
 func getEnvOrDefault(name, defaultVal string) string {
 	val := os.Getenv(name)
 	if val == "" {
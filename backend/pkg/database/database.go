@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -24,6 +25,9 @@ var (
 	DB *gorm.DB
 )
 
+// localeTag matches the BCP 47 tags accepted as the site language
+var localeTag = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
 type Database struct {
 	*gorm.DB
 }
@@ -48,6 +52,11 @@ func Setup() error {
 
 // AutoMigrate performs database migrations for all models
 func AutoMigrate() error {
+	// Locale columns default to the site language
+	if err := setLocaleDefault(config.GetConfig().Site.Language, &models.Post{}, &models.Category{}); err != nil {
+		return err
+	}
+
 	// Auto-migrate all models
 	err := DB.AutoMigrate(
 		&models.User{},
@@ -62,9 +71,114 @@ func AutoMigrate() error {
 		&models.RefreshToken{},
 		&models.VerificationToken{},
 		&models.Notification{},
+		&models.PostViewDaily{},
+		&models.AnalyticsDailyRollup{},
+		&models.UserLogin{},
+		&models.SEOMeta{},
+		&models.SlugHistory{},
+		&models.Redirect{},
+		&models.Series{},
+		&models.SeriesPost{},
+		&models.AuthorProfile{},
+		&models.PostAuthor{},
+		&models.CommentRule{},
+		&models.SpamToken{},
+		&models.Mention{},
+		&models.Subscription{},
+		&models.Reaction{},
+		&models.Report{},
+		&models.ModerationLog{},
 	)
+	if err != nil {
+		return err
+	}
+
+	// Slugs are unique per locale; drop the single-column indexes of older schemas
+	legacyIndexes := []struct {
+		model interface{}
+		name  string
+	}{
+		{&models.Post{}, "idx_posts_slug"},
+		{&models.Category{}, "idx_categories_slug"},
+	}
+	for _, index := range legacyIndexes {
+		if DB.Migrator().HasIndex(index.model, index.name) {
+			if err := DB.Migrator().DropIndex(index.model, index.name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return ProtectModerationLog(DB)
+}
 
-	return err
+// moderationLogTriggers reject changes to the moderation log in the database
+// itself, so raw SQL and other clients cannot rewrite it either
+var moderationLogTriggers = map[string][]string{
+	"postgres": {
+		`CREATE OR REPLACE FUNCTION reject_moderation_log_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'moderation log entries cannot be changed';
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS moderation_logs_append_only ON moderation_logs`,
+		`CREATE TRIGGER moderation_logs_append_only BEFORE UPDATE OR DELETE ON moderation_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_moderation_log_change()`,
+		`DROP TRIGGER IF EXISTS moderation_logs_no_truncate ON moderation_logs`,
+		`CREATE TRIGGER moderation_logs_no_truncate BEFORE TRUNCATE ON moderation_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_moderation_log_change()`,
+	},
+	"mysql": {
+		`DROP TRIGGER IF EXISTS moderation_logs_no_update`,
+		`CREATE TRIGGER moderation_logs_no_update BEFORE UPDATE ON moderation_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'moderation log entries cannot be changed'`,
+		`DROP TRIGGER IF EXISTS moderation_logs_no_delete`,
+		`CREATE TRIGGER moderation_logs_no_delete BEFORE DELETE ON moderation_logs
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'moderation log entries cannot be changed'`,
+	},
+	"sqlite": {
+		`CREATE TRIGGER IF NOT EXISTS moderation_logs_no_update BEFORE UPDATE ON moderation_logs
+BEGIN
+    SELECT RAISE(ABORT, 'moderation log entries cannot be changed');
+END`,
+		`CREATE TRIGGER IF NOT EXISTS moderation_logs_no_delete BEFORE DELETE ON moderation_logs
+BEGIN
+    SELECT RAISE(ABORT, 'moderation log entries cannot be changed');
+END`,
+	},
+}
+
+// ProtectModerationLog installs the triggers that keep the moderation log
+// append-only. Drivers without triggers here rely on the model's hooks.
+func ProtectModerationLog(db *gorm.DB) error {
+	for _, statement := range moderationLogTriggers[db.Dialector.Name()] {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to protect the moderation log: %w", err)
+		}
+	}
+	return nil
+}
+
+// setLocaleDefault sets the default of the models' locale column in their
+// parsed schemas, which AutoMigrate then applies to the tables
+func setLocaleDefault(language string, models ...interface{}) error {
+	if !localeTag.MatchString(language) {
+		return fmt.Errorf("invalid site language %q", language)
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		field := stmt.Schema.LookUpField("locale")
+		if field == nil {
+			return fmt.Errorf("%s has no locale column", stmt.Schema.Table)
+		}
+		field.HasDefaultValue = true
+		field.DefaultValue = "'" + language + "'"
+		field.DefaultValueInterface = language
+	}
+	return nil
 }
 
 func CreateDatabaseConnection(configuration *config.Configuration) (*gorm.DB, error) {
@@ -21,10 +21,18 @@ func NewEmailService(cfg SMTPConfig) *EmailService {
 }
 
 func (s *EmailService) SendEmail(to, subject, body string) error {
+	return s.SendEmailWithHeaders(to, subject, body, nil)
+}
+
+// SendEmailWithHeaders sends an email with extra headers, such as List-Unsubscribe
+func (s *EmailService) SendEmailWithHeaders(to, subject, body string, headers map[string]string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.config.From)
 	m.SetHeader("To", to)
 	m.SetHeader("Subject", subject)
+	for name, value := range headers {
+		m.SetHeader(name, value)
+	}
 	m.SetBody("text/html", body)
 	d := gomail.NewDialer(s.config.Host, s.config.Port, s.config.Username, s.config.Password)
 	return d.DialAndSend(m)
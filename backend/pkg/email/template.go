@@ -1,6 +1,9 @@
 package email
 
-import "fmt"
+import (
+	"fmt"
+	"html"
+)
 
 func UserWelcomeTemplate(username string) string {
 	return fmt.Sprintf(`
@@ -31,3 +34,53 @@ func PhoneVerificationTemplate(username, code string) string {
 		Hello, %s!\nYour phone verification code is: %s\nIf you did not request this, please ignore this message.
 	`, username, code)
 }
+
+// CommentNotificationTemplate tells a subscriber about a new comment. Every
+// argument is escaped, as titles and excerpts are written by users.
+func CommentNotificationTemplate(username, heading, excerpt, commentURL, unsubscribeURL string) string {
+	return fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Hello, %s!</h2>
+			<p>%s</p>
+			<blockquote>%s</blockquote>
+			<p><a href="%s">View the comment</a></p>
+			<p style="font-size: small">You receive this because you follow this conversation. <a href="%s">Unsubscribe</a></p>
+		</body>
+		</html>
+	`, html.EscapeString(username), html.EscapeString(heading), html.EscapeString(excerpt),
+		html.EscapeString(commentURL), html.EscapeString(unsubscribeURL))
+}
+
+// UnsubscribeConfirmPage asks to confirm cancelling a subscription from an
+// email link. Opening the link only shows this page, so link scanners and
+// prefetchers cannot unsubscribe anyone; the form posts to actionURL.
+func UnsubscribeConfirmPage(postTitle, actionURL string) string {
+	return fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Unsubscribe</h2>
+			<p>Stop receiving emails about new comments on %s?</p>
+			<form method="post" action="%s">
+				<button type="submit">Unsubscribe</button>
+			</form>
+		</body>
+		</html>
+	`, html.EscapeString(postTitle), html.EscapeString(actionURL))
+}
+
+// UnsubscribedPage confirms that a subscription is cancelled
+func UnsubscribedPage(postTitle string) string {
+	message := "You are no longer subscribed."
+	if postTitle != "" {
+		message = fmt.Sprintf("You will no longer receive emails about new comments on %s.", html.EscapeString(postTitle))
+	}
+	return fmt.Sprintf(`
+		<html>
+		<body>
+			<h2>Unsubscribed</h2>
+			<p>%s</p>
+		</body>
+		</html>
+	`, message)
+}
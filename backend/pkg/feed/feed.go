@@ -0,0 +1,329 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Feed is a format-neutral syndication feed
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	Language    string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is a single feed entry
+type Item struct {
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	Content string
+	Author  string
+	// Contributors are the co-authors credited after Author
+	Contributors []string
+	Categories   []string
+	Published    time.Time
+	Updated      time.Time
+	Enclosures   []Enclosure
+	Alternates   []Alternate
+}
+
+// Enclosure is a media file attached to an item
+type Enclosure struct {
+	URL      string
+	MimeType string
+	Length   int64
+}
+
+// Alternate is a translation of an item in another language
+type Alternate struct {
+	Hreflang string
+	Href     string
+}
+
+// RSS renders the feed as RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		Description:   f.Description,
+		Language:      f.Language,
+		LastBuildDate: f.Updated.Format(time.RFC1123Z),
+		AtomLink:      &atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+	}
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:        item.Title,
+			Link:         item.Link,
+			GUID:         rssGUID{Value: item.Link, IsPermaLink: true},
+			Description:  item.Summary,
+			Creator:      item.Author,
+			Contributors: item.Contributors,
+			Categories:   item.Categories,
+			PubDate:      item.Published.Format(time.RFC1123Z),
+		}
+		if item.Content != "" {
+			entry.Content = &rssContent{Value: item.Content}
+		}
+		// RSS allows a single enclosure per item
+		if len(item.Enclosures) > 0 {
+			enclosure := item.Enclosures[0]
+			entry.Enclosure = &rssEnclosure{URL: enclosure.URL, Type: enclosure.MimeType, Length: enclosure.Length}
+		}
+		for _, alternate := range item.Alternates {
+			entry.AtomLinks = append(entry.AtomLinks, atomLink{Href: alternate.Href, Rel: "alternate", Type: "text/html", Hreflang: alternate.Hreflang})
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+
+	return marshalXML(rss{
+		Version:      "2.0",
+		AtomNS:       "http://www.w3.org/2005/Atom",
+		ContentNS:    "http://purl.org/rss/1.0/modules/content/",
+		DublinCoreNS: "http://purl.org/dc/elements/1.1/",
+		Channel:      channel,
+	})
+}
+
+// Atom renders the feed as Atom 1.0
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		NS:       "http://www.w3.org/2005/Atom",
+		Lang:     f.Language,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "html", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		if item.Author != "" {
+			entry.Author = &atomAuthor{Name: item.Author}
+		}
+		for _, contributor := range item.Contributors {
+			entry.Contributors = append(entry.Contributors, atomAuthor{Name: contributor})
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		for _, enclosure := range item.Enclosures {
+			entry.Links = append(entry.Links, atomLink{Href: enclosure.URL, Rel: "enclosure", Type: enclosure.MimeType, Length: enclosure.Length})
+		}
+		for _, alternate := range item.Alternates {
+			entry.Links = append(entry.Links, atomLink{Href: alternate.Href, Rel: "alternate", Type: "text/html", Hreflang: alternate.Hreflang})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshalXML(doc)
+}
+
+// JSON renders the feed as JSON Feed 1.1
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       make([]jsonItem, 0, len(f.Items)),
+	}
+	for _, item := range f.Items {
+		entry := jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.Content,
+			Summary:       item.Summary,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		// JSON Feed requires content_html or content_text
+		if entry.ContentHTML == "" {
+			entry.ContentHTML = item.Summary
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonAuthor{{Name: item.Author}}
+		}
+		for _, contributor := range item.Contributors {
+			entry.Authors = append(entry.Authors, jsonAuthor{Name: contributor})
+		}
+		for _, enclosure := range item.Enclosures {
+			entry.Attachments = append(entry.Attachments, jsonAttachment{URL: enclosure.URL, MimeType: enclosure.MimeType, SizeInBytes: enclosure.Length})
+		}
+		for _, alternate := range item.Alternates {
+			entry.Translations = append(entry.Translations, jsonTranslation{Language: alternate.Hreflang, URL: alternate.Href})
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+
+	return json.Marshal(doc)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+type rss struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	AtomNS       string     `xml:"xmlns:atom,attr"`
+	ContentNS    string     `xml:"xmlns:content,attr"`
+	DublinCoreNS string     `xml:"xmlns:dc,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	AtomLink      *atomLink `xml:"atom:link"`
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title        string        `xml:"title"`
+	Link         string        `xml:"link"`
+	GUID         rssGUID       `xml:"guid"`
+	Description  string        `xml:"description"`
+	Content      *rssContent   `xml:"content:encoded"`
+	Creator      string        `xml:"dc:creator,omitempty"`
+	Contributors []string      `xml:"dc:contributor"`
+	Categories   []string      `xml:"category"`
+	PubDate      string        `xml:"pubDate"`
+	Enclosure    *rssEnclosure `xml:"enclosure"`
+	AtomLinks    []atomLink    `xml:"atom:link"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Type   string `xml:"type,attr"`
+	Length int64  `xml:"length,attr"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	NS       string      `xml:"xmlns,attr"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID           string         `xml:"id"`
+	Title        string         `xml:"title"`
+	Published    string         `xml:"published"`
+	Updated      string         `xml:"updated"`
+	Links        []atomLink     `xml:"link"`
+	Author       *atomAuthor    `xml:"author"`
+	Contributors []atomAuthor   `xml:"contributor"`
+	Categories   []atomCategory `xml:"category"`
+	Summary      *atomText      `xml:"summary"`
+	Content      *atomText      `xml:"content"`
+}
+
+type atomLink struct {
+	Href     string `xml:"href,attr"`
+	Rel      string `xml:"rel,attr,omitempty"`
+	Type     string `xml:"type,attr,omitempty"`
+	Hreflang string `xml:"hreflang,attr,omitempty"`
+	Length   int64  `xml:"length,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url,omitempty"`
+	FeedURL     string     `json:"feed_url,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url,omitempty"`
+	Title         string           `json:"title,omitempty"`
+	ContentHTML   string           `json:"content_html,omitempty"`
+	Summary       string           `json:"summary,omitempty"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonAuthor     `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Attachments   []jsonAttachment `json:"attachments,omitempty"`
+
+	// Translations is an extension; JSON Feed has no field for alternate languages
+	Translations []jsonTranslation `json:"_translations,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	SizeInBytes int64  `json:"size_in_bytes,omitempty"`
+}
+
+type jsonTranslation struct {
+	Language string `json:"language"`
+	URL      string `json:"url"`
+}
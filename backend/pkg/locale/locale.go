@@ -0,0 +1,127 @@
+package locale
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// XDefault is the hreflang value of the page shown to unmatched languages
+const XDefault = "x-default"
+
+// Normalize canonicalizes a BCP 47 tag, e.g. "en_us" becomes "en-US"
+func Normalize(tag string) (string, bool) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	if tag == "" {
+		return "", false
+	}
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", false
+	}
+	return parsed.String(), true
+}
+
+// Matcher negotiates the locale of a request among the supported locales
+type Matcher struct {
+	supported []string
+	tags      []language.Tag
+	matcher   language.Matcher
+	fallback  string
+}
+
+// NewMatcher creates a matcher; fallback is used when nothing matches and ends every chain.
+// Invalid and duplicate locales are ignored.
+func NewMatcher(supported []string, fallback string) *Matcher {
+	m := &Matcher{}
+	if normalized, ok := Normalize(fallback); ok {
+		fallback = normalized
+	}
+	m.fallback = fallback
+
+	// The fallback comes first so the language matcher returns it when nothing matches
+	seen := map[string]bool{}
+	for _, tag := range append([]string{fallback}, supported...) {
+		normalized, ok := Normalize(tag)
+		if !ok || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		m.supported = append(m.supported, normalized)
+		m.tags = append(m.tags, language.MustParse(normalized))
+	}
+	m.matcher = language.NewMatcher(m.tags)
+	return m
+}
+
+// Supported returns the supported locales, the fallback first
+func (m *Matcher) Supported() []string {
+	return append([]string(nil), m.supported...)
+}
+
+// Fallback returns the locale used when nothing matches
+func (m *Matcher) Fallback() string {
+	return m.fallback
+}
+
+// IsSupported reports whether a locale is supported, returning its canonical form
+func (m *Matcher) IsSupported(tag string) (string, bool) {
+	normalized, ok := Normalize(tag)
+	if !ok {
+		return "", false
+	}
+	for _, supported := range m.supported {
+		if supported == normalized {
+			return supported, true
+		}
+	}
+	return "", false
+}
+
+// Negotiate picks the best supported locale for an explicit choice such as
+// ?lang=, falling back to an Accept-Language header and then the fallback locale
+func (m *Matcher) Negotiate(explicit, acceptLanguage string) string {
+	if explicit != "" {
+		if tag, err := language.Parse(strings.ReplaceAll(explicit, "_", "-")); err == nil {
+			if _, index, confidence := m.matcher.Match(tag); confidence != language.No {
+				return m.supported[index]
+			}
+		}
+	}
+	if acceptLanguage != "" {
+		if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+			if _, index, confidence := m.matcher.Match(tags...); confidence != language.No {
+				return m.supported[index]
+			}
+		}
+	}
+	return m.fallback
+}
+
+// Chain returns a locale followed by the supported locales it falls back to:
+// its parents, then the fallback locale. pt-BR gives [pt-BR pt en] when pt
+// is supported and en is the fallback.
+func (m *Matcher) Chain(tag string) []string {
+	chain := []string{}
+	add := func(candidate string) {
+		for _, existing := range chain {
+			if existing == candidate {
+				return
+			}
+		}
+		chain = append(chain, candidate)
+	}
+
+	if normalized, ok := m.IsSupported(tag); ok {
+		add(normalized)
+		for parent := language.MustParse(normalized).Parent(); parent != language.Und; parent = parent.Parent() {
+			if supported, ok := m.IsSupported(parent.String()); ok {
+				add(supported)
+			}
+		}
+	}
+	if m.fallback != "" {
+		add(m.fallback)
+	}
+	return chain
+}
@@ -0,0 +1,224 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// WordsPerMinute is the reading speed used for reading time estimates
+const WordsPerMinute = 200
+
+// AnchorClass is the class of the link appended to every heading
+const AnchorClass = "heading-anchor"
+
+// Result is rendered Markdown with the facts derived from it
+type Result struct {
+	HTML        string     `json:"html"`
+	Text        string     `json:"text"`
+	TOC         []TOCEntry `json:"toc"`
+	WordCount   int        `json:"word_count"`
+	ReadingTime int        `json:"reading_time"`
+}
+
+// TOCEntry is a heading in the table of contents, with the headings nested under it
+type TOCEntry struct {
+	Level    int        `json:"level"`
+	Text     string     `json:"text"`
+	ID       string     `json:"id"`
+	Children []TOCEntry `json:"children,omitempty"`
+}
+
+// Heading is a heading found while rendering
+type Heading struct {
+	Level int
+	Text  string
+	ID    string
+}
+
+// Renderer converts CommonMark with GFM tables, footnotes and highlighted code to HTML.
+// It is safe for concurrent use.
+type Renderer struct {
+	md goldmark.Markdown
+}
+
+// NewRenderer creates a renderer; code is highlighted with CSS classes so the
+// output survives HTML sanitization and can be themed by the site stylesheet
+func NewRenderer() *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			extension.Footnote,
+			highlighting.NewHighlighting(
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+		),
+	)
+	return &Renderer{md: md}
+}
+
+// Render converts Markdown source to HTML
+func (r *Renderer) Render(source []byte) (*Result, error) {
+	doc := r.md.Parser().Parse(text.NewReader(source))
+
+	plain := plainText(doc, source)
+	headings := addHeadingAnchors(doc, source)
+	words := countWords(doc, source)
+
+	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		HTML:        buf.String(),
+		Text:        plain,
+		TOC:         BuildTOC(headings),
+		WordCount:   words,
+		ReadingTime: ReadingTime(words),
+	}, nil
+}
+
+// ReadingTime estimates whole minutes to read a number of words, at least one for any text
+func ReadingTime(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// CountWords counts the words of plain text
+func CountWords(s string) int {
+	return len(strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '\'' && r != '-')
+	}))
+}
+
+// BuildTOC nests headings under the closest preceding heading of a higher level
+func BuildTOC(headings []Heading) []TOCEntry {
+	root := &TOCEntry{}
+	stack := []*TOCEntry{root}
+	for _, h := range headings {
+		for len(stack) > 1 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, TOCEntry{Level: h.Level, Text: h.Text, ID: h.ID})
+		stack = append(stack, &parent.Children[len(parent.Children)-1])
+	}
+	return root.Children
+}
+
+// addHeadingAnchors appends a self link to every heading and returns the headings in order
+func addHeadingAnchors(doc ast.Node, source []byte) []Heading {
+	var headings []Heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+		value, ok := heading.AttributeString("id")
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		id := string(value.([]byte))
+		headings = append(headings, Heading{Level: heading.Level, Text: nodeText(heading, source), ID: id})
+
+		link := ast.NewLink()
+		link.Destination = []byte("#" + id)
+		link.SetAttributeString("class", []byte(AnchorClass))
+		link.AppendChild(link, ast.NewString([]byte("#")))
+		heading.AppendChild(heading, link)
+
+		return ast.WalkSkipChildren, nil
+	})
+	return headings
+}
+
+// countWords counts the words of prose, leaving out code blocks and raw HTML
+func countWords(doc ast.Node, source []byte) int {
+	words := 0
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			words += CountWords(string(node.Segment.Value(source)))
+		case *ast.CodeSpan:
+			words += CountWords(nodeText(node, source))
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return words
+}
+
+// plainText extracts the text of every paragraph, heading and code block,
+// one block per line, leaving out raw HTML
+func plainText(doc ast.Node, source []byte) string {
+	var lines []string
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || n.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.HTMLBlock:
+			return ast.WalkSkipChildren, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			var b strings.Builder
+			segments := node.Lines()
+			for i := 0; i < segments.Len(); i++ {
+				segment := segments.At(i)
+				b.Write(segment.Value(source))
+			}
+			if code := strings.TrimRight(b.String(), "\n"); code != "" {
+				lines = append(lines, code)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		if n.FirstChild() != nil && n.FirstChild().Type() == ast.TypeInline {
+			if line := nodeText(n, source); line != "" {
+				lines = append(lines, line)
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.Join(lines, "\n")
+}
+
+// nodeText concatenates the text under a node
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := child.(type) {
+		case *ast.Text:
+			b.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(string(util.UnescapePunctuations([]byte(b.String()))))
+}
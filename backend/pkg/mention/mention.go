@@ -0,0 +1,42 @@
+// Package mention finds @username mentions in user-written text.
+package mention
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxPerText caps the mentions taken from one text, so a comment cannot page everyone
+const MaxPerText = 20
+
+// Usernames are 3 to 50 letters, digits and underscores
+const (
+	minUsername = 3
+	maxUsername = 50
+)
+
+var (
+	// mentionPattern matches an @ that does not follow a word, so e-mail addresses are not mentions
+	mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@./\-])@([A-Za-z0-9_]+)`)
+	tagPattern     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Parse returns the distinct usernames mentioned in text, in order of first
+// mention and lower-cased. Markup is ignored, so attributes do not mention anyone.
+func Parse(text string) []string {
+	text = tagPattern.ReplaceAllString(text, " ")
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.ToLower(match[1])
+		if len(username) < minUsername || len(username) > maxUsername || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+		if len(usernames) == MaxPerText {
+			break
+		}
+	}
+	return usernames
+}
@@ -0,0 +1,269 @@
+// Package nestedset checks and rebuilds nested-set trees, where every node
+// holds an interval [Left, Right] enclosing the intervals of its descendants.
+package nestedset
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Node is the position of one row of a tree
+type Node struct {
+	ID        uuid.UUID
+	ParentID  *uuid.UUID
+	Left      int
+	Right     int
+	Depth     int
+	Ordering  int
+	CreatedAt time.Time
+}
+
+// Kinds of issues found by Check
+const (
+	// IssueBounds is a node without a valid interval, such as one never placed in the tree
+	IssueBounds = "bounds"
+	// IssueGap is a range of positions no node uses
+	IssueGap = "gap"
+	// IssueOverlap is a position used twice, or intervals that cross
+	IssueOverlap = "overlap"
+	// IssueOrphan is a node whose parent does not exist
+	IssueOrphan = "orphan"
+	// IssueCycle is a node that is its own ancestor through ParentID
+	IssueCycle = "cycle"
+	// IssueParent is a node whose ParentID is not the node enclosing it
+	IssueParent = "parent"
+	// IssueDepth is a node whose depth does not match its nesting
+	IssueDepth = "depth"
+)
+
+// Issue is an inconsistency of a tree; ID is empty for issues of positions
+type Issue struct {
+	Kind   string    `json:"kind"`
+	ID     uuid.UUID `json:"id"`
+	Detail string    `json:"detail"`
+}
+
+// Check finds the inconsistencies between the intervals, depths and parents
+// of a tree's nodes
+func Check(nodes []Node) []Issue {
+	var issues []Issue
+	byID := make(map[uuid.UUID]*Node, len(nodes))
+	for i := range nodes {
+		byID[nodes[i].ID] = &nodes[i]
+	}
+
+	for i := range nodes {
+		node := &nodes[i]
+		if node.ParentID != nil {
+			if _, ok := byID[*node.ParentID]; !ok {
+				issues = append(issues, Issue{IssueOrphan, node.ID, fmt.Sprintf("parent %s does not exist", node.ParentID)})
+			}
+		}
+		if inCycle(node, byID) {
+			issues = append(issues, Issue{IssueCycle, node.ID, "is its own ancestor"})
+		}
+	}
+
+	var placed []*Node
+	used := make(map[int]int, 2*len(nodes))
+	highest := 0
+	for i := range nodes {
+		node := &nodes[i]
+		if node.Left <= 0 || node.Right <= node.Left || (node.Right-node.Left)%2 == 0 {
+			issues = append(issues, Issue{IssueBounds, node.ID, fmt.Sprintf("invalid interval [%d, %d]", node.Left, node.Right)})
+			continue
+		}
+		placed = append(placed, node)
+		used[node.Left]++
+		used[node.Right]++
+		highest = max(highest, node.Right)
+	}
+
+	// The intervals of n nodes use every position from 1 to 2n once
+	last := max(highest, 2*len(placed))
+	gapStart := 0
+	for position := 1; position <= last; position++ {
+		count := used[position]
+		if count > 1 {
+			issues = append(issues, Issue{Kind: IssueOverlap, Detail: fmt.Sprintf("position %d is used %d times", position, count)})
+		}
+		if count == 0 && gapStart == 0 {
+			gapStart = position
+		} else if count > 0 && gapStart != 0 {
+			issues = append(issues, Issue{Kind: IssueGap, Detail: positionRange(gapStart, position-1) + " unused"})
+			gapStart = 0
+		}
+	}
+	if gapStart != 0 {
+		issues = append(issues, Issue{Kind: IssueGap, Detail: positionRange(gapStart, last) + " unused"})
+	}
+
+	sort.SliceStable(placed, func(i, j int) bool { return placed[i].Left < placed[j].Left })
+	var stack []*Node
+	for _, node := range placed {
+		for len(stack) > 0 && stack[len(stack)-1].Right < node.Left {
+			stack = stack[:len(stack)-1]
+		}
+		var enclosing *Node
+		if len(stack) > 0 {
+			enclosing = stack[len(stack)-1]
+			if node.Right > enclosing.Right {
+				issues = append(issues, Issue{IssueOverlap, node.ID, fmt.Sprintf("interval [%d, %d] crosses [%d, %d] of %s",
+					node.Left, node.Right, enclosing.Left, enclosing.Right, enclosing.ID)})
+			}
+		}
+
+		switch {
+		case enclosing == nil && node.ParentID != nil:
+			issues = append(issues, Issue{IssueParent, node.ID, fmt.Sprintf("has parent %s but is not inside it", node.ParentID)})
+		case enclosing != nil && (node.ParentID == nil || *node.ParentID != enclosing.ID):
+			issues = append(issues, Issue{IssueParent, node.ID, fmt.Sprintf("is inside %s but its parent is %s", enclosing.ID, describeParent(node.ParentID))})
+		}
+		if node.Depth != len(stack) {
+			issues = append(issues, Issue{IssueDepth, node.ID, fmt.Sprintf("depth is %d, nesting is %d", node.Depth, len(stack))})
+		}
+		stack = append(stack, node)
+	}
+	return issues
+}
+
+// Rebuild lays a tree out again from ParentID, ordering siblings by Ordering,
+// then CreatedAt. Orphans become roots, as does one node of each cycle; their
+// IDs are returned as detached. The rebuilt nodes are returned in tree order.
+func Rebuild(nodes []Node) ([]Node, []uuid.UUID) {
+	byID := make(map[uuid.UUID]*Node, len(nodes))
+	for i := range nodes {
+		byID[nodes[i].ID] = &nodes[i]
+	}
+
+	var roots []*Node
+	children := make(map[uuid.UUID][]*Node)
+	var detached []uuid.UUID
+	for i := range nodes {
+		node := &nodes[i]
+		switch {
+		case node.ParentID == nil:
+			roots = append(roots, node)
+		case byID[*node.ParentID] == nil:
+			detached = append(detached, node.ID)
+			roots = append(roots, node)
+		default:
+			children[*node.ParentID] = append(children[*node.ParentID], node)
+		}
+	}
+
+	reached := make(map[uuid.UUID]bool, len(nodes))
+	var reach func(node *Node)
+	reach = func(node *Node) {
+		reached[node.ID] = true
+		for _, child := range children[node.ID] {
+			if !reached[child.ID] {
+				reach(child)
+			}
+		}
+	}
+	for _, root := range roots {
+		reach(root)
+	}
+
+	// What the roots do not reach hangs off a cycle; cut each cycle at its first node
+	var unreached []*Node
+	for i := range nodes {
+		if !reached[nodes[i].ID] {
+			unreached = append(unreached, &nodes[i])
+		}
+	}
+	sortSiblings(unreached)
+	for _, node := range unreached {
+		if reached[node.ID] || !inCycle(node, byID) {
+			continue
+		}
+		parent := *node.ParentID
+		siblings := children[parent][:0]
+		for _, sibling := range children[parent] {
+			if sibling != node {
+				siblings = append(siblings, sibling)
+			}
+		}
+		children[parent] = siblings
+		detached = append(detached, node.ID)
+		roots = append(roots, node)
+		reach(node)
+	}
+
+	sortSiblings(roots)
+	for _, siblings := range children {
+		sortSiblings(siblings)
+	}
+
+	rebuilt := make([]Node, 0, len(nodes))
+	position := 0
+	var place func(node *Node, parentID *uuid.UUID, depth int)
+	place = func(node *Node, parentID *uuid.UUID, depth int) {
+		position++
+		index := len(rebuilt)
+		rebuilt = append(rebuilt, *node)
+		rebuilt[index].ParentID = parentID
+		rebuilt[index].Left = position
+		rebuilt[index].Depth = depth
+		id := node.ID
+		for _, child := range children[node.ID] {
+			place(child, &id, depth+1)
+		}
+		position++
+		rebuilt[index].Right = position
+	}
+	for _, root := range roots {
+		place(root, nil, 0)
+	}
+	return rebuilt, detached
+}
+
+func sortSiblings(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.Ordering != b.Ordering {
+			return a.Ordering < b.Ordering
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+}
+
+// inCycle reports whether walking up from node through ParentID comes back to it
+func inCycle(node *Node, byID map[uuid.UUID]*Node) bool {
+	current := node
+	for steps := 0; steps < len(byID); steps++ {
+		if current.ParentID == nil {
+			return false
+		}
+		parent, ok := byID[*current.ParentID]
+		if !ok {
+			return false
+		}
+		if parent.ID == node.ID {
+			return true
+		}
+		current = parent
+	}
+	return false
+}
+
+func positionRange(from, to int) string {
+	if from == to {
+		return fmt.Sprintf("position %d is", from)
+	}
+	return fmt.Sprintf("positions %d-%d are", from, to)
+}
+
+func describeParent(parentID *uuid.UUID) string {
+	if parentID == nil {
+		return "empty"
+	}
+	return parentID.String()
+}
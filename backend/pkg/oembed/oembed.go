@@ -0,0 +1,339 @@
+package oembed
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-next/pkg/sanitize"
+)
+
+// Version is the oEmbed specification version
+const Version = "1.0"
+
+// Response types
+const (
+	TypePhoto = "photo"
+	TypeVideo = "video"
+	TypeLink  = "link"
+	TypeRich  = "rich"
+)
+
+// MaxResponseSize limits how much of a provider response is read
+const MaxResponseSize = 1 << 20
+
+var (
+	ErrNoProvider     = errors.New("no oEmbed provider for url")
+	ErrProviderFailed = errors.New("oEmbed provider request failed")
+)
+
+// Response is an oEmbed response; see https://oembed.com/#section2.3
+type Response struct {
+	XMLName         xml.Name `json:"-" xml:"oembed"`
+	Type            string   `json:"type" xml:"type"`
+	Version         string   `json:"version" xml:"version"`
+	Title           string   `json:"title,omitempty" xml:"title,omitempty"`
+	AuthorName      string   `json:"author_name,omitempty" xml:"author_name,omitempty"`
+	AuthorURL       string   `json:"author_url,omitempty" xml:"author_url,omitempty"`
+	ProviderName    string   `json:"provider_name,omitempty" xml:"provider_name,omitempty"`
+	ProviderURL     string   `json:"provider_url,omitempty" xml:"provider_url,omitempty"`
+	CacheAge        int      `json:"cache_age,omitempty" xml:"cache_age,omitempty"`
+	ThumbnailURL    string   `json:"thumbnail_url,omitempty" xml:"thumbnail_url,omitempty"`
+	ThumbnailWidth  int      `json:"thumbnail_width,omitempty" xml:"thumbnail_width,omitempty"`
+	ThumbnailHeight int      `json:"thumbnail_height,omitempty" xml:"thumbnail_height,omitempty"`
+	URL             string   `json:"url,omitempty" xml:"url,omitempty"`
+	HTML            string   `json:"html,omitempty" xml:"html,omitempty"`
+	Width           int      `json:"width,omitempty" xml:"width,omitempty"`
+	Height          int      `json:"height,omitempty" xml:"height,omitempty"`
+}
+
+// Options are the optional consumer request parameters
+type Options struct {
+	MaxWidth  int
+	MaxHeight int
+}
+
+// Provider is a site whose URLs can be embedded. Providers without an oEmbed
+// endpoint build the response themselves with Build. FrameHosts are the hosts
+// the provider's embed markup may load in an iframe; other frames, scripts
+// and styles are removed from its responses.
+type Provider struct {
+	Name       string
+	URL        string
+	Endpoint   string
+	Schemes    []string
+	FrameHosts []string
+	Build      func(u *url.URL, opts Options) (*Response, error)
+
+	patterns []*regexp.Regexp
+}
+
+// Matches reports whether a URL matches one of the provider's URL schemes
+func (p *Provider) Matches(rawURL string) bool {
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(rawURL) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registry maps URLs to providers; the first registered match wins
+type Registry struct {
+	providers []*Provider
+}
+
+// NewRegistry creates a registry of providers
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds a provider. Schemes use * as a wildcard, as in the oEmbed
+// specification: "https://www.youtube.com/watch*".
+func (r *Registry) Register(p Provider) {
+	provider := p
+	provider.patterns = make([]*regexp.Regexp, 0, len(p.Schemes))
+	for _, scheme := range p.Schemes {
+		parts := strings.Split(scheme, "*")
+		for i := range parts {
+			parts[i] = regexp.QuoteMeta(parts[i])
+		}
+		provider.patterns = append(provider.patterns, regexp.MustCompile("^"+strings.Join(parts, ".*")+"$"))
+	}
+	r.providers = append(r.providers, &provider)
+}
+
+// Match returns the provider of a URL
+func (r *Registry) Match(rawURL string) (*Provider, bool) {
+	for _, p := range r.providers {
+		if p.Matches(rawURL) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// DefaultProviders are the providers registered out of the box
+func DefaultProviders() []Provider {
+	return []Provider{
+		{
+			Name:     "YouTube",
+			URL:      "https://www.youtube.com/",
+			Endpoint: "https://www.youtube.com/oembed",
+			Schemes: []string{
+				"https://www.youtube.com/watch*", "https://youtube.com/watch*", "https://m.youtube.com/watch*",
+				"https://www.youtube.com/shorts/*", "https://youtu.be/*",
+			},
+			FrameHosts: []string{"www.youtube.com", "www.youtube-nocookie.com"},
+		},
+		{
+			Name:       "Vimeo",
+			URL:        "https://vimeo.com/",
+			Endpoint:   "https://vimeo.com/api/oembed.json",
+			Schemes:    []string{"https://vimeo.com/*", "https://player.vimeo.com/video/*"},
+			FrameHosts: []string{"player.vimeo.com"},
+		},
+		{
+			Name:     "Twitter",
+			URL:      "https://twitter.com/",
+			Endpoint: "https://publish.twitter.com/oembed",
+			Schemes:  []string{"https://twitter.com/*/status/*", "https://x.com/*/status/*", "https://mobile.twitter.com/*/status/*"},
+		},
+		{
+			Name:       "GitHub Gist",
+			URL:        "https://gist.github.com/",
+			Schemes:    []string{"https://gist.github.com/*/*"},
+			FrameHosts: []string{"gist.github.com"},
+			Build:      buildGist,
+		},
+		{
+			Name:       "Spotify",
+			URL:        "https://spotify.com/",
+			Endpoint:   "https://open.spotify.com/oembed",
+			Schemes:    []string{"https://open.spotify.com/*"},
+			FrameHosts: []string{"open.spotify.com"},
+		},
+		{
+			Name:       "SoundCloud",
+			URL:        "https://soundcloud.com/",
+			Endpoint:   "https://soundcloud.com/oembed",
+			Schemes:    []string{"https://soundcloud.com/*"},
+			FrameHosts: []string{"w.soundcloud.com"},
+		},
+		{
+			Name:       "CodePen",
+			URL:        "https://codepen.io/",
+			Endpoint:   "https://codepen.io/api/oembed",
+			Schemes:    []string{"https://codepen.io/*/pen/*"},
+			FrameHosts: []string{"codepen.io"},
+		},
+	}
+}
+
+// gistID matches the path of a gist: /user/0123abcd
+var gistID = regexp.MustCompile(`^/[A-Za-z0-9-]+/[0-9a-f]+$`)
+
+// buildGist embeds a gist through its HTML view in a frame, as GitHub has no
+// oEmbed endpoint and its own embed is a script
+func buildGist(u *url.URL, _ Options) (*Response, error) {
+	path := strings.TrimSuffix(u.Path, ".js")
+	if !gistID.MatchString(path) {
+		return nil, fmt.Errorf("%w: not a gist url", ErrProviderFailed)
+	}
+	return &Response{
+		Type:         TypeRich,
+		Version:      Version,
+		ProviderName: "GitHub Gist",
+		ProviderURL:  "https://gist.github.com/",
+		HTML:         fmt.Sprintf(`<iframe src="https://gist.github.com%s.pibb" title="Gist" loading="lazy"></iframe>`, path),
+	}, nil
+}
+
+// HTTPClient sends provider requests; *http.Client satisfies it, and tests
+// can substitute the client of a local stub server
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client resolves URLs to embeds through a provider registry
+type Client struct {
+	Registry   *Registry
+	HTTPClient HTTPClient
+}
+
+// NewClient creates a consumer; a nil httpClient uses a client with a 10 second timeout
+func NewClient(registry *Registry, httpClient HTTPClient) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{Registry: registry, HTTPClient: httpClient}
+}
+
+// Fetch resolves a URL to an embed
+func (c *Client) Fetch(ctx context.Context, rawURL string, opts Options) (*Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("%w: %s", ErrNoProvider, rawURL)
+	}
+	provider, ok := c.Registry.Match(rawURL)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoProvider, rawURL)
+	}
+	if provider.Build != nil {
+		response, err := provider.Build(u, opts)
+		if err != nil {
+			return nil, err
+		}
+		return provider.sanitize(response)
+	}
+
+	endpoint, err := url.Parse(provider.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid endpoint for %s", ErrProviderFailed, provider.Name)
+	}
+	query := endpoint.Query()
+	query.Set("url", rawURL)
+	query.Set("format", "json")
+	if opts.MaxWidth > 0 {
+		query.Set("maxwidth", strconv.Itoa(opts.MaxWidth))
+	}
+	if opts.MaxHeight > 0 {
+		query.Set("maxheight", strconv.Itoa(opts.MaxHeight))
+	}
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrProviderFailed, provider.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s returned %d", ErrProviderFailed, provider.Name, resp.StatusCode)
+	}
+
+	var response Response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxResponseSize)).Decode(&response); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrProviderFailed, provider.Name, err)
+	}
+	if response.ProviderName == "" {
+		response.ProviderName = provider.Name
+	}
+	return provider.sanitize(&response)
+}
+
+// sanitize restricts a response's markup to the provider's frames, then
+// validates it, so a response left without markup is rejected
+func (p *Provider) sanitize(response *Response) (*Response, error) {
+	response.HTML = sanitize.Embed(response.HTML, p.FrameHosts)
+	if err := response.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrProviderFailed, p.Name, err)
+	}
+	return response, nil
+}
+
+// validate checks the parameters required for the response type
+func (r *Response) validate() error {
+	switch r.Type {
+	case TypePhoto:
+		if r.URL == "" {
+			return errors.New("photo without url")
+		}
+	case TypeVideo, TypeRich:
+		if r.HTML == "" {
+			return fmt.Errorf("%s without html", r.Type)
+		}
+	case TypeLink:
+	default:
+		return fmt.Errorf("unknown type %q", r.Type)
+	}
+	return nil
+}
+
+// EmbedHTML returns the markup to embed; links have none
+func (r *Response) EmbedHTML() string {
+	switch r.Type {
+	case TypeVideo, TypeRich:
+		return r.HTML
+	case TypePhoto:
+		size := ""
+		if r.Width > 0 && r.Height > 0 {
+			size = fmt.Sprintf(` width="%d" height="%d"`, r.Width, r.Height)
+		}
+		return fmt.Sprintf(`<img src="%s" alt="%s"%s loading="lazy">`, html.EscapeString(r.URL), html.EscapeString(r.Title), size)
+	}
+	return ""
+}
+
+// JSON encodes the response
+func (r *Response) JSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// XML encodes the response as a standalone document
+func (r *Response) XML() ([]byte, error) {
+	body, err := xml.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(`<?xml version="1.0" encoding="utf-8" standalone="yes"?>`), body...), nil
+}
@@ -13,6 +13,12 @@ type RedisConfig struct {
 	DB       int
 }
 
+// ScoredMember is a sorted set member with its score
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
 type RedisService struct {
 	Client *redis.Client
 }
@@ -66,6 +72,163 @@ func (r *RedisService) SetNX(ctx context.Context, key string, value interface{},
 	return r.Client.SetNX(ctx, key, value, expiration).Result()
 }
 
+func (r *RedisService) Expire(ctx context.Context, key string, expiration time.Duration) error {
+	return r.Client.Expire(ctx, key, expiration).Err()
+}
+
+func (r *RedisService) PFAdd(ctx context.Context, key string, els ...interface{}) (int64, error) {
+	return r.Client.PFAdd(ctx, key, els...).Result()
+}
+
+func (r *RedisService) SAdd(ctx context.Context, key string, members ...interface{}) error {
+	return r.Client.SAdd(ctx, key, members...).Err()
+}
+
+// SPopN removes and returns up to count random members of a set
+func (r *RedisService) SPopN(ctx context.Context, key string, count int64) ([]string, error) {
+	return r.Client.SPopN(ctx, key, count).Result()
+}
+
+func (r *RedisService) HIncrBy(ctx context.Context, key, field string, incr int64) (int64, error) {
+	return r.Client.HIncrBy(ctx, key, field, incr).Result()
+}
+
+// hIncrByIfExistsScript increments a hash field only while the hash exists
+var hIncrByIfExistsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// HIncrByIfExists increments field of an existing hash in one atomic step,
+// reporting whether the hash existed; a missing hash is not created
+func (r *RedisService) HIncrByIfExists(ctx context.Context, key, field string, incr int64) (bool, error) {
+	incremented, err := hIncrByIfExistsScript.Run(ctx, r.Client, []string{key}, field, incr).Int()
+	return incremented == 1, err
+}
+
+func (r *RedisService) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.Client.HGetAll(ctx, key).Result()
+}
+
+// HGetAllMany reads several hashes in one round trip; missing hashes are empty
+func (r *RedisService) HGetAllMany(ctx context.Context, keys ...string) ([]map[string]string, error) {
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	if _, err := r.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HGetAll(ctx, key)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	hashes := make([]map[string]string, len(keys))
+	for i, cmd := range cmds {
+		hashes[i] = cmd.Val()
+	}
+	return hashes, nil
+}
+
+func (r *RedisService) HSet(ctx context.Context, key string, values map[string]interface{}) error {
+	return r.Client.HSet(ctx, key, values).Err()
+}
+
+// delIfEqualScript deletes a key only while it still holds the given value
+var delIfEqualScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// DelIfEqual deletes key if its value is still value, reporting whether it did
+func (r *RedisService) DelIfEqual(ctx context.Context, key, value string) (bool, error) {
+	deleted, err := delIfEqualScript.Run(ctx, r.Client, []string{key}, value).Int()
+	return deleted == 1, err
+}
+
+// hGetAllDelScript returns the fields and values of each hash and deletes them
+var hGetAllDelScript = redis.NewScript(`
+local result = {}
+for i, key in ipairs(KEYS) do
+	result[i] = redis.call('HGETALL', key)
+	redis.call('DEL', key)
+end
+return result
+`)
+
+// HGetAllDel reads and deletes hashes in one atomic step, so each value is
+// claimed by exactly one caller
+func (r *RedisService) HGetAllDel(ctx context.Context, keys ...string) ([]map[string]string, error) {
+	reply, err := hGetAllDelScript.Run(ctx, r.Client, keys).Slice()
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]map[string]string, len(keys))
+	for i := range hashes {
+		hashes[i] = map[string]string{}
+		if i >= len(reply) {
+			continue
+		}
+		pairs, _ := reply[i].([]interface{})
+		for j := 0; j+1 < len(pairs); j += 2 {
+			field, _ := pairs[j].(string)
+			value, _ := pairs[j+1].(string)
+			hashes[i][field] = value
+		}
+	}
+	return hashes, nil
+}
+
+func (r *RedisService) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]ScoredMember, error) {
+	results, err := r.Client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	if err != nil {
+		return nil, err
+	}
+	members := make([]ScoredMember, 0, len(results))
+	for _, z := range results {
+		member, _ := z.Member.(string)
+		members = append(members, ScoredMember{Member: member, Score: z.Score})
+	}
+	return members, nil
+}
+
+// zIncrByDecayedScript adds weight·exp(rate·(now − epoch)) to a member's
+// score, setting the epoch to now if the set has none yet
+var zIncrByDecayedScript = redis.NewScript(`
+redis.call('SETNX', KEYS[2], ARGV[4])
+local epoch = tonumber(redis.call('GET', KEYS[2]))
+local increment = tonumber(ARGV[2]) * math.exp(tonumber(ARGV[3]) * (tonumber(ARGV[4]) - epoch))
+return redis.call('ZINCRBY', KEYS[1], increment, ARGV[1])
+`)
+
+// ZIncrByDecayed adds a forward-decayed increment to a member of the sorted
+// set key, whose reference time in Unix seconds is stored at epochKey. The
+// epoch is read and applied in one step, so an increment cannot be weighted
+// against an epoch that a concurrent ZRebase has already replaced.
+func (r *RedisService) ZIncrByDecayed(ctx context.Context, key, epochKey, member string, weight, rate float64, now int64) error {
+	return zIncrByDecayedScript.Run(ctx, r.Client, []string{key, epochKey}, member, weight, rate, now).Err()
+}
+
+// zRebaseScript scales every score by exp(−rate·(now − epoch)), moves the
+// epoch to now and drops the members scored below the minimum
+var zRebaseScript = redis.NewScript(`
+local epoch = tonumber(redis.call('GET', KEYS[2]) or ARGV[2])
+local factor = math.exp(-tonumber(ARGV[1]) * (tonumber(ARGV[2]) - epoch))
+redis.call('ZUNIONSTORE', KEYS[1], 1, KEYS[1], 'WEIGHTS', factor)
+redis.call('SET', KEYS[2], ARGV[2])
+return redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[3])
+`)
+
+// ZRebase moves the forward-decayed sorted set key onto the epoch now and
+// drops members whose score falls below minScore, atomically with respect
+// to ZIncrByDecayed
+func (r *RedisService) ZRebase(ctx context.Context, key, epochKey string, rate float64, now int64, minScore float64) error {
+	return zRebaseScript.Run(ctx, r.Client, []string{key, epochKey}, rate, now, minScore).Err()
+}
+
 func (r *RedisService) Close() error {
 	return r.Client.Close()
 }
@@ -0,0 +1,175 @@
+package sanitize
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+)
+
+var (
+	// classNames matches space separated CSS class names
+	classNames = regexp.MustCompile(`^[a-zA-Z0-9_\- ]+$`)
+
+	// fragmentID matches the ids of headings and footnotes
+	fragmentID = regexp.MustCompile(`^[a-zA-Z0-9_\-:.]+$`)
+)
+
+var (
+	inlinePolicy  = newInlinePolicy()
+	commentPolicy = newCommentPolicy()
+	editorPolicy  = newEditorPolicy()
+)
+
+// Inline sanitizes the text of structured content blocks. Only inline
+// formatting and links are kept.
+func Inline(html string) string {
+	return inlinePolicy.Sanitize(html)
+}
+
+// Comment sanitizes HTML written by readers. Only inline formatting, lists,
+// quotes, code and links are kept; links are marked nofollow.
+func Comment(html string) string {
+	return commentPolicy.Sanitize(html)
+}
+
+// Editor sanitizes HTML written by authors. On top of the comment policy it
+// keeps headings with anchors, images, tables, figures, footnotes and the
+// classes used by syntax highlighting.
+func Editor(html string) string {
+	return editorPolicy.Sanitize(html)
+}
+
+// Embed sanitizes the markup of an external embed. Iframes are kept only when
+// their https source is on one of frameHosts; scripts, styles and event
+// handlers are dropped, leaving fallback content such as a quoted post.
+func Embed(markup string, frameHosts []string) string {
+	return dropSourcelessFrames(newEmbedPolicy(frameHosts).Sanitize(markup))
+}
+
+// dropSourcelessFrames removes the iframes whose source the policy rejected;
+// their other attributes would otherwise keep them as empty frames
+func dropSourcelessFrames(markup string) string {
+	if !strings.Contains(markup, "<iframe") {
+		return markup
+	}
+
+	var b strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(markup))
+	skipping := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				return ""
+			}
+			return b.String()
+		}
+		token := tokenizer.Token()
+		if token.Data == "iframe" {
+			switch tokenType {
+			case html.StartTagToken, html.SelfClosingTagToken:
+				if !hasAttr(token, "src") {
+					if tokenType == html.StartTagToken {
+						skipping++
+					}
+					continue
+				}
+			case html.EndTagToken:
+				if skipping > 0 {
+					skipping--
+					continue
+				}
+			}
+		}
+		b.WriteString(token.String())
+	}
+}
+
+func hasAttr(token html.Token, key string) bool {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func newInlinePolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowStandardURLs()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoReferrerOnFullyQualifiedLinks(true)
+
+	p.AllowElements("br", "b", "strong", "i", "em", "u", "s", "del", "sub", "sup", "code", "mark", "kbd")
+
+	return p
+}
+
+func newCommentPolicy() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowStandardURLs()
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	p.AllowElements("p", "br", "b", "strong", "i", "em", "u", "s", "del", "sub", "sup",
+		"code", "pre", "blockquote", "ul", "ol", "li")
+
+	return p
+}
+
+func newEmbedPolicy(frameHosts []string) *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+
+	p.AllowURLSchemes("https")
+	p.RequireParseableURLs(true)
+	p.AllowAttrs("href").OnElements("a")
+	p.RequireNoFollowOnLinks(true)
+	p.RequireNoReferrerOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	p.AllowElements("p", "br", "b", "strong", "i", "em", "blockquote", "figure", "figcaption")
+	p.AllowAttrs("class").Matching(classNames).OnElements("blockquote", "p")
+	p.AllowAttrs("src", "alt").OnElements("img")
+	p.AllowAttrs("width", "height").Matching(bluemonday.Integer).OnElements("img", "iframe")
+
+	if len(frameHosts) > 0 {
+		hosts := make([]string, len(frameHosts))
+		for i, host := range frameHosts {
+			hosts[i] = regexp.QuoteMeta(strings.ToLower(host))
+		}
+		p.AllowAttrs("src").Matching(regexp.MustCompile(`^https://(` + strings.Join(hosts, "|") + `)(/|$)`)).OnElements("iframe")
+		p.AllowAttrs("title").OnElements("iframe")
+		p.AllowAttrs("allow").Matching(regexp.MustCompile(`^[a-z\-]+( [^;]*)?(; ?[a-z\-]+( [^;]*)?)*;?$`)).OnElements("iframe")
+		p.AllowAttrs("allowfullscreen").OnElements("iframe")
+		p.AllowAttrs("frameborder").Matching(bluemonday.Integer).OnElements("iframe")
+		p.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("iframe")
+		p.AllowAttrs("referrerpolicy").Matching(regexp.MustCompile(`^[a-z\-]+$`)).OnElements("iframe")
+	}
+
+	return p
+}
+
+func newEditorPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	p.AllowAttrs("id").Matching(fragmentID).OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup", "div", "section")
+	p.AllowAttrs("class").Matching(classNames).Globally()
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("a", "div", "section")
+	p.AllowAttrs("tabindex").Matching(regexp.MustCompile(`^0$`)).OnElements("pre")
+
+	p.AllowElements("figure", "figcaption", "section", "mark", "kbd", "abbr", "details", "summary")
+	p.AllowAttrs("loading").Matching(regexp.MustCompile(`^(lazy|eager)$`)).OnElements("img")
+	p.AllowAttrs("srcset", "sizes").OnElements("img")
+	p.AllowAttrs("open").OnElements("details")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+
+	return p
+}
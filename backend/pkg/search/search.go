@@ -0,0 +1,325 @@
+package search
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// Document types stored in the index
+const (
+	TypePost     = "post"
+	TypeCategory = "category"
+	TypeTag      = "tag"
+	TypeUser     = "user"
+)
+
+// Facet names supported by the engine
+const (
+	FacetCategory = "category"
+	FacetTag      = "tags"
+	FacetAuthor   = "author"
+	FacetYear     = "year"
+)
+
+// FieldVocabulary holds unstemmed title and body terms used for corrections
+const FieldVocabulary = "vocabulary"
+
+// Document is a single searchable record in the embedded index
+type Document struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Excerpt     string    `json:"excerpt"`
+	Body        string    `json:"body"`
+	Category    string    `json:"category"`
+	Tags        []string  `json:"tags"`
+	Author      string    `json:"author"`
+	Year        string    `json:"year"`
+	ViewCount   int64     `json:"view_count"`
+	PublishedAt time.Time `json:"published_at"`
+	// Locale is the language of a post; Translations lists the locales its
+	// translation group is published in, its own included
+	Locale       string   `json:"locale"`
+	Translations []string `json:"translations"`
+}
+
+// DocID returns the index identifier for a record of the given type
+func DocID(docType, id string) string {
+	return docType + ":" + id
+}
+
+// SplitDocID splits an index identifier into its type and record ID
+func SplitDocID(docID string) (string, string) {
+	parts := strings.SplitN(docID, ":", 2)
+	if len(parts) != 2 {
+		return "", docID
+	}
+	return parts[0], parts[1]
+}
+
+// Query describes a search against the index
+type Query struct {
+	Text      string
+	Type      string
+	Filters   map[string]string
+	Page      int
+	PerPage   int
+	FacetSize int
+	// Locales is a locale chain, most preferred first. Posts in a later locale
+	// only match when their translation group has no post in an earlier one.
+	Locales []string
+}
+
+// Hit is a single matching document
+type Hit struct {
+	ID    string  `json:"id"`
+	Type  string  `json:"type"`
+	Score float64 `json:"score"`
+}
+
+// FacetTerm is a single facet value with its document count
+type FacetTerm struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// Result holds the matching hits and facet counts of a search
+type Result struct {
+	Hits   []Hit                  `json:"hits"`
+	Total  uint64                 `json:"total"`
+	Facets map[string][]FacetTerm `json:"facets"`
+}
+
+// Engine wraps a Bleve index on local disk
+type Engine struct {
+	index bleve.Index
+	path  string
+}
+
+// Open opens the index at path, creating it when it does not exist yet
+func Open(path string) (*Engine, error) {
+	index, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, buildMapping())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{index: index, path: path}, nil
+}
+
+// Recreate drops the index on disk and opens an empty one in its place
+func Recreate(path string) (*Engine, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+	return Open(path)
+}
+
+// Index adds or replaces a document
+func (e *Engine) Index(doc Document) error {
+	return e.index.Index(DocID(doc.Type, doc.ID), doc)
+}
+
+// IndexBatch adds or replaces several documents in one batch
+func (e *Engine) IndexBatch(docs []Document) error {
+	batch := e.index.NewBatch()
+	for _, doc := range docs {
+		if err := batch.Index(DocID(doc.Type, doc.ID), doc); err != nil {
+			return err
+		}
+	}
+	return e.index.Batch(batch)
+}
+
+// Delete removes a document
+func (e *Engine) Delete(docType, id string) error {
+	return e.index.Delete(DocID(docType, id))
+}
+
+// Count returns the number of documents in the index
+func (e *Engine) Count() (uint64, error) {
+	return e.index.DocCount()
+}
+
+// Close closes the underlying index
+func (e *Engine) Close() error {
+	return e.index.Close()
+}
+
+// Search runs a full-text query with typo tolerance and facet counts
+func (e *Engine) Search(q Query) (*Result, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PerPage < 1 {
+		q.PerPage = 10
+	}
+	if q.FacetSize < 1 {
+		q.FacetSize = 10
+	}
+
+	conjuncts := []query.Query{textQuery(q.Text)}
+	if q.Type != "" {
+		conjuncts = append(conjuncts, termQuery("type", q.Type))
+	}
+	for field, value := range q.Filters {
+		if value != "" {
+			conjuncts = append(conjuncts, termQuery(field, value))
+		}
+	}
+	if len(q.Locales) > 0 {
+		conjuncts = append(conjuncts, localeQuery(q.Locales))
+	}
+
+	req := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(conjuncts...), q.PerPage, (q.Page-1)*q.PerPage, false)
+	for _, facet := range []string{FacetCategory, FacetTag, FacetAuthor, FacetYear} {
+		req.AddFacet(facet, bleve.NewFacetRequest(facet, q.FacetSize))
+	}
+
+	res, err := e.index.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Hits:   make([]Hit, 0, len(res.Hits)),
+		Total:  res.Total,
+		Facets: make(map[string][]FacetTerm),
+	}
+	for _, hit := range res.Hits {
+		docType, id := SplitDocID(hit.ID)
+		result.Hits = append(result.Hits, Hit{ID: id, Type: docType, Score: hit.Score})
+	}
+	for name, facet := range res.Facets {
+		terms := []FacetTerm{}
+		if facet.Terms != nil {
+			for _, term := range facet.Terms.Terms() {
+				terms = append(terms, FacetTerm{Term: term.Term, Count: term.Count})
+			}
+		}
+		result.Facets[name] = terms
+	}
+
+	return result, nil
+}
+
+// textQuery matches the text against title, excerpt and body, allowing for typos
+func textQuery(text string) query.Query {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return bleve.NewMatchAllQuery()
+	}
+
+	fields := map[string]float64{"title": 3, "excerpt": 1.5, "body": 1}
+	fuzziness := fuzzinessFor(text)
+
+	var disjuncts []query.Query
+	for field, boost := range fields {
+		exact := bleve.NewMatchQuery(text)
+		exact.SetField(field)
+		exact.SetBoost(boost * 2)
+		disjuncts = append(disjuncts, exact)
+
+		if fuzziness > 0 {
+			fuzzy := bleve.NewMatchQuery(text)
+			fuzzy.SetField(field)
+			fuzzy.SetFuzziness(fuzziness)
+			fuzzy.SetBoost(boost)
+			disjuncts = append(disjuncts, fuzzy)
+		}
+	}
+
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+// fuzzinessFor picks the edit distance allowed for the shortest word in text
+func fuzzinessFor(text string) int {
+	shortest := 0
+	for _, word := range strings.Fields(text) {
+		if shortest == 0 || len(word) < shortest {
+			shortest = len(word)
+		}
+	}
+	switch {
+	case shortest >= 8:
+		return 2
+	case shortest >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// localeQuery matches documents in the first locale of a chain, and those in a
+// later locale that have no translation in a locale preferred over it
+func localeQuery(chain []string) query.Query {
+	disjuncts := make([]query.Query, 0, len(chain))
+	for i, locale := range chain {
+		match := bleve.NewBooleanQuery()
+		match.AddMust(termQuery("locale", locale))
+		for _, preferred := range chain[:i] {
+			match.AddMustNot(termQuery("translations", preferred))
+		}
+		disjuncts = append(disjuncts, match)
+	}
+	return bleve.NewDisjunctionQuery(disjuncts...)
+}
+
+func termQuery(field, value string) query.Query {
+	q := bleve.NewTermQuery(value)
+	q.SetField(field)
+	return q
+}
+
+// buildMapping declares text fields with the English analyzer and facet fields as keywords
+func buildMapping() mapping.IndexMapping {
+	textField := bleve.NewTextFieldMapping()
+	textField.Analyzer = en.AnalyzerName
+
+	keywordField := bleve.NewTextFieldMapping()
+	keywordField.Analyzer = keyword.Name
+
+	// Indexed alongside title and body without stemming so corrections are real words
+	vocabularyField := bleve.NewTextFieldMapping()
+	vocabularyField.Name = FieldVocabulary
+	vocabularyField.Analyzer = standard.Name
+	vocabularyField.Store = false
+	vocabularyField.IncludeInAll = false
+	vocabularyField.IncludeTermVectors = false
+
+	numericField := bleve.NewNumericFieldMapping()
+	dateField := bleve.NewDateTimeFieldMapping()
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt("id", keywordField)
+	doc.AddFieldMappingsAt("type", keywordField)
+	doc.AddFieldMappingsAt("title", textField, vocabularyField)
+	doc.AddFieldMappingsAt("slug", keywordField)
+	doc.AddFieldMappingsAt("excerpt", textField)
+	doc.AddFieldMappingsAt("body", textField, vocabularyField)
+	doc.AddFieldMappingsAt(FacetCategory, keywordField)
+	doc.AddFieldMappingsAt(FacetTag, keywordField)
+	doc.AddFieldMappingsAt(FacetAuthor, keywordField)
+	doc.AddFieldMappingsAt(FacetYear, keywordField)
+	doc.AddFieldMappingsAt("view_count", numericField)
+	doc.AddFieldMappingsAt("published_at", dateField)
+	doc.AddFieldMappingsAt("locale", keywordField)
+	doc.AddFieldMappingsAt("translations", keywordField)
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = doc
+	indexMapping.DefaultAnalyzer = en.AnalyzerName
+
+	return indexMapping
+}
@@ -0,0 +1,171 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minCorrectionLength is the shortest word that is checked for typos
+const minCorrectionLength = 4
+
+// Correct builds a "did you mean" query by replacing unknown words with the
+// closest, most frequent term of the indexed vocabulary. It returns an empty
+// string when every word is known or no better spelling was found.
+func (e *Engine) Correct(text string) (string, error) {
+	c := newCorrector(text)
+	if len(c.words) == 0 {
+		return "", nil
+	}
+
+	dict, err := e.index.FieldDict(FieldVocabulary)
+	if err != nil {
+		return "", err
+	}
+	defer dict.Close()
+
+	for {
+		entry, err := dict.Next()
+		if err != nil {
+			return "", err
+		}
+		if entry == nil {
+			break
+		}
+		c.observe(entry.Term, entry.Count)
+	}
+
+	return c.result(), nil
+}
+
+// CorrectFrom builds a "did you mean" query like Engine.Correct from a
+// vocabulary of term counts, for sites that search without the index
+func CorrectFrom(text string, vocabulary map[string]uint64) string {
+	c := newCorrector(text)
+	if len(c.words) == 0 {
+		return ""
+	}
+	for term, count := range vocabulary {
+		c.observe(term, count)
+	}
+	return c.result()
+}
+
+// AddVocabulary counts the words of text into vocabulary the way the index
+// vocabulary field does: lower-cased runs of letters and digits
+func AddVocabulary(vocabulary map[string]uint64, text string) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		vocabulary[word]++
+	}
+}
+
+type candidate struct {
+	term     string
+	distance int
+	count    uint64
+}
+
+// corrector finds the closest, most frequent known spelling of each query word
+type corrector struct {
+	words []string
+	known []bool
+	best  []*candidate
+}
+
+func newCorrector(text string) *corrector {
+	words := strings.Fields(strings.ToLower(text))
+	return &corrector{
+		words: words,
+		known: make([]bool, len(words)),
+		best:  make([]*candidate, len(words)),
+	}
+}
+
+// observe considers one vocabulary term; ties go to the first term in lexical order
+func (c *corrector) observe(term string, count uint64) {
+	for i, word := range c.words {
+		if c.known[i] {
+			continue
+		}
+		if term == word {
+			c.known[i] = true
+			continue
+		}
+		if utf8.RuneCountInString(word) < minCorrectionLength {
+			continue
+		}
+
+		maxDistance := fuzzinessFor(word)
+		if abs(utf8.RuneCountInString(term)-utf8.RuneCountInString(word)) > maxDistance {
+			continue
+		}
+		distance := Levenshtein(word, term)
+		if distance > maxDistance {
+			continue
+		}
+		current := c.best[i]
+		if current == nil || distance < current.distance ||
+			(distance == current.distance && (count > current.count || (count == current.count && term < current.term))) {
+			c.best[i] = &candidate{term: term, distance: distance, count: count}
+		}
+	}
+}
+
+// result is the corrected query, or empty when nothing changed
+func (c *corrector) result() string {
+	corrected := make([]string, len(c.words))
+	changed := false
+	for i, word := range c.words {
+		corrected[i] = word
+		if !c.known[i] && c.best[i] != nil {
+			corrected[i] = c.best[i].term
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	return strings.Join(corrected, " ")
+}
+
+// Levenshtein returns the edit distance between two strings
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
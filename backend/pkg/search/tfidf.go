@@ -0,0 +1,89 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Vector is a sparse, L2-normalised TF-IDF term vector
+type Vector map[string]float64
+
+var stopWords = map[string]struct{}{
+	"a": {}, "about": {}, "after": {}, "all": {}, "also": {}, "an": {}, "and": {}, "any": {}, "are": {}, "as": {},
+	"at": {}, "be": {}, "been": {}, "but": {}, "by": {}, "can": {}, "do": {}, "for": {}, "from": {}, "has": {},
+	"have": {}, "how": {}, "if": {}, "in": {}, "into": {}, "is": {}, "it": {}, "its": {}, "more": {}, "not": {},
+	"of": {}, "on": {}, "one": {}, "or": {}, "our": {}, "so": {}, "than": {}, "that": {}, "the": {}, "their": {},
+	"then": {}, "there": {}, "these": {}, "they": {}, "this": {}, "to": {}, "up": {}, "use": {}, "was": {}, "we": {},
+	"were": {}, "what": {}, "when": {}, "which": {}, "will": {}, "with": {}, "you": {}, "your": {},
+}
+
+// Tokenize lowercases text and splits it into words, dropping stop words and single characters
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if len([]rune(field)) < 2 {
+			continue
+		}
+		if _, stop := stopWords[field]; stop {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// BuildVectors computes TF-IDF vectors for a corpus keyed by document ID
+func BuildVectors(docs map[string]string) map[string]Vector {
+	termCounts := make(map[string]map[string]int, len(docs))
+	docFreq := make(map[string]int)
+
+	for id, text := range docs {
+		counts := make(map[string]int)
+		for _, token := range Tokenize(text) {
+			counts[token]++
+		}
+		for term := range counts {
+			docFreq[term]++
+		}
+		termCounts[id] = counts
+	}
+
+	total := float64(len(docs))
+	vectors := make(map[string]Vector, len(docs))
+	for id, counts := range termCounts {
+		vector := make(Vector, len(counts))
+		var norm float64
+		for term, count := range counts {
+			// Smoothed IDF keeps terms present in every document from vanishing entirely
+			weight := (1 + math.Log(float64(count))) * math.Log(1+total/float64(docFreq[term]))
+			vector[term] = weight
+			norm += weight * weight
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for term := range vector {
+				vector[term] /= norm
+			}
+		}
+		vectors[id] = vector
+	}
+
+	return vectors
+}
+
+// Cosine returns the cosine similarity of two normalised vectors
+func Cosine(a, b Vector) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot float64
+	for term, weight := range a {
+		dot += weight * b[term]
+	}
+	return dot
+}
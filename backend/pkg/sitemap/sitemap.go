@@ -0,0 +1,145 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the most URLs the sitemap protocol allows in a single file
+const MaxURLs = 50000
+
+const (
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	imageNS   = "http://www.google.com/schemas/sitemap-image/1.1"
+	xhtmlNS   = "http://www.w3.org/1999/xhtml"
+)
+
+// URL is a page listed in a sitemap
+type URL struct {
+	Loc     string
+	LastMod time.Time
+	Images  []Image
+
+	// Alternates are the language versions of the page, the page itself included
+	Alternates []Alternate
+}
+
+// Alternate is a language version of a page; Hreflang is a BCP 47 tag or x-default
+type Alternate struct {
+	Hreflang string
+	Href     string
+}
+
+// Image is an image shown on a page
+type Image struct {
+	Loc string
+}
+
+// Entry is a child sitemap listed in a sitemap index
+type Entry struct {
+	Loc     string
+	LastMod time.Time
+}
+
+// Pages returns how many files are needed for n URLs; there is always at least one
+func Pages(n int) int {
+	if n <= MaxURLs {
+		return 1
+	}
+	return (n + MaxURLs - 1) / MaxURLs
+}
+
+// Page returns the URLs that belong in the 1-based page of a paginated sitemap
+func Page(urls []URL, page int) []URL {
+	start := (page - 1) * MaxURLs
+	if page < 1 || start >= len(urls) {
+		return nil
+	}
+	end := start + MaxURLs
+	if end > len(urls) {
+		end = len(urls)
+	}
+	return urls[start:end]
+}
+
+// URLSet renders a sitemap of pages, declaring the image and xhtml namespaces when needed
+func URLSet(urls []URL) ([]byte, error) {
+	doc := urlSet{NS: sitemapNS, URLs: make([]urlEntry, 0, len(urls))}
+	for _, u := range urls {
+		entry := urlEntry{Loc: u.Loc, LastMod: formatLastMod(u.LastMod)}
+		for _, image := range u.Images {
+			entry.Images = append(entry.Images, imageEntry{Loc: image.Loc})
+		}
+		if len(entry.Images) > 0 {
+			doc.ImageNS = imageNS
+		}
+		for _, alternate := range u.Alternates {
+			entry.Alternates = append(entry.Alternates, linkEntry{Rel: "alternate", Hreflang: alternate.Hreflang, Href: alternate.Href})
+		}
+		if len(entry.Alternates) > 0 {
+			doc.XHTMLNS = xhtmlNS
+		}
+		doc.URLs = append(doc.URLs, entry)
+	}
+	return marshal(doc)
+}
+
+// Index renders a sitemap index of child sitemaps
+func Index(entries []Entry) ([]byte, error) {
+	doc := sitemapIndex{NS: sitemapNS, Sitemaps: make([]indexEntry, 0, len(entries))}
+	for _, e := range entries {
+		doc.Sitemaps = append(doc.Sitemaps, indexEntry{Loc: e.Loc, LastMod: formatLastMod(e.LastMod)})
+	}
+	return marshal(doc)
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	NS      string     `xml:"xmlns,attr"`
+	ImageNS string     `xml:"xmlns:image,attr,omitempty"`
+	XHTMLNS string     `xml:"xmlns:xhtml,attr,omitempty"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc        string       `xml:"loc"`
+	LastMod    string       `xml:"lastmod,omitempty"`
+	Alternates []linkEntry  `xml:"xhtml:link"`
+	Images     []imageEntry `xml:"image:image"`
+}
+
+type linkEntry struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type imageEntry struct {
+	Loc string `xml:"image:loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	NS       string       `xml:"xmlns,attr"`
+	Sitemaps []indexEntry `xml:"sitemap"`
+}
+
+type indexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
@@ -0,0 +1,179 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Defaults of the naive-Bayes filter
+const (
+	// DefaultMinTraining is how many spam and ham comments each must be seen before the filter scores
+	DefaultMinTraining = 10
+	// DefaultInterestingTokens is how many of the most telling tokens are combined
+	DefaultInterestingTokens = 15
+
+	maxTokenLength = 40
+)
+
+// tokenPattern splits text into words, keeping URLs' host parts together
+var (
+	tokenPattern = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}'._-]*`)
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// TokenCounts is how many spam and ham documents contained a token
+type TokenCounts struct {
+	Spam int64
+	Ham  int64
+}
+
+// BayesStore keeps the token counts of a naive-Bayes filter
+type BayesStore interface {
+	// Counts returns the counts of tokens, and the number of spam and ham documents seen
+	Counts(ctx context.Context, tokens []string) (map[string]TokenCounts, TokenCounts, error)
+	// Add adds delta to the counts of tokens and to the document count of one class
+	Add(ctx context.Context, tokens []string, spam bool, delta int64) error
+}
+
+// Bayes is a naive-Bayes filter over the distinct words of a submission
+type Bayes struct {
+	Store BayesStore
+	// MinTraining and InterestingTokens default to DefaultMinTraining and DefaultInterestingTokens
+	MinTraining       int64
+	InterestingTokens int
+}
+
+// NewBayes creates a filter over a store
+func NewBayes(store BayesStore) *Bayes {
+	return &Bayes{Store: store, MinTraining: DefaultMinTraining, InterestingTokens: DefaultInterestingTokens}
+}
+
+// Name identifies the classifier in scores
+func (b *Bayes) Name() string {
+	return "bayes"
+}
+
+// Classify combines the spam probabilities of the most telling tokens.
+// Until the filter has seen enough of both classes it scores 0.
+func (b *Bayes) Classify(ctx context.Context, s Submission) (Score, error) {
+	tokens := Tokenize(s.Content)
+	counts, docs, err := b.Store.Counts(ctx, tokens)
+	if err != nil {
+		return Score{}, err
+	}
+	if docs.Spam < b.MinTraining || docs.Ham < b.MinTraining {
+		return Score{Reasons: []string{fmt.Sprintf("not enough training yet (%d spam, %d ham)", docs.Spam, docs.Ham)}}, nil
+	}
+
+	// Laplace-smoothed log-likelihood ratio of each token
+	type evidence struct {
+		token string
+		ratio float64
+	}
+	evidences := make([]evidence, 0, len(tokens))
+	for _, token := range tokens {
+		c := counts[token]
+		if c.Spam+c.Ham == 0 {
+			continue
+		}
+		pSpam := float64(c.Spam+1) / float64(docs.Spam+2)
+		pHam := float64(c.Ham+1) / float64(docs.Ham+2)
+		evidences = append(evidences, evidence{token, math.Log(pSpam) - math.Log(pHam)})
+	}
+	sort.Slice(evidences, func(i, j int) bool {
+		return math.Abs(evidences[i].ratio) > math.Abs(evidences[j].ratio)
+	})
+	if len(evidences) > b.InterestingTokens {
+		evidences = evidences[:b.InterestingTokens]
+	}
+
+	logOdds := math.Log(float64(docs.Spam)) - math.Log(float64(docs.Ham))
+	var spammy []string
+	for _, e := range evidences {
+		logOdds += e.ratio
+		if e.ratio > 0 && len(spammy) < 5 {
+			spammy = append(spammy, e.token)
+		}
+	}
+
+	score := Score{Score: 1 / (1 + math.Exp(-logOdds))}
+	if len(spammy) > 0 {
+		score.Reasons = []string{"spammy words: " + strings.Join(spammy, ", ")}
+	}
+	return score, nil
+}
+
+// Train learns a moderator decision
+func (b *Bayes) Train(ctx context.Context, s Submission, spam bool) error {
+	return b.Store.Add(ctx, Tokenize(s.Content), spam, 1)
+}
+
+// Untrain forgets a decision that was overturned
+func (b *Bayes) Untrain(ctx context.Context, s Submission, spam bool) error {
+	return b.Store.Add(ctx, Tokenize(s.Content), spam, -1)
+}
+
+// Tokenize returns the distinct lower-cased words of a text, markup removed
+func Tokenize(text string) []string {
+	text = strings.ToLower(tagPattern.ReplaceAllString(text, " "))
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range tokenPattern.FindAllString(text, -1) {
+		token = strings.TrimRight(token, "'._-")
+		if len([]rune(token)) < 2 || len(token) > maxTokenLength || seen[token] {
+			continue
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// MemoryStore keeps token counts in memory, for tests and single-process use
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens map[string]TokenCounts
+	docs   TokenCounts
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]TokenCounts)}
+}
+
+// Counts returns the counts of tokens and documents
+func (m *MemoryStore) Counts(_ context.Context, tokens []string) (map[string]TokenCounts, TokenCounts, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := make(map[string]TokenCounts, len(tokens))
+	for _, token := range tokens {
+		if c, ok := m.tokens[token]; ok {
+			counts[token] = c
+		}
+	}
+	return counts, m.docs, nil
+}
+
+// Add adjusts the counts of tokens and documents, never below zero
+func (m *MemoryStore) Add(_ context.Context, tokens []string, spam bool, delta int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	add := func(c TokenCounts) TokenCounts {
+		if spam {
+			c.Spam = max(c.Spam+delta, 0)
+		} else {
+			c.Ham = max(c.Ham+delta, 0)
+		}
+		return c
+	}
+	for _, token := range tokens {
+		m.tokens[token] = add(m.tokens[token])
+	}
+	m.docs = add(m.docs)
+	return nil
+}
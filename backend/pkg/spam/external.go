@@ -0,0 +1,97 @@
+package spam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxExternalResponse limits how much of a service response is read
+const maxExternalResponse = 64 << 10
+
+// HTTPClient sends requests to the external service; *http.Client satisfies it
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// External asks a remote spam-checking service. It POSTs the submission as
+// JSON and expects {"score": 0.97, "reasons": ["..."]} back.
+type External struct {
+	Endpoint   string
+	APIKey     string
+	HTTPClient HTTPClient
+}
+
+// NewExternal creates an adapter; a nil httpClient uses a client with a 5 second timeout
+func NewExternal(endpoint, apiKey string, httpClient HTTPClient) *External {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &External{Endpoint: endpoint, APIKey: apiKey, HTTPClient: httpClient}
+}
+
+// Name identifies the classifier in scores
+func (e *External) Name() string {
+	return "external"
+}
+
+type externalRequest struct {
+	Content   string `json:"content"`
+	Author    string `json:"author,omitempty"`
+	Email     string `json:"email,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+type externalResponse struct {
+	Score   *float64 `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// Classify sends the submission to the service
+func (e *External) Classify(ctx context.Context, s Submission) (Score, error) {
+	body, err := json.Marshal(externalRequest{
+		Content:   s.Content,
+		Author:    s.Author,
+		Email:     s.Email,
+		IP:        s.IP,
+		UserAgent: s.UserAgent,
+		Permalink: s.Permalink,
+	})
+	if err != nil {
+		return Score{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Score{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if e.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.APIKey)
+	}
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return Score{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Score{}, fmt.Errorf("service returned %d", resp.StatusCode)
+	}
+
+	var decoded externalResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxExternalResponse)).Decode(&decoded); err != nil {
+		return Score{}, fmt.Errorf("invalid response: %v", err)
+	}
+	if decoded.Score == nil {
+		return Score{}, fmt.Errorf("invalid response: no score")
+	}
+	return Score{Score: *decoded.Score, Reasons: decoded.Reasons}, nil
+}
@@ -0,0 +1,123 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Weights of the heuristic checks; they add up, capped at 1
+const (
+	weightHoneypot      = 1.0
+	weightBlockedDomain = 0.9
+	weightBlockedWord   = 0.6
+	weightLinks         = 0.4
+	weightFastFill      = 0.5
+	weightFastRepeat    = 0.4
+)
+
+// linkPattern finds URLs and bare domains with a path in comment text and markup
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s"'<>]+|\bwww\.[^\s"'<>]+`)
+
+// Heuristics flags the tell-tale signs of automated and promotional comments
+type Heuristics struct {
+	// MaxLinks is the number of links allowed before a comment looks promotional
+	MaxLinks int
+	// BlockedWords and BlockedDomains are matched case-insensitively
+	BlockedWords   []string
+	BlockedDomains []string
+	// MinFillTime is the least time a person needs to write a comment
+	MinFillTime time.Duration
+	// MinInterval is the least time between two comments of one author
+	MinInterval time.Duration
+}
+
+// Name identifies the classifier in scores
+func (h *Heuristics) Name() string {
+	return "heuristics"
+}
+
+// Classify adds up the weights of the checks a submission fails
+func (h *Heuristics) Classify(_ context.Context, s Submission) (Score, error) {
+	var score Score
+	flag := func(weight float64, reason string) {
+		score.Score += weight
+		score.Reasons = append(score.Reasons, reason)
+	}
+
+	if strings.TrimSpace(s.Honeypot) != "" {
+		flag(weightHoneypot, "honeypot field was filled in")
+	}
+
+	links := distinctLinks(s.Content)
+	if h.MaxLinks >= 0 && len(links) > h.MaxLinks {
+		flag(weightLinks, fmt.Sprintf("%d links, more than %d", len(links), h.MaxLinks))
+	}
+	for _, domain := range h.blockedDomains(links) {
+		flag(weightBlockedDomain, "links to blocked domain "+domain)
+	}
+
+	text := strings.ToLower(s.Content + " " + s.Author + " " + s.Email)
+	for _, word := range h.BlockedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" && strings.Contains(text, word) {
+			flag(weightBlockedWord, fmt.Sprintf("contains blocked word %q", word))
+		}
+	}
+
+	if s.FillTime > 0 && s.FillTime < h.MinFillTime {
+		flag(weightFastFill, fmt.Sprintf("sent %s after the form opened", s.FillTime.Round(100*time.Millisecond)))
+	}
+	if s.SincePrevious > 0 && s.SincePrevious < h.MinInterval {
+		flag(weightFastRepeat, fmt.Sprintf("sent %s after the author's previous comment", s.SincePrevious.Round(time.Second)))
+	}
+
+	score.Score = clamp(score.Score)
+	return score, nil
+}
+
+// blockedDomains returns the blocked domains the links point at, each once
+func (h *Heuristics) blockedDomains(links []string) []string {
+	if len(h.BlockedDomains) == 0 {
+		return nil
+	}
+	var blocked []string
+	seen := make(map[string]bool)
+	for _, link := range links {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(strings.TrimPrefix(u.Hostname(), "www."))
+		for _, domain := range h.BlockedDomains {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			if domain == "" || seen[domain] {
+				continue
+			}
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				seen[domain] = true
+				blocked = append(blocked, domain)
+			}
+		}
+	}
+	return blocked
+}
+
+// distinctLinks finds the links of a text; a link repeated as its own anchor text counts once
+func distinctLinks(text string) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		key := strings.TrimRight(strings.ToLower(link), "/.,;:!?)")
+		if !seen[key] {
+			seen[key] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
@@ -0,0 +1,83 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Submission is a comment being screened, with what is known about how it was sent
+type Submission struct {
+	Content   string
+	AuthorID  string
+	Author    string
+	Email     string
+	IP        string
+	UserAgent string
+	Permalink string
+
+	// Honeypot is the value of a form field hidden from people; bots fill it in
+	Honeypot string
+	// FillTime is how long the form was open before it was sent; 0 when unknown
+	FillTime time.Duration
+	// SincePrevious is the time since the author's previous comment; 0 when there is none
+	SincePrevious time.Duration
+}
+
+// Score is one classifier's opinion of a submission: 0 is certainly ham, 1 certainly spam
+type Score struct {
+	Classifier string   `json:"classifier"`
+	Score      float64  `json:"score"`
+	Reasons    []string `json:"reasons,omitempty"`
+}
+
+// Classifier scores submissions
+type Classifier interface {
+	Name() string
+	Classify(ctx context.Context, s Submission) (Score, error)
+}
+
+// Trainer is a classifier that learns from moderator decisions. Untraining
+// reverts an earlier lesson when a decision is overturned.
+type Trainer interface {
+	Train(ctx context.Context, s Submission, spam bool) error
+	Untrain(ctx context.Context, s Submission, spam bool) error
+}
+
+// Result combines the scores of every classifier
+type Result struct {
+	// Score is the highest score; one confident classifier is enough to flag a submission
+	Score  float64 `json:"score"`
+	Scores []Score `json:"scores"`
+	// Errors lists classifiers that failed; they do not count towards the score
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Screen runs a submission through classifiers in order
+func Screen(ctx context.Context, s Submission, classifiers ...Classifier) Result {
+	result := Result{Scores: make([]Score, 0, len(classifiers))}
+	for _, classifier := range classifiers {
+		score, err := classifier.Classify(ctx, s)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", classifier.Name(), err))
+			continue
+		}
+		score.Classifier = classifier.Name()
+		score.Score = clamp(score.Score)
+		if score.Score > result.Score {
+			result.Score = score.Score
+		}
+		result.Scores = append(result.Scores, score)
+	}
+	return result
+}
+
+func clamp(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
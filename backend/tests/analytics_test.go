@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupAnalyticsDB(t *testing.T) *gorm.DB {
+	return setupServiceDB(t, &models.User{}, &models.UserLogin{}, &models.Post{}, &models.Comment{}, &models.PostViewDaily{}, &models.AnalyticsDailyRollup{})
+}
+
+func createAnalyticsUser(t *testing.T, db *gorm.DB, n int, createdAt time.Time, logins ...time.Time) {
+	t.Helper()
+	user := models.User{
+		Username:     fmt.Sprintf("user%d", n),
+		Email:        fmt.Sprintf("user%d@example.com", n),
+		Phone:        fmt.Sprintf("555000%04d", n),
+		PasswordHash: "hash",
+	}
+	user.CreatedAt = createdAt
+	require.NoError(t, db.Create(&user).Error)
+	for _, at := range logins {
+		require.NoError(t, db.Create(&models.UserLogin{UserID: user.ID, CreatedAt: at}).Error)
+	}
+}
+
+func rollupValues(t *testing.T, db *gorm.DB, day time.Time) map[string]int64 {
+	t.Helper()
+	var rows []models.AnalyticsDailyRollup
+	require.NoError(t, db.Where("date = ?", models.TruncateToDay(day)).Find(&rows).Error)
+	values := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		values[row.Metric] = row.Value
+	}
+	return values
+}
+
+func TestAnalyticsRollupCountsEachMetric(t *testing.T) {
+	db := setupAnalyticsDB(t)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	noon := day.Add(12 * time.Hour)
+	nextDay := day.AddDate(0, 0, 1).Add(time.Hour)
+
+	createAnalyticsUser(t, db, 1, noon, noon, nextDay)
+	createAnalyticsUser(t, db, 2, noon)
+	createAnalyticsUser(t, db, 3, nextDay, noon)
+
+	published := models.Post{Title: "Published", Slug: "published", Content: "body", Status: "published", PublishedAt: &noon}
+	draft := models.Post{Title: "Draft", Slug: "draft", Content: "body", Status: "draft"}
+	require.NoError(t, db.Create(&published).Error)
+	require.NoError(t, db.Create(&draft).Error)
+
+	statuses := []string{models.CommentStatusApproved, models.CommentStatusApproved, models.CommentStatusPending, models.CommentStatusSpam, models.CommentStatusRejected}
+	for _, status := range statuses {
+		comment := models.Comment{Content: "comment", Status: status, UserID: uuid.New(), PostID: published.ID}
+		comment.CreatedAt = noon
+		if status == models.CommentStatusApproved {
+			comment.ApprovedAt = &noon
+		}
+		require.NoError(t, db.Create(&comment).Error)
+	}
+	// A comment written the day before counts on the day it was approved
+	late := models.Comment{Content: "comment", Status: models.CommentStatusApproved, UserID: uuid.New(), PostID: published.ID, ApprovedAt: &noon}
+	late.CreatedAt = noon.AddDate(0, 0, -1)
+	require.NoError(t, db.Create(&late).Error)
+
+	require.NoError(t, db.Create(&models.PostViewDaily{PostID: published.ID, Date: day, Views: 7, UniqueVisitors: 4}).Error)
+	require.NoError(t, db.Create(&models.PostViewDaily{PostID: draft.ID, Date: day, Views: 3, UniqueVisitors: 1}).Error)
+
+	require.NoError(t, services.AnalyticsSvc.Rollup(context.Background(), day, day))
+
+	assert.Equal(t, map[string]int64{
+		models.MetricSignups:        2,
+		models.MetricLogins:         2,
+		models.MetricPostsPublished: 1,
+		models.MetricComments:       3,
+		models.MetricViews:          10,
+	}, rollupValues(t, db, day))
+}
+
+func TestAnalyticsRollupRecomputesDaysWhoseApprovalsChanged(t *testing.T) {
+	db := setupServiceDB(t, &models.User{}, &models.UserLogin{}, &models.Post{}, &models.Comment{}, &models.PostViewDaily{},
+		&models.AnalyticsDailyRollup{}, &models.CommentRule{}, &models.Mention{}, &models.Subscription{}, &models.Notification{})
+	server := setupServiceRedis(t)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	noon := day.Add(12 * time.Hour)
+
+	post := models.Post{Title: "Post", Slug: "post", Content: "body", Status: "published", PublishedAt: &noon}
+	require.NoError(t, db.Create(&post).Error)
+	comment := models.Comment{Content: "comment", Status: models.CommentStatusApproved, ApprovedAt: &noon, UserID: uuid.New(), PostID: post.ID}
+	require.NoError(t, db.Create(&comment).Error)
+	require.NoError(t, services.AnalyticsSvc.Rollup(context.Background(), day, day))
+	assert.Equal(t, int64(1), rollupValues(t, db, day)[models.MetricComments])
+
+	// Marking the comment as spam weeks later takes it off the day it was approved
+	_, err := services.CommentModerationSvc.Moderate([]uuid.UUID{comment.ID}, services.CommentActionSpam, nil)
+	require.NoError(t, err)
+	members, err := server.Members("analytics:dirty_days")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-03-10"}, members)
+
+	require.NoError(t, services.AnalyticsSvc.RollupDirtyDays(context.Background()))
+	assert.Equal(t, int64(0), rollupValues(t, db, day)[models.MetricComments])
+	assert.False(t, server.Exists("analytics:dirty_days"))
+}
+
+func TestAnalyticsRecordLoginCountsEveryLogin(t *testing.T) {
+	db := setupAnalyticsDB(t)
+	userID := uuid.New()
+	for i := 0; i < 2; i++ {
+		require.NoError(t, services.AnalyticsSvc.RecordLogin(userID, "203.0.113.7", "test"))
+	}
+
+	today := time.Now()
+	require.NoError(t, services.AnalyticsSvc.Rollup(context.Background(), today, today))
+	assert.Equal(t, int64(2), rollupValues(t, db, today)[models.MetricLogins])
+}
+
+func TestAnalyticsReportBucketsAndComparesRanges(t *testing.T) {
+	db := setupAnalyticsDB(t)
+	from := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC) // a Monday
+	to := from.AddDate(0, 0, 13)
+
+	for day := from.AddDate(0, 0, -14); !day.After(to); day = day.AddDate(0, 0, 1) {
+		value := int64(1)
+		if !day.Before(from) {
+			value = 2
+		}
+		require.NoError(t, db.Create(&models.AnalyticsDailyRollup{Date: day, Metric: models.MetricViews, Value: value}).Error)
+	}
+
+	report, err := services.AnalyticsSvc.GetReport(from, to, services.AnalyticsIntervalWeek)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-02-23", report.PreviousFrom)
+	assert.Equal(t, "2026-03-08", report.PreviousTo)
+
+	views := report.Metrics[models.MetricViews]
+	assert.Equal(t, int64(28), views.Total)
+	assert.Equal(t, int64(14), views.PreviousTotal)
+	require.NotNil(t, views.Change)
+	assert.InDelta(t, 100, *views.Change, 0.001)
+	assert.Equal(t, []services.AnalyticsPoint{{Period: "2026-03-09", Value: 14}, {Period: "2026-03-16", Value: 14}}, views.Series)
+
+	// Metrics without data still have every period, and no change to report
+	signups := report.Metrics[models.MetricSignups]
+	assert.Len(t, signups.Series, 2)
+	assert.Nil(t, signups.Change)
+}
+
+func TestAnalyticsReportRejectsInvalidInput(t *testing.T) {
+	setupAnalyticsDB(t)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	_, err := services.AnalyticsSvc.GetReport(day, day, "hour")
+	assert.ErrorIs(t, err, services.ErrInvalidAnalyticsInterval)
+
+	_, err = services.AnalyticsSvc.GetReport(day, day.AddDate(0, 0, -1), services.AnalyticsIntervalDay)
+	assert.ErrorIs(t, err, services.ErrInvalidAnalyticsRange)
+
+	_, err = services.AnalyticsSvc.GetReport(day, day.AddDate(3, 0, 0), services.AnalyticsIntervalMonth)
+	assert.ErrorIs(t, err, services.ErrInvalidAnalyticsRange)
+}
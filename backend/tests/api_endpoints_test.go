@@ -8,55 +8,63 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/sqlite"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
-	internal "go-next/internal"
 	controllers "go-next/internal/http/controllers"
+	"go-next/internal/models"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func setupTestDB() *gorm.DB {
-	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	internal.DB = db
-	internal.AutoMigrate()
+func setupTestDB(t *testing.T) *gorm.DB {
+	db := setupServiceDB(t, &models.User{}, &models.UserLogin{}, &models.Role{}, &models.JWTKey{}, &models.Token{},
+		&models.Post{}, &models.Category{}, &models.Comment{}, &models.Content{}, &models.PostAuthor{},
+		&models.AuthorProfile{}, &models.Mention{}, &models.Subscription{})
+	setupServiceRedis(t)
+	require.NoError(t, db.Create(&models.Role{Name: "user"}).Error)
 	return db
 }
 
 func setupRouter() *gin.Engine {
 	r := gin.Default()
-	r.POST("/api/register", controllers.RegisterHandlerGin)
-	r.POST("/api/login", controllers.LoginHandlerGin)
+	authHandler := controllers.NewAuthHandler()
+	r.POST("/api/register", authHandler.Register)
+	r.POST("/api/login", authHandler.Login)
 	// Add more routes as needed for testing
 	return r
 }
 
-func TestRegisterAndLogin(t *testing.T) {
-	db := setupTestDB()
-	_ = db
-	r := setupRouter()
+// registerAndLogin registers a user through the router and returns its access token
+func registerAndLogin(t *testing.T, r *gin.Engine, username, email, password string) string {
+	t.Helper()
 
-	// Register
-	regBody := map[string]string{"username": "testuser", "email": "test@example.com", "password": "password123"}
+	regBody := map[string]string{"username": username, "email": email, "password": password}
 	regJSON, _ := json.Marshal(regBody)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer(regJSON))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
-	assert.Equal(t, 201, w.Code)
+	require.Equal(t, 201, w.Code, w.Body.String())
 
-	// Login
-	loginBody := map[string]string{"email": "test@example.com", "password": "password123"}
+	loginBody := map[string]string{"email": email, "password": password}
 	loginJSON, _ := json.Marshal(loginBody)
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("POST", "/api/login", bytes.NewBuffer(loginJSON))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
-	assert.Equal(t, 200, w.Code)
+	require.Equal(t, 200, w.Code, w.Body.String())
 	var loginResp map[string]string
 	json.Unmarshal(w.Body.Bytes(), &loginResp)
-	assert.NotEmpty(t, loginResp["token"])
+	return loginResp["token"]
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	token := registerAndLogin(t, r, "testuser", "test@example.com", "password123")
+	assert.NotEmpty(t, token)
 }
 
 func TestPostEndpoints(t *testing.T) {
@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"testing"
+
+	"go-next/internal/http/requests"
+	"go-next/internal/models"
+	"go-next/internal/rules"
+	"go-next/pkg/config"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorProfileLinks(t *testing.T) {
+	profile := &models.AuthorProfile{
+		Website:  "https://alice.dev",
+		GitHub:   "https://github.com/alice",
+		Mastodon: "https://hachyderm.io/@alice",
+	}
+
+	assert.Equal(t, []models.SocialLink{
+		{Network: "website", URL: "https://alice.dev"},
+		{Network: "github", URL: "https://github.com/alice"},
+		{Network: "mastodon", URL: "https://hachyderm.io/@alice"},
+	}, profile.Links())
+	assert.Empty(t, (&models.AuthorProfile{}).Links())
+}
+
+func TestAuthorURL(t *testing.T) {
+	site := config.SiteConfig{URL: "https://example.com/"}
+	assert.Equal(t, "https://example.com/blog/authors/alice", site.AuthorURL("alice"))
+}
+
+func TestAuthorProfileLinksMustBeHTTP(t *testing.T) {
+	cases := map[string]bool{
+		"":                           true,
+		"https://alice.dev":          true,
+		"http://alice.dev/about?x=1": true,
+		"HTTPS://github.com/alice":   true,
+		"javascript:alert(1)":        false,
+		"JavaScript://%0aalert(1)":   false,
+		"data:text/html,<script>":    false,
+		"ftp://files.example.com":    false,
+		"//alice.dev":                false,
+		"alice.dev":                  false,
+		"https://":                   false,
+	}
+	for link, valid := range cases {
+		t.Run(link, func(t *testing.T) {
+			request := requests.AuthorProfileRequest{Website: link, Twitter: link, GitHub: link, LinkedIn: link, Mastodon: link}
+			profile := models.AuthorProfile{Website: link, Twitter: link, GitHub: link, LinkedIn: link, Mastodon: link}
+			if valid {
+				assert.NoError(t, binding.Validator.ValidateStruct(&request))
+				assert.NoError(t, rules.Validate.Struct(&profile))
+			} else {
+				assert.Error(t, binding.Validator.ValidateStruct(&request))
+				assert.Error(t, rules.Validate.Struct(&profile))
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/blocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockParseValidates(t *testing.T) {
+	cases := map[string]string{
+		"unknown type":    `{"type":"video","data":{"url":"https://example.com"}}`,
+		"missing data":    `{"type":"paragraph"}`,
+		"unknown field":   `{"type":"paragraph","data":{"text":"hi","color":"red"}}`,
+		"empty paragraph": `{"type":"paragraph","data":{"text":"<script>x</script>"}}`,
+		"heading level":   `{"type":"heading","data":{"text":"Hi","level":7}}`,
+		"image source":    `{"type":"image","data":{"alt":"none"}}`,
+		"image scheme":    `{"type":"image","data":{"url":"javascript:alert(1)"}}`,
+		"empty gallery":   `{"type":"gallery","data":{"images":[]}}`,
+		"embed relative":  `{"type":"embed","data":{"url":"/local"}}`,
+		"code language":   `{"type":"code","data":{"code":"x","language":"<go>"}}`,
+		"callout variant": `{"type":"callout","data":{"text":"x","variant":"loud"}}`,
+	}
+	for name, source := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := blocks.Parse([]byte(source))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, blocks.ErrInvalidBlock))
+		})
+	}
+}
+
+func TestBlockParseNormalizes(t *testing.T) {
+	block, err := blocks.Parse([]byte(`{"type":"heading","data":{"text":"Getting <em>Started</em>!<script>x</script>"}}`))
+	require.NoError(t, err)
+
+	normalized, err := json.Marshal(block)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"heading","data":{"text":"Getting <em>Started</em>!","level":2,"id":"getting-started"}}`, string(normalized))
+
+	reparsed, err := blocks.Parse(normalized)
+	require.NoError(t, err)
+	assert.Equal(t, block, reparsed)
+}
+
+func TestBlockRender(t *testing.T) {
+	cases := []struct {
+		source string
+		html   string
+		text   string
+	}{
+		{
+			`{"type":"paragraph","data":{"text":"Hello <strong>world</strong> &amp; all"}}`,
+			"<p>Hello <strong>world</strong> &amp; all</p>\n",
+			"Hello world & all",
+		},
+		{
+			`{"type":"heading","data":{"text":"Setup","level":3}}`,
+			"<h3 id=\"setup\">Setup<a class=\"heading-anchor\" href=\"#setup\">#</a></h3>\n",
+			"Setup",
+		},
+		{
+			`{"type":"image","data":{"url":"https://example.com/a.png","alt":"A \"cat\"","caption":"Cat","width":640}}`,
+			"<figure class=\"block-image\"><img src=\"https://example.com/a.png\" alt=\"A &#34;cat&#34;\" width=\"640\" loading=\"lazy\"><figcaption>Cat</figcaption></figure>\n",
+			"A \"cat\"\nCat",
+		},
+		{
+			`{"type":"quote","data":{"text":"Simple is better","citation":"Someone"}}`,
+			"<blockquote><p>Simple is better</p><cite>Someone</cite></blockquote>\n",
+			"Simple is better — Someone",
+		},
+		{
+			`{"type":"code","data":{"code":"if a < b {}","language":"go"}}`,
+			"<figure class=\"block-code\"><pre><code class=\"language-go\">if a &lt; b {}</code></pre></figure>\n",
+			"if a < b {}",
+		},
+		{
+			`{"type":"callout","data":{"variant":"warning","title":"Careful","text":"Back up first"}}`,
+			"<aside class=\"block-callout callout-warning\" role=\"note\"><p><strong>Careful</strong></p><p>Back up first</p></aside>\n",
+			"Careful\nBack up first",
+		},
+	}
+	for _, tc := range cases {
+		block, err := blocks.Parse([]byte(tc.source))
+		require.NoError(t, err, tc.source)
+		assert.Equal(t, tc.html, block.HTML())
+		assert.Equal(t, tc.text, block.Text())
+	}
+}
+
+func TestBlockGalleryDefaults(t *testing.T) {
+	block, err := blocks.Parse([]byte(`{"type":"gallery","data":{"images":[{"url":"/a.png"},{"url":"/b.png","caption":"B"}]}}`))
+	require.NoError(t, err)
+
+	gallery := block.Data.(*blocks.Gallery)
+	assert.Equal(t, 3, gallery.Columns)
+	assert.Contains(t, block.HTML(), `<figure class="block-gallery columns-3">`)
+	assert.Contains(t, block.HTML(), `<img src="/b.png" alt="" loading="lazy"><figcaption>B</figcaption>`)
+}
+
+func TestAnchorID(t *testing.T) {
+	assert.Equal(t, "getting-started", blocks.AnchorID("Getting Started!"))
+	assert.Equal(t, "v2-release-notes", blocks.AnchorID("  v2 -- Release notes "))
+	assert.Equal(t, "section", blocks.AnchorID("¿?"))
+}
+
+func TestInsertBlockRenumbersBeforeShifting(t *testing.T) {
+	db := setupServiceDB(t, &models.Post{}, &models.Content{})
+
+	post := models.Post{Title: "Blocks", Slug: "blocks", Content: "body"}
+	require.NoError(t, db.Create(&post).Error)
+	// Blocks saved with the post can have gaps and ties in their sort order
+	for i, order := range []int{0, 0, 5} {
+		block := models.Content{ModelID: post.ID, ModelType: models.MediableTypePost, Type: "text", Content: string(rune('a' + i)), SortOrder: order}
+		require.NoError(t, db.Create(&block).Error)
+		time.Sleep(time.Millisecond)
+	}
+
+	position := 2
+	inserted := models.Content{Type: "text", Content: "new"}
+	require.NoError(t, services.BlockSvc.InsertBlock(post.ID, &inserted, &position))
+
+	list, err := services.BlockSvc.ListBlocks(post.ID)
+	require.NoError(t, err)
+	contents := make([]string, 0, len(list))
+	for i, block := range list {
+		contents = append(contents, block.Content)
+		assert.Equal(t, i, block.SortOrder)
+	}
+	assert.Equal(t, []string{"a", "b", "new", "c"}, contents)
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+	"go-next/pkg/spam"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCommentModerationTransitions(t *testing.T) {
+	comment := &models.Comment{Status: models.CommentStatusPending}
+
+	comment.Approve()
+	assert.True(t, comment.IsApproved())
+	assert.NotNil(t, comment.ApprovedAt)
+
+	comment.MarkSpam()
+	assert.True(t, comment.IsSpam())
+	assert.Nil(t, comment.ApprovedAt)
+
+	comment.Reject()
+	assert.True(t, comment.IsRejected())
+}
+
+func TestModerateRejectsUnknownAction(t *testing.T) {
+	_, err := services.CommentModerationSvc.Moderate([]uuid.UUID{uuid.New()}, "delete", nil)
+	assert.True(t, errors.Is(err, services.ErrInvalidModerationAction))
+
+	_, err = services.CommentModerationSvc.Moderate(nil, services.CommentActionApprove, nil)
+	assert.True(t, errors.Is(err, services.ErrInvalidModerationAction))
+}
+
+func TestSetCommentRuleRejectsUnknownMode(t *testing.T) {
+	_, err := services.CommentModerationSvc.SetRule(uuid.New(), "sometimes")
+	assert.True(t, errors.Is(err, services.ErrInvalidCommentRule))
+}
+
+func TestCommentEditsAreModeratedAndScreened(t *testing.T) {
+	db := setupServiceDB(t, &models.User{}, &models.Post{}, &models.Comment{}, &models.CommentRule{}, &models.Mention{})
+
+	cfg := config.GetConfig()
+	previousComments, previousSpam := cfg.Comments, services.SpamSvc
+	cfg.Comments.TrustedAfter = 5
+	cfg.Comments.Spam = config.SpamConfig{Enabled: true, SpamThreshold: 0.9, ReviewThreshold: 0.5}
+	services.SpamSvc = services.NewSpamService(&spam.Heuristics{BlockedDomains: []string{"spam.example"}})
+	t.Cleanup(func() {
+		cfg.Comments, services.SpamSvc = previousComments, previousSpam
+	})
+
+	user := models.User{Username: "alice", Email: "alice@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	post := models.Post{Title: "Post", Slug: "post", Content: "body"}
+	require.NoError(t, db.Create(&post).Error)
+
+	edit := func(content string) *models.Comment {
+		comment := models.Comment{UserID: user.ID, PostID: post.ID, Content: "First!"}
+		comment.Approve()
+		require.NoError(t, db.Create(&comment).Error)
+		comment.Content = content
+		require.NoError(t, services.CommentSvc.UpdateComment(&comment))
+		return &comment
+	}
+
+	// An untrusted author's edit waits for a moderator like a new comment would
+	assert.Equal(t, models.CommentStatusPending, edit("Thanks for the write-up").Status)
+	assert.Equal(t, models.CommentStatusSpam, edit("now see http://shop.spam.example").Status)
+
+	rejected := models.Comment{UserID: user.ID, PostID: post.ID, Content: "Rude"}
+	rejected.Reject()
+	require.NoError(t, db.Create(&rejected).Error)
+	rejected.Content = "Polite"
+	require.NoError(t, services.CommentSvc.UpdateComment(&rejected))
+	assert.Equal(t, models.CommentStatusRejected, rejected.Status)
+}
+
+func TestModerateAndTrustOnPostgres(t *testing.T) {
+	requirePostgres(t)
+	db := setupServiceDB(t, &models.User{}, &models.Post{}, &models.Comment{}, &models.CommentRule{},
+		&models.Mention{}, &models.Subscription{}, &models.Notification{})
+	setupServiceRedis(t)
+
+	cfg := config.GetConfig()
+	previous := cfg.Comments.TrustedAfter
+	cfg.Comments.TrustedAfter = 2
+	t.Cleanup(func() { cfg.Comments.TrustedAfter = previous })
+
+	// The database itself rejects usernames the model would not accept
+	assert.Error(t, db.Create(&models.User{Username: "bad name", Email: "bad@example.com", PasswordHash: "x"}).Error)
+
+	user := models.User{Username: "alice", Email: "alice@example.com", Phone: "+10000000003", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	post := models.Post{Title: "Post", Slug: "post", Content: "body"}
+	require.NoError(t, db.Create(&post).Error)
+	ids := make([]uuid.UUID, 0, 2)
+	for _, content := range []string{"First", "Second"} {
+		comment := models.Comment{UserID: user.ID, PostID: post.ID, Content: content, Status: models.CommentStatusPending}
+		require.NoError(t, db.Create(&comment).Error)
+		ids = append(ids, comment.ID)
+	}
+
+	trusted, err := services.CommentModerationSvc.IsTrusted(user.ID)
+	require.NoError(t, err)
+	assert.False(t, trusted)
+
+	moderatorID := uuid.New()
+	comments, err := services.CommentModerationSvc.Moderate(ids, services.CommentActionApprove, &moderatorID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	for _, comment := range comments {
+		assert.True(t, comment.IsApproved())
+		assert.Equal(t, &moderatorID, comment.ModeratedBy)
+	}
+	trusted, err = services.CommentModerationSvc.IsTrusted(user.ID)
+	require.NoError(t, err)
+	assert.True(t, trusted)
+
+	// One spam verdict costs the author their trust
+	_, err = services.CommentModerationSvc.Moderate(ids[:1], services.CommentActionSpam, &moderatorID)
+	require.NoError(t, err)
+	trusted, err = services.CommentModerationSvc.IsTrusted(user.ID)
+	require.NoError(t, err)
+	assert.False(t, trusted)
+
+	_, err = services.CommentModerationSvc.Moderate([]uuid.UUID{ids[0], uuid.New()}, services.CommentActionReject, &moderatorID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type threadFixture struct {
+	postID   uuid.UUID
+	comments []models.Comment
+	ids      map[string]uuid.UUID
+}
+
+// newThreadFixture builds a discussion in nested-set order:
+//
+//	a (2 replies: a1 with reply a1x, a2)
+//	b (no replies)
+//	c (1 reply: c1, under a hidden comment)
+func newThreadFixture() *threadFixture {
+	f := &threadFixture{postID: uuid.New(), ids: make(map[string]uuid.UUID)}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	add := func(name, parent string, minutes int) {
+		id := uuid.New()
+		f.ids[name] = id
+		comment := models.Comment{Content: name, PostID: f.postID, UserID: uuid.New()}
+		comment.ID = id
+		comment.CreatedAt = start.Add(time.Duration(minutes) * time.Minute)
+		if parent != "" {
+			parentID := f.ids[parent]
+			comment.ParentID = &parentID
+		}
+		f.comments = append(f.comments, comment)
+	}
+	add("a", "", 0)
+	add("a1", "a", 1)
+	add("a1x", "a1", 5)
+	add("a2", "a", 2)
+	add("b", "", 3)
+	add("c", "", 4)
+	f.ids["hidden"] = uuid.New()
+	add("c1", "hidden", 6)
+	return f
+}
+
+func (f *threadFixture) build(t *testing.T, opts services.CommentThreadOptions) *models.CommentThread {
+	t.Helper()
+	thread, err := services.BuildCommentThread(f.postID, f.comments, nil, opts)
+	require.NoError(t, err)
+	return thread
+}
+
+func threadContents(nodes []models.CommentNode) []string {
+	contents := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		contents = append(contents, node.Content)
+	}
+	return contents
+}
+
+func TestCommentThreadForest(t *testing.T) {
+	f := newThreadFixture()
+	thread := f.build(t, services.CommentThreadOptions{Depth: services.DefaultCommentDepth})
+
+	assert.Equal(t, services.CommentSortOldest, thread.Sort)
+	assert.Equal(t, 6, thread.Total, "replies under hidden comments are left out")
+	assert.Equal(t, []string{"a", "b", "c"}, threadContents(thread.Comments))
+	assert.Empty(t, thread.NextCursor)
+
+	a := thread.Comments[0]
+	assert.Equal(t, 2, a.ReplyCount)
+	assert.Equal(t, 3, a.TotalReplies)
+	assert.Equal(t, []string{"a1", "a2"}, threadContents(a.Replies))
+	assert.Equal(t, 1, a.Replies[0].Depth)
+	assert.Equal(t, []string{"a1x"}, threadContents(a.Replies[0].Replies))
+	assert.NotNil(t, thread.Comments[1].Replies)
+	assert.Empty(t, thread.Comments[2].Replies)
+}
+
+func TestCommentThreadSort(t *testing.T) {
+	f := newThreadFixture()
+
+	newest := f.build(t, services.CommentThreadOptions{Sort: services.CommentSortNewest, Depth: 1})
+	assert.Equal(t, []string{"c", "b", "a"}, threadContents(newest.Comments))
+	assert.Equal(t, []string{"a2", "a1"}, threadContents(newest.Comments[2].Replies))
+
+	top := f.build(t, services.CommentThreadOptions{Sort: services.CommentSortTop, Depth: 1})
+	assert.Equal(t, []string{"a", "b", "c"}, threadContents(top.Comments))
+	assert.Equal(t, []string{"a1", "a2"}, threadContents(top.Comments[0].Replies))
+
+	_, err := services.BuildCommentThread(f.postID, f.comments, nil, services.CommentThreadOptions{Sort: "random"})
+	assert.True(t, errors.Is(err, services.ErrInvalidCommentThread))
+}
+
+func TestCommentThreadTopCountsReactions(t *testing.T) {
+	f := newThreadFixture()
+	for i := range f.comments {
+		switch f.comments[i].Content {
+		case "b":
+			f.comments[i].ReactionCount = 5 // outranks a's 3 replies
+		case "a2":
+			f.comments[i].ReactionCount = 2 // outranks a1's single reply
+		}
+	}
+
+	top := f.build(t, services.CommentThreadOptions{Sort: services.CommentSortTop, Depth: 1})
+	assert.Equal(t, []string{"b", "a", "c"}, threadContents(top.Comments))
+	assert.Equal(t, []string{"a2", "a1"}, threadContents(top.Comments[1].Replies))
+	assert.Equal(t, int64(5), top.Comments[0].ReactionCount)
+
+	oldest := f.build(t, services.CommentThreadOptions{Depth: 1})
+	assert.Equal(t, []string{"a", "b", "c"}, threadContents(oldest.Comments))
+}
+
+func TestCommentThreadDepthLimit(t *testing.T) {
+	f := newThreadFixture()
+	thread := f.build(t, services.CommentThreadOptions{Depth: 1})
+
+	a1 := thread.Comments[0].Replies[0]
+	assert.Empty(t, a1.Replies)
+	require.NotEmpty(t, a1.MoreReplies)
+
+	more := f.build(t, services.CommentThreadOptions{Cursor: a1.MoreReplies})
+	require.NotNil(t, more.ParentID)
+	assert.Equal(t, f.ids["a1"], *more.ParentID)
+	assert.Equal(t, []string{"a1x"}, threadContents(more.Comments))
+	assert.Equal(t, 2, more.Comments[0].Depth)
+}
+
+func TestCommentThreadCursors(t *testing.T) {
+	f := newThreadFixture()
+	first := f.build(t, services.CommentThreadOptions{Limit: 2, Replies: 1, Depth: 1})
+
+	assert.Equal(t, []string{"a", "b"}, threadContents(first.Comments))
+	require.NotEmpty(t, first.NextCursor)
+	a := first.Comments[0]
+	assert.Equal(t, []string{"a1"}, threadContents(a.Replies))
+	require.NotEmpty(t, a.MoreReplies)
+
+	rest := f.build(t, services.CommentThreadOptions{Limit: 2, Cursor: first.NextCursor})
+	assert.Equal(t, []string{"c"}, threadContents(rest.Comments))
+	assert.Empty(t, rest.NextCursor)
+
+	replies := f.build(t, services.CommentThreadOptions{Replies: 1, Cursor: a.MoreReplies})
+	assert.Equal(t, []string{"a2"}, threadContents(replies.Comments))
+	assert.Empty(t, replies.NextCursor)
+}
+
+func TestCommentThreadRejectsForeignCursor(t *testing.T) {
+	f := newThreadFixture()
+	first := f.build(t, services.CommentThreadOptions{Limit: 1})
+	require.NotEmpty(t, first.NextCursor)
+
+	for _, cursor := range []string{first.NextCursor, "not-a-cursor"} {
+		_, err := services.BuildCommentThread(uuid.New(), f.comments, nil, services.CommentThreadOptions{Cursor: cursor})
+		assert.True(t, errors.Is(err, services.ErrInvalidCommentThread))
+	}
+}
@@ -0,0 +1,261 @@
+package tests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-next/internal/http/controllers"
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+	"go-next/pkg/feed"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleFeed() *feed.Feed {
+	published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &feed.Feed{
+		ID:          "https://example.com/feed.xml",
+		Title:       "Example",
+		Description: "Example blog",
+		Link:        "https://example.com",
+		FeedURL:     "https://example.com/feed.xml",
+		Language:    "en",
+		Updated:     published,
+		Items: []feed.Item{{
+			ID:         "urn:uuid:5f2b6f8e-3c1a-4d3e-9a6b-1f2e3d4c5b6a",
+			Title:      "Hello & welcome",
+			Link:       "https://example.com/blog/hello",
+			Summary:    "A short summary",
+			Content:    "<p>Full <b>content</b></p>",
+			Author:     "alice",
+			Categories: []string{"news", "go"},
+			Published:  published,
+			Updated:    published,
+			Enclosures: []feed.Enclosure{{URL: "https://example.com/cover.jpg", MimeType: "image/jpeg", Length: 1024}},
+		}},
+	}
+}
+
+func TestFeedRSS(t *testing.T) {
+	body, err := sampleFeed().RSS()
+	require.NoError(t, err)
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title     string `xml:"title"`
+				Encoded   string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+				Creator   string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Enclosure struct {
+					URL    string `xml:"url,attr"`
+					Length int64  `xml:"length,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Equal(t, "Example", doc.Channel.Title)
+	require.Len(t, doc.Channel.Items, 1)
+	item := doc.Channel.Items[0]
+	assert.Equal(t, "Hello & welcome", item.Title)
+	assert.Equal(t, "<p>Full <b>content</b></p>", item.Encoded)
+	assert.Equal(t, "alice", item.Creator)
+	assert.Equal(t, "https://example.com/cover.jpg", item.Enclosure.URL)
+	assert.Equal(t, int64(1024), item.Enclosure.Length)
+	assert.Contains(t, string(body), "Wed, 01 May 2024 12:00:00 +0000")
+}
+
+func TestFeedAtom(t *testing.T) {
+	body, err := sampleFeed().Atom()
+	require.NoError(t, err)
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID    string `xml:"id"`
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	assert.Equal(t, "2024-05-01T12:00:00Z", doc.Updated)
+	require.Len(t, doc.Entries, 1)
+	entry := doc.Entries[0]
+	assert.True(t, strings.HasPrefix(entry.ID, "urn:uuid:"))
+	assert.Equal(t, "<p>Full <b>content</b></p>", entry.Content)
+	require.Len(t, entry.Links, 2)
+	assert.Equal(t, "enclosure", entry.Links[1].Rel)
+}
+
+func TestFeedJSON(t *testing.T) {
+	f := sampleFeed()
+	f.Items[0].Content = ""
+	body, err := f.JSON()
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &doc))
+
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc["version"])
+	items := doc["items"].([]interface{})
+	require.Len(t, items, 1)
+	item := items[0].(map[string]interface{})
+	// Excerpt feeds fall back to the summary so every item has content
+	assert.Equal(t, "A short summary", item["content_html"])
+	assert.Len(t, item["attachments"], 1)
+}
+
+func TestFeedAlternates(t *testing.T) {
+	f := sampleFeed()
+	f.Items[0].Alternates = []feed.Alternate{{Hreflang: "fr", Href: "https://example.com/fr/blog/bonjour"}}
+
+	body, err := f.Atom()
+	require.NoError(t, err)
+	var atom struct {
+		Entries []struct {
+			Links []struct {
+				Href     string `xml:"href,attr"`
+				Rel      string `xml:"rel,attr"`
+				Hreflang string `xml:"hreflang,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &atom))
+	require.Len(t, atom.Entries[0].Links, 3)
+	assert.Equal(t, "alternate", atom.Entries[0].Links[2].Rel)
+	assert.Equal(t, "fr", atom.Entries[0].Links[2].Hreflang)
+
+	body, err = f.RSS()
+	require.NoError(t, err)
+	var rss struct {
+		Items []struct {
+			Links []struct {
+				Href     string `xml:"href,attr"`
+				Hreflang string `xml:"hreflang,attr"`
+			} `xml:"http://www.w3.org/2005/Atom link"`
+		} `xml:"channel>item"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &rss))
+	require.Len(t, rss.Items[0].Links, 1)
+	assert.Equal(t, "https://example.com/fr/blog/bonjour", rss.Items[0].Links[0].Href)
+
+	body, err = f.JSON()
+	require.NoError(t, err)
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &doc))
+	item := doc["items"].([]interface{})[0].(map[string]interface{})
+	assert.Len(t, item["_translations"], 1)
+}
+
+func TestFeedContributors(t *testing.T) {
+	f := sampleFeed()
+	f.Items[0].Contributors = []string{"bob", "carol"}
+
+	body, err := f.Atom()
+	require.NoError(t, err)
+	var atom struct {
+		Entries []struct {
+			Author       string   `xml:"author>name"`
+			Contributors []string `xml:"contributor>name"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &atom))
+	assert.Equal(t, "alice", atom.Entries[0].Author)
+	assert.Equal(t, []string{"bob", "carol"}, atom.Entries[0].Contributors)
+
+	body, err = f.RSS()
+	require.NoError(t, err)
+	var rss struct {
+		Items []struct {
+			Contributors []string `xml:"http://purl.org/dc/elements/1.1/ contributor"`
+		} `xml:"channel>item"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &rss))
+	assert.Equal(t, []string{"bob", "carol"}, rss.Items[0].Contributors)
+
+	body, err = f.JSON()
+	require.NoError(t, err)
+	var doc struct {
+		Items []struct {
+			Authors []struct {
+				Name string `json:"name"`
+			} `json:"authors"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(body, &doc))
+	require.Len(t, doc.Items[0].Authors, 3)
+	assert.Equal(t, "alice", doc.Items[0].Authors[0].Name)
+	assert.Equal(t, "carol", doc.Items[0].Authors[2].Name)
+}
+
+func TestFeedAnswersConditionalRequestsBeforeBuilding(t *testing.T) {
+	db := setupServiceDB(t, &models.User{}, &models.Post{}, &models.Category{}, &models.PostAuthor{}, &models.AuthorProfile{},
+		&models.Media{}, &models.Mediable{}, &models.Tag{})
+	setupPostRelationTables(t, db)
+	site := &config.GetConfig().Site
+	previous := site.URL
+	site.URL = "https://blog.example.com/"
+	t.Cleanup(func() { site.URL = previous })
+
+	published := time.Now().Add(-time.Hour)
+	post := models.Post{Title: "Hello", Slug: "hello", Content: "body", Status: "published", Public: true, PublishedAt: &published}
+	require.NoError(t, db.Create(&post).Error)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/feed.json", controllers.NewFeedHandler(services.NewFeedService(services.NewBlogService())).GetJSONFeed)
+	request := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feed.json", nil)
+		// The feed URL comes from the site configuration, not the client
+		req.Host = "attacker.example"
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var doc struct {
+		FeedURL string `json:"feed_url"`
+		Items   []struct {
+			Title string `json:"title"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "https://blog.example.com/feed.json", doc.FeedURL)
+	require.Len(t, doc.Items, 1)
+	etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	require.NotEmpty(t, etag)
+
+	// Without its post tags the feed could not be built, so these answers
+	// come from the validators alone
+	require.NoError(t, db.Exec("DROP TABLE post_tags").Error)
+	assert.Equal(t, http.StatusNotModified, request("If-None-Match", etag).Code)
+	assert.Equal(t, http.StatusNotModified, request("If-Modified-Since", lastModified).Code)
+
+	// Any post change moves the validators on
+	require.NoError(t, db.Model(&post).UpdateColumn("updated_at", time.Now().Add(time.Minute)).Error)
+	assert.Equal(t, http.StatusInternalServerError, request("If-None-Match", etag).Code)
+}
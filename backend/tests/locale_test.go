@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+	"go-next/pkg/locale"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLocaleNormalize(t *testing.T) {
+	tag, ok := locale.Normalize("pt_br")
+	assert.True(t, ok)
+	assert.Equal(t, "pt-BR", tag)
+
+	_, ok = locale.Normalize("not a locale")
+	assert.False(t, ok)
+}
+
+func TestLocaleNegotiate(t *testing.T) {
+	m := locale.NewMatcher([]string{"en", "fr", "pt", "pt-BR"}, "en")
+
+	assert.Equal(t, []string{"en", "fr", "pt", "pt-BR"}, m.Supported())
+	// An explicit choice wins over the header
+	assert.Equal(t, "fr", m.Negotiate("fr", "pt-BR,pt;q=0.9"))
+	assert.Equal(t, "pt-BR", m.Negotiate("", "pt-BR,pt;q=0.9"))
+	assert.Equal(t, "fr", m.Negotiate("", "de-DE,fr-CA;q=0.8"))
+	// Unsupported choices fall through to the header, then the fallback
+	assert.Equal(t, "fr", m.Negotiate("ja", "fr"))
+	assert.Equal(t, "en", m.Negotiate("", "ja"))
+	assert.Equal(t, "en", m.Negotiate("", ""))
+}
+
+func TestLocaleChain(t *testing.T) {
+	m := locale.NewMatcher([]string{"fr", "pt", "pt-BR"}, "en")
+
+	assert.Equal(t, []string{"pt-BR", "pt", "en"}, m.Chain("pt-BR"))
+	assert.Equal(t, []string{"fr", "en"}, m.Chain("fr"))
+	assert.Equal(t, []string{"en"}, m.Chain("en"))
+	assert.Equal(t, []string{"en"}, m.Chain("ja"))
+
+	tag, ok := m.IsSupported("PT_br")
+	assert.True(t, ok)
+	assert.Equal(t, "pt-BR", tag)
+}
+
+func TestLocalizedURLs(t *testing.T) {
+	site := config.SiteConfig{URL: "https://example.com/", Language: "en"}
+
+	assert.Equal(t, "https://example.com/blog/hello", site.LocalizedPostURL("en", "hello"))
+	assert.Equal(t, "https://example.com/blog/hello", site.LocalizedPostURL("", "hello"))
+	assert.Equal(t, "https://example.com/pt-br/blog/ola", site.LocalizedPostURL("pt-BR", "ola"))
+	assert.Equal(t, "https://example.com/fr/blog/category/actualites", site.LocalizedCategoryURL("fr", "actualites"))
+}
+
+func TestPostSlugLookupsFollowTheLocaleChain(t *testing.T) {
+	db := setupServiceDB(t, &models.Post{}, &models.Content{}, &models.SlugHistory{})
+
+	published := time.Now().Add(-time.Hour)
+	group := uuid.New()
+	posts := []models.Post{
+		{Title: "Hello", Slug: "hello", Locale: "en", TranslationGroupID: &group},
+		{Title: "Bonjour", Slug: "hello", Locale: "fr", TranslationGroupID: &group},
+		{Title: "Only English", Slug: "english", Locale: "en"},
+		{Title: "Nur Deutsch", Slug: "deutsch", Locale: "de"},
+	}
+	for i := range posts {
+		posts[i].Content = "<p>" + posts[i].Title + "</p>"
+		posts[i].Status = "published"
+		posts[i].Public = true
+		posts[i].PublishedAt = &published
+		require.NoError(t, db.Create(&posts[i]).Error)
+	}
+
+	render := func(slug string, locales ...string) (string, error) {
+		rendered, err := services.NewRenderService().RenderPost(context.Background(), slug, locales)
+		if err != nil {
+			return "", err
+		}
+		return rendered.Text, nil
+	}
+
+	text, err := render("hello", "fr", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Bonjour", text)
+
+	text, err = render("hello", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", text)
+
+	// Untranslated posts fall back along the chain
+	text, err = render("english", "fr", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Only English", text)
+
+	// Slugs of locales outside the chain do not resolve
+	_, err = render("deutsch", "fr", "en")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// Single-language sites look slugs up in every locale
+	text, err = render("deutsch")
+	require.NoError(t, err)
+	assert.Equal(t, "Nur Deutsch", text)
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"go-next/pkg/markdown"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleMarkdown = "# Getting *started*\n\nInstall it first[^1].\n\n## Setup\n\n| OS | Command |\n|----|---------|\n| Linux | apt |\n\n```go\nfunc main() {}\n```\n\n### Details\n\n## Usage\n\n[^1]: See the docs.\n"
+
+func TestMarkdownRender(t *testing.T) {
+	result, err := markdown.NewRenderer().Render([]byte(sampleMarkdown))
+	require.NoError(t, err)
+
+	assert.Contains(t, result.HTML, `<h1 id="getting-started">`)
+	assert.Contains(t, result.HTML, `<a href="#setup" class="heading-anchor">#</a>`)
+	assert.Contains(t, result.HTML, "<table>")
+	assert.Contains(t, result.HTML, `class="footnotes"`)
+	assert.Contains(t, result.HTML, `class="chroma"`)
+	// Highlighting uses classes, not inline styles
+	assert.False(t, strings.Contains(result.HTML, "style="))
+}
+
+func TestMarkdownTOC(t *testing.T) {
+	result, err := markdown.NewRenderer().Render([]byte(sampleMarkdown))
+	require.NoError(t, err)
+
+	require.Len(t, result.TOC, 1)
+	root := result.TOC[0]
+	assert.Equal(t, "Getting started", root.Text)
+	require.Len(t, root.Children, 2)
+	assert.Equal(t, "setup", root.Children[0].ID)
+	require.Len(t, root.Children[0].Children, 1)
+	assert.Equal(t, "Details", root.Children[0].Children[0].Text)
+	assert.Equal(t, "usage", root.Children[1].ID)
+}
+
+func TestMarkdownWordCount(t *testing.T) {
+	result, err := markdown.NewRenderer().Render([]byte("Hello, world! This isn't counted:\n\n```\nignored code here\n```\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, result.WordCount)
+	assert.Equal(t, 1, result.ReadingTime)
+	assert.Equal(t, 0, markdown.ReadingTime(0))
+	assert.Equal(t, 2, markdown.ReadingTime(markdown.WordsPerMinute+1))
+}
+
+func TestMarkdownPlainText(t *testing.T) {
+	result, err := markdown.NewRenderer().Render([]byte(sampleMarkdown))
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(result.Text, "Getting started\nInstall it first"))
+	assert.Contains(t, result.Text, "\nSetup\n")
+	assert.Contains(t, result.Text, "func main() {}")
+	assert.NotContains(t, result.Text, "#")
+	assert.NotContains(t, result.Text, "<")
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/mention"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestMentionParse(t *testing.T) {
+	usernames := mention.Parse("Thanks @Alice and @bob_99! cc @alice, (@carol)")
+	assert.Equal(t, []string{"alice", "bob_99", "carol"}, usernames)
+}
+
+func TestMentionParseIgnoresEmailsAndPaths(t *testing.T) {
+	assert.Empty(t, mention.Parse("Mail me at dave@example.com or see example.com/@dave and @@eve"))
+}
+
+func TestMentionParseIgnoresMarkup(t *testing.T) {
+	usernames := mention.Parse(`<a href="https://x.com/@mallory" title="@trent">hi</a> <p>@frank</p>`)
+	assert.Equal(t, []string{"frank"}, usernames)
+}
+
+func TestMentionParseLengthBounds(t *testing.T) {
+	long := strings.Repeat("a", 51)
+	assert.Equal(t, []string{"abc"}, mention.Parse("@ab @abc @"+long))
+}
+
+func TestMentionParseCapsMentions(t *testing.T) {
+	var text []string
+	for i := 0; i < mention.MaxPerText+5; i++ {
+		text = append(text, fmt.Sprintf("@user%d", i))
+	}
+	usernames := mention.Parse(strings.Join(text, " "))
+	assert.Len(t, usernames, mention.MaxPerText)
+	assert.Equal(t, "user0", usernames[0])
+}
+
+// mentionedUsers lists the usernames recorded as mentioned in a post
+func mentionedUsers(t *testing.T, db *gorm.DB, postID uuid.UUID) []string {
+	t.Helper()
+
+	var usernames []string
+	require.NoError(t, db.Table("mentions").
+		Joins("JOIN users ON users.id = mentions.user_id").
+		Where("mentions.mentionable_type = ? AND mentions.mentionable_id = ?", models.MentionableTypePost, postID).
+		Order("users.username").
+		Pluck("users.username", &usernames).Error)
+	return usernames
+}
+
+func TestSyncPostMentions(t *testing.T) {
+	db := setupServiceDB(t, &models.User{}, &models.AuthorProfile{}, &models.Media{}, &models.Post{}, &models.Content{},
+		&models.Mention{}, &models.Notification{})
+
+	users := map[string]models.User{}
+	for i, name := range []string{"alice", "bob", "carol"} {
+		user := models.User{Username: name, Email: name + "@example.com", Phone: fmt.Sprintf("555000000%d", i), PasswordHash: "x", IsActive: true}
+		require.NoError(t, db.Create(&user).Error)
+		users[name] = user
+	}
+	author := users["alice"].ID
+	published := time.Now().Add(-time.Hour)
+	post := models.Post{Title: "Post", Slug: "post", Content: "body", Status: "published", PublishedAt: &published}
+	post.CreatedBy = &author
+	require.NoError(t, db.Create(&post).Error)
+
+	// The author mentioning themselves is not recorded
+	block := models.Content{Type: "text", Content: "Thanks @Bob, says @alice"}
+	require.NoError(t, services.BlockSvc.InsertBlock(post.ID, &block, nil))
+	assert.Equal(t, []string{"bob"}, mentionedUsers(t, db, post.ID))
+
+	// Editing the text drops the mentions it no longer has and adds the new ones
+	_, err := services.BlockSvc.UpdateBlock(post.ID, block.ID, "text", "Thanks @carol")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol"}, mentionedUsers(t, db, post.ID))
+
+	var notified []uuid.UUID
+	require.NoError(t, db.Model(&models.Notification{}).Where("type = ?", models.NotificationTypeMention).
+		Order("created_at").Pluck("user_id", &notified).Error)
+	assert.Equal(t, []uuid.UUID{users["bob"].ID, users["carol"].ID}, notified)
+
+	var pending int64
+	require.NoError(t, db.Model(&models.Mention{}).Where("notified_at IS NULL").Count(&pending).Error)
+	assert.Zero(t, pending)
+}
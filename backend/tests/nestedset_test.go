@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"go-next/pkg/nestedset"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleTree is a consistent tree:
+//
+//	root [1, 8]
+//	  a [2, 5]
+//	    a1 [3, 4]
+//	  b [6, 7]
+//	other [9, 10]
+func sampleTree() ([]nestedset.Node, map[string]uuid.UUID) {
+	ids := map[string]uuid.UUID{}
+	for _, name := range []string{"root", "a", "a1", "b", "other"} {
+		ids[name] = uuid.New()
+	}
+	parent := func(name string) *uuid.UUID {
+		id := ids[name]
+		return &id
+	}
+	return []nestedset.Node{
+		{ID: ids["root"], Left: 1, Right: 8, Ordering: 1},
+		{ID: ids["a"], ParentID: parent("root"), Left: 2, Right: 5, Depth: 1, Ordering: 1},
+		{ID: ids["a1"], ParentID: parent("a"), Left: 3, Right: 4, Depth: 2, Ordering: 1},
+		{ID: ids["b"], ParentID: parent("root"), Left: 6, Right: 7, Depth: 1, Ordering: 2},
+		{ID: ids["other"], Left: 9, Right: 10, Ordering: 2},
+	}, ids
+}
+
+func issueKinds(issues []nestedset.Issue) []string {
+	kinds := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		kinds = append(kinds, issue.Kind)
+	}
+	return kinds
+}
+
+func TestNestedSetCheckConsistentTree(t *testing.T) {
+	nodes, _ := sampleTree()
+	assert.Empty(t, nestedset.Check(nodes))
+}
+
+func TestNestedSetCheckGap(t *testing.T) {
+	nodes, _ := sampleTree()
+	nodes[4].Left, nodes[4].Right = 11, 12
+
+	issues := nestedset.Check(nodes)
+	require.Len(t, issues, 1)
+	assert.Equal(t, nestedset.IssueGap, issues[0].Kind)
+	assert.Equal(t, "positions 9-10 are unused", issues[0].Detail)
+}
+
+func TestNestedSetCheckOverlap(t *testing.T) {
+	nodes, ids := sampleTree()
+	nodes[3].Left, nodes[3].Right = 4, 7 // b crosses a
+
+	issues := nestedset.Check(nodes)
+	assert.Contains(t, issueKinds(issues), nestedset.IssueOverlap)
+	assert.Contains(t, issueKinds(issues), nestedset.IssueGap)
+	var crossing *nestedset.Issue
+	for i := range issues {
+		if issues[i].Kind == nestedset.IssueOverlap && issues[i].ID == ids["b"] {
+			crossing = &issues[i]
+		}
+	}
+	require.NotNil(t, crossing)
+}
+
+func TestNestedSetCheckOrphanAndParent(t *testing.T) {
+	nodes, ids := sampleTree()
+	missing := uuid.New()
+	nodes[4].ParentID = &missing
+	nodes[2].ParentID = nil
+
+	issues := nestedset.Check(nodes)
+	assert.ElementsMatch(t, []string{nestedset.IssueOrphan, nestedset.IssueParent, nestedset.IssueParent}, issueKinds(issues))
+	for _, issue := range issues {
+		assert.Contains(t, []uuid.UUID{ids["other"], ids["a1"]}, issue.ID)
+	}
+}
+
+func TestNestedSetCheckBoundsAndDepth(t *testing.T) {
+	nodes, _ := sampleTree()
+	nodes[2].Depth = 1
+	nodes = append(nodes, nestedset.Node{ID: uuid.New()})
+
+	assert.ElementsMatch(t, []string{nestedset.IssueDepth, nestedset.IssueBounds}, issueKinds(nestedset.Check(nodes)))
+}
+
+func TestNestedSetRebuild(t *testing.T) {
+	nodes, ids := sampleTree()
+	// Scramble every position; only parents and ordering are kept
+	for i := range nodes {
+		nodes[i].Left, nodes[i].Right, nodes[i].Depth = 0, 0, 0
+	}
+	nodes[1].Ordering, nodes[3].Ordering = 2, 1 // b now comes before a
+
+	rebuilt, detached := nestedset.Rebuild(nodes)
+	assert.Empty(t, detached)
+	assert.Empty(t, nestedset.Check(rebuilt))
+
+	positions := map[uuid.UUID][3]int{}
+	for _, node := range rebuilt {
+		positions[node.ID] = [3]int{node.Left, node.Right, node.Depth}
+	}
+	assert.Equal(t, [3]int{1, 8, 0}, positions[ids["root"]])
+	assert.Equal(t, [3]int{2, 3, 1}, positions[ids["b"]])
+	assert.Equal(t, [3]int{4, 7, 1}, positions[ids["a"]])
+	assert.Equal(t, [3]int{5, 6, 2}, positions[ids["a1"]])
+	assert.Equal(t, [3]int{9, 10, 0}, positions[ids["other"]])
+}
+
+func TestNestedSetRebuildDetachesOrphansAndCycles(t *testing.T) {
+	nodes, ids := sampleTree()
+	missing := uuid.New()
+	nodes[4].ParentID = &missing
+
+	// x and y are each other's parent, and z hangs below y
+	x, y, z := uuid.New(), uuid.New(), uuid.New()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	nodes = append(nodes,
+		nestedset.Node{ID: x, ParentID: &y, CreatedAt: created},
+		nestedset.Node{ID: y, ParentID: &x, CreatedAt: created.Add(time.Minute)},
+		nestedset.Node{ID: z, ParentID: &y, CreatedAt: created.Add(2 * time.Minute)},
+	)
+
+	rebuilt, detached := nestedset.Rebuild(nodes)
+	assert.ElementsMatch(t, []uuid.UUID{ids["other"], x}, detached)
+	require.Len(t, rebuilt, len(nodes))
+	assert.Empty(t, nestedset.Check(rebuilt))
+
+	byID := map[uuid.UUID]nestedset.Node{}
+	for _, node := range rebuilt {
+		byID[node.ID] = node
+	}
+	assert.Nil(t, byID[ids["other"]].ParentID)
+	assert.Nil(t, byID[x].ParentID)
+	assert.Equal(t, x, *byID[y].ParentID)
+	assert.Equal(t, 2, byID[z].Depth)
+}
@@ -0,0 +1,160 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-next/internal/services"
+	"go-next/pkg/oembed"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubProvider serves oEmbed responses for https://video.example/* URLs
+func newStubProvider(t *testing.T, handler http.HandlerFunc) *oembed.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	registry := oembed.NewRegistry(oembed.Provider{
+		Name:       "Stub Video",
+		Endpoint:   server.URL + "/oembed",
+		Schemes:    []string{"https://video.example/watch/*"},
+		FrameHosts: []string{"video.example"},
+	})
+	return oembed.NewClient(registry, server.Client())
+}
+
+func TestOEmbedFetch(t *testing.T) {
+	client := newStubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "https://video.example/watch/42", r.URL.Query().Get("url"))
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.Equal(t, "480", r.URL.Query().Get("maxwidth"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"type":"video","version":"1.0","title":"Clip","html":"<iframe src=\"https://video.example/embed/42\"></iframe>","width":480,"height":270}`))
+	})
+
+	response, err := client.Fetch(context.Background(), "https://video.example/watch/42", oembed.Options{MaxWidth: 480})
+	require.NoError(t, err)
+	assert.Equal(t, "Clip", response.Title)
+	assert.Equal(t, "Stub Video", response.ProviderName)
+	assert.Equal(t, `<iframe src="https://video.example/embed/42"></iframe>`, response.EmbedHTML())
+}
+
+func TestOEmbedFetchErrors(t *testing.T) {
+	client := newStubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Query().Get("url"), "/missing") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"type":"video","version":"1.0"}`))
+	})
+
+	_, err := client.Fetch(context.Background(), "https://other.example/watch/1", oembed.Options{})
+	assert.True(t, errors.Is(err, oembed.ErrNoProvider))
+
+	_, err = client.Fetch(context.Background(), "https://video.example/watch/missing", oembed.Options{})
+	assert.True(t, errors.Is(err, oembed.ErrProviderFailed))
+
+	// A video without html is not a valid response
+	_, err = client.Fetch(context.Background(), "https://video.example/watch/1", oembed.Options{})
+	assert.True(t, errors.Is(err, oembed.ErrProviderFailed))
+}
+
+func TestOEmbedDefaultProviders(t *testing.T) {
+	registry := oembed.NewRegistry(oembed.DefaultProviders()...)
+
+	cases := map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":    "YouTube",
+		"https://youtu.be/dQw4w9WgXcQ":                   "YouTube",
+		"https://x.com/golang/status/123":                "Twitter",
+		"https://gist.github.com/octocat/6cad326836d38b": "GitHub Gist",
+	}
+	for rawURL, name := range cases {
+		provider, ok := registry.Match(rawURL)
+		require.True(t, ok, rawURL)
+		assert.Equal(t, name, provider.Name, rawURL)
+	}
+
+	_, ok := registry.Match("https://example.com/watch?v=1")
+	assert.False(t, ok)
+
+	// Gists are embedded in a frame without a network request
+	client := oembed.NewClient(registry, nil)
+	response, err := client.Fetch(context.Background(), "https://gist.github.com/octocat/6cad326836d38b", oembed.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, `<iframe src="https://gist.github.com/octocat/6cad326836d38b.pibb" title="Gist" loading="lazy"></iframe>`, response.EmbedHTML())
+}
+
+func TestOEmbedFetchSanitizesMarkup(t *testing.T) {
+	client := newStubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("url") {
+		case "https://video.example/watch/frames":
+			_, _ = w.Write([]byte(`{"type":"video","version":"1.0","html":"<iframe src=\"https://video.example/embed/1\" width=\"560\" allowfullscreen onload=\"steal()\"></iframe><iframe src=\"https://evil.example/\" width=\"1\"></iframe><iframe src=\"https://video.example.evil.example/\"></iframe>"}`))
+		case "https://video.example/watch/quote":
+			_, _ = w.Write([]byte(`{"type":"rich","version":"1.0","html":"<blockquote class=\"post\"><p>Hello</p><a href=\"javascript:alert(1)\">x</a></blockquote><script src=\"https://video.example/widgets.js\"></script><style>body{}</style>"}`))
+		default:
+			_, _ = w.Write([]byte(`{"type":"rich","version":"1.0","html":"<script>alert(1)</script>"}`))
+		}
+	})
+
+	response, err := client.Fetch(context.Background(), "https://video.example/watch/frames", oembed.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, `<iframe src="https://video.example/embed/1" width="560" allowfullscreen=""></iframe>`, response.EmbedHTML())
+
+	response, err = client.Fetch(context.Background(), "https://video.example/watch/quote", oembed.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, `<blockquote class="post"><p>Hello</p>x</blockquote>`, response.EmbedHTML())
+
+	// Markup with nothing left to embed is not a valid response
+	_, err = client.Fetch(context.Background(), "https://video.example/watch/script", oembed.Options{})
+	assert.True(t, errors.Is(err, oembed.ErrProviderFailed))
+}
+
+func TestOEmbedResponseXML(t *testing.T) {
+	body, err := (&oembed.Response{Type: oembed.TypeLink, Version: oembed.Version, Title: "A & B"}).XML()
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8" standalone="yes"?><oembed><type>link</type><version>1.0</version><title>A &amp; B</title></oembed>`, string(body))
+}
+
+func TestRenderEmbedBlock(t *testing.T) {
+	client := newStubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"type":"video","version":"1.0","html":"<iframe src=\"https://video.example/embed/7\"></iframe>"}`))
+	})
+	previous := services.OEmbedSvc
+	services.OEmbedSvc = services.NewOEmbedService(client)
+	t.Cleanup(func() { services.OEmbedSvc = previous })
+
+	result, err := services.NewRenderService().Render(context.Background(), "json", `{"type":"embed","data":{"url":"https://video.example/watch/7","caption":"Demo"}}`)
+	require.NoError(t, err)
+	assert.Equal(t, "<figure class=\"block-embed\" data-provider=\"Stub Video\"><iframe src=\"https://video.example/embed/7\"></iframe><figcaption>Demo</figcaption></figure>\n", result.HTML)
+
+	// URLs without a provider stay links
+	result, err = services.NewRenderService().Render(context.Background(), "json", `{"type":"embed","data":{"url":"https://other.example/page"}}`)
+	require.NoError(t, err)
+	assert.Contains(t, result.HTML, `<a href="https://other.example/page">`)
+}
+
+func TestRenderEmbedBlockStopsWithTheRequest(t *testing.T) {
+	release := make(chan struct{})
+	client := newStubProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	t.Cleanup(func() { close(release) })
+	previous := services.OEmbedSvc
+	services.OEmbedSvc = services.NewOEmbedService(client)
+	t.Cleanup(func() { services.OEmbedSvc = previous })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := services.NewRenderService().Render(ctx, "json", `{"type":"embed","data":{"url":"https://video.example/watch/9"}}`)
+	require.NoError(t, err)
+	assert.Contains(t, result.HTML, `<a href="https://video.example/watch/9">`)
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeReactionTypes(t *testing.T) {
+	types := services.NormalizeReactionTypes([]string{" Heart", "laugh", "heart", "like", "party time", "", "🎉", "thumbs_up"})
+	assert.Equal(t, []string{"like", "heart", "laugh", "thumbs_up"}, types)
+}
+
+func TestNormalizeReactionTypesAlwaysOffersLike(t *testing.T) {
+	assert.Equal(t, []string{"like"}, services.NormalizeReactionTypes(nil))
+}
+
+// reactionFixture sets up the database and Redis with two published posts and
+// two users to react with
+func reactionFixture(t *testing.T) (*gorm.DB, *miniredis.Miniredis, []models.Post, []uuid.UUID) {
+	t.Helper()
+
+	db := setupServiceDB(t, &models.Post{}, &models.Comment{}, &models.Reaction{})
+	server := setupServiceRedis(t)
+	published := time.Now().Add(-time.Hour)
+	posts := make([]models.Post, 0, 2)
+	for _, slug := range []string{"first", "second"} {
+		post := models.Post{Title: slug, Slug: slug, Content: "body", Status: "published", PublishedAt: &published}
+		require.NoError(t, db.Create(&post).Error)
+		posts = append(posts, post)
+	}
+	return db, server, posts, []uuid.UUID{uuid.New(), uuid.New()}
+}
+
+func reactionCountsKey(id uuid.UUID) string {
+	return fmt.Sprintf("reactions:counts:%s:%s", models.ReactableTypePost, id)
+}
+
+func TestReactionCounting(t *testing.T) {
+	_, _, posts, users := reactionFixture(t)
+	post := posts[0].ID
+
+	_, err := services.ReactionSvc.React(users[0], models.ReactableTypePost, post, "like")
+	require.NoError(t, err)
+	_, err = services.ReactionSvc.React(users[1], models.ReactableTypePost, post, "like")
+	require.NoError(t, err)
+	// Reacting twice with the same type counts once
+	_, err = services.ReactionSvc.React(users[0], models.ReactableTypePost, post, "like")
+	require.NoError(t, err)
+	summary, err := services.ReactionSvc.React(users[0], models.ReactableTypePost, post, "heart")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"like": 2, "heart": 1}, summary.Counts)
+	assert.EqualValues(t, 3, summary.Total)
+	assert.Equal(t, []string{"like", "heart"}, summary.Reacted)
+
+	summary, err = services.ReactionSvc.Unreact(users[1], models.ReactableTypePost, post, "like")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"like": 1, "heart": 1}, summary.Counts)
+	assert.Empty(t, summary.Reacted)
+	_, err = services.ReactionSvc.Unreact(users[1], models.ReactableTypePost, post, "like")
+	require.NoError(t, err)
+
+	_, err = services.ReactionSvc.React(users[0], models.ReactableTypePost, post, "shrug")
+	assert.ErrorIs(t, err, services.ErrInvalidReaction)
+	_, err = services.ReactionSvc.React(users[0], models.ReactableTypePost, uuid.New(), "like")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestReactionSummariesLoadMissingCounters(t *testing.T) {
+	db, server, posts, users := reactionFixture(t)
+	require.NoError(t, db.Create(&[]models.Reaction{
+		{UserID: users[0], ReactableID: posts[0].ID, ReactableType: models.ReactableTypePost, Type: "like"},
+		{UserID: users[1], ReactableID: posts[0].ID, ReactableType: models.ReactableTypePost, Type: "party"},
+	}).Error)
+	// A hash without the loaded marker holds partial counts and is not trusted
+	server.HSet(reactionCountsKey(posts[0].ID), "like", "99")
+
+	ids := []uuid.UUID{posts[0].ID, posts[1].ID}
+	summaries, err := services.ReactionSvc.GetSummaries(models.ReactableTypePost, ids, &users[1])
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"like": 1, "party": 1}, summaries[posts[0].ID].Counts)
+	assert.Equal(t, []string{"party"}, summaries[posts[0].ID].Reacted)
+	assert.Empty(t, summaries[posts[1].ID].Counts)
+	assert.Zero(t, summaries[posts[1].ID].Total)
+
+	// Both counters are cached, including the one without reactions
+	for _, id := range ids {
+		assert.Equal(t, "1", server.HGet(reactionCountsKey(id), "_loaded"))
+	}
+	assert.Equal(t, "1", server.HGet(reactionCountsKey(posts[0].ID), "like"))
+
+	// Later reads come from the counters
+	server.HSet(reactionCountsKey(posts[1].ID), "wow", "4")
+	summaries, err = services.ReactionSvc.GetSummaries(models.ReactableTypePost, ids, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"wow": 4}, summaries[posts[1].ID].Counts)
+	assert.EqualValues(t, 4, summaries[posts[1].ID].Total)
+}
+
+func TestReactionReconcile(t *testing.T) {
+	db, server, posts, users := reactionFixture(t)
+	ctx := context.Background()
+
+	reconciled, err := services.ReactionSvc.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reconciled)
+
+	_, err = services.ReactionSvc.React(users[0], models.ReactableTypePost, posts[0].ID, "like")
+	require.NoError(t, err)
+	_, err = services.ReactionSvc.React(users[1], models.ReactableTypePost, posts[0].ID, "heart")
+	require.NoError(t, err)
+	// A counter that drifted from the reactions
+	server.HSet(reactionCountsKey(posts[0].ID), "like", "7")
+
+	reconciled, err = services.ReactionSvc.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reconciled)
+	assert.False(t, server.Exists("reactions:dirty"))
+	assert.Equal(t, "1", server.HGet(reactionCountsKey(posts[0].ID), "like"))
+
+	var stored []int64
+	require.NoError(t, db.Model(&models.Post{}).Order("slug").Pluck("reaction_count", &stored).Error)
+	assert.Equal(t, []int64{2, 0}, stored)
+
+	// A run that fails after claiming the dirty set puts it back for the next one
+	_, err = services.ReactionSvc.Unreact(users[1], models.ReactableTypePost, posts[0].ID, "heart")
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("ALTER TABLE reactions RENAME TO reactions_away").Error)
+	_, err = services.ReactionSvc.Reconcile(ctx)
+	assert.Error(t, err)
+	assert.True(t, server.Exists("reactions:dirty"))
+	require.NoError(t, db.Exec("ALTER TABLE reactions_away RENAME TO reactions").Error)
+	reconciled, err = services.ReactionSvc.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reconciled)
+	require.NoError(t, db.Model(&models.Post{}).Order("slug").Pluck("reaction_count", &stored).Error)
+	assert.Equal(t, []int64{1, 0}, stored)
+}
+
+func TestPostStatusChangesKeepTheCounters(t *testing.T) {
+	db, _, posts, users := reactionFixture(t)
+	_, err := services.ReactionSvc.React(users[0], models.ReactableTypePost, posts[0].ID, "like")
+	require.NoError(t, err)
+	_, err = services.ReactionSvc.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.Post{}).Where("id = ?", posts[0].ID).UpdateColumn("view_count", 12).Error)
+
+	blog := services.NewBlogService()
+	for _, change := range []func(string) error{blog.UnpublishPost, blog.PublishPost, blog.ArchivePost} {
+		require.NoError(t, change(posts[0].ID.String()))
+	}
+
+	var post models.Post
+	require.NoError(t, db.First(&post, "id = ?", posts[0].ID).Error)
+	assert.Equal(t, "archived", post.Status)
+	assert.NotNil(t, post.PublishedAt)
+	assert.EqualValues(t, 1, post.ReactionCount)
+	assert.EqualValues(t, 12, post.ViewCount)
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeRedirectPath(t *testing.T) {
+	cases := map[string]string{
+		"/blog/old-slug/":                   "/blog/old-slug",
+		"blog/old-slug":                     "/blog/old-slug",
+		" /blog/old-slug?utm=1 ":            "/blog/old-slug",
+		"https://example.com/blog/old-slug": "/blog/old-slug",
+		"":                                  "/",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, models.NormalizeRedirectPath(input), input)
+	}
+}
+
+func TestSlugRedirectErrorIsNotFound(t *testing.T) {
+	var err error = &services.SlugRedirectError{Type: models.MediableTypePost, Slug: "new-slug", StatusCode: 301}
+
+	var redirect *services.SlugRedirectError
+	assert.True(t, errors.As(err, &redirect))
+	assert.Equal(t, "new-slug", redirect.Slug)
+	// Callers unaware of redirects still see a missing record
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestGetRedirectsSearchIgnoresCase(t *testing.T) {
+	requirePostgres(t)
+	db := setupServiceDB(t, &models.Redirect{})
+	for _, redirect := range []models.Redirect{
+		{SourcePath: "/Blog/Launch-Notes", TargetURL: "/blog/launch", StatusCode: 301, IsActive: true},
+		{SourcePath: "/docs/setup", TargetURL: "https://docs.example.com/LAUNCH", StatusCode: 302, IsActive: true},
+		{SourcePath: "/about", TargetURL: "/team", StatusCode: 301, IsActive: true},
+	} {
+		require.NoError(t, db.Create(&redirect).Error)
+	}
+
+	redirects, total, err := services.RedirectSvc.GetRedirects(1, 10, "launch")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, redirects, 2)
+	assert.Equal(t, "/Blog/Launch-Notes", redirects[0].SourcePath)
+	assert.Equal(t, "/docs/setup", redirects[1].SourcePath)
+}
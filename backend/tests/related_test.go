@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecomputeRelatedPostsScoresCandidates(t *testing.T) {
+	db := setupServiceDB(t, &models.Post{}, &models.Category{})
+	setupServiceRedis(t)
+	setupPostRelationTables(t, db)
+
+	// devops holds kubernetes and docker; cooking is a separate tree
+	category := func(name string, left, right, depth int) *uuid.UUID {
+		c := models.Category{Name: name, Slug: name}
+		c.RecordLeft, c.RecordRight, c.RecordDept = left, right, depth
+		require.NoError(t, db.Create(&c).Error)
+		return &c.ID
+	}
+	category("devops", 1, 6, 0)
+	kubernetes := category("kubernetes", 2, 3, 1)
+	docker := category("docker", 4, 5, 1)
+	cooking := category("cooking", 7, 8, 0)
+
+	published := time.Now().Add(-time.Hour)
+	post := func(slug, title string, categoryID *uuid.UUID, tags ...int) uuid.UUID {
+		p := models.Post{Title: title, Slug: slug, Content: title, Status: "published", PublishedAt: &published, CategoryID: categoryID}
+		require.NoError(t, db.Create(&p).Error)
+		for _, tag := range tags {
+			require.NoError(t, db.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", p.ID, tag).Error)
+		}
+		return p.ID
+	}
+	subject := post("subject", "Deploying kubernetes clusters", kubernetes, 1, 2)
+	sameCategory := post("same-category", "Kubernetes operators", kubernetes, 1)
+	sibling := post("sibling", "Docker images", docker)
+	sharedTag := post("shared-tag", "Sourdough starters", cooking, 2)
+	// Similar text alone does not make a post a candidate
+	textOnly := post("text-only", "Deploying kubernetes clusters", cooking)
+
+	count, err := services.RelatedPostSvc.Precompute(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	scores, err := services.RelatedPostSvc.GetRelated(subject)
+	require.NoError(t, err)
+	byPost := make(map[uuid.UUID]services.RelatedPostScore, len(scores))
+	for _, score := range scores {
+		byPost[score.PostID] = score
+	}
+	require.Len(t, byPost, 3)
+	assert.Equal(t, sameCategory, scores[0].PostID)
+	assert.NotContains(t, byPost, textOnly)
+	assert.Equal(t, 1, byPost[sharedTag].SharedTags)
+	assert.Zero(t, byPost[sharedTag].Category)
+	// Sibling categories are two edges apart
+	assert.InDelta(t, 1.0/3, byPost[sibling].Category, 1e-9)
+	assert.Equal(t, 1.0, byPost[sameCategory].Category)
+}
@@ -0,0 +1,351 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-next/internal/http/controllers"
+	"go-next/internal/http/middleware"
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+	"go-next/pkg/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestIsReportReason(t *testing.T) {
+	for _, reason := range models.ReportReasons {
+		assert.True(t, services.IsReportReason(reason), reason)
+	}
+	assert.False(t, services.IsReportReason(""))
+	assert.False(t, services.IsReportReason("Spam"))
+}
+
+func TestNormalizeReportActionsOrdersAndDeduplicates(t *testing.T) {
+	actions, err := services.NormalizeReportActions([]string{"suspend", "warn", "hide", "warn"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hide", "warn", "suspend"}, actions)
+
+	actions, err = services.NormalizeReportActions(nil)
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+}
+
+func TestNormalizeReportActionsRejectsUnknownActions(t *testing.T) {
+	_, err := services.NormalizeReportActions([]string{"hide", "ban"})
+	assert.True(t, errors.Is(err, services.ErrInvalidReportAction))
+}
+
+func TestGroupReports(t *testing.T) {
+	postID, commentID := uuid.New(), uuid.New()
+	queue := []models.ReportedContent{
+		{ReportableType: models.ReportableTypePost, ReportableID: postID, ReportCount: 2},
+		{ReportableType: models.ReportableTypeComment, ReportableID: commentID, ReportCount: 1},
+	}
+	reports := []models.Report{
+		{ReportableType: models.ReportableTypePost, ReportableID: postID, Reason: models.ReportReasonSpam},
+		{ReportableType: models.ReportableTypeComment, ReportableID: commentID, Reason: models.ReportReasonHate},
+		{ReportableType: models.ReportableTypePost, ReportableID: postID, Reason: models.ReportReasonSpam},
+		// A comment with the same ID as the post is another target
+		{ReportableType: models.ReportableTypeComment, ReportableID: postID, Reason: models.ReportReasonOther},
+	}
+
+	services.GroupReports(queue, reports)
+
+	assert.Len(t, queue[0].Reports, 2)
+	assert.Equal(t, map[string]int64{models.ReportReasonSpam: 2}, queue[0].Reasons)
+	assert.Len(t, queue[1].Reports, 1)
+	assert.Equal(t, map[string]int64{models.ReportReasonHate: 1}, queue[1].Reasons)
+}
+
+func TestModerationLogIsImmutable(t *testing.T) {
+	entry := &models.ModerationLog{Action: models.ModerationActionAutoHide}
+	assert.ErrorIs(t, entry.BeforeUpdate(nil), models.ErrModerationLogImmutable)
+	assert.ErrorIs(t, entry.BeforeDelete(nil), models.ErrModerationLogImmutable)
+}
+
+// reportFixture sets up the database with an approved, public comment and
+// sets how many reports hide content
+func reportFixture(t *testing.T, hideAfter int) (*gorm.DB, models.User, models.Comment) {
+	t.Helper()
+
+	db := setupServiceDB(t, &models.User{}, &models.Post{}, &models.Comment{}, &models.Report{},
+		&models.ModerationLog{}, &models.RefreshToken{}, &models.Notification{}, &models.JWTKey{})
+	setupServiceRedis(t)
+	moderation := &config.GetConfig().Moderation
+	previous := moderation.ReportHideAfter
+	moderation.ReportHideAfter = hideAfter
+	t.Cleanup(func() { moderation.ReportHideAfter = previous })
+
+	author := models.User{Username: "author", Email: "author@example.com", Phone: "+10000000001", PasswordHash: "x", IsActive: true}
+	require.NoError(t, db.Create(&author).Error)
+	published := time.Now().Add(-time.Hour)
+	post := models.Post{Title: "Reported", Slug: "reported", Content: "body", Status: "published", PublishedAt: &published}
+	require.NoError(t, db.Create(&post).Error)
+	comment := models.Comment{Content: "reported comment", UserID: author.ID, PostID: post.ID, Status: models.CommentStatusApproved, IsPublic: true}
+	require.NoError(t, db.Create(&comment).Error)
+	return db, author, comment
+}
+
+// moderationActions lists the logged actions in order
+func moderationActions(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var actions []string
+	require.NoError(t, db.Model(&models.ModerationLog{}).Order("created_at ASC").Pluck("action", &actions).Error)
+	return actions
+}
+
+func TestReportAutoHidesAndDismissRestores(t *testing.T) {
+	db, author, comment := reportFixture(t, 2)
+	readers := []uuid.UUID{uuid.New(), uuid.New()}
+
+	_, err := services.ReportSvc.Report(readers[0], models.ReportableTypeComment, comment.ID, models.ReportReasonSpam, "")
+	require.NoError(t, err)
+	_, err = services.ReportSvc.Report(readers[0], models.ReportableTypeComment, comment.ID, models.ReportReasonHate, "")
+	assert.ErrorIs(t, err, services.ErrAlreadyReported)
+	_, err = services.ReportSvc.Report(author.ID, models.ReportableTypeComment, comment.ID, models.ReportReasonSpam, "")
+	assert.ErrorIs(t, err, services.ErrInvalidReport)
+
+	var stored models.Comment
+	require.NoError(t, db.First(&stored, "id = ?", comment.ID).Error)
+	assert.True(t, stored.IsPublic)
+	assert.Empty(t, moderationActions(t, db))
+
+	_, err = services.ReportSvc.Report(readers[1], models.ReportableTypeComment, comment.ID, models.ReportReasonSpam, "")
+	require.NoError(t, err)
+	require.NoError(t, db.First(&stored, "id = ?", comment.ID).Error)
+	assert.False(t, stored.IsPublic)
+	assert.NotNil(t, stored.HiddenAt)
+	assert.Equal(t, []string{models.ModerationActionAutoHide}, moderationActions(t, db))
+
+	moderatorID := uuid.New()
+	reports, err := services.ReportSvc.Dismiss(models.ReportableTypeComment, comment.ID, "not spam", &moderatorID)
+	require.NoError(t, err)
+	assert.Len(t, reports, 2)
+	var restored models.Comment
+	require.NoError(t, db.First(&restored, "id = ?", comment.ID).Error)
+	assert.True(t, restored.IsPublic)
+	assert.Nil(t, restored.HiddenAt)
+
+	var open int64
+	require.NoError(t, db.Model(&models.Report{}).Where("status = ?", models.ReportStatusOpen).Count(&open).Error)
+	assert.Zero(t, open)
+	var entry models.ModerationLog
+	require.NoError(t, db.First(&entry, "action = ?", models.ModerationActionDismiss).Error)
+	assert.Equal(t, &moderatorID, entry.ModeratorID)
+	assert.Equal(t, "not spam", entry.Note)
+	var details map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(entry.Details), &details))
+	assert.Equal(t, true, details["restored"])
+
+	_, err = services.ReportSvc.Dismiss(models.ReportableTypeComment, comment.ID, "", &moderatorID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestResolveHidesContentAndSuspendsItsAuthor(t *testing.T) {
+	db, author, comment := reportFixture(t, 0)
+	refresh := models.RefreshToken{UserID: author.ID, Token: "refresh", ExpiresAt: time.Now().Add(time.Hour), IsActive: true}
+	require.NoError(t, db.Create(&refresh).Error)
+
+	_, err := services.ReportSvc.Report(uuid.New(), models.ReportableTypeComment, comment.ID, models.ReportReasonHarassment, "")
+	require.NoError(t, err)
+
+	moderatorID := uuid.New()
+	reports, err := services.ReportSvc.Resolve(models.ReportableTypeComment, comment.ID,
+		[]string{services.ReportActionSuspend, services.ReportActionHide}, "repeated abuse", &moderatorID)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, models.ReportStatusResolved, reports[0].Status)
+	assert.Equal(t, &moderatorID, reports[0].ResolvedBy)
+
+	var stored models.Comment
+	require.NoError(t, db.First(&stored, "id = ?", comment.ID).Error)
+	assert.False(t, stored.IsPublic)
+	assert.Nil(t, stored.HiddenAt)
+	var user models.User
+	require.NoError(t, db.First(&user, "id = ?", author.ID).Error)
+	assert.False(t, user.IsActive)
+	require.NoError(t, db.First(&refresh, "id = ?", refresh.ID).Error)
+	assert.False(t, refresh.IsActive)
+
+	assert.Equal(t, []string{models.ModerationActionHide, models.ModerationActionSuspend, models.ModerationActionResolve}, moderationActions(t, db))
+	var suspension models.ModerationLog
+	require.NoError(t, db.First(&suspension, "action = ?", models.ModerationActionSuspend).Error)
+	assert.Equal(t, models.ModerationTargetUser, suspension.TargetType)
+	assert.Equal(t, author.ID, suspension.TargetID)
+
+	_, err = services.ReportSvc.Resolve(models.ReportableTypeComment, comment.ID, nil, "", &moderatorID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestModerationLogRejectsChangesInTheDatabase(t *testing.T) {
+	db := setupServiceDB(t, &models.ModerationLog{})
+	require.NoError(t, database.ProtectModerationLog(db))
+	// Installing the triggers again is a no-op
+	require.NoError(t, database.ProtectModerationLog(db))
+
+	require.NoError(t, db.Create(&models.ModerationLog{Action: models.ModerationActionAutoHide, TargetType: models.ReportableTypePost, TargetID: uuid.New()}).Error)
+	assert.Error(t, db.Exec("UPDATE moderation_logs SET note = ?", "rewritten").Error)
+	assert.Error(t, db.Exec("DELETE FROM moderation_logs").Error)
+
+	var entries []models.ModerationLog
+	require.NoError(t, db.Find(&entries).Error)
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0].Note)
+}
+
+func TestModerationLogRejectsTruncateOnPostgres(t *testing.T) {
+	requirePostgres(t)
+	db := setupServiceDB(t, &models.ModerationLog{})
+	require.NoError(t, database.ProtectModerationLog(db))
+
+	require.NoError(t, db.Create(&models.ModerationLog{Action: models.ModerationActionAutoHide, TargetType: models.ReportableTypePost, TargetID: uuid.New()}).Error)
+	assert.Error(t, db.Exec("TRUNCATE moderation_logs").Error)
+
+	var count int64
+	require.NoError(t, db.Model(&models.ModerationLog{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestJWTMiddlewareRejectsSuspendedUsers(t *testing.T) {
+	db, author, comment := reportFixture(t, 0)
+	require.NoError(t, db.Create(&models.JWTKey{KeyID: "test", Algorithm: "HS256", Key: "test-secret", IsActive: true}).Error)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": author.ID.String(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/me", middleware.JWTMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, fmt.Sprint(c.MustGet("user_id")))
+	})
+	request := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, author.ID.String(), w.Body.String())
+
+	// The status is cached, so later requests do not read the user
+	require.NoError(t, db.Migrator().RenameTable("users", "users_away"))
+	assert.Equal(t, http.StatusOK, request().Code)
+	require.NoError(t, db.Migrator().RenameTable("users_away", "users"))
+
+	// A suspension takes effect at once
+	_, err = services.ReportSvc.Report(uuid.New(), models.ReportableTypeComment, comment.ID, models.ReportReasonHarassment, "")
+	require.NoError(t, err)
+	_, err = services.ReportSvc.Resolve(models.ReportableTypeComment, comment.ID, []string{services.ReportActionSuspend}, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, request().Code)
+}
+
+func TestHiddenCommentsAreLeftOutOfPublicReads(t *testing.T) {
+	db := setupServiceDB(t, &models.User{}, &models.Category{}, &models.Media{}, &models.Post{}, &models.Comment{},
+		&models.Report{}, &models.ModerationLog{}, &models.Notification{}, &models.Reaction{}, &models.Mention{},
+		&models.Series{}, &models.SeriesPost{}, &models.PostAuthor{}, &models.AuthorProfile{})
+	setupPostRelationTables(t, db)
+	setupServiceRedis(t)
+	moderation := &config.GetConfig().Moderation
+	previous := moderation.ReportHideAfter
+	moderation.ReportHideAfter = 1
+	t.Cleanup(func() { moderation.ReportHideAfter = previous })
+
+	author := models.User{Username: "author", Email: "author@example.com", Phone: "+10000000001", PasswordHash: "x", IsActive: true}
+	require.NoError(t, db.Create(&author).Error)
+	published := time.Now().Add(-time.Hour)
+	post := models.Post{Title: "Reported", Slug: "reported", Content: "body", Status: "published", Public: true, PublishedAt: &published}
+	require.NoError(t, db.Create(&post).Error)
+	// A thread whose first reply gets hidden by a report: root > hidden > reply, and a visible sibling
+	comment := func(content string, parent *models.Comment, left, right int) models.Comment {
+		c := models.Comment{Content: content, UserID: author.ID, PostID: post.ID, Status: models.CommentStatusApproved, IsPublic: true}
+		if parent != nil {
+			c.ParentID = &parent.ID
+			c.RecordDept = parent.RecordDept + 1
+		}
+		c.RecordLeft, c.RecordRight = left, right
+		require.NoError(t, db.Create(&c).Error)
+		return c
+	}
+	root := comment("root", nil, 1, 8)
+	hidden := comment("hidden", &root, 2, 5)
+	reply := comment("reply", &hidden, 3, 4)
+	sibling := comment("sibling", &root, 6, 7)
+	reader := uuid.New()
+	notified := time.Now()
+	require.NoError(t, db.Create(&models.Mention{UserID: reader, MentionableID: hidden.ID, MentionableType: models.MentionableTypeComment,
+		PostID: post.ID, NotifiedAt: &notified}).Error)
+
+	_, err := services.ReportSvc.Report(reader, models.ReportableTypeComment, hidden.ID, models.ReportReasonSpam, "")
+	require.NoError(t, err)
+
+	stored, err := services.NewBlogService().GetPublicPost(post.Slug)
+	require.NoError(t, err)
+	var shown []uuid.UUID
+	for _, comment := range stored.Comments {
+		shown = append(shown, comment.ID)
+	}
+	assert.NotContains(t, shown, hidden.ID)
+	assert.Contains(t, shown, sibling.ID)
+
+	gin.SetMode(gin.TestMode)
+	handler := controllers.NewCommentHandler(services.CommentSvc)
+	router := gin.New()
+	router.GET("/comments/:id", handler.GetComment)
+	router.GET("/comments/:id/siblings", handler.GetSiblingComments)
+	router.GET("/comments/:id/parent", handler.GetParentComment)
+	router.GET("/comments/:id/descendants", handler.GetDescendantComments)
+	router.GET("/comments/:id/children", handler.GetChildrenComments)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	commentIDs := func(w *httptest.ResponseRecorder) []uuid.UUID {
+		require.Equal(t, http.StatusOK, w.Code)
+		var comments []models.Comment
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &comments))
+		ids := make([]uuid.UUID, 0, len(comments))
+		for _, comment := range comments {
+			ids = append(ids, comment.ID)
+		}
+		return ids
+	}
+
+	for _, path := range []string{"", "/siblings", "/parent", "/descendants", "/children"} {
+		assert.Equal(t, http.StatusNotFound, get("/comments/"+hidden.ID.String()+path).Code, path)
+	}
+	assert.Equal(t, http.StatusOK, get("/comments/"+sibling.ID.String()).Code)
+	assert.Equal(t, http.StatusNotFound, get("/comments/"+reply.ID.String()+"/parent").Code)
+	assert.Empty(t, commentIDs(get("/comments/"+sibling.ID.String()+"/siblings")))
+	assert.Equal(t, []uuid.UUID{sibling.ID}, commentIDs(get("/comments/"+root.ID.String()+"/children")))
+	assert.NotContains(t, commentIDs(get("/comments/"+root.ID.String()+"/descendants")), hidden.ID)
+
+	_, err = services.ReactionSvc.React(reader, models.ReactableTypeComment, hidden.ID, models.ReactionLike)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = services.ReactionSvc.React(reader, models.ReactableTypeComment, sibling.ID, models.ReactionLike)
+	require.NoError(t, err)
+
+	mentions, total, err := services.MentionSvc.GetUserMentions(reader, 1, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, mentions)
+}
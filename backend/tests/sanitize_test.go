@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"testing"
+
+	"go-next/pkg/markdown"
+	"go-next/pkg/sanitize"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeComment(t *testing.T) {
+	clean := sanitize.Comment(`<p onclick="steal()">Nice <strong>post</strong><script>alert(1)</script></p><img src="x" onerror="alert(1)"><h1>Big</h1>`)
+	assert.Equal(t, `<p>Nice <strong>post</strong></p>Big`, clean)
+
+	link := sanitize.Comment(`<a href="javascript:alert(1)">x</a> <a href="https://example.com">y</a>`)
+	assert.NotContains(t, link, "javascript:")
+	assert.Contains(t, link, `href="https://example.com"`)
+	assert.Contains(t, link, "nofollow")
+}
+
+func TestSanitizeEditor(t *testing.T) {
+	clean := sanitize.Editor(`<h2 id="intro" style="color:red">Intro</h2><img src="https://example.com/a.png" alt="A"><table><tr><td>1</td></tr></table><iframe src="https://evil.example"></iframe>`)
+	assert.Contains(t, clean, `<h2 id="intro">Intro</h2>`)
+	assert.Contains(t, clean, `<img src="https://example.com/a.png" alt="A">`)
+	assert.Contains(t, clean, "<td>1</td>")
+	assert.NotContains(t, clean, "iframe")
+	assert.NotContains(t, clean, "style=")
+}
+
+func TestSanitizeEditorKeepsRenderedMarkdown(t *testing.T) {
+	result, err := markdown.NewRenderer().Render([]byte("## Setup\n\nText[^1]\n\n```go\nx := 1\n```\n\n[^1]: Note\n"))
+	require.NoError(t, err)
+
+	clean := sanitize.Editor(result.HTML)
+	assert.Contains(t, clean, `<h2 id="setup">`)
+	assert.Contains(t, clean, `class="heading-anchor"`)
+	assert.Contains(t, clean, `<sup id="fnref:1">`)
+	assert.Contains(t, clean, `role="doc-noteref"`)
+	assert.Contains(t, clean, `<pre tabindex="0" class="chroma">`)
+	assert.Contains(t, clean, `<span class="nx">x</span>`)
+	assert.Contains(t, clean, `<li id="fn:1">`)
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/search"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSearchEngine(t *testing.T) *search.Engine {
+	engine, err := search.Open(filepath.Join(t.TempDir(), "index.bleve"))
+	require.NoError(t, err)
+	t.Cleanup(func() { engine.Close() })
+
+	published := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	docs := []search.Document{
+		{ID: "1", Type: search.TypePost, Title: "Getting started with Kubernetes", Body: "Deploy containers", Category: "devops", Tags: []string{"k8s", "docker"}, Author: "alice", Year: "2024", PublishedAt: published},
+		{ID: "2", Type: search.TypePost, Title: "Kubernetes networking deep dive", Body: "Services and ingress", Category: "devops", Tags: []string{"k8s"}, Author: "bob", Year: "2023", PublishedAt: published.AddDate(-1, 0, 0)},
+		{ID: "3", Type: search.TypePost, Title: "Baking sourdough bread", Body: "Flour and water", Category: "cooking", Tags: []string{"bread"}, Author: "alice", Year: "2024", PublishedAt: published},
+		{ID: "devops", Type: search.TypeCategory, Title: "DevOps", Slug: "devops"},
+	}
+	require.NoError(t, engine.IndexBatch(docs))
+
+	return engine
+}
+
+func TestSearchEngineFacets(t *testing.T) {
+	engine := setupSearchEngine(t)
+
+	res, err := engine.Search(search.Query{Text: "kubernetes", Type: search.TypePost})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(2), res.Total)
+	assert.Contains(t, res.Facets[search.FacetCategory], search.FacetTerm{Term: "devops", Count: 2})
+	assert.Contains(t, res.Facets[search.FacetTag], search.FacetTerm{Term: "k8s", Count: 2})
+	assert.Contains(t, res.Facets[search.FacetYear], search.FacetTerm{Term: "2024", Count: 1})
+}
+
+func TestSearchEngineFilters(t *testing.T) {
+	engine := setupSearchEngine(t)
+
+	res, err := engine.Search(search.Query{
+		Text:    "kubernetes",
+		Type:    search.TypePost,
+		Filters: map[string]string{search.FacetAuthor: "bob"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Hits, 1)
+	assert.Equal(t, "2", res.Hits[0].ID)
+	assert.Equal(t, search.TypePost, res.Hits[0].Type)
+}
+
+func TestSearchEngineTypoTolerance(t *testing.T) {
+	engine := setupSearchEngine(t)
+
+	res, err := engine.Search(search.Query{Text: "kubernets", Type: search.TypePost})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), res.Total)
+
+	res, err = engine.Search(search.Query{Text: "sourdugh", Type: search.TypePost})
+	require.NoError(t, err)
+	require.Len(t, res.Hits, 1)
+	assert.Equal(t, "3", res.Hits[0].ID)
+}
+
+func TestSearchEngineDelete(t *testing.T) {
+	engine := setupSearchEngine(t)
+
+	require.NoError(t, engine.Delete(search.TypePost, "3"))
+
+	res, err := engine.Search(search.Query{Text: "sourdough", Type: search.TypePost})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), res.Total)
+}
+
+func TestSearchEngineCorrect(t *testing.T) {
+	engine := setupSearchEngine(t)
+
+	correction, err := engine.Correct("kubernets networkng")
+	require.NoError(t, err)
+	assert.Equal(t, "kubernetes networking", correction)
+
+	correction, err = engine.Correct("sourdough bread")
+	require.NoError(t, err)
+	assert.Empty(t, correction)
+}
+
+func TestCorrectFromVocabulary(t *testing.T) {
+	vocabulary := map[string]uint64{}
+	search.AddVocabulary(vocabulary, "Getting started with Kubernetes")
+	search.AddVocabulary(vocabulary, "Kubernetes networking: a deep-dive")
+	search.AddVocabulary(vocabulary, "Baking sourdough bread")
+	assert.Equal(t, uint64(2), vocabulary["kubernetes"])
+	assert.Equal(t, uint64(1), vocabulary["networking"])
+	assert.Equal(t, uint64(1), vocabulary["dive"])
+
+	assert.Equal(t, "kubernetes networking", search.CorrectFrom("Kubernets networkng", vocabulary))
+	assert.Empty(t, search.CorrectFrom("sourdough bread", vocabulary))
+	assert.Empty(t, search.CorrectFrom("", vocabulary))
+}
+
+func TestCorrectFromPrefersFrequentTerms(t *testing.T) {
+	vocabulary := map[string]uint64{"bread": 5, "breed": 1, "bead": 5}
+	// bread and bead are both one edit from "brad" and equally frequent; the lexically first wins
+	assert.Equal(t, "bead", search.CorrectFrom("brad", vocabulary))
+	assert.Equal(t, "bread", search.CorrectFrom("breadd", vocabulary))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 0, search.Levenshtein("bread", "bread"))
+	assert.Equal(t, 1, search.Levenshtein("bred", "bread"))
+	assert.Equal(t, 3, search.Levenshtein("kitten", "sitting"))
+	assert.Equal(t, 5, search.Levenshtein("", "bread"))
+}
+
+func TestTFIDFSimilarity(t *testing.T) {
+	vectors := search.BuildVectors(map[string]string{
+		"k8s":     "Kubernetes cluster networking with ingress controllers",
+		"k8s-ops": "Operating a Kubernetes cluster in production",
+		"bread":   "Baking sourdough bread with a starter",
+	})
+
+	related := search.Cosine(vectors["k8s"], vectors["k8s-ops"])
+	unrelated := search.Cosine(vectors["k8s"], vectors["bread"])
+
+	assert.Greater(t, related, unrelated)
+	assert.InDelta(t, 1.0, search.Cosine(vectors["bread"], vectors["bread"]), 1e-9)
+	assert.Equal(t, 0.0, unrelated)
+}
+
+func TestTokenizeDropsStopWords(t *testing.T) {
+	assert.Equal(t, []string{"building", "api", "go"}, search.Tokenize("Building an API in Go!"))
+}
+
+func TestSearchEngineLocales(t *testing.T) {
+	engine, err := search.Open(filepath.Join(t.TempDir(), "index.bleve"))
+	require.NoError(t, err)
+	t.Cleanup(func() { engine.Close() })
+	require.NoError(t, engine.IndexBatch([]search.Document{
+		{ID: "en", Type: search.TypePost, Title: "Release notes", Locale: "en", Translations: []string{"de", "en"}},
+		{ID: "de", Type: search.TypePost, Title: "Release notes", Locale: "de", Translations: []string{"de", "en"}},
+		{ID: "de-only", Type: search.TypePost, Title: "Release schedule", Locale: "de", Translations: []string{"de"}},
+	}))
+
+	matches := func(locales ...string) []string {
+		res, err := engine.Search(search.Query{Text: "release", Type: search.TypePost, Locales: locales})
+		require.NoError(t, err)
+		var ids []string
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+	assert.ElementsMatch(t, []string{"en", "de", "de-only"}, matches())
+	assert.ElementsMatch(t, []string{"en"}, matches("en"))
+	// A fallback locale only fills in for posts without a preferred translation
+	assert.ElementsMatch(t, []string{"en", "de-only"}, matches("en", "de"))
+	assert.ElementsMatch(t, []string{"de", "de-only"}, matches("de", "en"))
+}
+
+func TestSearchKeepsScoreOrderForEveryType(t *testing.T) {
+	db := setupServiceDB(t, &models.Category{})
+	categories := []models.Category{
+		{Name: "Backend", Slug: "backend", Description: "Services written in golang and other languages"},
+		{Name: "Golang", Slug: "golang", Description: "golang"},
+	}
+	require.NoError(t, db.Create(&categories).Error)
+
+	engine, err := search.Open(filepath.Join(t.TempDir(), "index.bleve"))
+	require.NoError(t, err)
+	svc := services.NewSearchService(engine, "")
+	t.Cleanup(func() { svc.Close() })
+	for i := range categories {
+		require.NoError(t, svc.IndexCategory(&categories[i]))
+	}
+
+	result, err := svc.Search(services.SearchRequest{Query: "golang", Type: search.TypeCategory})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 2)
+	// The more relevant category was stored last
+	require.Equal(t, categories[1].ID.String(), result.Hits[0].ID)
+	found := result.Data.([]models.Category)
+	require.Len(t, found, 2)
+	for i, hit := range result.Hits {
+		assert.Equal(t, hit.ID, found[i].ID.String())
+	}
+}
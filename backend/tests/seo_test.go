@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"go-next/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeRobots(t *testing.T) {
+	assert.Equal(t, "noindex, follow", models.NormalizeRobots(" NoIndex ,follow,, "))
+	assert.Equal(t, "", models.NormalizeRobots(" , "))
+}
+
+func TestValidateRobots(t *testing.T) {
+	valid := []string{"", "index, follow", "noindex, nofollow", "max-snippet:50, max-image-preview:large", "none"}
+	for _, robots := range valid {
+		assert.NoError(t, models.ValidateRobots(robots), robots)
+	}
+
+	invalid := []string{"indexx", "follow, nocache", "max-snippet", "noindex:1"}
+	for _, robots := range invalid {
+		assert.Error(t, models.ValidateRobots(robots), robots)
+	}
+}
+
+func TestSEOMetaIsEmpty(t *testing.T) {
+	assert.True(t, (&models.SEOMeta{}).IsEmpty())
+	assert.False(t, (&models.SEOMeta{Robots: "noindex"}).IsEmpty())
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSeriesURL(t *testing.T) {
+	site := config.SiteConfig{URL: "https://example.com/"}
+	assert.Equal(t, "https://example.com/blog/series/go-basics", site.SeriesURL("go-basics"))
+}
+
+func TestPostSeriesNavigationJSON(t *testing.T) {
+	post := models.Post{Title: "Part two", Slug: "part-two"}
+
+	body, err := json.Marshal(post)
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), `"series"`)
+
+	post.Series = &models.SeriesNavigation{
+		ID:       uuid.New(),
+		Title:    "Go basics",
+		Slug:     "go-basics",
+		Part:     2,
+		Total:    3,
+		Previous: &models.SeriesLink{ID: uuid.New(), Title: "Part one", Slug: "part-one"},
+	}
+	body, err = json.Marshal(post)
+	require.NoError(t, err)
+
+	var doc struct {
+		Series struct {
+			Part     int                    `json:"part"`
+			Total    int                    `json:"total"`
+			Previous map[string]interface{} `json:"previous"`
+			Next     map[string]interface{} `json:"next"`
+		} `json:"series"`
+	}
+	require.NoError(t, json.Unmarshal(body, &doc))
+	assert.Equal(t, 2, doc.Series.Part)
+	assert.Equal(t, 3, doc.Series.Total)
+	assert.Equal(t, "part-one", doc.Series.Previous["slug"])
+	assert.Nil(t, doc.Series.Next)
+}
+
+// seedSeries creates an active series of published posts, one per slug,
+// entered at the given stored positions
+func seedSeries(t *testing.T, db *gorm.DB, slugs []string, positions []int) (models.Series, []models.Post) {
+	t.Helper()
+
+	series := models.Series{Title: "Go basics", Slug: "go-basics", IsActive: true}
+	require.NoError(t, db.Create(&series).Error)
+	published := time.Now().Add(-time.Hour)
+	posts := make([]models.Post, 0, len(slugs))
+	for i, slug := range slugs {
+		post := models.Post{Title: slug, Slug: slug, Content: "body", Status: "published", Public: true, PublishedAt: &published}
+		require.NoError(t, db.Create(&post).Error)
+		entry := models.SeriesPost{SeriesID: series.ID, PostID: post.ID, Position: positions[i]}
+		require.NoError(t, db.Create(&entry).Error)
+		time.Sleep(time.Millisecond)
+		posts = append(posts, post)
+	}
+	return series, posts
+}
+
+// seriesSlugs lists the slugs of a series in order and checks the positions are dense
+func seriesSlugs(t *testing.T, seriesID uuid.UUID) []string {
+	t.Helper()
+
+	entries, err := services.SeriesSvc.ListEntries(seriesID)
+	require.NoError(t, err)
+	slugs := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		assert.Equal(t, i, entry.Position)
+		slugs = append(slugs, entry.Post.Slug)
+	}
+	return slugs
+}
+
+func TestSeriesAddPost(t *testing.T) {
+	db := setupServiceDB(t, &models.Series{}, &models.SeriesPost{}, &models.Post{})
+
+	// Entries saved directly can have gaps and ties in their positions
+	series, _ := seedSeries(t, db, []string{"a", "b", "c"}, []int{0, 0, 5})
+
+	var posts []models.Post
+	for _, slug := range []string{"new", "last"} {
+		post := models.Post{Title: slug, Slug: slug, Content: "body"}
+		require.NoError(t, db.Create(&post).Error)
+		posts = append(posts, post)
+	}
+
+	position := 2
+	entry, err := services.SeriesSvc.AddPost(series.ID, posts[0].ID, &position)
+	require.NoError(t, err)
+	assert.Equal(t, 2, entry.Position)
+	_, err = services.SeriesSvc.AddPost(series.ID, posts[1].ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "new", "c", "last"}, seriesSlugs(t, series.ID))
+
+	_, err = services.SeriesSvc.AddPost(series.ID, posts[0].ID, nil)
+	assert.True(t, errors.Is(err, services.ErrPostInSeries))
+	_, err = services.SeriesSvc.AddPost(series.ID, uuid.New(), nil)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestSeriesRemovePost(t *testing.T) {
+	db := setupServiceDB(t, &models.Series{}, &models.SeriesPost{}, &models.Post{})
+	series, posts := seedSeries(t, db, []string{"a", "b", "c"}, []int{0, 1, 2})
+
+	require.NoError(t, services.SeriesSvc.RemovePost(series.ID, posts[0].ID))
+	assert.Equal(t, []string{"b", "c"}, seriesSlugs(t, series.ID))
+
+	err := services.SeriesSvc.RemovePost(series.ID, posts[0].ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestSeriesReorderPosts(t *testing.T) {
+	db := setupServiceDB(t, &models.Series{}, &models.SeriesPost{}, &models.Post{})
+	series, posts := seedSeries(t, db, []string{"a", "b", "c"}, []int{0, 1, 2})
+
+	reordered, err := services.SeriesSvc.ReorderPosts(series.ID, []uuid.UUID{posts[2].ID, posts[0].ID, posts[1].ID})
+	require.NoError(t, err)
+	require.Len(t, reordered, 3)
+	assert.Equal(t, posts[2].ID, reordered[0].PostID)
+	assert.Equal(t, []string{"c", "a", "b"}, seriesSlugs(t, series.ID))
+
+	for name, order := range map[string][]uuid.UUID{
+		"missing":   {posts[0].ID, posts[1].ID},
+		"duplicate": {posts[0].ID, posts[0].ID, posts[1].ID},
+		"unknown":   {posts[0].ID, posts[1].ID, uuid.New()},
+	} {
+		_, err := services.SeriesSvc.ReorderPosts(series.ID, order)
+		assert.True(t, errors.Is(err, services.ErrSeriesOrderMismatch), name)
+	}
+	assert.Equal(t, []string{"c", "a", "b"}, seriesSlugs(t, series.ID))
+}
+
+func TestSeriesGetNavigation(t *testing.T) {
+	db := setupServiceDB(t, &models.Series{}, &models.SeriesPost{}, &models.Post{})
+	series, posts := seedSeries(t, db, []string{"a", "draft", "b", "c"}, []int{0, 1, 2, 3})
+	require.NoError(t, db.Model(&posts[1]).Update("status", "draft").Error)
+
+	navigation, err := services.SeriesSvc.GetNavigation(posts[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, series.ID, navigation.ID)
+	assert.Equal(t, 2, navigation.Part)
+	assert.Equal(t, 3, navigation.Total)
+	require.NotNil(t, navigation.Previous)
+	assert.Equal(t, "a", navigation.Previous.Slug)
+	require.NotNil(t, navigation.Next)
+	assert.Equal(t, "c", navigation.Next.Slug)
+
+	navigation, err = services.SeriesSvc.GetNavigation(posts[0].ID)
+	require.NoError(t, err)
+	assert.Nil(t, navigation.Previous)
+
+	// Drafts and posts of inactive series have no navigation
+	_, err = services.SeriesSvc.GetNavigation(posts[1].ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	require.NoError(t, db.Model(&series).Update("is_active", false).Error)
+	_, err = services.SeriesSvc.GetNavigation(posts[2].ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"encoding/xml"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/sitemap"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemapPagination(t *testing.T) {
+	assert.Equal(t, 1, sitemap.Pages(0))
+	assert.Equal(t, 1, sitemap.Pages(sitemap.MaxURLs))
+	assert.Equal(t, 2, sitemap.Pages(sitemap.MaxURLs+1))
+
+	urls := make([]sitemap.URL, sitemap.MaxURLs+10)
+	for i := range urls {
+		urls[i].Loc = "https://example.com/" + strconv.Itoa(i)
+	}
+	assert.Len(t, sitemap.Page(urls, 1), sitemap.MaxURLs)
+	assert.Len(t, sitemap.Page(urls, 2), 10)
+	assert.Equal(t, urls[sitemap.MaxURLs].Loc, sitemap.Page(urls, 2)[0].Loc)
+	assert.Nil(t, sitemap.Page(urls, 3))
+}
+
+func TestSitemapURLSet(t *testing.T) {
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	body, err := sitemap.URLSet([]sitemap.URL{
+		{Loc: "https://example.com/blog/a", LastMod: updated, Images: []sitemap.Image{{Loc: "https://example.com/a.jpg"}}},
+		{Loc: "https://example.com/blog/b"},
+	})
+	require.NoError(t, err)
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+		URLs    []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+			Images  []struct {
+				Loc string `xml:"http://www.google.com/schemas/sitemap-image/1.1 loc"`
+			} `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+		} `xml:"url"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	require.Len(t, doc.URLs, 2)
+	assert.Equal(t, "2024-05-01T12:00:00Z", doc.URLs[0].LastMod)
+	require.Len(t, doc.URLs[0].Images, 1)
+	assert.Equal(t, "https://example.com/a.jpg", doc.URLs[0].Images[0].Loc)
+	assert.Empty(t, doc.URLs[1].LastMod)
+}
+
+func TestSitemapAlternates(t *testing.T) {
+	body, err := sitemap.URLSet([]sitemap.URL{{
+		Loc: "https://example.com/blog/hello",
+		Alternates: []sitemap.Alternate{
+			{Hreflang: "en", Href: "https://example.com/blog/hello"},
+			{Hreflang: "fr", Href: "https://example.com/fr/blog/bonjour"},
+			{Hreflang: "x-default", Href: "https://example.com/blog/hello"},
+		},
+	}})
+	require.NoError(t, err)
+
+	var doc struct {
+		URLs []struct {
+			Links []struct {
+				Rel      string `xml:"rel,attr"`
+				Hreflang string `xml:"hreflang,attr"`
+				Href     string `xml:"href,attr"`
+			} `xml:"http://www.w3.org/1999/xhtml link"`
+		} `xml:"url"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	require.Len(t, doc.URLs, 1)
+	require.Len(t, doc.URLs[0].Links, 3)
+	assert.Equal(t, "alternate", doc.URLs[0].Links[1].Rel)
+	assert.Equal(t, "fr", doc.URLs[0].Links[1].Hreflang)
+	assert.Equal(t, "https://example.com/fr/blog/bonjour", doc.URLs[0].Links[1].Href)
+
+	// Without alternates the xhtml namespace is not declared
+	body, err = sitemap.URLSet([]sitemap.URL{{Loc: "https://example.com/blog/hello"}})
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "xhtml")
+}
+
+func TestSitemapIndex(t *testing.T) {
+	body, err := sitemap.Index([]sitemap.Entry{
+		{Loc: "https://example.com/sitemaps/posts-1.xml", LastMod: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Loc: "https://example.com/sitemaps/tags-1.xml"},
+	})
+	require.NoError(t, err)
+
+	var doc struct {
+		XMLName  xml.Name `xml:"sitemapindex"`
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+	require.Len(t, doc.Sitemaps, 2)
+	assert.Equal(t, "https://example.com/sitemaps/posts-1.xml", doc.Sitemaps[0].Loc)
+	assert.NotContains(t, string(body), "xmlns:image")
+}
+
+func TestSitemapRefreshFollowsChangesOnOtherInstances(t *testing.T) {
+	db := setupServiceDB(t, &models.Post{}, &models.Category{}, &models.Tag{}, &models.Series{}, &models.SeriesPost{}, &models.Media{})
+	setupPostRelationTables(t, db)
+	setupServiceRedis(t)
+	ctx := context.Background()
+
+	local, other := services.NewSitemapService(), services.NewSitemapService()
+	refreshed, err := local.Refresh(ctx)
+	require.NoError(t, err)
+	assert.True(t, refreshed, "sitemaps are built on the first run")
+	refreshed, err = local.Refresh(ctx)
+	require.NoError(t, err)
+	assert.False(t, refreshed)
+
+	published := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&models.Post{Title: "Hello", Slug: "hello", Content: "body", Status: "published", Public: true, PublishedAt: &published}).Error)
+	// A publish handled by another instance reaches this one through Redis
+	other.MarkDirty()
+	refreshed, err = local.Refresh(ctx)
+	require.NoError(t, err)
+	assert.True(t, refreshed)
+
+	doc, err := local.GetSitemap(services.SitemapPosts + "-1")
+	require.NoError(t, err)
+	assert.Contains(t, string(doc.Body), "/blog/hello")
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-next/pkg/spam"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleHeuristics() *spam.Heuristics {
+	return &spam.Heuristics{
+		MaxLinks:       2,
+		BlockedWords:   []string{"casino"},
+		BlockedDomains: []string{"spam.example"},
+		MinFillTime:    3 * time.Second,
+		MinInterval:    15 * time.Second,
+	}
+}
+
+func TestSpamHeuristicsCleanComment(t *testing.T) {
+	score, err := sampleHeuristics().Classify(context.Background(), spam.Submission{
+		Content:       "Thanks, this cleared up how the nested set works. See https://go.dev/doc too.",
+		FillTime:      time.Minute,
+		SincePrevious: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, score.Score)
+	assert.Empty(t, score.Reasons)
+}
+
+func TestSpamHeuristicsFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		submission spam.Submission
+		min        float64
+	}{
+		{"honeypot", spam.Submission{Content: "hello", Honeypot: "http://bot.example"}, 1},
+		{"blocked domain", spam.Submission{Content: `<a href="https://www.spam.example/buy">deal</a>`}, 0.9},
+		{"blocked subdomain", spam.Submission{Content: "see http://shop.spam.example"}, 0.9},
+		{"blocked word", spam.Submission{Content: "Best CASINO bonus"}, 0.6},
+		{"too many links", spam.Submission{Content: "http://a.example http://b.example http://c.example"}, 0.4},
+		{"fast fill", spam.Submission{Content: "hello", FillTime: time.Second}, 0.5},
+		{"fast repeat", spam.Submission{Content: "hello", SincePrevious: 2 * time.Second}, 0.4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, err := sampleHeuristics().Classify(context.Background(), tt.submission)
+			require.NoError(t, err)
+			assert.GreaterOrEqual(t, score.Score, tt.min)
+			assert.NotEmpty(t, score.Reasons)
+		})
+	}
+}
+
+func TestSpamHeuristicsRepeatedLinkCountsOnce(t *testing.T) {
+	score, err := sampleHeuristics().Classify(context.Background(), spam.Submission{
+		Content: `<a href="https://go.dev/">https://go.dev</a> and <a href="https://pkg.go.dev">https://pkg.go.dev</a>`,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, score.Score)
+}
+
+func TestSpamHeuristicsCapsScore(t *testing.T) {
+	score, err := sampleHeuristics().Classify(context.Background(), spam.Submission{
+		Content:  "casino http://spam.example",
+		Honeypot: "x",
+		FillTime: time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score.Score)
+}
+
+func TestSpamTokenize(t *testing.T) {
+	tokens := spam.Tokenize("<p>Buy CHEAP pills, buy now at cheap-pills.example!</p> a")
+	assert.Equal(t, []string{"buy", "cheap", "pills", "now", "at", "cheap-pills.example"}, tokens)
+}
+
+func trainBayes(t *testing.T, b *spam.Bayes, rounds int) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < rounds; i++ {
+		require.NoError(t, b.Train(ctx, spam.Submission{Content: fmt.Sprintf("cheap pills viagra discount offer %d", i)}, true))
+		require.NoError(t, b.Train(ctx, spam.Submission{Content: fmt.Sprintf("great article about golang testing %d", i)}, false))
+	}
+}
+
+func TestSpamBayesNeedsTraining(t *testing.T) {
+	b := spam.NewBayes(spam.NewMemoryStore())
+	trainBayes(t, b, spam.DefaultMinTraining-1)
+
+	score, err := b.Classify(context.Background(), spam.Submission{Content: "cheap pills"})
+	require.NoError(t, err)
+	assert.Zero(t, score.Score)
+	assert.NotEmpty(t, score.Reasons)
+}
+
+func TestSpamBayesClassifies(t *testing.T) {
+	b := spam.NewBayes(spam.NewMemoryStore())
+	trainBayes(t, b, 20)
+	ctx := context.Background()
+
+	spammy, err := b.Classify(ctx, spam.Submission{Content: "Cheap viagra pills, discount!"})
+	require.NoError(t, err)
+	assert.Greater(t, spammy.Score, 0.9)
+	assert.Contains(t, spammy.Reasons[0], "viagra")
+
+	hammy, err := b.Classify(ctx, spam.Submission{Content: "A great golang article"})
+	require.NoError(t, err)
+	assert.Less(t, hammy.Score, 0.1)
+}
+
+func TestSpamBayesUntrain(t *testing.T) {
+	store := spam.NewMemoryStore()
+	b := spam.NewBayes(store)
+	ctx := context.Background()
+	s := spam.Submission{Content: "golang generics"}
+
+	require.NoError(t, b.Train(ctx, s, true))
+	require.NoError(t, b.Untrain(ctx, s, true))
+	require.NoError(t, b.Untrain(ctx, s, true))
+	require.NoError(t, b.Train(ctx, s, false))
+
+	counts, docs, err := store.Counts(ctx, []string{"golang"})
+	require.NoError(t, err)
+	assert.Equal(t, spam.TokenCounts{Spam: 0, Ham: 1}, docs)
+	assert.Equal(t, spam.TokenCounts{Spam: 0, Ham: 1}, counts["golang"])
+}
+
+func TestSpamExternal(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"score": 0.97, "reasons": ["known spammer"]}`))
+	}))
+	defer server.Close()
+
+	external := spam.NewExternal(server.URL, "secret", server.Client())
+	score, err := external.Classify(context.Background(), spam.Submission{
+		Content: "hello",
+		Author:  "bob",
+		IP:      "203.0.113.9",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0.97, score.Score)
+	assert.Equal(t, []string{"known spammer"}, score.Reasons)
+	assert.Equal(t, "hello", received["content"])
+	assert.Equal(t, "203.0.113.9", received["ip"])
+}
+
+func TestSpamExternalErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"reasons": []}`))
+	}))
+	defer server.Close()
+
+	_, err := spam.NewExternal(server.URL+"/down", "", server.Client()).Classify(context.Background(), spam.Submission{})
+	assert.Error(t, err)
+	_, err = spam.NewExternal(server.URL, "", server.Client()).Classify(context.Background(), spam.Submission{})
+	assert.Error(t, err)
+}
+
+type fixedClassifier struct {
+	name  string
+	score float64
+	err   error
+}
+
+func (f fixedClassifier) Name() string { return f.name }
+
+func (f fixedClassifier) Classify(context.Context, spam.Submission) (spam.Score, error) {
+	return spam.Score{Score: f.score}, f.err
+}
+
+func TestSpamScreen(t *testing.T) {
+	result := spam.Screen(context.Background(), spam.Submission{Content: "hello"},
+		fixedClassifier{name: "low", score: 0.2},
+		fixedClassifier{name: "broken", err: errors.New("timeout")},
+		fixedClassifier{name: "high", score: 1.7},
+	)
+
+	assert.Equal(t, 1.0, result.Score)
+	require.Len(t, result.Scores, 2)
+	assert.Equal(t, "low", result.Scores[0].Classifier)
+	assert.Equal(t, "high", result.Scores[1].Classifier)
+	assert.Equal(t, []string{"broken: timeout"}, result.Errors)
+}
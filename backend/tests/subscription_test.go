@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go-next/internal/http/controllers"
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/email"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCommentNotificationTemplateEscapes(t *testing.T) {
+	body := email.CommentNotificationTemplate("bob", `Eve commented on "<b>Post</b>"`, `<script>alert(1)</script>`,
+		"https://example.com/blog/post#comment-1", "https://example.com/api/v1/subscriptions/unsubscribe?token=a&b")
+
+	assert.NotContains(t, body, "<script>")
+	assert.Contains(t, body, "&lt;script&gt;")
+	assert.Contains(t, body, "&#34;&lt;b&gt;Post&lt;/b&gt;&#34;")
+	assert.Contains(t, body, `href="https://example.com/api/v1/subscriptions/unsubscribe?token=a&amp;b"`)
+}
+
+// subscriptionFixture subscribes a user to a published post and returns the
+// subscription with the token of its unsubscribe link
+func subscriptionFixture(t *testing.T) (*gorm.DB, *models.Subscription, string) {
+	t.Helper()
+
+	db := setupServiceDB(t, &models.User{}, &models.Post{}, &models.Comment{}, &models.Subscription{})
+	user := models.User{Username: "bob", Email: "bob@example.com", PasswordHash: "x"}
+	require.NoError(t, db.Create(&user).Error)
+	published := time.Now().Add(-time.Hour)
+	post := models.Post{Title: "Nested sets", Slug: "nested-sets", Content: "body", Status: "published", PublishedAt: &published}
+	require.NoError(t, db.Create(&post).Error)
+
+	subscription, err := services.SubscriptionSvc.Subscribe(user.ID, post.ID, nil)
+	require.NoError(t, err)
+	parsed, err := url.Parse(services.UnsubscribeURL(subscription))
+	require.NoError(t, err)
+	return db, subscription, parsed.Query().Get("token")
+}
+
+func TestUnsubscribeURLUsesTheStoredToken(t *testing.T) {
+	_, subscription, token := subscriptionFixture(t)
+	assert.Equal(t, subscription.UnsubscribeToken, token)
+	assert.Len(t, token, 64)
+}
+
+func TestSubscribeTwiceReturnsTheExistingSubscription(t *testing.T) {
+	db, subscription, _ := subscriptionFixture(t)
+	comment := models.Comment{PostID: subscription.PostID, UserID: subscription.UserID, Content: "First", Status: models.CommentStatusApproved}
+	require.NoError(t, db.Create(&comment).Error)
+
+	again, err := services.SubscriptionSvc.Subscribe(subscription.UserID, subscription.PostID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, subscription.ID, again.ID)
+
+	thread, err := services.SubscriptionSvc.Subscribe(subscription.UserID, subscription.PostID, &comment.ID)
+	require.NoError(t, err)
+	again, err = services.SubscriptionSvc.Subscribe(subscription.UserID, subscription.PostID, &comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, thread.ID, again.ID)
+
+	// A racing insert of the same thread subscription is rejected by the index
+	duplicate := models.Subscription{UserID: thread.UserID, PostID: thread.PostID, CommentID: thread.CommentID, UnsubscribeToken: strings.Repeat("1", 64)}
+	assert.Error(t, db.Create(&duplicate).Error)
+	var count int64
+	require.NoError(t, db.Model(&models.Subscription{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestUnsubscribeByToken(t *testing.T) {
+	db, subscription, token := subscriptionFixture(t)
+
+	_, err := services.SubscriptionSvc.UnsubscribeByToken("")
+	assert.ErrorIs(t, err, services.ErrInvalidUnsubscribeToken)
+	cancelled, err := services.SubscriptionSvc.UnsubscribeByToken(strings.Repeat("0", 64))
+	require.NoError(t, err)
+	assert.Nil(t, cancelled)
+
+	cancelled, err = services.SubscriptionSvc.UnsubscribeByToken(token)
+	require.NoError(t, err)
+	require.NotNil(t, cancelled)
+	assert.Equal(t, subscription.ID, cancelled.ID)
+	var left int64
+	require.NoError(t, db.Unscoped().Model(&models.Subscription{}).Count(&left).Error)
+	assert.Zero(t, left)
+
+	// Using a link twice is not an error
+	cancelled, err = services.SubscriptionSvc.UnsubscribeByToken(token)
+	require.NoError(t, err)
+	assert.Nil(t, cancelled)
+}
+
+func TestUnsubscribeLinkOnlyCancelsOnPost(t *testing.T) {
+	db, _, token := subscriptionFixture(t)
+
+	handler := controllers.NewSubscriptionHandler(services.SubscriptionSvc)
+	router := gin.New()
+	router.GET("/subscriptions/unsubscribe", handler.ConfirmUnsubscribe)
+	router.POST("/subscriptions/unsubscribe", handler.UnsubscribeByToken)
+	subscriptions := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.Subscription{}).Count(&count).Error)
+		return count
+	}
+
+	// Opening the link, as mail scanners do, only asks for confirmation
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/subscriptions/unsubscribe?token="+token, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<form method="post" action="?token=`+token+`">`)
+	assert.Contains(t, w.Body.String(), "Nested sets")
+	assert.EqualValues(t, 1, subscriptions())
+
+	// One-click unsubscribe posts List-Unsubscribe=One-Click to the link (RFC 8058)
+	req := httptest.NewRequest(http.MethodPost, "/subscriptions/unsubscribe?token="+token, strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Zero(t, subscriptions())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/subscriptions/unsubscribe?token="+token, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "You are no longer subscribed.")
+}
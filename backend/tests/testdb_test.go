@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+	"go-next/pkg/database"
+	"go-next/pkg/redis"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// testDatabaseURL names the environment variable holding a PostgreSQL
+// connection string for the tests that need the production dialect
+const testDatabaseURL = "TEST_DATABASE_URL"
+
+// requirePostgres skips a test that depends on PostgreSQL behaviour, such as
+// ILIKE, CHECK constraints or triggers, unless a test database is configured
+func requirePostgres(t *testing.T) {
+	t.Helper()
+	if os.Getenv(testDatabaseURL) == "" {
+		t.Skipf("set %s to a PostgreSQL database to run this test", testDatabaseURL)
+	}
+}
+
+// setupServiceDB points database.DB at a fresh in-memory SQLite database with
+// the given models migrated, and restores the previous connection afterwards.
+// SQLite has no gen_random_uuid() or regular expression checks, so those are
+// dropped from the schemas and UUID primary keys are generated on create instead.
+// When TEST_DATABASE_URL is set the models are migrated unchanged into a
+// PostgreSQL schema of their own instead.
+func setupServiceDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	if url := os.Getenv(testDatabaseURL); url != "" {
+		return setupPostgresDB(t, url, models...)
+	}
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", strings.ReplaceAll(t.Name(), "/", "_"))
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// One connection keeps the shared in-memory database free of table locks
+	sqlDB.SetMaxOpenConns(1)
+
+	visited := map[*schema.Schema]bool{}
+	var adapt func(s *schema.Schema)
+	adapt = func(s *schema.Schema) {
+		if s == nil || visited[s] {
+			return
+		}
+		visited[s] = true
+		for _, field := range s.Fields {
+			if field.DefaultValue == "gen_random_uuid()" {
+				field.DefaultValue = ""
+				field.HasDefaultValue = false
+				field.DefaultValueInterface = nil
+			}
+			delete(field.TagSettings, "CHECK")
+		}
+		for _, relationship := range s.Relationships.Relations {
+			adapt(relationship.FieldSchema)
+			adapt(relationship.JoinTable)
+		}
+	}
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		adapt(stmt.Schema)
+	}
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("tests:uuid", func(tx *gorm.DB) {
+		if tx.Statement.Schema == nil || tx.Statement.Schema.PrioritizedPrimaryField == nil {
+			return
+		}
+		field := tx.Statement.Schema.PrioritizedPrimaryField
+		if field.FieldType != reflect.TypeOf(uuid.UUID{}) {
+			return
+		}
+		setID := func(value reflect.Value) {
+			if _, zero := field.ValueOf(tx.Statement.Context, value); zero {
+				_ = field.Set(tx.Statement.Context, value, uuid.New())
+			}
+		}
+		switch tx.Statement.ReflectValue.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < tx.Statement.ReflectValue.Len(); i++ {
+				setID(reflect.Indirect(tx.Statement.ReflectValue.Index(i)))
+			}
+		case reflect.Struct:
+			setID(tx.Statement.ReflectValue)
+		}
+	}))
+	require.NoError(t, db.AutoMigrate(models...))
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+	return db
+}
+
+// setupPostgresDB migrates the models into a new schema of the PostgreSQL
+// database at url, points database.DB at it and drops the schema afterwards
+func setupPostgresDB(t *testing.T, url string, models ...interface{}) *gorm.DB {
+	t.Helper()
+
+	gormConfig := &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	}
+	admin, err := gorm.Open(postgres.Open(url), gormConfig)
+	require.NoError(t, err)
+	adminDB, err := admin.DB()
+	require.NoError(t, err)
+	schemaName := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	require.NoError(t, admin.Exec("CREATE SCHEMA "+schemaName).Error)
+
+	connConfig, err := pgx.ParseConfig(url)
+	require.NoError(t, err)
+	connConfig.RuntimeParams["search_path"] = schemaName
+	sqlDB := stdlib.OpenDB(*connConfig)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), gormConfig)
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(models...))
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+		admin.Exec("DROP SCHEMA " + schemaName + " CASCADE")
+		adminDB.Close()
+	})
+	return db
+}
+
+// setupPostRelationTables creates the join tables the post queries read
+// but no migrated model declares in full: post_tags, and the mediables
+// table that Post.Media and the polymorphic Mediable model share. SQLite
+// keeps only the columns of whichever of those is migrated first.
+func setupPostRelationTables(t *testing.T, db *gorm.DB) {
+	t.Helper()
+
+	if !db.Migrator().HasTable("post_tags") {
+		require.NoError(t, db.Exec("CREATE TABLE post_tags (post_id TEXT, tag_id INTEGER)").Error)
+	}
+	if !db.Migrator().HasTable("mediables") {
+		require.NoError(t, db.AutoMigrate(&models.Mediable{}))
+	}
+	for _, column := range []string{"media_id", "post_id", "mediable_id", "mediable_type", "group", "sort_order"} {
+		if !db.Migrator().HasColumn("mediables", column) {
+			require.NoError(t, db.Exec(`ALTER TABLE mediables ADD COLUMN "`+column+`" TEXT`).Error)
+		}
+	}
+}
+
+// setupServiceRedis points the services at a fresh in-memory Redis server and
+// restores the previous client afterwards
+func setupServiceRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewRedisService(redis.RedisConfig{Addr: server.Addr()})
+
+	previous := services.GlobalRedisClient
+	services.GlobalRedisClient = client
+	t.Cleanup(func() {
+		services.GlobalRedisClient = previous
+		client.Close()
+	})
+	return server
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-next/internal/services"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ageTrendingEpochs moves the reference time of every window back by age
+func ageTrendingEpochs(t *testing.T, server *miniredis.Miniredis, age time.Duration) {
+	t.Helper()
+	for name := range services.TrendingWindows {
+		require.NoError(t, server.Set("trending:"+name+":epoch", strconv.FormatInt(time.Now().Add(-age).Unix(), 10)))
+	}
+}
+
+func TestTrendingRanksByWeightedInteractions(t *testing.T) {
+	setupServiceRedis(t)
+
+	viewed, commented := uuid.New(), uuid.New()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, services.TrendingSvc.Record(viewed, services.TrendingEventView))
+	}
+	require.NoError(t, services.TrendingSvc.Record(commented, services.TrendingEventComment))
+
+	for name := range services.TrendingWindows {
+		ids, err := services.TrendingSvc.GetTrendingPostIDs(name, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{commented, viewed}, ids, name)
+	}
+
+	_, err := services.TrendingSvc.GetTrendingPostIDs("30d", 10)
+	assert.ErrorIs(t, err, services.ErrInvalidTrendingWindow)
+	assert.Error(t, services.TrendingSvc.Record(viewed, "share"))
+}
+
+func TestTrendingRescaleDropsCooledPosts(t *testing.T) {
+	server := setupServiceRedis(t)
+
+	postID := uuid.New()
+	require.NoError(t, services.TrendingSvc.Record(postID, services.TrendingEventView))
+
+	// Two days is eight half-lives of the 24h window but barely one of the 7d window
+	ageTrendingEpochs(t, server, 48*time.Hour)
+	require.NoError(t, services.TrendingSvc.Rescale(context.Background()))
+
+	daily, err := services.TrendingSvc.GetTrendingPostIDs("24h", 10)
+	require.NoError(t, err)
+	assert.Empty(t, daily)
+
+	weekly, err := services.TrendingSvc.GetTrendingPostIDs("7d", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{postID}, weekly)
+
+	score, err := server.ZScore("trending:7d", postID.String())
+	require.NoError(t, err)
+	assert.InDelta(t, 0.45, score, 0.01)
+
+	epoch, err := server.Get("trending:7d:epoch")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Unix(), mustParseInt(t, epoch), 5)
+}
+
+func TestTrendingRescaleSkipsWhileAnotherInstanceHoldsTheLock(t *testing.T) {
+	server := setupServiceRedis(t)
+
+	postID := uuid.New()
+	require.NoError(t, services.TrendingSvc.Record(postID, services.TrendingEventView))
+	ageTrendingEpochs(t, server, 48*time.Hour)
+
+	require.NoError(t, server.Set("lock:job:trending_rescale", "another-instance"))
+	require.NoError(t, services.TrendingSvc.Rescale(context.Background()))
+
+	score, err := server.ZScore("trending:24h", postID.String())
+	require.NoError(t, err)
+	assert.InDelta(t, 1, score, 0.01)
+
+	lock, err := server.Get("lock:job:trending_rescale")
+	require.NoError(t, err)
+	assert.Equal(t, "another-instance", lock)
+
+	// Once the holder is gone the rescale runs and releases its own lock
+	server.Del("lock:job:trending_rescale")
+	require.NoError(t, services.TrendingSvc.Rescale(context.Background()))
+	assert.False(t, server.Exists("trending:24h"))
+	assert.False(t, server.Exists("lock:job:trending_rescale"))
+}
+
+func TestTrendingRecordWeighsEventsAgainstTheStoredEpoch(t *testing.T) {
+	server := setupServiceRedis(t)
+
+	// One half-life after the epoch an event counts twice as much
+	ageTrendingEpochs(t, server, 6*time.Hour)
+	postID := uuid.New()
+	require.NoError(t, services.TrendingSvc.Record(postID, services.TrendingEventReaction))
+	score, err := server.ZScore("trending:24h", postID.String())
+	require.NoError(t, err)
+	assert.InDelta(t, 6, score, 0.01)
+
+	// Rebasing onto the current time keeps the decayed score, and later
+	// events are weighted against the new epoch
+	require.NoError(t, services.TrendingSvc.Rescale(context.Background()))
+	score, err = server.ZScore("trending:24h", postID.String())
+	require.NoError(t, err)
+	assert.InDelta(t, 3, score, 0.01)
+	require.NoError(t, services.TrendingSvc.Record(postID, services.TrendingEventReaction))
+	score, err = server.ZScore("trending:24h", postID.String())
+	require.NoError(t, err)
+	assert.InDelta(t, 6, score, 0.01)
+}
+
+func mustParseInt(t *testing.T, value string) int64 {
+	t.Helper()
+	n, err := strconv.ParseInt(value, 10, 64)
+	require.NoError(t, err)
+	return n
+}
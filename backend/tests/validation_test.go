@@ -9,66 +9,68 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 
-	internal "go-next/internal"
+	controllers "go-next/internal/http/controllers"
+	"go-next/internal/http/middleware"
+	"go-next/internal/http/responses"
+	"go-next/internal/services"
 )
 
-type ValidationErrorResponse struct {
-	Message string              `json:"message"`
-	Errors  map[string][]string `json:"errors"`
+func setupValidationRouter() *gin.Engine {
+	r := setupRouter()
+	postHandler := controllers.NewPostHandler(services.PostSvc)
+	r.POST("/api/posts", middleware.JWTMiddleware(), postHandler.CreatePost)
+	return r
 }
 
-func setupValidationTestDB() *gorm.DB {
-	db, _ := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	db.AutoMigrate(&internal.User{}, &internal.Post{}, &internal.Comment{}, &internal.Category{})
-	return db
-}
+// validationErrorFields lists the fields named in a validation error response
+func validationErrorFields(t *testing.T, w *httptest.ResponseRecorder) (string, []string) {
+	t.Helper()
 
-func setupValidationRouter(db *gorm.DB) *gin.Engine {
-	internal.DB = db
-	r := gin.Default()
-	r.POST("/api/register", internal.RegisterHandlerGin)
-	r.POST("/api/posts", internal.CreatePost)
-	return r
+	var resp responses.ValidationErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	fields := make([]string, 0, len(resp.Errors))
+	for _, e := range resp.Errors {
+		fields = append(fields, e.Field)
+	}
+	return resp.Message, fields
 }
 
 func TestRegisterValidationErrors(t *testing.T) {
-	db := setupValidationTestDB()
-	r := setupValidationRouter(db)
+	setupTestDB(t)
+	r := setupValidationRouter()
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/api/register", bytes.NewBuffer([]byte(`{}`)))
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, 400, w.Code)
-	var resp ValidationErrorResponse
-	json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.Equal(t, "Validation failed", resp.Message)
-	assert.Contains(t, resp.Errors, "Username")
-	assert.Contains(t, resp.Errors, "Email")
-	assert.Contains(t, resp.Errors, "Password")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	message, fields := validationErrorFields(t, w)
+	assert.Equal(t, "The given data was invalid.", message)
+	assert.Contains(t, fields, "Username")
+	assert.Contains(t, fields, "Email")
+	assert.Contains(t, fields, "Password")
 }
 
 func TestPostCreateValidationErrors(t *testing.T) {
-	db := setupValidationTestDB()
-	r := setupValidationRouter(db)
+	setupTestDB(t)
+	r := setupValidationRouter()
+	token := registerAndLogin(t, r, "testuser", "test@example.com", "password")
 
 	w := httptest.NewRecorder()
-	invalid := map[string]interface{}{"title": "", "content": "", "user_id": 0, "category_id": 0}
+	invalid := map[string]interface{}{"title": "", "content": ""}
 	body, _ := json.Marshal(invalid)
 	req, _ := http.NewRequest("POST", "/api/posts", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, 400, w.Code)
-	var resp ValidationErrorResponse
-	json.Unmarshal(w.Body.Bytes(), &resp)
-	assert.Equal(t, "Validation failed", resp.Message)
-	assert.Contains(t, resp.Errors, "Title")
-	assert.Contains(t, resp.Errors, "Content")
-	assert.Contains(t, resp.Errors, "UserID")
-	assert.Contains(t, resp.Errors, "CategoryID")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	message, fields := validationErrorFields(t, w)
+	assert.Equal(t, "The given data was invalid.", message)
+	assert.Contains(t, fields, "Title")
+	assert.Contains(t, fields, "Content")
+	assert.Contains(t, fields, "UserID")
+	assert.Contains(t, fields, "CategoryID")
 }
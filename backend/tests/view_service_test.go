@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go-next/internal/models"
+	"go-next/internal/services"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBotUserAgent(t *testing.T) {
+	bots := []string{
+		"",
+		"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		"facebookexternalhit/1.1",
+		"curl/8.4.0",
+		"python-requests/2.31.0",
+		"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36",
+	}
+	for _, ua := range bots {
+		assert.True(t, services.IsBotUserAgent(ua), ua)
+	}
+
+	browsers := []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+	}
+	for _, ua := range browsers {
+		assert.False(t, services.IsBotUserAgent(ua), ua)
+	}
+}
+
+func TestVisitorFingerprint(t *testing.T) {
+	a := services.Visitor{IP: "203.0.113.7", UserAgent: "Firefox"}
+	b := services.Visitor{IP: "203.0.113.7", UserAgent: "Chrome"}
+
+	assert.Equal(t, a.Fingerprint(), a.Fingerprint())
+	assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+	assert.NotContains(t, a.Fingerprint(), "203.0.113.7")
+
+	userID := uuid.New()
+	a.UserID, b.UserID = &userID, &userID
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+}
+
+func recordViews(t *testing.T, postID uuid.UUID, visitors ...string) {
+	t.Helper()
+	for _, ip := range visitors {
+		_, err := services.ViewSvc.RecordView(postID, services.Visitor{IP: ip, UserAgent: "Firefox"})
+		require.NoError(t, err)
+	}
+}
+
+func TestViewFlushCountsEachViewOnce(t *testing.T) {
+	db := setupServiceDB(t, &models.Post{}, &models.PostViewDaily{})
+	setupServiceRedis(t)
+
+	post := models.Post{Title: "Views", Slug: "views", Content: "body"}
+	require.NoError(t, db.Create(&post).Error)
+
+	recordViews(t, post.ID, "203.0.113.1", "203.0.113.1", "203.0.113.2")
+
+	// Concurrent flushes claim the buffer once between them
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := services.ViewSvc.Flush(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	flushed, err := services.ViewSvc.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, flushed)
+
+	var daily models.PostViewDaily
+	require.NoError(t, db.Where("post_id = ?", post.ID).First(&daily).Error)
+	assert.Equal(t, int64(3), daily.Views)
+	assert.Equal(t, int64(2), daily.UniqueVisitors)
+
+	require.NoError(t, db.First(&post, "id = ?", post.ID).Error)
+	assert.Equal(t, int64(2), post.ViewCount)
+}
+
+func TestViewFlushRestoresCountsWhenTheWriteFails(t *testing.T) {
+	db := setupServiceDB(t, &models.Post{}, &models.PostViewDaily{})
+	setupServiceRedis(t)
+
+	post := models.Post{Title: "Views", Slug: "views", Content: "body"}
+	require.NoError(t, db.Create(&post).Error)
+
+	recordViews(t, post.ID, "203.0.113.1", "203.0.113.2")
+
+	require.NoError(t, db.Migrator().DropTable(&models.PostViewDaily{}))
+	_, err := services.ViewSvc.Flush(context.Background())
+	require.Error(t, err)
+
+	require.NoError(t, db.AutoMigrate(&models.PostViewDaily{}))
+	flushed, err := services.ViewSvc.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, flushed)
+
+	var daily models.PostViewDaily
+	require.NoError(t, db.Where("post_id = ?", post.ID).First(&daily).Error)
+	assert.Equal(t, int64(2), daily.Views)
+	assert.Equal(t, int64(2), daily.UniqueVisitors)
+}